@@ -0,0 +1,163 @@
+// Command worker runs the extraction worker pool without the web server, so
+// extraction can be scaled separately from the site. Start the web server
+// with EXTRACTION_DISABLE_IN_PROCESS_WORKER=true when using it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/honeycombio/otel-config-go/otelconfig"
+	"github.com/mr-flannery/go-recipe-book/src/config"
+	"github.com/mr-flannery/go-recipe-book/src/db"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store/postgres"
+
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+func main() {
+	ctx := context.Background()
+
+	otelShutdown, err := otelconfig.ConfigureOpenTelemetry()
+	if err != nil {
+		slog.Warn("Failed to configure OpenTelemetry, continuing without tracing", "error", err)
+	} else {
+		defer otelShutdown()
+		slog.Info("OpenTelemetry configured")
+	}
+
+	logShutdown, err := logging.InitOTLPLogging(ctx)
+	if err != nil {
+		slog.Warn("Failed to configure OTLP log exporter, continuing with stdout only", "error", err)
+	} else {
+		defer logShutdown(ctx)
+	}
+
+	// The health endpoint listens on PORT so platforms that probe the
+	// service's port (Railway) can check the worker like the web server.
+	addr := ":8081"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+
+	slog.Info("Loading configuration...")
+	config := config.GetConfig()
+	if config.Extraction.OpenRouterAPIKey == "" {
+		slog.Error("OPENROUTER_API_KEY must be set to run the extraction worker")
+		panic("OPENROUTER_API_KEY must be set")
+	}
+
+	// Migrations take a database lock, so it doesn't matter whether the web
+	// server or the worker is deployed first.
+	slog.Info("Running migrations...")
+	err = db.RunMigrations()
+	if err != nil {
+		slog.Error("Failed to run migrations", "error", err)
+		panic(err)
+	}
+
+	slog.Info("Initializing database connection pool...")
+	database, err := db.InitPool()
+	if err != nil {
+		slog.Error("Failed to initialize database pool", "error", err)
+		panic(err)
+	}
+	defer db.ClosePool()
+
+	authStore := postgres.NewAuthStore(database)
+	recipeStore := postgres.NewRecipeStore(database)
+	tagStore := postgres.NewTagStore(database)
+	userPreferencesStore := postgres.NewUserPreferencesStore(database)
+	extractionJobStore := postgres.NewExtractionJobStore(database)
+	extractionCacheStore := postgres.NewExtractionCacheStore(database)
+	notificationStore := postgres.NewNotificationStore(database)
+
+	// Emails go to the outbox, which the web server delivers from.
+	mailClient := mail.NewOutboxClient(postgres.NewEmailOutboxStore(database))
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	concurrency := config.Extraction.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	worker := extraction.NewWorker(extraction.WorkerConfig{
+		Concurrency:      concurrency,
+		PollInterval:     5 * time.Second,
+		MaxJobsPerUser:   config.Extraction.MaxJobsPerUser,
+		OpenRouterAPIKey: config.Extraction.OpenRouterAPIKey,
+		BaseURL:          baseURL,
+		Experiment: extraction.Experiment{
+			Control:        extraction.Variant{PromptVersion: config.Extraction.PromptVersion, Model: config.Extraction.Model},
+			Candidate:      extraction.Variant{PromptVersion: config.Extraction.Experiment.PromptVersion, Model: config.Extraction.Experiment.Model},
+			CandidateShare: config.Extraction.Experiment.Share,
+		},
+	}, extractionJobStore, extractionCacheStore, recipeStore, tagStore, authStore, userPreferencesStore, notifications.NewNotifier(notificationStore, mailClient))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		commit := os.Getenv("COMMIT_HASH")
+		if commit == "" {
+			commit = "dev"
+		}
+		status := http.StatusOK
+		response := map[string]any{
+			"success":   true,
+			"worker_id": worker.ID(),
+			"commit":    commit,
+		}
+
+		pingCtx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := database.PingContext(pingCtx); err != nil {
+			slog.Warn("Health check failed to reach the database", "error", err)
+			status = http.StatusServiceUnavailable
+			response["success"] = false
+			response["message"] = "database unreachable"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	// On SIGTERM the worker stops claiming jobs, lets in-flight jobs finish
+	// within its stop timeout and hands the rest back to the queue.
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	worker.Start()
+	slog.Info("Extraction worker started", "worker_id", worker.ID(), "concurrency", concurrency, "health_address", addr)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Health server failed to start", "error", err)
+		}
+	}()
+
+	<-signalCtx.Done()
+
+	slog.Info("Shutting down extraction worker...")
+	worker.Stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down health server", "error", err)
+	}
+}
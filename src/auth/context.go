@@ -22,9 +22,11 @@ type UserInfo struct {
 	Username   string
 	UserID     int
 	Theme      string
+	// UnreadNotifications is shown next to the bell in the navbar.
+	UnreadNotifications int
 }
 
-func UserContextMiddleware(authStore store.AuthStore, prefsStore store.UserPreferencesStore) func(http.Handler) http.Handler {
+func UserContextMiddleware(authStore store.AuthStore, prefsStore store.UserPreferencesStore, notificationStore store.NotificationStore) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -47,12 +49,18 @@ func UserContextMiddleware(authStore store.AuthStore, prefsStore store.UserPrefe
 					theme = prefs.Theme
 				}
 
+				unread, err := notificationStore.CountUnread(ctx, user.ID)
+				if err != nil {
+					slog.Warn("Failed to count unread notifications", "userID", user.ID, "error", err)
+				}
+
 				userInfo := &UserInfo{
-					IsLoggedIn: true,
-					IsAdmin:    user.IsAdmin,
-					Username:   user.Username,
-					UserID:     user.ID,
-					Theme:      theme,
+					IsLoggedIn:          true,
+					IsAdmin:             user.IsAdmin,
+					Username:            user.Username,
+					UserID:              user.ID,
+					Theme:               theme,
+					UnreadNotifications: unread,
 				}
 				ctx = context.WithValue(ctx, userInfoKey, userInfo)
 				r = r.WithContext(ctx)
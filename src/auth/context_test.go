@@ -32,7 +32,13 @@ func TestUserContextMiddleware_PopulatesContextWhenSessionIsValid(t *testing.T)
 		},
 	}
 
-	middleware := UserContextMiddleware(mockAuthStore, mockPrefsStore)
+	mockNotificationStore := &mocks.MockNotificationStore{
+		CountUnreadFunc: func(ctx context.Context, userID int) (int, error) {
+			return 3, nil
+		},
+	}
+
+	middleware := UserContextMiddleware(mockAuthStore, mockPrefsStore, mockNotificationStore)
 	var capturedUserInfo *UserInfo
 
 	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,12 +70,16 @@ func TestUserContextMiddleware_PopulatesContextWhenSessionIsValid(t *testing.T)
 	if capturedUserInfo.Theme != models.ThemeEditorial{
 		t.Errorf("expected theme '%s', got %s", models.ThemeEditorial, capturedUserInfo.Theme)
 	}
+	if capturedUserInfo.UnreadNotifications != 3 {
+		t.Errorf("expected 3 unread notifications, got %d", capturedUserInfo.UnreadNotifications)
+	}
 }
 
 func TestUserContextMiddleware_SetsGuestInfoWhenNoSessionPresent(t *testing.T) {
 	mockAuthStore := &mocks.MockAuthStore{}
 	mockPrefsStore := &mocks.MockUserPreferencesStore{}
-	middleware := UserContextMiddleware(mockAuthStore, mockPrefsStore)
+	mockNotificationStore := &mocks.MockNotificationStore{}
+	middleware := UserContextMiddleware(mockAuthStore, mockPrefsStore, mockNotificationStore)
 	var capturedUserInfo *UserInfo
 
 	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
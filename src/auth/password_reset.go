@@ -34,7 +34,10 @@ func HashResetToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func CreatePasswordResetToken(ctx context.Context, authStore store.AuthStore, userID int) (string, error) {
+// CreatePasswordResetToken stores a new reset token for the user and returns
+// it. If resetEmail is set, the email it builds for the token's reset URL is
+// queued together with the token.
+func CreatePasswordResetToken(ctx context.Context, authStore store.AuthStore, userID int, resetEmail func(resetURL string) (*store.OutgoingEmail, error)) (string, error) {
 	plainToken, hashedToken, err := GenerateResetToken()
 	if err != nil {
 		return "", err
@@ -42,7 +45,15 @@ func CreatePasswordResetToken(ctx context.Context, authStore store.AuthStore, us
 
 	expiresAt := time.Now().Add(ResetTokenDuration)
 
-	err = authStore.CreatePasswordResetToken(ctx, userID, hashedToken, expiresAt)
+	var notification *store.OutgoingEmail
+	if resetEmail != nil {
+		notification, err = resetEmail(GetResetURL(plainToken))
+		if err != nil {
+			return "", fmt.Errorf("failed to build password reset email: %w", err)
+		}
+	}
+
+	err = authStore.CreatePasswordResetToken(ctx, userID, hashedToken, expiresAt, notification)
 	if err != nil {
 		return "", fmt.Errorf("failed to store password reset token: %w", err)
 	}
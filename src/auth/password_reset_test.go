@@ -69,7 +69,7 @@ func TestCreatePasswordResetToken_StoresTokenAndReturnsPlaintext(t *testing.T) {
 	var storedExpiresAt time.Time
 
 	mockStore := &mocks.MockAuthStore{
-		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
 			storedUserID = userID
 			storedTokenHash = tokenHash
 			storedExpiresAt = expiresAt
@@ -77,7 +77,7 @@ func TestCreatePasswordResetToken_StoresTokenAndReturnsPlaintext(t *testing.T) {
 		},
 	}
 
-	plainToken, err := CreatePasswordResetToken(context.Background(), mockStore, 42)
+	plainToken, err := CreatePasswordResetToken(context.Background(), mockStore, 42, nil)
 	if err != nil {
 		t.Fatalf("failed to create password reset token: %v", err)
 	}
@@ -106,12 +106,12 @@ func TestCreatePasswordResetToken_StoresTokenAndReturnsPlaintext(t *testing.T) {
 
 func TestCreatePasswordResetToken_ReturnsErrorOnStoreFailure(t *testing.T) {
 	mockStore := &mocks.MockAuthStore{
-		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
 			return errors.New("database error")
 		},
 	}
 
-	_, err := CreatePasswordResetToken(context.Background(), mockStore, 42)
+	_, err := CreatePasswordResetToken(context.Background(), mockStore, 42, nil)
 	if err == nil {
 		t.Error("expected error when store fails")
 	}
@@ -20,7 +20,9 @@ type RegistrationRequest struct {
 	ReviewedAt   *time.Time
 }
 
-func CreateRegistrationRequest(ctx context.Context, authStore store.AuthStore, username, email, password string) error {
+// CreateRegistrationRequest stores a pending registration. notification, if
+// set, is queued together with it.
+func CreateRegistrationRequest(ctx context.Context, authStore store.AuthStore, username, email, password string, notification *store.OutgoingEmail) error {
 	if err := ValidatePasswordStrength(password); err != nil {
 		return fmt.Errorf("password validation failed: %w", err)
 	}
@@ -30,7 +32,7 @@ func CreateRegistrationRequest(ctx context.Context, authStore store.AuthStore, u
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return authStore.CreateRegistrationRequest(ctx, username, email, passwordHash)
+	return authStore.CreateRegistrationRequest(ctx, username, email, passwordHash, notification)
 }
 
 func GetPendingRegistrations(ctx context.Context, authStore store.AuthStore) ([]RegistrationRequest, error) {
@@ -97,8 +99,8 @@ func CountAllRegistrations(ctx context.Context, authStore store.AuthStore) (int,
 	return authStore.CountAllRegistrations(ctx)
 }
 
-func ApproveRegistration(ctx context.Context, authStore store.AuthStore, requestID int, adminID int) error {
-	return authStore.ApproveRegistration(ctx, requestID, adminID)
+func ApproveRegistration(ctx context.Context, authStore store.AuthStore, requestID int, adminID int, notification *store.OutgoingEmail) error {
+	return authStore.ApproveRegistration(ctx, requestID, adminID, notification)
 }
 
 func RejectRegistration(ctx context.Context, authStore store.AuthStore, requestID int, adminID int) error {
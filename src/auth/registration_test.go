@@ -12,7 +12,7 @@ import (
 func TestCreateRegistrationRequest_CreatesRequestWhenPasswordIsStrong(t *testing.T) {
 	var capturedUsername, capturedEmail, capturedHash string
 	mockStore := &mocks.MockAuthStore{
-		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string) error {
+		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
 			capturedUsername = username
 			capturedEmail = email
 			capturedHash = passwordHash
@@ -20,7 +20,7 @@ func TestCreateRegistrationRequest_CreatesRequestWhenPasswordIsStrong(t *testing
 		},
 	}
 
-	err := CreateRegistrationRequest(context.Background(), mockStore, "newuser", "new@example.com", "ValidPassword123!")
+	err := CreateRegistrationRequest(context.Background(), mockStore, "newuser", "new@example.com", "ValidPassword123!", nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -38,7 +38,7 @@ func TestCreateRegistrationRequest_CreatesRequestWhenPasswordIsStrong(t *testing
 func TestCreateRegistrationRequest_ReturnsErrorWhenPasswordIsWeak(t *testing.T) {
 	mockStore := &mocks.MockAuthStore{}
 
-	err := CreateRegistrationRequest(context.Background(), mockStore, "user", "user@example.com", "weak")
+	err := CreateRegistrationRequest(context.Background(), mockStore, "user", "user@example.com", "weak", nil)
 	if err == nil {
 		t.Fatal("expected error for weak password, got nil")
 	}
@@ -46,12 +46,12 @@ func TestCreateRegistrationRequest_ReturnsErrorWhenPasswordIsWeak(t *testing.T)
 
 func TestCreateRegistrationRequest_ReturnsErrorWhenStoreFails(t *testing.T) {
 	mockStore := &mocks.MockAuthStore{
-		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string) error {
+		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
 			return errors.New("duplicate email")
 		},
 	}
 
-	err := CreateRegistrationRequest(context.Background(), mockStore, "user", "existing@example.com", "StrongPassword123!")
+	err := CreateRegistrationRequest(context.Background(), mockStore, "user", "existing@example.com", "StrongPassword123!", nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -117,14 +117,14 @@ func TestGetPendingRegistrations_ReturnsErrorWhenStoreFails(t *testing.T) {
 func TestApproveRegistration_ApprovesRequestAndRecordsAdminID(t *testing.T) {
 	var approvedID, adminID int
 	mockStore := &mocks.MockAuthStore{
-		ApproveRegistrationFunc: func(ctx context.Context, requestID, aID int) error {
+		ApproveRegistrationFunc: func(ctx context.Context, requestID, aID int, notification *store.OutgoingEmail) error {
 			approvedID = requestID
 			adminID = aID
 			return nil
 		},
 	}
 
-	err := ApproveRegistration(context.Background(), mockStore, 5, 1)
+	err := ApproveRegistration(context.Background(), mockStore, 5, 1, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/mr-flannery/go-recipe-book/src/utils"
@@ -37,8 +38,19 @@ type Config struct {
 		EncryptionKey string   `yaml:"encryption_key"`
 	} `yaml:"api"`
 	Mail struct {
-		Domain string `yaml:"domain"`
-		ApiKey string `yaml:"api_key"`
+		// Provider is "maileroo" or "smtp". Empty uses Maileroo, or logs
+		// emails instead of sending them in development mode.
+		Provider string `yaml:"provider"`
+		Domain   string `yaml:"domain"`
+		ApiKey   string `yaml:"api_key"`
+		// UnsubscribeKey signs the unsubscribe links in digest emails.
+		UnsubscribeKey string `yaml:"unsubscribe_key"`
+		SMTP           struct {
+			Host     string `yaml:"host"`
+			Port     int    `yaml:"port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"smtp"`
 	} `yaml:"mail"`
 	Imprint struct {
 		Name    string `yaml:"name"`
@@ -48,6 +60,22 @@ type Config struct {
 	} `yaml:"imprint"`
 	Extraction struct {
 		OpenRouterAPIKey string `yaml:"openrouter_api_key"`
+		// DisableInProcessWorker keeps the web server from processing
+		// extraction jobs itself, for deployments that run cmd/worker.
+		DisableInProcessWorker bool `yaml:"disable_in_process_worker"`
+		Concurrency            int  `yaml:"concurrency"`
+		MaxJobsPerUser         int  `yaml:"max_jobs_per_user"`
+		// PromptVersion and Model are what jobs are extracted with. Empty
+		// values use the defaults of the extraction package.
+		PromptVersion string `yaml:"prompt_version"`
+		Model         string `yaml:"model"`
+		// Experiment extracts Share percent of jobs with another prompt
+		// version and/or model, to compare their feedback on /admin/feedback.
+		Experiment struct {
+			PromptVersion string `yaml:"prompt_version"`
+			Model         string `yaml:"model"`
+			Share         int    `yaml:"share"`
+		} `yaml:"experiment"`
 	} `yaml:"extraction"`
 }
 
@@ -129,6 +157,28 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("MAIL_API_KEY"); v != "" {
 		cfg.Mail.ApiKey = v
 	}
+	if v := os.Getenv("MAIL_PROVIDER"); v != "" {
+		cfg.Mail.Provider = v
+	}
+	if v := os.Getenv("MAIL_UNSUBSCRIBE_KEY"); v != "" {
+		cfg.Mail.UnsubscribeKey = v
+	}
+	if v := os.Getenv("SMTP_HOST"); v != "" {
+		cfg.Mail.SMTP.Host = v
+	}
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Mail.SMTP.Port = n
+		} else {
+			slog.Warn("Ignoring invalid SMTP_PORT", "value", v)
+		}
+	}
+	if v := os.Getenv("SMTP_USERNAME"); v != "" {
+		cfg.Mail.SMTP.Username = v
+	}
+	if v := os.Getenv("SMTP_PASSWORD"); v != "" {
+		cfg.Mail.SMTP.Password = v
+	}
 	if v := os.Getenv("IMPRINT_NAME"); v != "" {
 		cfg.Imprint.Name = v
 	}
@@ -144,4 +194,44 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("OPENROUTER_API_KEY"); v != "" {
 		cfg.Extraction.OpenRouterAPIKey = v
 	}
+	if v := os.Getenv("EXTRACTION_DISABLE_IN_PROCESS_WORKER"); v != "" {
+		if disabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Extraction.DisableInProcessWorker = disabled
+		} else {
+			slog.Warn("Ignoring invalid EXTRACTION_DISABLE_IN_PROCESS_WORKER", "value", v)
+		}
+	}
+	if v := os.Getenv("EXTRACTION_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Extraction.Concurrency = n
+		} else {
+			slog.Warn("Ignoring invalid EXTRACTION_CONCURRENCY", "value", v)
+		}
+	}
+	if v := os.Getenv("EXTRACTION_MAX_JOBS_PER_USER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Extraction.MaxJobsPerUser = n
+		} else {
+			slog.Warn("Ignoring invalid EXTRACTION_MAX_JOBS_PER_USER", "value", v)
+		}
+	}
+	if v := os.Getenv("EXTRACTION_PROMPT_VERSION"); v != "" {
+		cfg.Extraction.PromptVersion = v
+	}
+	if v := os.Getenv("EXTRACTION_MODEL"); v != "" {
+		cfg.Extraction.Model = v
+	}
+	if v := os.Getenv("EXTRACTION_EXPERIMENT_PROMPT_VERSION"); v != "" {
+		cfg.Extraction.Experiment.PromptVersion = v
+	}
+	if v := os.Getenv("EXTRACTION_EXPERIMENT_MODEL"); v != "" {
+		cfg.Extraction.Experiment.Model = v
+	}
+	if v := os.Getenv("EXTRACTION_EXPERIMENT_SHARE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Extraction.Experiment.Share = n
+		} else {
+			slog.Warn("Ignoring invalid EXTRACTION_EXPERIMENT_SHARE", "value", v)
+		}
+	}
 }
@@ -192,3 +192,44 @@ server:
 		t.Errorf("expected Server.Port = 9090, got %d", cfg.Server.Port)
 	}
 }
+
+func TestApplyEnvOverrides_ExtractionWorkerSettings(t *testing.T) {
+	t.Setenv("EXTRACTION_DISABLE_IN_PROCESS_WORKER", "true")
+	t.Setenv("EXTRACTION_CONCURRENCY", "4")
+	t.Setenv("EXTRACTION_MAX_JOBS_PER_USER", "not-a-number")
+
+	var cfg Config
+	cfg.Extraction.MaxJobsPerUser = 2
+	applyEnvOverrides(&cfg)
+
+	if !cfg.Extraction.DisableInProcessWorker {
+		t.Error("expected Extraction.DisableInProcessWorker to be true")
+	}
+	if cfg.Extraction.Concurrency != 4 {
+		t.Errorf("expected Extraction.Concurrency = 4, got %d", cfg.Extraction.Concurrency)
+	}
+	if cfg.Extraction.MaxJobsPerUser != 2 {
+		t.Errorf("expected invalid value to be ignored, got Extraction.MaxJobsPerUser = %d", cfg.Extraction.MaxJobsPerUser)
+	}
+}
+
+func TestApplyEnvOverrides_SMTPSettings(t *testing.T) {
+	t.Setenv("MAIL_PROVIDER", "smtp")
+	t.Setenv("SMTP_HOST", "localhost")
+	t.Setenv("SMTP_PORT", "1025")
+	t.Setenv("SMTP_USERNAME", "user")
+	t.Setenv("SMTP_PASSWORD", "secret")
+
+	var cfg Config
+	applyEnvOverrides(&cfg)
+
+	if cfg.Mail.Provider != "smtp" {
+		t.Errorf("expected Mail.Provider = smtp, got %s", cfg.Mail.Provider)
+	}
+	if cfg.Mail.SMTP.Host != "localhost" || cfg.Mail.SMTP.Port != 1025 {
+		t.Errorf("expected SMTP server localhost:1025, got %s:%d", cfg.Mail.SMTP.Host, cfg.Mail.SMTP.Port)
+	}
+	if cfg.Mail.SMTP.Username != "user" || cfg.Mail.SMTP.Password != "secret" {
+		t.Errorf("expected SMTP credentials from the environment, got %s/%s", cfg.Mail.SMTP.Username, cfg.Mail.SMTP.Password)
+	}
+}
@@ -18,7 +18,8 @@ import (
 
 var pool *sql.DB
 
-func getConnectionString() string {
+// ConnectionString returns the connection string the pool is opened with.
+func ConnectionString() string {
 	cfg := config.GetConfig()
 
 	if cfg.DatabasePublicURL != "" {
@@ -43,7 +44,7 @@ func InitPool() (*sql.DB, error) {
 		return pool, nil
 	}
 
-	connectionString := getConnectionString()
+	connectionString := ConnectionString()
 
 	db, err := otelsql.Open("postgres", connectionString,
 		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
@@ -75,7 +76,7 @@ func ClosePool() error {
 }
 
 func GetConnection() (*sql.DB, error) {
-	return sql.Open("postgres", getConnectionString())
+	return sql.Open("postgres", ConnectionString())
 }
 
 func RunMigrations() error {
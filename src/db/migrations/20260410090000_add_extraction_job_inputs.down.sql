@@ -0,0 +1,14 @@
+DELETE FROM extraction_jobs WHERE job_type = 'pdf';
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image'));
+
+ALTER TABLE extraction_jobs ADD COLUMN input_data BYTEA;
+
+UPDATE extraction_jobs ej
+SET input_data = eji.data
+FROM extraction_job_inputs eji
+WHERE eji.job_id = ej.id AND eji.position = 0;
+
+DROP TABLE IF EXISTS extraction_job_inputs;
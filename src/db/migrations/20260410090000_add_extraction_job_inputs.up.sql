@@ -0,0 +1,21 @@
+CREATE TABLE extraction_job_inputs (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL REFERENCES extraction_jobs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    data BYTEA NOT NULL,
+    UNIQUE(job_id, position)
+);
+
+-- Existing image jobs stored a single blob without a content type; the worker
+-- sniffs the type for 'application/octet-stream' inputs.
+INSERT INTO extraction_job_inputs (job_id, position, content_type, data)
+SELECT id, 0, 'application/octet-stream', input_data
+FROM extraction_jobs
+WHERE input_data IS NOT NULL;
+
+ALTER TABLE extraction_jobs DROP COLUMN input_data;
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf'));
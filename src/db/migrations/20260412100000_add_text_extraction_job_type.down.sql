@@ -0,0 +1,5 @@
+DELETE FROM extraction_jobs WHERE job_type = 'text';
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf'));
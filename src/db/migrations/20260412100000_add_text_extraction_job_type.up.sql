@@ -0,0 +1,3 @@
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf', 'text'));
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS extraction_quotas;
+
+DROP INDEX IF EXISTS idx_extraction_jobs_user_created;
+
+ALTER TABLE extraction_jobs
+    DROP COLUMN IF EXISTS prompt_tokens,
+    DROP COLUMN IF EXISTS completion_tokens,
+    DROP COLUMN IF EXISTS audio_tokens,
+    DROP COLUMN IF EXISTS cost_usd;
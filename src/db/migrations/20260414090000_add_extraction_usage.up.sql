@@ -0,0 +1,14 @@
+ALTER TABLE extraction_jobs
+    ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN audio_tokens INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_extraction_jobs_user_created ON extraction_jobs(user_id, created_at);
+
+CREATE TABLE extraction_quotas (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    monthly_job_limit INTEGER CHECK (monthly_job_limit >= 0),
+    monthly_cost_limit_usd NUMERIC(10, 2) CHECK (monthly_cost_limit_usd >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
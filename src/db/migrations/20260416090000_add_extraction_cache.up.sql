@@ -0,0 +1,9 @@
+CREATE TABLE extraction_cache (
+    cache_key TEXT PRIMARY KEY,
+    result JSONB NOT NULL,
+    recipe_id INTEGER REFERENCES recipes(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_extraction_cache_expires ON extraction_cache(expires_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_extraction_jobs_batch;
+
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS extraction_batches;
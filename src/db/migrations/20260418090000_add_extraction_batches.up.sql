@@ -0,0 +1,13 @@
+CREATE TABLE extraction_batches (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_extraction_batches_user ON extraction_batches(user_id, created_at DESC);
+
+ALTER TABLE extraction_jobs ADD COLUMN batch_id INTEGER REFERENCES extraction_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_extraction_jobs_batch ON extraction_jobs(batch_id) WHERE batch_id IS NOT NULL;
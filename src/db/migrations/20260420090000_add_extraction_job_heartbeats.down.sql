@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_extraction_jobs_heartbeat;
+
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS worker_id;
@@ -0,0 +1,4 @@
+ALTER TABLE extraction_jobs ADD COLUMN worker_id VARCHAR(255);
+ALTER TABLE extraction_jobs ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_extraction_jobs_heartbeat ON extraction_jobs(heartbeat_at) WHERE status = 'processing';
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_extraction_jobs_user_claimed;
+
+UPDATE extraction_jobs SET status = 'failed', error_message = 'Cancelled' WHERE status = 'cancelled';
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_status_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed'));
+
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS claimed_at;
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,8 @@
+ALTER TABLE extraction_jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE extraction_jobs ADD COLUMN claimed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_status_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+CREATE INDEX idx_extraction_jobs_user_claimed ON extraction_jobs(user_id, claimed_at DESC);
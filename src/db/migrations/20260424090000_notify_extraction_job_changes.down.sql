@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS extraction_jobs_notify_status ON extraction_jobs;
+DROP TRIGGER IF EXISTS extraction_jobs_notify_insert ON extraction_jobs;
+DROP FUNCTION IF EXISTS notify_extraction_job_change();
@@ -0,0 +1,22 @@
+-- Publish every extraction job status change so web processes can push it to
+-- open pages instead of polling. The payload is read by
+-- postgres.ListenExtractionJobEvents.
+CREATE OR REPLACE FUNCTION notify_extraction_job_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('extraction_job_events', json_build_object(
+        'id', NEW.id,
+        'user_id', NEW.user_id,
+        'status', NEW.status
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER extraction_jobs_notify_insert
+    AFTER INSERT ON extraction_jobs
+    FOR EACH ROW EXECUTE FUNCTION notify_extraction_job_change();
+
+CREATE TRIGGER extraction_jobs_notify_status
+    AFTER UPDATE OF status ON extraction_jobs
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION notify_extraction_job_change();
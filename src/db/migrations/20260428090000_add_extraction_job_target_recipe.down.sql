@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_extraction_jobs_target_recipe;
+
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS proposed_recipe;
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS model;
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS target_recipe_id;
@@ -0,0 +1,5 @@
+ALTER TABLE extraction_jobs ADD COLUMN target_recipe_id INTEGER REFERENCES recipes(id) ON DELETE CASCADE;
+ALTER TABLE extraction_jobs ADD COLUMN model VARCHAR(100);
+ALTER TABLE extraction_jobs ADD COLUMN proposed_recipe JSONB;
+
+CREATE INDEX idx_extraction_jobs_target_recipe ON extraction_jobs(target_recipe_id) WHERE target_recipe_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS prompt_version;
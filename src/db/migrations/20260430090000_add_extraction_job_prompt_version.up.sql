@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs ADD COLUMN prompt_version VARCHAR(50);
@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS extracted_recipe;
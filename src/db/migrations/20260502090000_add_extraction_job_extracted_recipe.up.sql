@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs ADD COLUMN extracted_recipe JSONB;
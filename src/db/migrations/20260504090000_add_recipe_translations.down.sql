@@ -0,0 +1,13 @@
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS translation_language;
+
+DELETE FROM extraction_jobs WHERE job_type = 'translation';
+
+ALTER TABLE extraction_jobs DROP COLUMN IF EXISTS target_language;
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf', 'text'));
+
+DROP INDEX IF EXISTS idx_recipes_translation_of;
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS translation_of;
+ALTER TABLE recipes DROP COLUMN IF EXISTS language;
@@ -0,0 +1,11 @@
+ALTER TABLE recipes ADD COLUMN language VARCHAR(10);
+ALTER TABLE recipes ADD COLUMN translation_of INTEGER REFERENCES recipes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_recipes_translation_of ON recipes(translation_of) WHERE translation_of IS NOT NULL;
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf', 'text', 'translation'));
+ALTER TABLE extraction_jobs ADD COLUMN target_language VARCHAR(10);
+
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS translation_language VARCHAR(10);
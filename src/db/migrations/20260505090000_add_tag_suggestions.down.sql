@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS tag_suggestions;
+
+DELETE FROM extraction_jobs WHERE job_type = 'tagging';
+
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf', 'text', 'translation'));
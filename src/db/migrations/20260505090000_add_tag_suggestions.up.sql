@@ -0,0 +1,15 @@
+ALTER TABLE extraction_jobs DROP CONSTRAINT IF EXISTS extraction_jobs_job_type_check;
+ALTER TABLE extraction_jobs ADD CONSTRAINT extraction_jobs_job_type_check
+    CHECK (job_type IN ('website', 'video', 'image', 'pdf', 'text', 'translation', 'tagging'));
+
+CREATE TABLE IF NOT EXISTS tag_suggestions (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER REFERENCES extraction_jobs(id) ON DELETE SET NULL,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    tags JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'dismissed')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tag_suggestions_pending_recipe ON tag_suggestions(recipe_id) WHERE status = 'pending';
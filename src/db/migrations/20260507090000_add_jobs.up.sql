@@ -0,0 +1,18 @@
+CREATE TABLE jobs (
+    id SERIAL PRIMARY KEY,
+    job_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    retry_after TIMESTAMP WITH TIME ZONE,
+    worker_id VARCHAR(255),
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_jobs_pending ON jobs(job_type, retry_after) WHERE status = 'pending';
+CREATE INDEX idx_jobs_heartbeat ON jobs(heartbeat_at) WHERE status = 'processing';
+CREATE INDEX idx_jobs_status_updated_at ON jobs(status, updated_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS digest_sends;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS digest_hour;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS digest_day;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS digest_enabled;
@@ -0,0 +1,14 @@
+-- digest_day follows Go's time.Weekday: 0 is Sunday.
+ALTER TABLE user_preferences ADD COLUMN digest_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_preferences ADD COLUMN digest_day SMALLINT NOT NULL DEFAULT 0 CHECK (digest_day BETWEEN 0 AND 6);
+ALTER TABLE user_preferences ADD COLUMN digest_hour SMALLINT NOT NULL DEFAULT 8 CHECK (digest_hour BETWEEN 0 AND 23);
+
+-- One row per user and week a digest was handled in, whether or not an email
+-- was sent, so a digest is never sent twice for the same week.
+CREATE TABLE digest_sends (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    week DATE NOT NULL,
+    email_id INTEGER REFERENCES email_outbox(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, week)
+);
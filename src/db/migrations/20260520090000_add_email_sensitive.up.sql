@@ -0,0 +1,10 @@
+-- Sensitive emails, like password resets, carry a secret link. Their body is
+-- hidden from admins, blanked once the email is sent or given up on, and
+-- they can't be resent.
+ALTER TABLE email_outbox ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE email_outbox SET sensitive = TRUE
+WHERE subject IN ('Password Reset Request - Recipe Book', 'Passwort zurücksetzen - Recipe Book');
+
+UPDATE email_outbox SET body = '', html_body = ''
+WHERE sensitive AND status <> 'pending';
@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs DROP COLUMN bypass_cache;
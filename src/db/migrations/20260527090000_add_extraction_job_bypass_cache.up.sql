@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs ADD COLUMN bypass_cache BOOLEAN NOT NULL DEFAULT FALSE;
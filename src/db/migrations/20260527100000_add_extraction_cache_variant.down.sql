@@ -0,0 +1,2 @@
+ALTER TABLE extraction_cache DROP COLUMN model;
+ALTER TABLE extraction_cache DROP COLUMN prompt_version;
@@ -0,0 +1,2 @@
+ALTER TABLE extraction_cache ADD COLUMN prompt_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE extraction_cache ADD COLUMN model TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs DROP COLUMN proposal_applied_at;
@@ -0,0 +1 @@
+ALTER TABLE extraction_jobs ADD COLUMN proposal_applied_at TIMESTAMPTZ;
@@ -0,0 +1,104 @@
+// Package digest sends the weekly digest email to the users who subscribed
+// to it.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// Sender queues the digests that are due. Every user gets at most one
+// digest per week, however often SendDue runs.
+type Sender struct {
+	store   store.DigestStore
+	signer  *Signer
+	baseURL string
+}
+
+func NewSender(digestStore store.DigestStore, signer *Signer, baseURL string) *Sender {
+	return &Sender{store: digestStore, signer: signer, baseURL: baseURL}
+}
+
+// UnsubscribeURL returns the link that unsubscribes the user from the
+// digest without logging in.
+func UnsubscribeURL(baseURL string, signer *Signer, userID int) string {
+	return baseURL + "/digest/unsubscribe?token=" + url.QueryEscape(signer.Token(userID))
+}
+
+// SendDue queues the digest of every subscriber whose day and hour have
+// come this week and who didn't get it yet. A digest covers the seven days
+// before it was due; if nothing happened in them, the week is skipped
+// without an email. It returns how many digests were queued.
+func (s *Sender) SendDue(ctx context.Context, now time.Time) (int, error) {
+	week := WeekStart(now)
+	subscribers, err := s.store.ListUnsent(ctx, week)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, subscriber := range subscribers {
+		due := ScheduledAt(now, subscriber.Day, subscriber.Hour)
+		if now.Before(due) {
+			continue
+		}
+
+		sent, err := s.send(ctx, subscriber, week, due)
+		if err != nil {
+			slog.Error("Failed to send weekly digest", "user_id", subscriber.UserID, "error", err)
+			continue
+		}
+		if sent {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (s *Sender) send(ctx context.Context, subscriber store.DigestSubscriber, week, due time.Time) (bool, error) {
+	content, err := s.store.GetContent(ctx, subscriber.UserID, due.AddDate(0, 0, -7), due)
+	if err != nil {
+		return false, err
+	}
+	if content.Empty() {
+		_, err := s.store.Record(ctx, subscriber.UserID, week, nil)
+		return false, err
+	}
+
+	to := mail.Recipient{
+		Email:    subscriber.Email,
+		Name:     subscriber.Username,
+		Theme:    subscriber.Theme,
+		Language: subscriber.Language,
+	}
+	email, err := mail.WeeklyDigestEmail(to, content, s.baseURL, UnsubscribeURL(s.baseURL, s.signer, subscriber.UserID))
+	if err != nil {
+		return false, fmt.Errorf("failed to render digest: %w", err)
+	}
+	return s.store.Record(ctx, subscriber.UserID, week, email)
+}
+
+// Run calls SendDue every interval until ctx is done.
+func (s *Sender) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if queued, err := s.SendDue(ctx, time.Now()); err != nil {
+			slog.Error("Failed to send weekly digests", "error", err)
+		} else if queued > 0 {
+			slog.Info("Queued weekly digests", "count", queued)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package digest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token := signer.Token(42)
+
+	userID, err := signer.UserID(token)
+	if err != nil || userID != 42 {
+		t.Fatalf("expected user 42, got %d (%v)", userID, err)
+	}
+
+	invalid := []string{
+		"",
+		"42",
+		"43" + token[2:],
+		token + "x",
+		NewSigner([]byte("other")).Token(42),
+	}
+	for _, token := range invalid {
+		if _, err := signer.UserID(token); err != ErrInvalidToken {
+			t.Errorf("expected ErrInvalidToken for %q, got %v", token, err)
+		}
+	}
+}
+
+func TestScheduledAt(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 5, 13, 10, 30, 0, 0, time.UTC)
+
+	if got, want := WeekStart(now), time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("WeekStart = %v, want %v", got, want)
+	}
+	if got, want := WeekStart(time.Date(2026, 5, 17, 23, 0, 0, 0, time.UTC)), time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("WeekStart of a Sunday = %v, want %v", got, want)
+	}
+	if got, want := ScheduledAt(now, time.Monday, 8), time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ScheduledAt Monday = %v, want %v", got, want)
+	}
+	if got, want := ScheduledAt(now, time.Sunday, 18), time.Date(2026, 5, 17, 18, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ScheduledAt Sunday = %v, want %v", got, want)
+	}
+}
+
+func TestSendDue(t *testing.T) {
+	// Wednesday, 10:30
+	now := time.Date(2026, 5, 13, 10, 30, 0, 0, time.UTC)
+	content := &store.DigestContent{
+		NewRecipes: []store.DigestRecipe{{ID: 1, Title: "Maultaschen", AuthorName: "anna"}},
+	}
+
+	tests := []struct {
+		name       string
+		subscriber store.DigestSubscriber
+		content    *store.DigestContent
+		claimed    bool
+		wantQueued int
+		wantRecord bool
+		wantEmail  bool
+	}{
+		{"queues a due digest", store.DigestSubscriber{UserID: 1, Day: time.Wednesday, Hour: 10}, content, true, 1, true, true},
+		{"waits until the hour has come", store.DigestSubscriber{UserID: 1, Day: time.Wednesday, Hour: 11}, content, true, 0, false, false},
+		{"waits until the day has come", store.DigestSubscriber{UserID: 1, Day: time.Sunday, Hour: 8}, content, true, 0, false, false},
+		{"records an empty week without an email", store.DigestSubscriber{UserID: 1, Day: time.Monday, Hour: 8}, &store.DigestContent{}, true, 0, true, false},
+		{"doesn't count a week that was already sent", store.DigestSubscriber{UserID: 1, Day: time.Monday, Hour: 8}, content, false, 0, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded bool
+			var recordedEmail *store.OutgoingEmail
+			var since, until time.Time
+			digestStore := &mocks.MockDigestStore{
+				ListUnsentFunc: func(_ context.Context, week time.Time) ([]store.DigestSubscriber, error) {
+					if !week.Equal(WeekStart(now)) {
+						t.Errorf("listed week %v", week)
+					}
+					return []store.DigestSubscriber{tt.subscriber}, nil
+				},
+				GetContentFunc: func(_ context.Context, _ int, s, u time.Time) (*store.DigestContent, error) {
+					since, until = s, u
+					return tt.content, nil
+				},
+				RecordFunc: func(_ context.Context, userID int, week time.Time, email *store.OutgoingEmail) (bool, error) {
+					recorded = true
+					recordedEmail = email
+					return tt.claimed, nil
+				},
+			}
+
+			queued, err := NewSender(digestStore, NewSigner([]byte("secret")), "http://example.com").SendDue(context.Background(), now)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if queued != tt.wantQueued {
+				t.Errorf("queued %d, want %d", queued, tt.wantQueued)
+			}
+			if recorded != tt.wantRecord {
+				t.Fatalf("recorded = %v, want %v", recorded, tt.wantRecord)
+			}
+			if (recordedEmail != nil) != tt.wantEmail {
+				t.Fatalf("recorded email %+v, want email %v", recordedEmail, tt.wantEmail)
+			}
+			if !tt.wantRecord {
+				return
+			}
+			if due := ScheduledAt(now, tt.subscriber.Day, tt.subscriber.Hour); !until.Equal(due) || !since.Equal(due.AddDate(0, 0, -7)) {
+				t.Errorf("content window %v - %v, want the week before %v", since, until, due)
+			}
+			if tt.wantEmail && !strings.Contains(recordedEmail.Body, "/digest/unsubscribe?token=") {
+				t.Errorf("expected an unsubscribe link, got %q", recordedEmail.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package digest
+
+import "time"
+
+// WeekStart returns the start of t's week, Monday at midnight in t's
+// location. Digests are sent at most once per week.
+func WeekStart(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.Date()
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// ScheduledAt returns when a digest sent on day at hour is due in t's week.
+func ScheduledAt(t time.Time, day time.Weekday, hour int) time.Time {
+	start := WeekStart(t)
+	daysAfterMonday := (int(day) + 6) % 7
+	return time.Date(start.Year(), start.Month(), start.Day()+daysAfterMonday, hour, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,54 @@
+package digest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidToken is returned for unsubscribe tokens that weren't signed
+// with the signer's key.
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// Signer signs the tokens of unsubscribe links, which identify the user
+// without them having to log in. Tokens don't expire, so links in old
+// digests keep working.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Token returns the unsubscribe token of the user.
+func (s *Signer) Token(userID int) string {
+	id := strconv.Itoa(userID)
+	return id + "." + base64.RawURLEncoding.EncodeToString(s.sign(id))
+}
+
+// UserID returns the user the token was signed for.
+func (s *Signer) UserID(token string) (int, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, s.sign(id)) {
+		return 0, ErrInvalidToken
+	}
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func (s *Signer) sign(id string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("digest-unsubscribe:" + id))
+	return mac.Sum(nil)
+}
@@ -1,30 +1,44 @@
 package extraction
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
+const (
+	maxDirectVideoSize = 500 * 1024 * 1024 // 500MB
+	directVideoTimeout = 10 * time.Minute
+)
+
+// directVideoTypes are the content types accepted for direct video links.
+// Many file hosts serve videos as generic binary data.
+var directVideoTypes = []string{"video/mp4", "video/x-m4v", "video/quicktime", "video/webm", "application/octet-stream"}
+
 type AudioDownloadResult struct {
 	FilePath string
 	Cleanup  func() error
 }
 
-func DownloadYouTubeAudio(videoURL string) (*AudioDownloadResult, error) {
-	videoID, err := ExtractVideoID(videoURL)
-	if err != nil {
-		return nil, err
-	}
-
-	tempDir, err := os.MkdirTemp("", "yt-audio-*")
+// DownloadVideoAudio downloads the audio track of a video with yt-dlp. Direct
+// links are downloaded with the hardened client first and yt-dlp only reads
+// the local file. The download is killed when ctx is cancelled.
+func DownloadVideoAudio(ctx context.Context, videoURL string) (*AudioDownloadResult, error) {
+	tempDir, err := os.MkdirTemp("", "video-audio-*")
 	if err != nil {
 		return nil, technicalErrorf("failed to create temp directory: %w", err)
 	}
 
-	outputPath := filepath.Join(tempDir, videoID+".mp3")
-
-	cmd := exec.Command("yt-dlp",
+	outputPath := filepath.Join(tempDir, "audio.mp3")
+	args := []string{
 		"-x",
 		"--audio-format", "mp3",
 		"--audio-quality", "128K",
@@ -32,13 +46,22 @@ func DownloadYouTubeAudio(videoURL string) (*AudioDownloadResult, error) {
 		"--no-playlist",
 		"--no-warnings",
 		"--quiet",
-		videoURL,
-	)
+	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	if VideoPlatform(videoURL) == "direct" {
+		videoPath, err := downloadDirectVideo(ctx, videoURL, tempDir)
+		if err != nil {
+			os.RemoveAll(tempDir)
+			return nil, err
+		}
+		args = append(args, "--enable-file-urls", "file://"+videoPath)
+	} else {
+		args = append(args, videoURL)
+	}
+
+	if _, err := runYTDLP(ctx, args...); err != nil {
 		os.RemoveAll(tempDir)
-		return nil, technicalErrorf("yt-dlp failed: %w, output: %s", err, string(output))
+		return nil, err
 	}
 
 	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
@@ -53,3 +76,51 @@ func DownloadYouTubeAudio(videoURL string) (*AudioDownloadResult, error) {
 		},
 	}, nil
 }
+
+// downloadDirectVideo saves the video file at videoURL in dir and returns its
+// path.
+func downloadDirectVideo(ctx context.Context, videoURL, dir string) (string, error) {
+	resp, err := fetch(ctx, fetchRequest{
+		URL:          videoURL,
+		ContentTypes: directVideoTypes,
+		MaxSize:      maxDirectVideoSize,
+		Timeout:      directVideoTimeout,
+	})
+	switch {
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrBlockedURL):
+		return "", err
+	case errors.Is(err, ErrUnexpectedContentType), errors.Is(err, ErrContentTooLarge):
+		return "", fmt.Errorf("%w: %s: %w", ErrUnsupportedVideo, videoURL, err)
+	case err != nil:
+		return "", technicalErrorf("failed to download video %s: %w", videoURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return "", fmt.Errorf("%w: status %d", ErrVideoUnavailable, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return "", technicalErrorf("failed to download video %s: status %d", videoURL, resp.StatusCode)
+	}
+
+	// Direct links end in a video file extension, which tells yt-dlp the
+	// format.
+	parsed, _ := url.Parse(videoURL)
+	videoPath := filepath.Join(dir, "video"+strings.ToLower(path.Ext(parsed.Path)))
+	file, err := os.Create(videoPath)
+	if err != nil {
+		return "", technicalErrorf("failed to create video file: %w", err)
+	}
+
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, ErrContentTooLarge) {
+		return "", fmt.Errorf("%w: larger than %d bytes", ErrUnsupportedVideo, maxDirectVideoSize)
+	}
+	if err != nil {
+		return "", technicalErrorf("failed to download video %s: %w", videoURL, err)
+	}
+	return videoPath, nil
+}
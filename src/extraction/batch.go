@@ -0,0 +1,201 @@
+package extraction
+
+import (
+	"bufio"
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// BatchDomainSpacing is the minimum time between two batch jobs for the same
+// domain, so that a migration of hundreds of links doesn't hammer one site.
+const BatchDomainSpacing = 30 * time.Second
+
+// BatchLink is a link found in an uploaded URL list or bookmarks export.
+type BatchLink struct {
+	URL     string
+	Title   string
+	JobType JobType
+}
+
+var (
+	bookmarkFolderRegexp = regexp.MustCompile(`(?i)<H3[^>]*>(.*?)</H3>`)
+	bookmarkLinkRegexp   = regexp.MustCompile(`(?i)<A\s[^>]*HREF="([^"]+)"[^>]*>(.*?)</A>`)
+	bookmarkListOpen     = regexp.MustCompile(`(?i)<DL>`)
+	bookmarkListClose    = regexp.MustCompile(`(?i)</DL>`)
+	listURLRegexp        = regexp.MustCompile(`https?://[^\s<>"]+`)
+)
+
+// recipeKeywords mark bookmark folders and titles that most likely contain
+// recipes.
+var recipeKeywords = []string{
+	"recipe", "rezept", "recette", "receta", "ricetta",
+	"cooking", "kochen", "backen", "baking", "food",
+}
+
+// IsBookmarksExport reports whether data looks like a Netscape bookmarks
+// file, the format all major browsers export.
+func IsBookmarksExport(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	upper := bytes.ToUpper(head)
+	return bytes.Contains(upper, []byte("NETSCAPE-BOOKMARK-FILE")) || bytes.Contains(upper, []byte("<DL>"))
+}
+
+// ParseURLList returns every http(s) URL in a plain text list. The user picked
+// these links explicitly, so all of them are kept.
+func ParseURLList(data []byte) []BatchLink {
+	var links []BatchLink
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		for _, match := range listURLRegexp.FindAllString(scanner.Text(), -1) {
+			match = strings.TrimRight(match, ".,;:!?)")
+			if link, ok := newBatchLink(match, ""); ok && !seen[link.URL] {
+				seen[link.URL] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// ParseBookmarks returns the recipe-looking links of a Netscape bookmarks
+// export: links inside a folder named like "Recipes", links whose URL or
+// title looks like a recipe, and videos.
+func ParseBookmarks(data []byte) []BatchLink {
+	var links []BatchLink
+	seen := make(map[string]bool)
+
+	var folders []string
+	var pendingFolder string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := bookmarkFolderRegexp.FindStringSubmatch(line); match != nil {
+			pendingFolder = html.UnescapeString(match[1])
+		}
+		if bookmarkListOpen.MatchString(line) {
+			folders = append(folders, pendingFolder)
+			pendingFolder = ""
+		}
+
+		if match := bookmarkLinkRegexp.FindStringSubmatch(line); match != nil {
+			title := html.UnescapeString(match[2])
+			link, ok := newBatchLink(html.UnescapeString(match[1]), title)
+			if ok && !seen[link.URL] && looksLikeRecipe(link, folders) {
+				seen[link.URL] = true
+				links = append(links, link)
+			}
+		}
+
+		if bookmarkListClose.MatchString(line) && len(folders) > 0 {
+			folders = folders[:len(folders)-1]
+		}
+	}
+
+	return links
+}
+
+func newBatchLink(rawURL, title string) (BatchLink, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return BatchLink{}, false
+	}
+
+	link := BatchLink{URL: parsed.String(), Title: strings.TrimSpace(title), JobType: JobTypeWebsite}
+	if IsVideoURL(link.URL) {
+		link.JobType = JobTypeVideo
+	}
+	return link, true
+}
+
+func looksLikeRecipe(link BatchLink, folders []string) bool {
+	if link.JobType == JobTypeVideo {
+		return true
+	}
+
+	parsed, _ := url.Parse(link.URL)
+	if parsed.Path == "" || parsed.Path == "/" {
+		return false
+	}
+
+	for _, pattern := range recipeURLPatterns {
+		if pattern.MatchString(link.URL) {
+			return true
+		}
+	}
+
+	if containsRecipeKeyword(link.Title) {
+		return true
+	}
+	for _, folder := range folders {
+		if containsRecipeKeyword(folder) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRecipeKeyword(s string) bool {
+	lower := strings.ToLower(s)
+	for _, keyword := range recipeKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleBatch returns the earliest start time for each link. Links to
+// different domains can start right away; links to the same domain are
+// spaced BatchDomainSpacing apart, including from the jobs already queued
+// for that domain by earlier batches of any user.
+func ScheduleBatch(links []BatchLink, start time.Time, queued []store.ExtractionBatchJob) []time.Time {
+	next := make(map[string]time.Time)
+	for _, job := range queued {
+		domain := linkDomain(BatchLink{URL: job.InputURL, JobType: JobType(job.JobType)})
+		if slot := job.NotBefore.Add(BatchDomainSpacing); slot.After(next[domain]) {
+			next[domain] = slot
+		}
+	}
+
+	schedule := make([]time.Time, len(links))
+	for i, link := range links {
+		domain := linkDomain(link)
+		slot := start
+		if next[domain].After(slot) {
+			slot = next[domain]
+		}
+		schedule[i] = slot
+		next[domain] = slot.Add(BatchDomainSpacing)
+	}
+
+	return schedule
+}
+
+func linkDomain(link BatchLink) string {
+	if link.JobType == JobTypeVideo {
+		if platform := VideoPlatform(link.URL); platform != "direct" {
+			return platform
+		}
+	}
+	parsed, err := url.Parse(link.URL)
+	if err != nil {
+		return link.URL
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
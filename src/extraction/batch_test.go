@@ -0,0 +1,132 @@
+package extraction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+func TestParseURLList(t *testing.T) {
+	data := []byte(`https://example.com/recipes/pancakes
+Check this out: https://www.youtube.com/watch?v=dQw4w9WgXcQ.
+
+not a link
+https://example.com/recipes/pancakes
+ftp://example.com/file`)
+
+	links := ParseURLList(data)
+
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d: %+v", len(links), links)
+	}
+	if links[0].URL != "https://example.com/recipes/pancakes" || links[0].JobType != JobTypeWebsite {
+		t.Errorf("unexpected first link: %+v", links[0])
+	}
+	if links[1].URL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" || links[1].JobType != JobTypeVideo {
+		t.Errorf("unexpected second link: %+v", links[1])
+	}
+}
+
+func TestIsBookmarksExport(t *testing.T) {
+	if !IsBookmarksExport([]byte("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<DL><p>")) {
+		t.Error("expected bookmarks export to be detected")
+	}
+	if IsBookmarksExport([]byte("https://example.com/recipes/pancakes\n")) {
+		t.Error("expected plain URL list not to be detected as bookmarks export")
+	}
+}
+
+func TestParseBookmarks(t *testing.T) {
+	data := []byte(`<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000">Work</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/docs/handbook">Handbook</A>
+    </DL><p>
+    <DT><H3>Rezepte</H3>
+    <DL><p>
+        <DT><A HREF="https://blog.example.org/2023/05/grandmas-goulash">Grandma&#39;s Goulash</A>
+        <DT><H3>Desserts</H3>
+        <DL><p>
+            <DT><A HREF="https://sweets.example.net/tiramisu">Tiramisu</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://cooking.example.com/recipe/lasagna">Lasagna</A>
+    <DT><A HREF="https://example.com/news/today">News</A>
+    <DT><A HREF="https://www.youtube.com/watch?v=dQw4w9WgXcQ">Some video</A>
+    <DT><A HREF="https://example.com/">Example home</A>
+</DL><p>`)
+
+	links := ParseBookmarks(data)
+
+	want := []string{
+		"https://blog.example.org/2023/05/grandmas-goulash",
+		"https://sweets.example.net/tiramisu",
+		"https://cooking.example.com/recipe/lasagna",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	}
+	if len(links) != len(want) {
+		t.Fatalf("expected %d links, got %d: %+v", len(want), len(links), links)
+	}
+	for i, url := range want {
+		if links[i].URL != url {
+			t.Errorf("link %d = %q, want %q", i, links[i].URL, url)
+		}
+	}
+	if links[0].Title != "Grandma's Goulash" {
+		t.Errorf("title = %q, want unescaped title", links[0].Title)
+	}
+}
+
+func TestScheduleBatch(t *testing.T) {
+	start := time.Date(2026, 4, 18, 12, 0, 0, 0, time.UTC)
+	links := []BatchLink{
+		{URL: "https://example.com/recipes/a", JobType: JobTypeWebsite},
+		{URL: "https://www.example.com/recipes/b", JobType: JobTypeWebsite},
+		{URL: "https://other.example.org/recipes/c", JobType: JobTypeWebsite},
+		{URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", JobType: JobTypeVideo},
+		{URL: "https://youtu.be/abcdefghijk", JobType: JobTypeVideo},
+		{URL: "https://example.com/recipes/d", JobType: JobTypeWebsite},
+	}
+
+	schedule := ScheduleBatch(links, start, nil)
+
+	want := []time.Duration{0, BatchDomainSpacing, 0, 0, BatchDomainSpacing, 2 * BatchDomainSpacing}
+	for i, offset := range want {
+		if got := schedule[i].Sub(start); got != offset {
+			t.Errorf("link %d starts after %v, want %v", i, got, offset)
+		}
+	}
+}
+
+func TestScheduleBatch_QueuesAfterEarlierBatches(t *testing.T) {
+	start := time.Date(2026, 4, 18, 12, 0, 0, 0, time.UTC)
+	queued := []store.ExtractionBatchJob{
+		{JobType: "website", InputURL: "https://www.example.com/recipes/x", NotBefore: start.Add(time.Minute)},
+		{JobType: "website", InputURL: "https://example.com/recipes/y", NotBefore: start.Add(2 * time.Minute)},
+		{JobType: "video", InputURL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", NotBefore: start.Add(time.Minute)},
+	}
+	links := []BatchLink{
+		{URL: "https://example.com/recipes/a", JobType: JobTypeWebsite},
+		{URL: "https://youtu.be/abcdefghijk", JobType: JobTypeVideo},
+		{URL: "https://other.example.org/recipes/c", JobType: JobTypeWebsite},
+		{URL: "https://example.com/recipes/d", JobType: JobTypeWebsite},
+	}
+
+	schedule := ScheduleBatch(links, start, queued)
+
+	want := []time.Duration{
+		2*time.Minute + BatchDomainSpacing,
+		time.Minute + BatchDomainSpacing,
+		0,
+		2*time.Minute + 2*BatchDomainSpacing,
+	}
+	for i, offset := range want {
+		if got := schedule[i].Sub(start); got != offset {
+			t.Errorf("link %d starts after %v, want %v", i, got, offset)
+		}
+	}
+}
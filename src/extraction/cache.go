@@ -0,0 +1,93 @@
+package extraction
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+var ErrInvalidCacheURL = errors.New("URL cannot be used as a cache key")
+
+// trackingParams are query parameters that don't change the page content and
+// are stripped before URLs are compared.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref":     true,
+	"ref_src": true,
+	"si":      true,
+	"_ga":     true,
+	"_gl":     true,
+}
+
+// CacheKeyForURL normalises a URL so that links to the same recipe map to the
+// same key: YouTube links become their video ID, everything else loses its
+// scheme, "www." prefix, fragment, trailing slash and tracking parameters.
+func CacheKeyForURL(rawURL string) (string, error) {
+	if videoID, err := ExtractVideoID(rawURL); err == nil {
+		return "youtube:" + videoID, nil
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return "", ErrInvalidCacheURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+
+	query := parsed.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		lower := strings.ToLower(key)
+		if trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	key := "url:" + host + path
+	if len(params) > 0 {
+		key += "?" + strings.Join(params, "&")
+	}
+	return key, nil
+}
+
+// CacheKeyForInputs hashes the uploaded files of a job in order, so the same
+// photos or PDF uploaded again map to the same key.
+func CacheKeyForInputs(jobType string, inputs []store.ExtractionJobInput) string {
+	hash := sha256.New()
+	hash.Write([]byte(jobType))
+	for _, input := range inputs {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(input.Data)))
+		hash.Write(length[:])
+		hash.Write(input.Data)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
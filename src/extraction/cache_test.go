@@ -0,0 +1,56 @@
+package extraction
+
+import (
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+func TestCacheKeyForURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"plain URL", "https://example.com/recipes/pancakes", "url:example.com/recipes/pancakes"},
+		{"www and trailing slash", "http://www.Example.com/recipes/pancakes/", "url:example.com/recipes/pancakes"},
+		{"tracking parameters and fragment", "https://example.com/recipes/pancakes?utm_source=x&fbclid=y#comments", "url:example.com/recipes/pancakes"},
+		{"meaningful parameters are kept sorted", "https://example.com/recipe?b=2&id=5&utm_medium=mail", "url:example.com/recipe?b=2&id=5"},
+		{"youtube watch URL", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42s", "youtube:dQw4w9WgXcQ"},
+		{"youtube short URL", "https://youtu.be/dQw4w9WgXcQ?si=abc", "youtube:dQw4w9WgXcQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CacheKeyForURL(tt.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CacheKeyForURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheKeyForURL_RejectsRelativeURL(t *testing.T) {
+	if _, err := CacheKeyForURL("/recipes/pancakes"); err == nil {
+		t.Error("expected error for URL without host")
+	}
+}
+
+func TestCacheKeyForInputs(t *testing.T) {
+	page1 := store.ExtractionJobInput{Data: []byte("page one")}
+	page2 := store.ExtractionJobInput{Data: []byte("page two")}
+
+	key := CacheKeyForInputs("image", []store.ExtractionJobInput{page1, page2})
+	if key != CacheKeyForInputs("image", []store.ExtractionJobInput{page1, page2}) {
+		t.Error("expected the same inputs to produce the same key")
+	}
+	if key == CacheKeyForInputs("image", []store.ExtractionJobInput{page2, page1}) {
+		t.Error("expected page order to change the key")
+	}
+	if key == CacheKeyForInputs("pdf", []store.ExtractionJobInput{page1, page2}) {
+		t.Error("expected job type to change the key")
+	}
+}
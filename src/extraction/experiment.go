@@ -0,0 +1,58 @@
+package extraction
+
+import "log/slog"
+
+// Variant is a prompt version and model to extract recipes with.
+type Variant struct {
+	PromptVersion string
+	Model         string
+}
+
+// Experiment splits jobs between two variants so their feedback can be
+// compared. CandidateShare percent of jobs use Candidate, the rest Control.
+// Without a share, every job uses Control.
+type Experiment struct {
+	Control        Variant
+	Candidate      Variant
+	CandidateShare int
+}
+
+// Assign returns the variant for a job. It only depends on the job ID, so
+// retries of a job use the same variant.
+func (e Experiment) Assign(jobID int) Variant {
+	if e.CandidateShare > 0 && jobID%100 < e.CandidateShare {
+		return e.Candidate
+	}
+	return e.Control
+}
+
+// withDefaults fills in the default prompt version and model, and the
+// control's settings for whatever the candidate leaves out. Unknown prompt
+// versions are replaced by the default so that jobs don't fail on a typo.
+func (e Experiment) withDefaults() Experiment {
+	if e.Control.PromptVersion == "" {
+		e.Control.PromptVersion = DefaultPromptVersion
+	} else if !IsPromptVersion(e.Control.PromptVersion) {
+		slog.Warn("Unknown prompt version, using the default", "prompt_version", e.Control.PromptVersion, "available", PromptVersions())
+		e.Control.PromptVersion = DefaultPromptVersion
+	}
+	if e.Control.Model == "" {
+		e.Control.Model = defaultModel
+	}
+
+	if e.Candidate.PromptVersion == "" {
+		e.Candidate.PromptVersion = e.Control.PromptVersion
+	} else if !IsPromptVersion(e.Candidate.PromptVersion) {
+		slog.Warn("Unknown experiment prompt version, disabling the experiment", "prompt_version", e.Candidate.PromptVersion, "available", PromptVersions())
+		e.CandidateShare = 0
+	}
+	if e.Candidate.Model == "" {
+		e.Candidate.Model = e.Control.Model
+	}
+
+	e.CandidateShare = min(max(e.CandidateShare, 0), 100)
+	if e.Candidate == e.Control {
+		e.CandidateShare = 0
+	}
+	return e
+}
@@ -0,0 +1,76 @@
+package extraction
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExperiment_AssignSplitsJobsByShare(t *testing.T) {
+	experiment := Experiment{
+		Candidate:      Variant{Model: "google/gemini-2.5-flash"},
+		CandidateShare: 30,
+	}.withDefaults()
+
+	if experiment.Control != (Variant{PromptVersion: DefaultPromptVersion, Model: defaultModel}) {
+		t.Errorf("control = %+v, want the defaults", experiment.Control)
+	}
+
+	candidates := 0
+	for jobID := 1; jobID <= 1000; jobID++ {
+		variant := experiment.Assign(jobID)
+		if variant != experiment.Assign(jobID) {
+			t.Fatalf("job %d was assigned different variants", jobID)
+		}
+		if variant.Model == "google/gemini-2.5-flash" {
+			if variant.PromptVersion != DefaultPromptVersion {
+				t.Errorf("candidate prompt version = %q, want the control's", variant.PromptVersion)
+			}
+			candidates++
+		}
+	}
+	if candidates != 300 {
+		t.Errorf("%d of 1000 jobs used the candidate, want 300", candidates)
+	}
+}
+
+func TestExperiment_UnknownCandidatePromptDisablesExperiment(t *testing.T) {
+	experiment := Experiment{
+		Candidate:      Variant{PromptVersion: "does-not-exist"},
+		CandidateShare: 50,
+	}.withDefaults()
+
+	for jobID := 1; jobID <= 100; jobID++ {
+		if variant := experiment.Assign(jobID); variant.PromptVersion != DefaultPromptVersion {
+			t.Fatalf("job %d got prompt version %q", jobID, variant.PromptVersion)
+		}
+	}
+}
+
+func TestBuildPrompt_UsesPromptVersionFromContext(t *testing.T) {
+	for _, version := range PromptVersions() {
+		t.Run(version, func(t *testing.T) {
+			ctx := WithPromptVersion(context.Background(), version)
+
+			prompt, err := buildPrompt(ctx, "website", "<h1>Pancakes</h1>")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(prompt, "website HTML content") || !strings.HasSuffix(prompt, "<h1>Pancakes</h1>") {
+				t.Errorf("text prompt is missing the source or content:\n%s", prompt)
+			}
+
+			prompt, err = buildAudioPrompt(ctx, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(prompt, "Additional Context") {
+				t.Errorf("audio prompt without context has a context section:\n%s", prompt)
+			}
+		})
+	}
+
+	if _, err := buildPrompt(WithPromptVersion(context.Background(), "does-not-exist"), "website", ""); err == nil {
+		t.Error("expected an error for an unknown prompt version")
+	}
+}
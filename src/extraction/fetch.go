@@ -0,0 +1,280 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrBlockedURL is returned for URLs the server must not fetch: schemes other
+// than http and https, and hosts that resolve to private, loopback,
+// link-local or otherwise non-public addresses. Without the check, users
+// could make the server request internal services on their behalf.
+var ErrBlockedURL = errors.New("URL not allowed")
+
+// ErrUnexpectedContentType is returned when a fetched resource isn't one of
+// the content types the caller accepts.
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+const maxRedirects = 5
+
+// reservedPrefixes are non-public ranges that netip's predicates don't cover.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can embed any IPv4 address
+}
+
+// isPublicIP reports whether ip is a public unicast address.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialAllowed reports whether outbound fetches may connect to addr. Tests
+// replace it to reach httptest servers, which listen on loopback.
+var dialAllowed = func(addr netip.AddrPort) bool {
+	return isPublicIP(addr.Addr())
+}
+
+// resolvePublic resolves host and fails with ErrBlockedURL if any of its
+// addresses may not be connected to.
+func resolvePublic(ctx context.Context, host string, port uint16) ([]netip.AddrPort, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]netip.AddrPort, 0, len(ips))
+	for _, ip := range ips {
+		addr := netip.AddrPortFrom(ip.Unmap(), port)
+		if !dialAllowed(addr) {
+			return nil, fmt.Errorf("%w: %s resolves to %s, which is not a public address", ErrBlockedURL, host, addr.Addr())
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// dialPublic connects to one of the addresses of the host in address, after
+// checking all of them. Connecting to the checked address rather than the
+// host name keeps a second DNS lookup from returning a different one.
+func dialPublic(ctx context.Context, network, address string) (net.Conn, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portString)
+	}
+
+	addrs, err := resolvePublic(ctx, host, uint16(port))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, addr.String())
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// fetchTransport dials only public addresses, which also applies to every
+// redirect. It ignores proxy settings, since a proxy would connect to the
+// target on our behalf without the check.
+var fetchTransport = &http.Transport{
+	DialContext:           dialPublic,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+// newHTTPClient returns the client for all fetches of user-supplied URLs and
+// of URLs found in fetched content.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: fetchTransport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s", ErrBlockedURL, req.URL.Redacted())
+			}
+			return nil
+		},
+	}
+}
+
+// CheckURL checks that rawURL is an http or https URL whose host resolves to
+// public addresses. It rejects blocked URLs early with a clear error; the
+// connections are checked again when they are made, by the hardened client
+// for our own fetches and by a fetchProxy for yt-dlp.
+func CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%w: only http and https URLs can be fetched", ErrBlockedURL)
+	}
+
+	if _, err := resolvePublic(ctx, parsed.Hostname(), 0); err != nil {
+		if errors.Is(err, ErrBlockedURL) {
+			return err
+		}
+		return technicalErrorf("failed to resolve %s: %w", parsed.Hostname(), err)
+	}
+	return nil
+}
+
+// fetchRequest describes an outbound fetch. ContentTypes lists the media
+// types that are accepted; responses without a Content-Type header are
+// sniffed. Timeout replaces the client's 30 second limit for large
+// downloads.
+type fetchRequest struct {
+	URL          string
+	Header       http.Header
+	ContentTypes []string
+	MaxSize      int64
+	Timeout      time.Duration
+}
+
+// fetch GETs req.URL with the hardened client. On success the response's
+// status is 200, its media type is one of req.ContentTypes and its body fails
+// with ErrContentTooLarge once more than req.MaxSize bytes were read, so the
+// caller never holds more than that in memory. Errors from the client are
+// returned as is; blocked URLs are unwrapped to their ErrBlockedURL error.
+func fetch(ctx context.Context, req fetchRequest) (*http.Response, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("%w: only http and https URLs can be fetched", ErrBlockedURL)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", req.URL, nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+
+	client := newHTTPClient()
+	if req.Timeout > 0 {
+		client.Timeout = req.Timeout
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.Is(err, ErrBlockedURL) && errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	if resp.ContentLength > req.MaxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrContentTooLarge, resp.ContentLength, req.MaxSize)
+	}
+	resp.Body = &cappedBody{ReadCloser: resp.Body, remaining: req.MaxSize}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType, err = sniffContentType(resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, allowed := range req.ContentTypes {
+		if mediaType == allowed {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("%w: %s", ErrUnexpectedContentType, contentType)
+}
+
+// sniffContentType detects the content type from the start of the body and
+// puts the bytes it read back in front of the body.
+func sniffContentType(resp *http.Response) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	head = head[:n]
+	resp.Body = readCloser{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return http.DetectContentType(head), nil
+}
+
+// cappedBody fails with ErrContentTooLarge instead of returning more than
+// remaining bytes.
+type cappedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *cappedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Read one more byte to tell a body of exactly the limit from a
+		// larger one.
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, ErrContentTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
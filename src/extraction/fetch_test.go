@@ -0,0 +1,209 @@
+package extraction
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// allowServer lets fetches connect to server, which like all httptest servers
+// listens on loopback. Other loopback addresses stay blocked.
+func allowServer(t *testing.T, server *httptest.Server) {
+	t.Helper()
+
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	original := dialAllowed
+	dialAllowed = func(addr netip.AddrPort) bool {
+		return int(addr.Port()) == port || original(addr)
+	}
+	t.Cleanup(func() { dialAllowed = original })
+}
+
+func fetchHTML(t *testing.T, rawURL string, maxSize int64) (*http.Response, error) {
+	t.Helper()
+	return fetch(context.Background(), fetchRequest{URL: rawURL, ContentTypes: []string{"text/html"}, MaxSize: maxSize})
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublicIP(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestFetch_BlocksPrivateAddresses(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	for _, rawURL := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := fetchHTML(t, rawURL, 1024)
+		if !errors.Is(err, ErrBlockedURL) {
+			t.Errorf("fetch(%s): expected ErrBlockedURL, got %v", rawURL, err)
+		} else if !strings.Contains(err.Error(), "not a public address") {
+			t.Errorf("fetch(%s): error %q doesn't say why", rawURL, err)
+		}
+	}
+	if requested {
+		t.Error("expected no request to reach the server")
+	}
+}
+
+func TestFetch_BlocksRedirectsToPrivateAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the redirect not to be followed")
+	}))
+	defer internal.Close()
+
+	for _, target := range []string{internal.URL + "/latest/meta-data", "file:///etc/passwd"} {
+		t.Run(target, func(t *testing.T) {
+			server := httptest.NewServer(http.RedirectHandler(target, http.StatusFound))
+			defer server.Close()
+			allowServer(t, server)
+
+			_, err := fetchHTML(t, server.URL, 1024)
+			if !errors.Is(err, ErrBlockedURL) {
+				t.Errorf("expected ErrBlockedURL, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetch_RejectsUnexpectedContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("binary"))
+	}))
+	defer server.Close()
+	allowServer(t, server)
+
+	_, err := fetchHTML(t, server.URL, 1024)
+	if !errors.Is(err, ErrUnexpectedContentType) {
+		t.Errorf("expected ErrUnexpectedContentType, got %v", err)
+	}
+}
+
+func TestFetch_SniffsMissingContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.Write([]byte("<!DOCTYPE html><html><body>Pancakes</body></html>"))
+	}))
+	defer server.Close()
+	allowServer(t, server)
+
+	resp, err := fetchHTML(t, server.URL, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Pancakes") {
+		t.Errorf("body = %q, want the whole page including the sniffed bytes", body)
+	}
+}
+
+func TestFetch_CapsBodySize(t *testing.T) {
+	page := "<html>" + strings.Repeat("x", 100) + "</html>"
+	tests := []struct {
+		name    string
+		chunked bool
+		maxSize int64
+		wantErr bool
+	}{
+		{"announced length above the limit", false, 50, true},
+		{"streamed body above the limit", true, 50, true},
+		{"body of exactly the limit", true, int64(len(page)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				if tt.chunked {
+					w.Write([]byte(page[:10]))
+					w.(http.Flusher).Flush()
+					w.Write([]byte(page[10:]))
+					return
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(page)))
+				w.Write([]byte(page))
+			}))
+			defer server.Close()
+			allowServer(t, server)
+
+			resp, err := fetchHTML(t, server.URL, tt.maxSize)
+			if err == nil {
+				defer resp.Body.Close()
+				var body []byte
+				body, err = io.ReadAll(resp.Body)
+				if int64(len(body)) > tt.maxSize {
+					t.Errorf("read %d bytes, more than the limit of %d", len(body), tt.maxSize)
+				}
+			}
+			if tt.wantErr != errors.Is(err, ErrContentTooLarge) {
+				t.Errorf("error = %v, want ErrContentTooLarge: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchWebsite_BlockedURLIsNotRetried(t *testing.T) {
+	_, err := FetchWebsite(context.Background(), "http://169.254.169.254/latest/meta-data")
+	if !errors.Is(err, ErrBlockedURL) {
+		t.Fatalf("expected ErrBlockedURL, got %v", err)
+	}
+	var technical *TechnicalError
+	if errors.As(err, &technical) {
+		t.Error("expected a blocked URL to fail the job instead of being retried")
+	}
+	if strings.Contains(err.Error(), "wayback") {
+		t.Errorf("expected no Wayback Machine fallback, got %v", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://localhost:8080/video.mp4",
+		"https://10.0.0.5/video.mp4",
+		"ftp://example.com/video.mp4",
+	} {
+		if err := CheckURL(context.Background(), rawURL); !errors.Is(err, ErrBlockedURL) {
+			t.Errorf("CheckURL(%q): expected ErrBlockedURL, got %v", rawURL, err)
+		}
+	}
+}
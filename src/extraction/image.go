@@ -0,0 +1,218 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Hero images match what the recipe form's cropper produces: 16:9, 800
+// pixels wide, JPEG.
+const (
+	heroImageWidth     = 800
+	heroImageHeight    = 450
+	heroImageQuality   = 85
+	minHeroImageWidth  = 300
+	maxHeroImagePixels = 50_000_000
+	maxHeroImageSize   = 10 * 1024 * 1024 // 10MB
+)
+
+var ErrUnsuitableImage = errors.New("image is not suitable as a recipe image")
+
+var (
+	jsonLDRegexp  = regexp.MustCompile(`(?is)<script[^>]+type=["']application/ld\+json["'][^>]*>(.*?)</script>`)
+	ogImageRegexp = regexp.MustCompile(`(?i)<meta[^>]+(?:property|name)=["'](?:og:image(?::url|:secure_url)?|twitter:image)["'][^>]*>`)
+	contentRegexp = regexp.MustCompile(`(?i)\scontent=["']([^"']+)["']`)
+)
+
+// FindImageURL returns the recipe image of an HTML page: the image of a
+// JSON-LD Recipe if there is one, otherwise the og:image. Relative URLs are
+// resolved against pageURL. It returns "" if the page names no image.
+func FindImageURL(htmlContent, pageURL string) string {
+	imageURL := ""
+	if node := findJSONLDRecipe(htmlContent); node != nil {
+		imageURL = jsonLDImage(node["image"])
+	}
+
+	if imageURL == "" {
+		for _, tag := range ogImageRegexp.FindAllString(htmlContent, -1) {
+			if matches := contentRegexp.FindStringSubmatch(tag); len(matches) > 1 {
+				imageURL = html.UnescapeString(matches[1])
+				break
+			}
+		}
+	}
+
+	if imageURL == "" {
+		return ""
+	}
+	return resolveURL(pageURL, imageURL)
+}
+
+// jsonLDImage reads an image property, which can be a URL, an ImageObject
+// or a list of either. Lists are usually ordered by preference.
+func jsonLDImage(image any) string {
+	switch value := image.(type) {
+	case string:
+		return value
+	case []any:
+		for _, item := range value {
+			if imageURL := jsonLDImage(item); imageURL != "" {
+				return imageURL
+			}
+		}
+	case map[string]any:
+		if imageURL, ok := value["url"].(string); ok {
+			return imageURL
+		}
+	}
+	return ""
+}
+
+func resolveURL(base, ref string) string {
+	refURL, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return refURL.String()
+	}
+	resolved := baseURL.ResolveReference(refURL)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// heroImageTypes are the image types that can be decoded.
+var heroImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// FetchHeroImage downloads imageURL and prepares it as a recipe image.
+func FetchHeroImage(ctx context.Context, imageURL string) ([]byte, error) {
+	resp, err := fetch(ctx, fetchRequest{
+		URL: imageURL,
+		Header: http.Header{
+			"User-Agent": {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+			// WebP can't be decoded, so ask sites that negotiate formats for others.
+			"Accept": {"image/jpeg,image/png,image/gif;q=0.9"},
+		},
+		ContentTypes: heroImageTypes,
+		MaxSize:      maxHeroImageSize,
+	})
+	if errors.Is(err, ErrUnexpectedContentType) || errors.Is(err, ErrContentTooLarge) {
+		return nil, fmt.Errorf("%w: %s: %w", ErrUnsuitableImage, imageURL, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image %s: %w", imageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image %s: status %d", imageURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if errors.Is(err, ErrContentTooLarge) {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrUnsuitableImage, maxHeroImageSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image %s: %w", imageURL, err)
+	}
+
+	return PrepareHeroImage(data)
+}
+
+// PrepareHeroImage validates an image, crops it to 16:9 around its center,
+// downscales it and encodes it as JPEG. Images that are too small to look
+// good, such as icons and tracking pixels, are rejected.
+func PrepareHeroImage(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsuitableImage, err)
+	}
+	if config.Width < minHeroImageWidth || config.Width*config.Height > maxHeroImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrUnsuitableImage, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsuitableImage, err)
+	}
+
+	cropped := cropToAspect(img, heroImageWidth, heroImageHeight)
+	width, height := heroImageWidth, heroImageHeight
+	if size := cropped.Bounds().Size(); size.X < width {
+		width, height = size.X, size.Y
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(cropped, width, height), &jpeg.Options{Quality: heroImageQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// cropToAspect copies the largest centered part of img with the aspect ratio
+// width:height.
+func cropToAspect(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dx()*height/width
+	if cropHeight > bounds.Dy() {
+		cropWidth, cropHeight = bounds.Dy()*width/height, bounds.Dy()
+	}
+
+	offset := image.Pt((bounds.Dx()-cropWidth)/2, (bounds.Dy()-cropHeight)/2)
+	cropped := image.NewRGBA(image.Rect(0, 0, cropWidth, cropHeight))
+	draw.Draw(cropped, cropped.Bounds(), img, bounds.Min.Add(offset), draw.Src)
+	return cropped
+}
+
+// downscale resizes src to width x height by averaging the source pixels
+// that fall into each target pixel.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcWidth == width && srcHeight == height {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,109 @@
+package extraction
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestFindImageURL_PrefersRecipeImageOverOpenGraph(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "recipe in graph with image object list",
+			html: `<meta property="og:image" content="https://example.com/og.jpg">
+				<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+					{"@type": "WebPage", "image": "https://example.com/page.jpg"},
+					{"@type": ["Recipe"], "image": [{"@type": "ImageObject", "url": "https://example.com/recipe.jpg"}]}
+				]}</script>`,
+			want: "https://example.com/recipe.jpg",
+		},
+		{
+			name: "open graph with relative URL",
+			html: `<meta content="/images/pancakes.jpg?w=1200&amp;h=800" property="og:image" />`,
+			want: "https://example.com/images/pancakes.jpg?w=1200&h=800",
+		},
+		{
+			name: "invalid JSON-LD falls back to open graph",
+			html: `<script type="application/ld+json">{"@type": "Recipe",</script>
+				<meta name="twitter:image" content="https://example.com/twitter.jpg">`,
+			want: "https://example.com/twitter.jpg",
+		},
+		{
+			name: "no image",
+			html: `<html><body>Pancakes</body></html>`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindImageURL(tt.html, "https://example.com/recipes/pancakes")
+			if got != tt.want {
+				t.Errorf("FindImageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareHeroImage_CropsAndDownscales(t *testing.T) {
+	// A square photo with a red band in the middle: the centered 16:9 crop
+	// must keep the band and drop the white top and bottom.
+	photo := image.NewRGBA(image.Rect(0, 0, 1600, 1600))
+	for y := 0; y < 1600; y++ {
+		for x := 0; x < 1600; x++ {
+			c := color.RGBA{255, 255, 255, 255}
+			if y >= 350 && y < 1250 {
+				c = color.RGBA{200, 0, 0, 255}
+			}
+			photo.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, photo); err != nil {
+		t.Fatalf("failed to encode photo: %v", err)
+	}
+
+	data, err := PrepareHeroImage(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hero, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a JPEG, got %v", err)
+	}
+	if size := hero.Bounds().Size(); size != image.Pt(heroImageWidth, heroImageHeight) {
+		t.Errorf("size = %v, want %dx%d", size, heroImageWidth, heroImageHeight)
+	}
+	for _, y := range []int{0, heroImageHeight - 1} {
+		if r, g, _, _ := hero.At(heroImageWidth/2, y).RGBA(); r>>8 < 150 || g>>8 > 60 {
+			t.Errorf("pixel at row %d is not red, the crop is off center", y)
+		}
+	}
+}
+
+func TestPrepareHeroImage_RejectsUnsuitableImages(t *testing.T) {
+	var icon bytes.Buffer
+	if err := png.Encode(&icon, image.NewRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatalf("failed to encode icon: %v", err)
+	}
+
+	for name, data := range map[string][]byte{
+		"icon":      icon.Bytes(),
+		"not image": []byte("<html></html>"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := PrepareHeroImage(data); !errors.Is(err, ErrUnsuitableImage) {
+				t.Errorf("expected ErrUnsuitableImage, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package extraction
+
+import (
+	"sync"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// jobEventBuffer is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const jobEventBuffer = 16
+
+// JobEventBroker fans extraction job events out to the subscribers in this
+// process, e.g. open status pages. Publishing never blocks: a subscriber that
+// doesn't keep up misses events and has to re-read the job.
+type JobEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[*jobEventSubscriber]struct{}
+}
+
+type jobEventSubscriber struct {
+	userID int
+	events chan store.ExtractionJobEvent
+}
+
+func NewJobEventBroker() *JobEventBroker {
+	return &JobEventBroker{subscribers: make(map[*jobEventSubscriber]struct{})}
+}
+
+// Subscribe returns the events of the user's jobs. The returned function
+// unsubscribes and must be called once the caller stops reading.
+func (b *JobEventBroker) Subscribe(userID int) (<-chan store.ExtractionJobEvent, func()) {
+	subscriber := &jobEventSubscriber{
+		userID: userID,
+		events: make(chan store.ExtractionJobEvent, jobEventBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return subscriber.events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, subscriber)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish delivers the event to every subscriber of the job's user.
+func (b *JobEventBroker) Publish(event store.ExtractionJobEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers {
+		if subscriber.userID != event.UserID {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package extraction
+
+import (
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+func TestJobEventBroker_DeliversEventsToSubscribersOfTheUser(t *testing.T) {
+	broker := NewJobEventBroker()
+	mine, unsubscribeMine := broker.Subscribe(1)
+	defer unsubscribeMine()
+	others, unsubscribeOthers := broker.Subscribe(2)
+	defer unsubscribeOthers()
+
+	broker.Publish(store.ExtractionJobEvent{JobID: 7, UserID: 1, Status: "processing"})
+
+	select {
+	case event := <-mine:
+		if event.JobID != 7 || event.Status != "processing" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("expected the user's subscriber to receive the event")
+	}
+	select {
+	case event := <-others:
+		t.Errorf("expected other users not to receive the event, got %+v", event)
+	default:
+	}
+}
+
+func TestJobEventBroker_DropsEventsForSlowAndRemovedSubscribers(t *testing.T) {
+	broker := NewJobEventBroker()
+	slow, unsubscribeSlow := broker.Subscribe(1)
+	defer unsubscribeSlow()
+	gone, unsubscribeGone := broker.Subscribe(1)
+	unsubscribeGone()
+
+	for i := 0; i < jobEventBuffer+5; i++ {
+		broker.Publish(store.ExtractionJobEvent{JobID: i, UserID: 1, Status: "pending"})
+	}
+
+	if len(slow) != jobEventBuffer {
+		t.Errorf("expected the slow subscriber's buffer to be full, got %d events", len(slow))
+	}
+	if len(gone) != 0 {
+		t.Errorf("expected no events after unsubscribing, got %d", len(gone))
+	}
+}
@@ -0,0 +1,63 @@
+package extraction
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+// stopwords are frequent words that are specific enough to one of the
+// supported languages to tell them apart in recipe text.
+var stopwords = map[string]map[string]bool{
+	models.LanguageEnglish: wordSet("the and with for into until about then from over each of it is are or to in on"),
+	models.LanguageGerman:  wordSet("der die das und mit für bis etwa dann aus über jeweils von ist sind oder zu im auf den dem ein eine einen nach"),
+}
+
+// minLanguageWords is the number of stopwords a text needs before its
+// language is trusted.
+const minLanguageWords = 5
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// DetectLanguage guesses which of models.Languages text is written in by
+// counting stopwords. It returns "" if the text is too short or too mixed to
+// tell. The language is detected here rather than asked from the LLM so that
+// the extraction prompts stay unchanged.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for language, words := range stopwords {
+			if words[word] {
+				counts[language]++
+			}
+		}
+	}
+
+	best, total := "", 0
+	for _, language := range models.Languages {
+		total += counts[language.Code]
+		if counts[language.Code] > counts[best] {
+			best = language.Code
+		}
+	}
+	// Recipes quote the odd foreign dish name, so require a clear majority.
+	if counts[best] < minLanguageWords || counts[best]*3 < total*2 {
+		return ""
+	}
+	return best
+}
+
+// detectRecipeLanguage detects the language of an extracted recipe from its
+// text.
+func detectRecipeLanguage(recipe *ExtractedRecipe) string {
+	return DetectLanguage(strings.Join([]string{recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD}, "\n"))
+}
@@ -0,0 +1,45 @@
+package extraction
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "english",
+			text: "Preheat the oven to 180°C. Mix the flour with the butter and sugar, then bake for 25 minutes until golden.",
+			want: "en",
+		},
+		{
+			name: "german",
+			text: "Den Backofen auf 180°C vorheizen. Das Mehl mit der Butter und dem Zucker verrühren, dann etwa 25 Minuten backen, bis die Oberfläche goldbraun ist.",
+			want: "de",
+		},
+		{
+			name: "english with a german dish name",
+			text: "Spätzle mit Käse: cook the noodles in salted water, drain them and layer them with the cheese and the onions in a dish.",
+			want: "en",
+		},
+		{
+			name: "too short",
+			text: "Pancakes",
+			want: "",
+		},
+		{
+			name: "ingredient list without stopwords",
+			text: "- 250g flour\n- 2 eggs\n- 500ml milk",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -24,18 +24,62 @@ type LLMClient struct {
 }
 
 func NewLLMClient(apiKey string) *LLMClient {
+	return NewLLMClientWithHTTPClient(apiKey, &http.Client{
+		Timeout: 120 * time.Second,
+	})
+}
+
+// NewLLMClientWithHTTPClient is like NewLLMClient but sends its requests with
+// httpClient, e.g. one whose transport records or replays them.
+func NewLLMClientWithHTTPClient(apiKey string, httpClient *http.Client) *LLMClient {
 	return &LLMClient{
-		apiKey: apiKey,
-		model:  defaultModel,
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		apiKey:     apiKey,
+		model:      defaultModel,
+		httpClient: httpClient,
+	}
+}
+
+// Model is an OpenRouter model users can choose for a re-extraction.
+type Model struct {
+	ID    string
+	Label string
+}
+
+// Models lists the selectable models, the default first.
+var Models = []Model{
+	{ID: defaultModel, Label: "Gemini 2.5 Flash Lite (default)"},
+	{ID: "google/gemini-2.5-flash", Label: "Gemini 2.5 Flash"},
+	{ID: "google/gemini-2.5-pro", Label: "Gemini 2.5 Pro"},
+}
+
+// IsSelectableModel reports whether id is one of Models.
+func IsSelectableModel(id string) bool {
+	for _, model := range Models {
+		if model.ID == id {
+			return true
+		}
 	}
+	return false
+}
+
+type modelKey struct{}
+
+// WithModel returns a context whose LLM requests use model instead of the
+// client's default.
+func WithModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelKey{}, model)
 }
 
 type chatRequest struct {
 	Model    string        `json:"model"`
 	Messages []chatMessage `json:"messages"`
+	Usage    usageOptions  `json:"usage"`
+}
+
+// usageOptions asks OpenRouter to include token counts and cost in the
+// response.
+type usageOptions struct {
+	Include bool `json:"include"`
 }
 
 type chatMessage struct {
@@ -65,14 +109,27 @@ type chatResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Code    string `json:"code"`
 	} `json:"error"`
 }
 
+type chatUsage struct {
+	PromptTokens        int     `json:"prompt_tokens"`
+	CompletionTokens    int     `json:"completion_tokens"`
+	Cost                float64 `json:"cost"`
+	PromptTokensDetails *struct {
+		AudioTokens int `json:"audio_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
 func (c *LLMClient) ExtractRecipeFromText(ctx context.Context, sourceType, content string) (string, *ExtractedRecipe, error) {
-	prompt := buildPrompt(sourceType, content)
+	prompt, err := buildPrompt(ctx, sourceType, content)
+	if err != nil {
+		return "", nil, err
+	}
 
 	request := chatRequest{
 		Model: c.model,
@@ -96,15 +153,11 @@ func (c *LLMClient) ExtractRecipeFromText(ctx context.Context, sourceType, conte
 }
 
 func (c *LLMClient) ExtractRecipeFromImage(ctx context.Context, imageData []byte, mimeType string) (string, *ExtractedRecipe, error) {
-	if mimeType == "" {
-		mimeType = detectMimeType(imageData)
+	prompt, err := buildPrompt(ctx, "image", "")
+	if err != nil {
+		return "", nil, err
 	}
 
-	base64Image := base64.StdEncoding.EncodeToString(imageData)
-	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64Image)
-
-	prompt := buildPrompt("image", "")
-
 	request := chatRequest{
 		Model: c.model,
 		Messages: []chatMessage{
@@ -112,7 +165,7 @@ func (c *LLMClient) ExtractRecipeFromImage(ctx context.Context, imageData []byte
 				Role: "user",
 				Content: []contentPart{
 					{Type: "text", Text: prompt},
-					{Type: "image_url", ImageURL: &imageURL{URL: dataURL}},
+					imageContentPart(imageData, mimeType),
 				},
 			},
 		},
@@ -127,10 +180,57 @@ func (c *LLMClient) ExtractRecipeFromImage(ctx context.Context, imageData []byte
 	return prompt, recipe, err
 }
 
+// ExtractRecipeFromImages sends several images in a single request so that
+// e.g. a two-page cookbook spread is extracted as one recipe. Text content,
+// if any, is appended to the prompt like for text extraction.
+func (c *LLMClient) ExtractRecipeFromImages(ctx context.Context, sourceType, content string, images [][]byte) (string, *ExtractedRecipe, error) {
+	prompt, err := buildPrompt(ctx, sourceType, content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parts := []contentPart{{Type: "text", Text: prompt}}
+	for _, image := range images {
+		parts = append(parts, imageContentPart(image, ""))
+	}
+
+	request := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{
+				Role:    "user",
+				Content: parts,
+			},
+		},
+	}
+
+	responseText, err := c.sendRequest(ctx, request)
+	if err != nil {
+		return prompt, nil, err
+	}
+
+	recipe, err := parseRecipeResponse(responseText)
+	return prompt, recipe, err
+}
+
+func imageContentPart(imageData []byte, mimeType string) contentPart {
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = detectMimeType(imageData)
+	}
+
+	base64Image := base64.StdEncoding.EncodeToString(imageData)
+	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64Image)
+
+	return contentPart{Type: "image_url", ImageURL: &imageURL{URL: dataURL}}
+}
+
 func (c *LLMClient) ExtractRecipeFromAudio(ctx context.Context, audioData []byte, additionalContext string) (string, *ExtractedRecipe, error) {
 	base64Audio := base64.StdEncoding.EncodeToString(audioData)
 
-	prompt := buildAudioPrompt(additionalContext)
+	prompt, err := buildAudioPrompt(ctx, additionalContext)
+	if err != nil {
+		return "", nil, err
+	}
 
 	request := chatRequest{
 		Model: c.model,
@@ -155,6 +255,11 @@ func (c *LLMClient) ExtractRecipeFromAudio(ctx context.Context, audioData []byte
 }
 
 func (c *LLMClient) sendRequest(ctx context.Context, request chatRequest) (string, error) {
+	request.Usage = usageOptions{Include: true}
+	if model, ok := ctx.Value(modelKey{}).(string); ok && model != "" {
+		request.Model = model
+	}
+
 	jsonBody, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
@@ -206,6 +311,8 @@ func (c *LLMClient) sendRequest(ctx context.Context, request chatRequest) (strin
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	recordUsage(ctx, request.Model, chatResp.Usage)
+
 	if chatResp.Error != nil {
 		return "", technicalErrorf("API error: %s", chatResp.Error.Message)
 	}
@@ -217,145 +324,32 @@ func (c *LLMClient) sendRequest(ctx context.Context, request chatRequest) (strin
 	return chatResp.Choices[0].Message.Content, nil
 }
 
-func buildAudioPrompt(additionalContext string) string {
-	prompt := `Extract a recipe from the attached audio of a cooking video. Listen carefully to identify all ingredients, quantities, and cooking steps. Return the result as valid JSON only, with no additional text.
-
-## Output Format
-
-{
-  "title": "Recipe title",
-  "description": "1-2 sentence description of the dish",
-  "ingredients_md": "Markdown bullet list of ingredients with quantities",
-  "instructions_md": "Markdown numbered list of steps",
-  "prep_time_minutes": <integer or null if unknown>,
-  "cook_time_minutes": <integer or null if unknown>,
-  "calories_per_serving": <integer or null if unknown>,
-  "suggested_tags": ["tag1", "tag2"],
-  "confidence": <0.0 to 1.0>,
-  "confidence_notes": "Any issues, uncertainties, or assumptions made"
+func buildAudioPrompt(ctx context.Context, additionalContext string) (string, error) {
+	return renderPrompt(ctx, promptKindAudio, promptData{Content: additionalContext})
 }
 
-## Rules
-
-### Ingredients (ingredients_md)
-- Use markdown bullet list format: "- 250g flour"
-- Include quantity, unit, and ingredient name
-- Prefer metric units (g, ml, °C) but preserve original if clearly imperial
-- One ingredient per line
-- Include preparation notes in parentheses: "- 2 onions (finely diced)"
-
-### Instructions (instructions_md)
-- Use markdown numbered list: "1. Preheat oven to 180°C"
-- Each step should be a single, clear action
-- Preserve the original order
-- Include temperatures, times, and visual cues where mentioned
-
-### Metadata
-- prep_time_minutes: Time for preparation before cooking starts
-- cook_time_minutes: Active cooking/baking time
-- calories_per_serving: Per single serving, if mentioned or calculable
-- Use null if information is not available or cannot be reasonably inferred
-
-### Tags
-- Suggest 2-5 relevant tags based on the recipe
-- Use lowercase, single words or hyphenated phrases
-- Examples: "vegetarian", "quick-meal", "german", "dessert", "one-pot"
-
-### Confidence
-- 1.0: Perfect extraction, all information clear
-- 0.8-0.9: Minor uncertainties (e.g., portion size unclear)
-- 0.6-0.7: Some information missing or ambiguous
-- Below 0.6: Significant issues, recommend manual review
-- Always explain any uncertainties in confidence_notes
-
-### Language
-- Output in the same language as the audio
-- If source is German, output German text
-- If source is English, output English text`
-
-	if additionalContext != "" {
-		prompt += fmt.Sprintf("\n\n## Additional Context from Video Description\n\n%s", additionalContext)
-	}
-
-	return prompt
+// sourceDescriptions name the source types in text prompts.
+var sourceDescriptions = map[string]string{
+	"website":    "website HTML content",
+	"video":      "video transcript",
+	"image":      "image",
+	"images":     "images (pages of the same recipe, in order)",
+	"pdf":        "PDF document (the text layer is included below; pages without text are attached as images)",
+	"transcript": "video transcript",
+	"text":       "text pasted by the user (e.g. from a message, email, or notes app)",
 }
 
-func buildPrompt(sourceType, content string) string {
-	sourceDescription := map[string]string{
-		"website":    "website HTML content",
-		"video":      "video transcript",
-		"image":      "image",
-		"transcript": "video transcript",
-	}
-
-	desc := sourceDescription[sourceType]
+func buildPrompt(ctx context.Context, sourceType, content string) (string, error) {
+	desc := sourceDescriptions[sourceType]
 	if desc == "" {
 		desc = sourceType
 	}
 
-	prompt := fmt.Sprintf(`Extract a recipe from the following %s. Return the result as valid JSON only, with no additional text.
-
-## Output Format
-
-{
-  "title": "Recipe title",
-  "description": "1-2 sentence description of the dish",
-  "ingredients_md": "Markdown bullet list of ingredients with quantities",
-  "instructions_md": "Markdown numbered list of steps",
-  "prep_time_minutes": <integer or null if unknown>,
-  "cook_time_minutes": <integer or null if unknown>,
-  "calories_per_serving": <integer or null if unknown>,
-  "suggested_tags": ["tag1", "tag2"],
-  "confidence": <0.0 to 1.0>,
-  "confidence_notes": "Any issues, uncertainties, or assumptions made"
-}
-
-## Rules
-
-### Ingredients (ingredients_md)
-- Use markdown bullet list format: "- 250g flour"
-- Include quantity, unit, and ingredient name
-- Prefer metric units (g, ml, °C) but preserve original if clearly imperial
-- One ingredient per line
-- Include preparation notes in parentheses: "- 2 onions (finely diced)"
-
-### Instructions (instructions_md)
-- Use markdown numbered list: "1. Preheat oven to 180°C"
-- Each step should be a single, clear action
-- Preserve the original order
-- Include temperatures, times, and visual cues where mentioned
-
-### Metadata
-- prep_time_minutes: Time for preparation before cooking starts
-- cook_time_minutes: Active cooking/baking time
-- calories_per_serving: Per single serving, if mentioned or calculable
-- Use null if information is not available or cannot be reasonably inferred
-
-### Tags
-- Suggest 2-5 relevant tags based on the recipe
-- Use lowercase, single words or hyphenated phrases
-- Examples: "vegetarian", "quick-meal", "german", "dessert", "one-pot"
-
-### Confidence
-- 1.0: Perfect extraction, all information clear
-- 0.8-0.9: Minor uncertainties (e.g., portion size unclear)
-- 0.6-0.7: Some information missing or ambiguous
-- Below 0.6: Significant issues, recommend manual review
-- Always explain any uncertainties in confidence_notes
-
-### Language
-- Output in the same language as the source
-- If source is German, output German text
-- If source is English, output English text`, desc)
-
-	if content != "" {
-		prompt += fmt.Sprintf("\n\n## Source Content\n\n%s", content)
-	}
-
-	return prompt
+	return renderPrompt(ctx, promptKindText, promptData{Source: desc, Content: content})
 }
 
-func parseRecipeResponse(responseText string) (*ExtractedRecipe, error) {
+// trimCodeFence removes the markdown code fence models sometimes wrap JSON in.
+func trimCodeFence(responseText string) string {
 	responseText = strings.TrimSpace(responseText)
 
 	if strings.HasPrefix(responseText, "```json") {
@@ -367,6 +361,11 @@ func parseRecipeResponse(responseText string) (*ExtractedRecipe, error) {
 		responseText = strings.TrimSuffix(responseText, "```")
 		responseText = strings.TrimSpace(responseText)
 	}
+	return responseText
+}
+
+func parseRecipeResponse(responseText string) (*ExtractedRecipe, error) {
+	responseText = trimCodeFence(responseText)
 
 	var recipe ExtractedRecipe
 	if err := json.Unmarshal([]byte(responseText), &recipe); err != nil {
@@ -0,0 +1,159 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPDF = errors.New("file is not a valid PDF")
+
+const (
+	maxPDFPages = 20
+
+	// Pages whose text layer is shorter than this are treated as scanned and
+	// rendered to an image instead.
+	minPageTextLength = 40
+	pdfRenderDPI      = 150
+)
+
+// PDFContent is the result of reading a PDF: the text of all pages that have
+// a usable text layer, plus rendered images of the pages that don't.
+type PDFContent struct {
+	Text       string
+	PageImages [][]byte
+	PageCount  int
+}
+
+// ReadPDF extracts the text layer of each page using pdftotext and falls back
+// to rendering the page with pdftoppm when a page has no usable text (e.g. a
+// scanned cookbook page). Both tools are part of poppler-utils. The tools are
+// killed when ctx is cancelled.
+func ReadPDF(ctx context.Context, pdfData []byte) (*PDFContent, error) {
+	if !bytes.HasPrefix(pdfData, []byte("%PDF-")) {
+		return nil, ErrInvalidPDF
+	}
+
+	tempDir, err := os.MkdirTemp("", "pdf-extract-*")
+	if err != nil {
+		return nil, technicalErrorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	pdfPath := filepath.Join(tempDir, "input.pdf")
+	if err := os.WriteFile(pdfPath, pdfData, 0o600); err != nil {
+		return nil, technicalErrorf("failed to write PDF: %w", err)
+	}
+
+	pageCount, err := pdfPageCount(ctx, pdfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &PDFContent{PageCount: pageCount}
+	if pageCount > maxPDFPages {
+		pageCount = maxPDFPages
+	}
+
+	var textParts []string
+	for page := 1; page <= pageCount; page++ {
+		text, err := pdfPageText(ctx, pdfPath, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(strings.TrimSpace(text)) >= minPageTextLength {
+			textParts = append(textParts, fmt.Sprintf("--- Page %d ---\n%s", page, normalizeWhitespace(text)))
+			continue
+		}
+
+		image, err := renderPDFPage(ctx, pdfPath, tempDir, page)
+		if err != nil {
+			return nil, err
+		}
+		content.PageImages = append(content.PageImages, image)
+	}
+
+	content.Text = strings.Join(textParts, "\n\n")
+	return content, nil
+}
+
+var pdfPagesRegexp = regexp.MustCompile(`(?m)^Pages:\s+(\d+)\s*$`)
+
+func pdfPageCount(ctx context.Context, pdfPath string) (int, error) {
+	output, err := exec.CommandContext(ctx, "pdfinfo", pdfPath).CombinedOutput()
+	if err != nil {
+		// A killed pdfinfo exits with an error too, which says nothing
+		// about the file.
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if _, ok := err.(*exec.ExitError); ok {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidPDF, strings.TrimSpace(string(output)))
+		}
+		return 0, technicalErrorf("pdfinfo failed: %w", err)
+	}
+
+	return parsePDFInfoPages(string(output))
+}
+
+func parsePDFInfoPages(pdfInfoOutput string) (int, error) {
+	matches := pdfPagesRegexp.FindStringSubmatch(pdfInfoOutput)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("%w: could not determine page count", ErrInvalidPDF)
+	}
+
+	pages, err := strconv.Atoi(matches[1])
+	if err != nil || pages < 1 {
+		return 0, fmt.Errorf("%w: document has no pages", ErrInvalidPDF)
+	}
+
+	return pages, nil
+}
+
+func pdfPageText(ctx context.Context, pdfPath string, page int) (string, error) {
+	pageArg := strconv.Itoa(page)
+	output, err := exec.CommandContext(ctx, "pdftotext", "-f", pageArg, "-l", pageArg, "-layout", "-enc", "UTF-8", pdfPath, "-").Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("%w: page %d: %s", ErrInvalidPDF, page, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", technicalErrorf("pdftotext failed on page %d: %w", page, err)
+	}
+	return string(output), nil
+}
+
+func renderPDFPage(ctx context.Context, pdfPath, tempDir string, page int) ([]byte, error) {
+	pageArg := strconv.Itoa(page)
+	outputPrefix := filepath.Join(tempDir, "page-"+pageArg)
+
+	cmd := exec.CommandContext(ctx, "pdftoppm",
+		"-f", pageArg,
+		"-l", pageArg,
+		"-r", strconv.Itoa(pdfRenderDPI),
+		"-png",
+		"-singlefile",
+		pdfPath,
+		outputPrefix,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, technicalErrorf("pdftoppm failed on page %d: %w, output: %s", page, err, string(output))
+	}
+
+	image, err := os.ReadFile(outputPrefix + ".png")
+	if err != nil {
+		return nil, technicalErrorf("failed to read rendered page %d: %w", page, err)
+	}
+	return image, nil
+}
@@ -0,0 +1,89 @@
+package extraction
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePDFInfoPages(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:   "typical output",
+			output: "Title:          Grandma's Cookbook\nProducer:       LibreOffice\nTagged:         no\nPages:          12\nEncrypted:      no\nPage size:      595 x 842 pts (A4)\n",
+			want:   12,
+		},
+		{
+			name:   "Windows line endings",
+			output: "Creator:        Word\r\nPages:          3\r\nEncrypted:      no\r\n",
+			want:   3,
+		},
+		{
+			name:    "no pages",
+			output:  "Producer:       broken\nPages:          0\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing page count",
+			output:  "Syntax Error: Couldn't find trailer dictionary\n",
+			wantErr: true,
+		},
+		{
+			name:    "pages in the title",
+			output:  "Title:          Pages: 5\nEncrypted:      no\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePDFInfoPages(tt.output)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPDF) {
+					t.Errorf("expected ErrInvalidPDF, got %d, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("pages = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPDF_RejectsNonPDF(t *testing.T) {
+	if _, err := ReadPDF(context.Background(), []byte("<html>not a pdf</html>")); !errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("expected ErrInvalidPDF, got %v", err)
+	}
+}
+
+func TestReadPDF_StopsWhenCancelled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pdfinfo"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatalf("failed to write pdfinfo stub: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := ReadPDF(ctx, []byte("%PDF-1.4"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline error rather than an invalid PDF, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected pdfinfo to be killed, took %s", elapsed)
+	}
+}
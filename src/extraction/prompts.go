@@ -0,0 +1,109 @@
+package extraction
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DefaultPromptVersion is the prompt version jobs are extracted with unless
+// configured otherwise.
+const DefaultPromptVersion = "v1"
+
+// Every prompt version has one template of each kind: text for sources that
+// are sent as text or images, audio for audio tracks.
+const (
+	promptKindText  = "text"
+	promptKindAudio = "audio"
+)
+
+// promptData is what prompt templates are executed with. Content is the
+// source text for text prompts and the video description for audio prompts.
+type promptData struct {
+	Source  string
+	Content string
+}
+
+//go:embed prompts
+var promptFiles embed.FS
+
+// prompts holds the prompt templates by version. A version is a directory in
+// src/extraction/prompts; once jobs have been extracted with a version, its
+// templates should not change, so that ratings can be compared by version.
+var prompts = template.Must(loadPrompts(promptFiles, "prompts"))
+
+func loadPrompts(fsys fs.FS, root string) (*template.Template, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	set := template.New("")
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, kind := range []string{promptKindText, promptKindAudio} {
+			data, err := fs.ReadFile(fsys, path.Join(root, entry.Name(), kind+".tmpl"))
+			if err != nil {
+				return nil, fmt.Errorf("prompt version %s: %w", entry.Name(), err)
+			}
+			name := entry.Name() + "/" + kind
+			if _, err := set.New(name).Parse(strings.TrimSuffix(string(data), "\n")); err != nil {
+				return nil, fmt.Errorf("prompt version %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return set, nil
+}
+
+// PromptVersions lists the available prompt versions in order.
+func PromptVersions() []string {
+	var versions []string
+	for _, tmpl := range prompts.Templates() {
+		if version, kind, ok := strings.Cut(tmpl.Name(), "/"); ok && kind == promptKindText {
+			versions = append(versions, version)
+		}
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// IsPromptVersion reports whether version is one of PromptVersions.
+func IsPromptVersion(version string) bool {
+	return prompts.Lookup(version+"/"+promptKindText) != nil
+}
+
+type promptVersionKey struct{}
+
+// WithPromptVersion returns a context whose LLM requests use the prompts of
+// version instead of DefaultPromptVersion.
+func WithPromptVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, promptVersionKey{}, version)
+}
+
+func promptVersionFromContext(ctx context.Context) string {
+	if version, ok := ctx.Value(promptVersionKey{}).(string); ok && version != "" {
+		return version
+	}
+	return DefaultPromptVersion
+}
+
+func renderPrompt(ctx context.Context, kind string, data promptData) (string, error) {
+	version := promptVersionFromContext(ctx)
+	tmpl := prompts.Lookup(version + "/" + kind)
+	if tmpl == nil {
+		return "", fmt.Errorf("unknown prompt version %q", version)
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt %s: %w", kind, version, err)
+	}
+	return prompt.String(), nil
+}
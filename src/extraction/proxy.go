@@ -0,0 +1,100 @@
+package extraction
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// fetchProxy is an HTTP proxy that connects only to public addresses. Tools
+// that fetch URLs themselves, such as yt-dlp, follow redirects and load URLs
+// they find in pages, so checking the URL they were given isn't enough;
+// sending all their requests through the proxy checks every connection.
+type fetchProxy struct {
+	forward *httputil.ReverseProxy
+}
+
+func newFetchProxy() *fetchProxy {
+	return &fetchProxy{
+		forward: &httputil.ReverseProxy{
+			// Proxy requests carry the absolute target URL, which the
+			// outgoing request already has.
+			Rewrite:      func(*httputil.ProxyRequest) {},
+			Transport:    fetchTransport,
+			ErrorHandler: proxyError,
+		},
+	}
+}
+
+func (p *fetchProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	if r.URL.Scheme != "http" || r.URL.Host == "" {
+		http.Error(w, "only absolute http URLs can be proxied", http.StatusBadRequest)
+		return
+	}
+	p.forward.ServeHTTP(w, r)
+}
+
+// tunnel connects to the host of a CONNECT request and copies bytes both
+// ways until either side closes.
+func (p *fetchProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	target, err := dialPublic(r.Context(), "tcp", r.Host)
+	if err != nil {
+		proxyError(w, r, err)
+		return
+	}
+	defer target.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "tunneling is not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		io.Copy(target, buffered)
+		// Unblock the copy below if the client is done first.
+		target.Close()
+	}()
+	io.Copy(client, target)
+	client.Close()
+	wg.Wait()
+}
+
+func proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrBlockedURL) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+// startFetchProxy serves a fetchProxy on a loopback port and returns its URL
+// and a function that stops it.
+func startFetchProxy() (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	server := &http.Server{Handler: newFetchProxy()}
+	go server.Serve(listener)
+	return "http://" + listener.Addr().String(), func() { server.Close() }, nil
+}
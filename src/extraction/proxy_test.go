@@ -0,0 +1,82 @@
+package extraction
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// proxiedClient returns a client for server that sends its requests through
+// a fetchProxy.
+func proxiedClient(t *testing.T, server *httptest.Server) *http.Client {
+	t.Helper()
+
+	proxyURL, stop, err := startFetchProxy()
+	if err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	t.Cleanup(stop)
+
+	parsed, _ := url.Parse(proxyURL)
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(parsed)
+	return &http.Client{Transport: transport}
+}
+
+func TestFetchProxy_ForwardsToPublicAddresses(t *testing.T) {
+	for name, newServer := range map[string]func(http.Handler) *httptest.Server{
+		"http":  httptest.NewServer,
+		"https": httptest.NewTLSServer,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "video")
+			}))
+			defer server.Close()
+			allowServer(t, server)
+
+			resp, err := proxiedClient(t, server).Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || string(body) != "video" {
+				t.Errorf("got status %d and body %q", resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestFetchProxy_BlocksPrivateAddresses(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request to reach the server")
+	})
+
+	t.Run("http", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := proxiedClient(t, server).Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status 403, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("https", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		if resp, err := proxiedClient(t, server).Get(server.URL); err == nil {
+			resp.Body.Close()
+			t.Error("expected the tunnel to be refused")
+		}
+	})
+}
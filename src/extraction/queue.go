@@ -0,0 +1,119 @@
+package extraction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// runnerJobType is the job type extraction jobs are run as. The kind of
+// extraction (website, video, translation, ...) is the JobType of the
+// extraction job in the payload.
+const runnerJobType = "extraction"
+
+// jobQueue runs the extraction_jobs table as a job queue. Extraction jobs
+// keep their own table since they belong to users and carry their inputs,
+// results and usage; claiming takes turns between users so one user's batch
+// can't occupy every worker. The claimed extraction job is the payload.
+type jobQueue struct {
+	store      store.ExtractionJobStore
+	maxPerUser int
+}
+
+func (q *jobQueue) Claim(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error) {
+	if !slices.Contains(jobTypes, runnerJobType) {
+		return nil, nil
+	}
+
+	job, err := q.store.ClaimPendingJob(ctx, workerID, q.maxPerUser)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := q.store.IncrementAttemptCount(ctx, job.ID); err != nil {
+		slog.Error("Failed to increment attempt count", "job_id", job.ID, "error", err)
+	}
+	job.AttemptCount++
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extraction job: %w", err)
+	}
+	return &store.Job{
+		ID:           job.ID,
+		Type:         runnerJobType,
+		Payload:      payload,
+		Status:       job.Status,
+		AttemptCount: job.AttemptCount,
+		RetryAfter:   job.RetryAfter,
+		WorkerID:     &workerID,
+		CreatedAt:    job.CreatedAt,
+		UpdatedAt:    job.UpdatedAt,
+	}, nil
+}
+
+func (q *jobQueue) Heartbeat(ctx context.Context, id int, workerID string) error {
+	return q.store.Heartbeat(ctx, id, workerID)
+}
+
+// Complete does nothing: extraction jobs are marked completed by the worker
+// before their user is notified, so the job page is up to date when they
+// follow the link.
+func (q *jobQueue) Complete(ctx context.Context, id int) error {
+	return nil
+}
+
+// Retry requeues the job right away if retryAfter has passed and schedules it
+// otherwise. The error isn't kept, since the job page only shows the error of
+// failed jobs.
+func (q *jobQueue) Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error {
+	if !retryAfter.After(time.Now()) {
+		return q.store.ResetForRetry(ctx, id)
+	}
+	return q.store.ScheduleRetry(ctx, id, retryAfter)
+}
+
+func (q *jobQueue) Fail(ctx context.Context, id int, lastError string) error {
+	return q.store.UpdateStatus(ctx, id, "failed", &lastError)
+}
+
+func (q *jobQueue) Release(ctx context.Context, id int, workerID string) error {
+	return q.store.ReleaseJob(ctx, id, workerID)
+}
+
+func (q *jobQueue) ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error) {
+	reaped, err := q.store.ReapStaleJobs(ctx, staleBefore, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]store.Job, 0, len(reaped))
+	for _, job := range reaped {
+		payload, err := json.Marshal(store.ExtractionJob{ID: job.ID, UserID: job.UserID, JobType: job.JobType})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode extraction job: %w", err)
+		}
+		jobs = append(jobs, store.Job{
+			ID:        job.ID,
+			Type:      runnerJobType,
+			Payload:   payload,
+			Status:    job.Status,
+			LastError: job.ErrorMessage,
+			WorkerID:  job.WorkerID,
+		})
+	}
+	return jobs, nil
+}
+
+// decodeJob returns the extraction job a claimed job was made from.
+func decodeJob(job *store.Job) (*store.ExtractionJob, error) {
+	var extractionJob store.ExtractionJob
+	if err := json.Unmarshal(job.Payload, &extractionJob); err != nil {
+		return nil, fmt.Errorf("failed to decode extraction job: %w", err)
+	}
+	return &extractionJob, nil
+}
@@ -0,0 +1,279 @@
+package extraction
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxStructuredTags limits the tags taken from a page's recipe data, which
+// often lists dozens of keywords.
+const maxStructuredTags = 5
+
+// findJSONLDRecipe returns the first schema.org Recipe node in the JSON-LD
+// blocks of an HTML page, or nil.
+func findJSONLDRecipe(htmlContent string) map[string]any {
+	for _, match := range jsonLDRegexp.FindAllStringSubmatch(htmlContent, -1) {
+		var data any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(match[1])), &data); err != nil {
+			continue
+		}
+		if node := recipeNode(data); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// recipeNode looks for a Recipe node in JSON-LD data, which may be a single
+// node, a list of nodes or a @graph.
+func recipeNode(data any) map[string]any {
+	switch node := data.(type) {
+	case []any:
+		for _, item := range node {
+			if recipe := recipeNode(item); recipe != nil {
+				return recipe
+			}
+		}
+	case map[string]any:
+		if isRecipeNode(node["@type"]) {
+			return node
+		}
+		if graph, ok := node["@graph"]; ok {
+			return recipeNode(graph)
+		}
+	}
+	return nil
+}
+
+func isRecipeNode(nodeType any) bool {
+	switch t := nodeType.(type) {
+	case string:
+		return t == "Recipe"
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s == "Recipe" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recipeFromJSONLD maps the schema.org Recipe of an HTML page to a recipe. It
+// returns nil if the page has no Recipe or the Recipe lacks ingredients or
+// instructions, so the page can still be handed to the LLM.
+func recipeFromJSONLD(htmlContent string) *ExtractedRecipe {
+	node := findJSONLDRecipe(htmlContent)
+	if node == nil {
+		return nil
+	}
+
+	var ingredients []string
+	for _, ingredient := range jsonLDStrings(node["recipeIngredient"]) {
+		if ingredient = cleanJSONLDText(ingredient); ingredient != "" {
+			ingredients = append(ingredients, "- "+ingredient)
+		}
+	}
+	instructions := jsonLDInstructions(node["recipeInstructions"])
+	title := cleanJSONLDText(jsonLDString(node["name"]))
+	if title == "" || len(ingredients) == 0 || instructions == "" {
+		return nil
+	}
+
+	recipe := &ExtractedRecipe{
+		Title:              title,
+		Description:        cleanJSONLDText(jsonLDString(node["description"])),
+		IngredientsMD:      strings.Join(ingredients, "\n"),
+		InstructionsMD:     instructions,
+		PrepTimeMinutes:    parseISODuration(jsonLDString(node["prepTime"])),
+		CookTimeMinutes:    parseISODuration(jsonLDString(node["cookTime"])),
+		CaloriesPerServing: jsonLDCalories(node["nutrition"]),
+		SuggestedTags:      jsonLDTags(node),
+		Confidence:         0.95,
+		ConfidenceNotes:    "Read from the recipe data published by the site rather than extracted from the page text.",
+	}
+	if servings := cleanJSONLDText(jsonLDString(node["recipeYield"])); servings != "" {
+		if recipe.Description != "" {
+			recipe.Description += "\n\n"
+		}
+		recipe.Description += "Servings: " + servings
+	}
+	return recipe
+}
+
+// jsonLDContent renders the schema.org Recipe of an HTML page as text, for
+// pages whose visible text doesn't hold the recipe. It returns "" if the page
+// has no complete Recipe.
+func jsonLDContent(htmlContent string) string {
+	recipe := recipeFromJSONLD(htmlContent)
+	if recipe == nil {
+		return ""
+	}
+
+	parts := []string{recipe.Title}
+	if recipe.Description != "" {
+		parts = append(parts, recipe.Description)
+	}
+	parts = append(parts, "Ingredients:\n"+recipe.IngredientsMD, "Instructions:\n"+recipe.InstructionsMD)
+	return strings.Join(parts, "\n\n")
+}
+
+// jsonLDString reads a text property. Lists, such as recipeYield's
+// ["4", "4 servings"], yield their last, usually most descriptive, entry.
+func jsonLDString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		for i := len(v) - 1; i >= 0; i-- {
+			if s := jsonLDString(v[i]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// jsonLDStrings reads a property that holds a list of texts, or a single
+// text.
+func jsonLDStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// jsonLDInstructions renders recipeInstructions as a numbered markdown list.
+// The property can be a single text with one step per line, a list of texts,
+// HowToSteps or HowToSections of steps. Section names are kept as bold lines
+// between the steps, which stay numbered throughout.
+func jsonLDInstructions(value any) string {
+	var lines []string
+	step := 0
+	addStep := func(text string) {
+		if text = cleanJSONLDText(text); text != "" {
+			step++
+			lines = append(lines, fmt.Sprintf("%d. %s", step, text))
+		}
+	}
+
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case string:
+			for _, line := range strings.Split(stripHTMLTags(html.UnescapeString(v)), "\n") {
+				addStep(line)
+			}
+		case []any:
+			for _, item := range v {
+				if text, ok := item.(string); ok {
+					addStep(text)
+				} else {
+					walk(item)
+				}
+			}
+		case map[string]any:
+			if items, ok := v["itemListElement"]; ok {
+				if name := cleanJSONLDText(jsonLDString(v["name"])); name != "" {
+					if len(lines) > 0 {
+						lines = append(lines, "")
+					}
+					lines = append(lines, "**"+name+"**", "")
+				}
+				walk(items)
+				return
+			}
+			text := jsonLDString(v["text"])
+			if text == "" {
+				text = jsonLDString(v["name"])
+			}
+			addStep(text)
+		}
+	}
+	walk(value)
+
+	return strings.Join(lines, "\n")
+}
+
+var caloriesRegexp = regexp.MustCompile(`\d+`)
+
+// jsonLDCalories reads the calories of a NutritionInformation, e.g.
+// "550 kcal".
+func jsonLDCalories(value any) *int {
+	nutrition, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	match := caloriesRegexp.FindString(jsonLDString(nutrition["calories"]))
+	calories, err := strconv.Atoi(match)
+	if err != nil || calories == 0 {
+		return nil
+	}
+	return &calories
+}
+
+// jsonLDTags turns the category, cuisine and keywords of a recipe into tags
+// in the format the LLM is asked for: lowercase and hyphenated.
+func jsonLDTags(node map[string]any) []string {
+	var candidates []string
+	for _, property := range []string{"recipeCategory", "recipeCuisine", "keywords"} {
+		for _, value := range jsonLDStrings(node[property]) {
+			candidates = append(candidates, strings.Split(value, ",")...)
+		}
+	}
+
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		tag := normalizeTag(cleanJSONLDText(candidate))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxStructuredTags {
+			break
+		}
+	}
+	return tags
+}
+
+var isoDurationRegexp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:\d+(?:\.\d+)?S)?)?$`)
+
+// parseISODuration returns the minutes of an ISO 8601 duration like "PT1H30M"
+// or "P0DT0H20M", or nil if there are none.
+func parseISODuration(duration string) *int {
+	matches := isoDurationRegexp.FindStringSubmatch(strings.TrimSpace(duration))
+	if matches == nil {
+		return nil
+	}
+	days, _ := strconv.Atoi(matches[1])
+	hours, _ := strconv.Atoi(matches[2])
+	minutes, _ := strconv.Atoi(matches[3])
+	total := days*24*60 + hours*60 + minutes
+	if total == 0 {
+		return nil
+	}
+	return &total
+}
+
+// cleanJSONLDText decodes entities, drops markup that some sites leave in
+// their recipe data and collapses whitespace.
+func cleanJSONLDText(text string) string {
+	return strings.Join(strings.Fields(stripHTMLTags(html.UnescapeString(text))), " ")
+}
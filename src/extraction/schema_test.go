@@ -0,0 +1,139 @@
+package extraction
+
+import (
+	"testing"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     int
+	}{
+		{"PT20M", 20},
+		{"PT1H30M", 90},
+		{"P0DT0H20M", 20},
+		{"P1DT2H", 26 * 60},
+		{"PT45M30S", 45},
+		{" PT5M ", 5},
+		{"PT0M", 0},
+		{"PT30S", 0},
+		{"20 minutes", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		got := parseISODuration(tt.duration)
+		if tt.want == 0 {
+			if got != nil {
+				t.Errorf("parseISODuration(%q) = %d, want nil", tt.duration, *got)
+			}
+			continue
+		}
+		if got == nil || *got != tt.want {
+			t.Errorf("parseISODuration(%q) = %v, want %d", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestJSONLDInstructions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			"text with a step per line",
+			"Mix the batter.\n\nFry the pancakes.\n",
+			"1. Mix the batter.\n2. Fry the pancakes.",
+		},
+		{
+			"list of texts",
+			[]any{"Mix the batter.", "  Fry the\n pancakes. "},
+			"1. Mix the batter.\n2. Fry the pancakes.",
+		},
+		{
+			"HowToSteps with markup",
+			[]any{
+				map[string]any{"@type": "HowToStep", "text": "<p>Mix the <strong>batter</strong>.</p>"},
+				map[string]any{"@type": "HowToStep", "name": "Fry the pancakes."},
+			},
+			"1. Mix the batter.\n2. Fry the pancakes.",
+		},
+		{
+			"HowToSections",
+			[]any{
+				map[string]any{"@type": "HowToSection", "name": "Batter", "itemListElement": []any{
+					map[string]any{"@type": "HowToStep", "text": "Mix."},
+				}},
+				map[string]any{"@type": "HowToSection", "name": "Cooking", "itemListElement": []any{
+					map[string]any{"@type": "HowToStep", "text": "Fry."},
+					map[string]any{"@type": "HowToStep", "text": "Serve."},
+				}},
+			},
+			"**Batter**\n\n1. Mix.\n\n**Cooking**\n\n2. Fry.\n3. Serve.",
+		},
+		{"missing", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonLDInstructions(tt.value); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecipeFromJSONLD(t *testing.T) {
+	page := `<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+		{"@type": "WebPage", "name": "Pancakes | Example"},
+		{"@type": ["Recipe", "NewsArticle"], "name": "Pancakes &amp; Syrup",
+		 "recipeYield": ["4", "4 pancakes"], "cookTime": "PT15M",
+		 "recipeIngredient": ["200 g flour", "", "2 eggs"],
+		 "recipeInstructions": "Mix.\nFry.",
+		 "nutrition": {"calories": "250 calories"},
+		 "recipeCategory": "Breakfast", "keywords": "breakfast, Quick Meal, sweet"}
+	]}</script>`
+
+	recipe := recipeFromJSONLD(page)
+	if recipe == nil {
+		t.Fatal("expected a recipe")
+	}
+	if recipe.Title != "Pancakes & Syrup" {
+		t.Errorf("Title = %q", recipe.Title)
+	}
+	if recipe.Description != "Servings: 4 pancakes" {
+		t.Errorf("Description = %q", recipe.Description)
+	}
+	if recipe.IngredientsMD != "- 200 g flour\n- 2 eggs" {
+		t.Errorf("IngredientsMD = %q", recipe.IngredientsMD)
+	}
+	if recipe.InstructionsMD != "1. Mix.\n2. Fry." {
+		t.Errorf("InstructionsMD = %q", recipe.InstructionsMD)
+	}
+	if recipe.PrepTimeMinutes != nil || recipe.CookTimeMinutes == nil || *recipe.CookTimeMinutes != 15 {
+		t.Errorf("PrepTimeMinutes = %v, CookTimeMinutes = %v", recipe.PrepTimeMinutes, recipe.CookTimeMinutes)
+	}
+	if recipe.CaloriesPerServing == nil || *recipe.CaloriesPerServing != 250 {
+		t.Errorf("CaloriesPerServing = %v", recipe.CaloriesPerServing)
+	}
+	if got := recipe.SuggestedTags; len(got) != 3 || got[0] != "breakfast" || got[1] != "quick-meal" || got[2] != "sweet" {
+		t.Errorf("SuggestedTags = %v", got)
+	}
+}
+
+func TestRecipeFromJSONLD_IncompleteRecipe(t *testing.T) {
+	pages := map[string]string{
+		"no JSON-LD":      `<html><body><h1>Pancakes</h1></body></html>`,
+		"no Recipe":       `<script type="application/ld+json">{"@type": "Article", "name": "Pancakes"}</script>`,
+		"no ingredients":  `<script type="application/ld+json">{"@type": "Recipe", "name": "Pancakes", "recipeInstructions": "Fry."}</script>`,
+		"no instructions": `<script type="application/ld+json">{"@type": "Recipe", "name": "Pancakes", "recipeIngredient": ["2 eggs"]}</script>`,
+		"invalid JSON":    `<script type="application/ld+json">{"@type": "Recipe",</script>`,
+	}
+
+	for name, page := range pages {
+		if recipe := recipeFromJSONLD(page); recipe != nil {
+			t.Errorf("%s: expected no recipe, got %+v", name, recipe)
+		}
+	}
+}
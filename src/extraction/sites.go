@@ -0,0 +1,71 @@
+package extraction
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// siteExtractor handles the pages of a site that the generic website
+// extraction doesn't get right. Every hook is optional: without fetch the
+// page is fetched like any other, without content its text is extracted with
+// ExtractTextContent, and without recipe, or when recipe finds nothing, the
+// LLM extracts the recipe from the text.
+type siteExtractor struct {
+	name string
+	// hosts are path.Match patterns for the page's hostname, like
+	// "*.chefkoch.de".
+	hosts []string
+	// fetch returns the HTML of the page at pageURL, e.g. after fetching all
+	// pages of a recipe that is split across several.
+	fetch func(ctx context.Context, pageURL string) (string, error)
+	// content selects the text the LLM extracts the recipe from.
+	content func(htmlContent string) string
+	// recipe maps the page to a recipe directly, skipping the LLM. It returns
+	// nil if the page doesn't have what it needs.
+	recipe func(htmlContent string) *ExtractedRecipe
+}
+
+// siteExtractors are checked in order; the first whose hosts match handles
+// the page.
+var siteExtractors = []siteExtractor{
+	{
+		// Chefkoch pages are mostly ads, comments and suggestions, and split
+		// the recipe over several articles, but carry all of it as JSON-LD.
+		name:    "chefkoch",
+		hosts:   []string{"chefkoch.de", "*.chefkoch.de"},
+		content: jsonLDContent,
+		recipe:  recipeFromJSONLD,
+	},
+	{
+		// NYT Cooking hides the recipe behind its paywall, while the JSON-LD
+		// it includes for search engines holds all of it.
+		name:    "nyt-cooking",
+		hosts:   []string{"cooking.nytimes.com"},
+		content: jsonLDContent,
+		recipe:  recipeFromJSONLD,
+	},
+}
+
+// siteExtractorFor returns the extractor for the site of pageURL, or nil if
+// the page takes the generic path.
+func siteExtractorFor(pageURL string) *siteExtractor {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return nil
+	}
+
+	for i := range siteExtractors {
+		for _, pattern := range siteExtractors[i].hosts {
+			if matched, _ := path.Match(pattern, host); matched {
+				return &siteExtractors[i]
+			}
+		}
+	}
+	return nil
+}
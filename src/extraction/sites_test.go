@@ -0,0 +1,177 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/utils"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the site extractor tests")
+
+// siteSamples names a saved page in test/llm-extraction/samples for every
+// site extractor, and the URL it was saved from.
+var siteSamples = map[string]struct {
+	url    string
+	sample string
+}{
+	"chefkoch":    {"https://www.chefkoch.de/rezepte/1234561234567/Kaiserschmarrn-von-Omas-Kueche.html", "website-04-chefkoch-de.html"},
+	"nyt-cooking": {"https://cooking.nytimes.com/recipes/1025000-sheet-pan-chicken-with-lemon-and-olives", "website-05-nyt-cooking-en.html"},
+}
+
+// TestSiteExtractors_Golden reads the saved page of every site extractor and
+// compares the result with testdata/sites/<name>.golden.json. Run with
+// -update to rewrite the golden files after an intended change.
+func TestSiteExtractors_Golden(t *testing.T) {
+	for _, site := range siteExtractors {
+		t.Run(site.name, func(t *testing.T) {
+			sample, ok := siteSamples[site.name]
+			if !ok {
+				t.Fatalf("no saved page for site extractor %s", site.name)
+			}
+			if matched := siteExtractorFor(sample.url); matched == nil || matched.name != site.name {
+				t.Fatalf("expected %s to be handled by %s", sample.url, site.name)
+			}
+
+			body, err := os.ReadFile(filepath.Join(utils.GetBasePath(), "test", "llm-extraction", "samples", sample.sample))
+			if err != nil {
+				t.Fatalf("failed to read sample: %v", err)
+			}
+
+			got, err := json.MarshalIndent(readWebsitePage(&site, string(body), sample.url), "", "  ")
+			if err != nil {
+				t.Fatalf("failed to encode page: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "sites", site.name+".golden.json")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("page doesn't match %s, run with -update if the change is intended\ngot:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestSiteExtractorFor(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.chefkoch.de/rezepte/123/Kaiserschmarrn.html", "chefkoch"},
+		{"https://chefkoch.de/rezepte/123/Kaiserschmarrn.html", "chefkoch"},
+		{"https://WWW.CHEFKOCH.DE./rezepte/123/", "chefkoch"},
+		{"https://cooking.nytimes.com/recipes/1025000", "nyt-cooking"},
+		{"https://www.nytimes.com/2024/01/10/dining/chicken.html", ""},
+		{"https://notchefkoch.de/rezepte/123/", ""},
+		{"https://chefkoch.de.example.com/rezepte/123/", ""},
+		{"https://example.com/?next=https://www.chefkoch.de/", ""},
+		{"not a url", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if site := siteExtractorFor(tt.url); site != nil {
+			got = site.name
+		}
+		if got != tt.want {
+			t.Errorf("siteExtractorFor(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestFetchWebsite_UsesSiteExtractorHooks(t *testing.T) {
+	pages := map[string]string{
+		"/recipe":        `<html><body><article><p>Step 1: Mix.</p></article><a rel="next" href="/recipe?page=2">Next</a></body></html>`,
+		"/recipe?page=2": `<html><body><article><p>Step 2: Bake.</p></article></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, pages[r.URL.RequestURI()])
+	}))
+	defer server.Close()
+	allowServer(t, server)
+
+	nextPage := regexp.MustCompile(`<a rel="next" href="([^"]+)"`)
+	original := siteExtractors
+	siteExtractors = []siteExtractor{{
+		name:  "paged",
+		hosts: []string{"127.0.0.1"},
+		fetch: func(ctx context.Context, pageURL string) (string, error) {
+			var combined strings.Builder
+			for pageURL != "" {
+				body, err := fetchURL(ctx, pageURL)
+				if err != nil {
+					return "", err
+				}
+				combined.WriteString(body)
+				pageURL = ""
+				if match := nextPage.FindStringSubmatch(body); match != nil {
+					pageURL = resolveURL(server.URL, match[1])
+				}
+			}
+			return combined.String(), nil
+		},
+		content: func(htmlContent string) string {
+			return strings.ToUpper(normalizeWhitespace(stripHTMLTags(htmlContent)))
+		},
+		recipe: func(htmlContent string) *ExtractedRecipe { return nil },
+	}}
+	t.Cleanup(func() { siteExtractors = original })
+
+	page, err := FetchWebsite(context.Background(), server.URL+"/recipe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Site != "paged" {
+		t.Errorf("Site = %q, want paged", page.Site)
+	}
+	if page.Recipe != nil {
+		t.Errorf("expected no recipe when the site extractor finds none, got %+v", page.Recipe)
+	}
+	if !strings.Contains(page.Content, "STEP 1: MIX.") || !strings.Contains(page.Content, "STEP 2: BAKE.") {
+		t.Errorf("expected the selected content of both pages, got %q", page.Content)
+	}
+}
+
+func TestReadWebsitePage_FallsBackToGenericPath(t *testing.T) {
+	body := `<html><head><meta property="og:image" content="/pancakes.jpg"></head><body><main><h1>Pancakes</h1><p>Mix and fry.</p></main></body></html>`
+
+	page := readWebsitePage(siteExtractorFor("https://www.chefkoch.de/rezepte/1/"), body, "https://www.chefkoch.de/rezepte/1/")
+	if page.Recipe != nil {
+		t.Errorf("expected no recipe for a page without JSON-LD, got %+v", page.Recipe)
+	}
+	if page.Content != "Pancakes\nMix and fry." {
+		t.Errorf("Content = %q, want the generic text extraction", page.Content)
+	}
+	if page.ImageURL != "https://www.chefkoch.de/pancakes.jpg" {
+		t.Errorf("ImageURL = %q", page.ImageURL)
+	}
+
+	generic := readWebsitePage(nil, body, "https://example.com/pancakes")
+	if generic.Site != "" || generic.Content != page.Content {
+		t.Errorf("unexpected generic page %+v", generic)
+	}
+}
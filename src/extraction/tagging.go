@@ -0,0 +1,128 @@
+package extraction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxSuggestedTags limits the tags suggested for a recipe in one run.
+const maxSuggestedTags = 5
+
+// taggingPrompt asks for tags for an existing recipe. The vocabulary lists
+// the tags already in use so the model reuses them instead of inventing
+// near-duplicates.
+const taggingPrompt = `Suggest tags for the following recipe. Return valid JSON only, with no additional text, in this format:
+
+{
+  "tags": ["tag1", "tag2"]
+}
+
+## Rules
+
+- Suggest at most %d tags
+- Prefer tags from the list of existing tags below whenever one fits
+- Only add a new tag if none of the existing ones describes the recipe
+- Tags are lowercase, single words or hyphenated phrases
+- Describe the dish: its course, cuisine, main ingredient, diet or occasion
+
+## Existing tags
+
+%s
+
+## Recipe
+
+%s`
+
+// SuggestTags asks the model for tags fitting recipe, preferring the ones in
+// vocabulary. The returned tags are normalized and deduplicated.
+func (c *LLMClient) SuggestTags(ctx context.Context, recipe *ExtractedRecipe, vocabulary []string) (string, []string, error) {
+	content, err := json.MarshalIndent(map[string]any{
+		"title":           recipe.Title,
+		"description":     recipe.Description,
+		"ingredients_md":  recipe.IngredientsMD,
+		"instructions_md": recipe.InstructionsMD,
+	}, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal recipe: %w", err)
+	}
+	existing := strings.Join(vocabulary, ", ")
+	if existing == "" {
+		existing = "(none yet)"
+	}
+	prompt := fmt.Sprintf(taggingPrompt, maxSuggestedTags, existing, content)
+
+	request := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{
+				Role: "user",
+				Content: []contentPart{
+					{Type: "text", Text: prompt},
+				},
+			},
+		},
+	}
+
+	responseText, err := c.sendRequest(ctx, request)
+	if err != nil {
+		return prompt, nil, err
+	}
+
+	var response struct {
+		Tags []string `json:"tags"`
+	}
+	responseText = trimCodeFence(responseText)
+	if err := json.Unmarshal([]byte(responseText), &response); err != nil {
+		return prompt, nil, technicalErrorf("failed to parse tags JSON: %w (response: %s)", err, truncate(responseText, 200))
+	}
+
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, name := range response.Tags {
+		tag := normalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxSuggestedTags {
+			break
+		}
+	}
+	return prompt, tags, nil
+}
+
+// normalizeTag brings a tag into the format tags are stored in: lowercase,
+// with words joined by hyphens.
+func normalizeTag(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// snapToVocabulary replaces each tag with an existing tag that differs from
+// it only by a plural "s", so "cookie" and "cookies" don't both end up in
+// use. Tags without a close match are kept as they are.
+func snapToVocabulary(tags, vocabulary []string) []string {
+	known := make(map[string]bool, len(vocabulary))
+	for _, name := range vocabulary {
+		known[name] = true
+	}
+
+	snapped := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		switch {
+		case known[tag]:
+		case known[tag+"s"]:
+			tag += "s"
+		case strings.HasSuffix(tag, "s") && known[strings.TrimSuffix(tag, "s")]:
+			tag = strings.TrimSuffix(tag, "s")
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			snapped = append(snapped, tag)
+		}
+	}
+	return snapped
+}
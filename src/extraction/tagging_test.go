@@ -0,0 +1,79 @@
+package extraction
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+func TestSuggestTags(t *testing.T) {
+	client := translationClient(t, "```json\n{\"tags\": [\"Main Course\", \"soup\", \"SOUP\", \"vegan\", \"quick\", \"winter\", \"lentils\"]}\n```")
+
+	prompt, tags, err := client.SuggestTags(context.Background(), &ExtractedRecipe{Title: "Lentil Soup"}, []string{"soup", "dinner"})
+	if err != nil {
+		t.Fatalf("SuggestTags() error = %v", err)
+	}
+
+	want := []string{"main-course", "soup", "vegan", "quick", "winter"}
+	if !slices.Equal(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+	if prompt == "" {
+		t.Error("expected the prompt to be returned")
+	}
+}
+
+func TestSnapToVocabulary(t *testing.T) {
+	vocabulary := []string{"cookies", "soup", "dinner"}
+
+	got := snapToVocabulary([]string{"cookie", "soups", "dinner", "cookies", "brunch"}, vocabulary)
+
+	want := []string{"cookies", "soup", "dinner", "brunch"}
+	if !slices.Equal(got, want) {
+		t.Errorf("snapToVocabulary() = %v, want %v", got, want)
+	}
+}
+
+func TestProcessTagging_SnapsOntoTagsOutsideTheOfferedVocabulary(t *testing.T) {
+	var searched []string
+	var saved []string
+	tagStore := &mocks.MockTagStore{
+		GetMostUsedFunc: func(_ context.Context, _ int) ([]models.Tag, error) {
+			return []models.Tag{{ID: 1, Name: "soup"}}, nil
+		},
+		SearchFunc: func(_ context.Context, query string) ([]models.Tag, error) {
+			searched = append(searched, query)
+			if query == "cookie" {
+				return []models.Tag{{ID: 2, Name: "cookies"}, {ID: 3, Name: "chocolate-cookies"}}, nil
+			}
+			return nil, nil
+		},
+		SaveSuggestionFunc: func(_ context.Context, _, _ int, tags []string) error {
+			saved = tags
+			return nil
+		},
+	}
+	recipeStore := &mocks.MockRecipeStore{
+		GetByIDFunc: func(_ context.Context, id string) (models.Recipe, error) {
+			return models.Recipe{ID: 5, Title: "Cookies"}, nil
+		},
+	}
+	worker := NewWorker(WorkerConfig{}, &processingJobStore{}, &memoryCacheStore{}, recipeStore, tagStore, &mocks.MockAuthStore{}, &mocks.MockUserPreferencesStore{}, nil)
+	worker.llmClient = translationClient(t, `{"tags": ["soup", "cookie", "brunch"]}`)
+
+	recipeID := 5
+	if err := worker.processTagging(context.Background(), &store.ExtractionJob{ID: 1, JobType: "tagging", TargetRecipeID: &recipeID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"cookie", "brunch"}; !slices.Equal(searched, want) {
+		t.Errorf("searched %v, want only the tags that weren't offered %v", searched, want)
+	}
+	if want := []string{"soup", "cookies", "brunch"}; !slices.Equal(saved, want) {
+		t.Errorf("saved %v, want %v", saved, want)
+	}
+}
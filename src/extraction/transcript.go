@@ -11,7 +11,6 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
-	"time"
 )
 
 var (
@@ -21,9 +20,11 @@ var (
 )
 
 type VideoMetadata struct {
-	Title       string
-	Description string
-	RecipeLinks []string
+	Platform     string
+	Title        string
+	Description  string
+	RecipeLinks  []string
+	ThumbnailURL string
 }
 
 type TranscriptSegment struct {
@@ -33,7 +34,7 @@ type TranscriptSegment struct {
 }
 
 var videoIDRegexps = []*regexp.Regexp{
-	regexp.MustCompile(`(?:youtube\.com/watch\?v=|youtu\.be/|youtube\.com/embed/|youtube\.com/v/)([a-zA-Z0-9_-]{11})`),
+	regexp.MustCompile(`(?:youtube\.com/watch\?v=|youtu\.be/|youtube\.com/embed/|youtube\.com/v/|youtube\.com/shorts/)([a-zA-Z0-9_-]{11})`),
 	regexp.MustCompile(`^([a-zA-Z0-9_-]{11})$`),
 }
 
@@ -71,7 +72,7 @@ func FetchYouTubeTranscript(videoURL string, preferredLangs []string) (string, e
 }
 
 func getCaptionTrackURL(videoID string, preferredLangs []string) (string, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := newHTTPClient()
 
 	watchURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
 	req, err := http.NewRequest("GET", watchURL, nil)
@@ -208,7 +209,7 @@ func fetchAndParseTranscript(captionURL string) ([]TranscriptSegment, error) {
 	query.Set("fmt", "srv3")
 	parsedURL.RawQuery = query.Encode()
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := newHTTPClient()
 	resp, err := client.Get(parsedURL.String())
 	if err != nil {
 		return nil, technicalErrorf("failed to fetch transcript: %w", err)
@@ -267,13 +268,14 @@ func formatTranscriptAsText(segments []TranscriptSegment) string {
 	return builder.String()
 }
 
-func FetchVideoMetadata(videoURL string) (*VideoMetadata, error) {
+// fetchYouTubeMetadata reads a video's metadata from its YouTube watch page.
+func fetchYouTubeMetadata(videoURL string) (*VideoMetadata, error) {
 	videoID, err := ExtractVideoID(videoURL)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := newHTTPClient()
 
 	watchURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
 	req, err := http.NewRequest("GET", watchURL, nil)
@@ -306,7 +308,18 @@ func FetchVideoMetadata(videoURL string) (*VideoMetadata, error) {
 		return nil, ErrVideoUnavailable
 	}
 
-	return extractMetadataFromPage(pageContent)
+	metadata, err := extractMetadataFromPage(pageContent)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Platform = "youtube"
+	metadata.ThumbnailURL = FindImageURL(pageContent, watchURL)
+	if metadata.ThumbnailURL == "" {
+		metadata.ThumbnailURL = fmt.Sprintf("https://i.ytimg.com/vi/%s/hqdefault.jpg", videoID)
+	}
+
+	return metadata, nil
 }
 
 func extractMetadataFromPage(pageContent string) (*VideoMetadata, error) {
@@ -0,0 +1,118 @@
+package extraction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/mr-flannery/go-recipe-book/src/markdown"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+// translationPrompt asks for the translation of a recipe in the extraction
+// format. It is not versioned with the extraction prompts, since translations
+// aren't evaluated against samples.
+const translationPrompt = `Translate the following recipe into %s. Return the result as valid JSON only, with no additional text, in the same format as the recipe:
+
+{
+  "title": "Translated title",
+  "description": "Translated description",
+  "ingredients_md": "Translated markdown bullet list of ingredients",
+  "instructions_md": "Translated markdown numbered list of steps",
+  "suggested_tags": ["tag1", "tag2"]
+}
+
+## Rules
+
+- Keep the markdown structure: the same bullet points, numbered steps, headings and blank lines
+- Keep every ingredient reference written as @ingredient{name|quantity}: translate the name and unit words, keep the numbers, and keep the @ingredient{...|...} syntax exactly
+- Keep every link written as [[Recipe Name]] unchanged, since it links to another recipe by its title
+- Keep all quantities, temperatures and times as they are; don't convert units
+- Translate the tags into lowercase, single words or hyphenated phrases
+- Translate naturally, the way a cookbook in %s would phrase it
+
+## Recipe
+
+%s`
+
+var wikilinkRegexp = regexp.MustCompile(`\[\[[^\]]+\]\]`)
+
+// TranslateRecipe translates recipe into the language with the given code.
+// Times and calories are taken from the original. It fails if the translation
+// lost or added ingredient references or recipe links, which would break the
+// rendered recipe.
+func (c *LLMClient) TranslateRecipe(ctx context.Context, recipe *ExtractedRecipe, language string) (string, *ExtractedRecipe, error) {
+	languageName := models.LanguageName(language)
+	if languageName == "" {
+		return "", nil, fmt.Errorf("unsupported language %q", language)
+	}
+
+	original, err := json.MarshalIndent(map[string]any{
+		"title":           recipe.Title,
+		"description":     recipe.Description,
+		"ingredients_md":  recipe.IngredientsMD,
+		"instructions_md": recipe.InstructionsMD,
+		"suggested_tags":  recipe.SuggestedTags,
+	}, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal recipe: %w", err)
+	}
+	prompt := fmt.Sprintf(translationPrompt, languageName, languageName, original)
+
+	request := chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{
+				Role: "user",
+				Content: []contentPart{
+					{Type: "text", Text: prompt},
+				},
+			},
+		},
+	}
+
+	responseText, err := c.sendRequest(ctx, request)
+	if err != nil {
+		return prompt, nil, err
+	}
+
+	translated, err := parseRecipeResponse(responseText)
+	if err != nil {
+		return prompt, nil, err
+	}
+	if err := checkTranslation(recipe, translated); err != nil {
+		return prompt, nil, err
+	}
+
+	translated.PrepTimeMinutes = recipe.PrepTimeMinutes
+	translated.CookTimeMinutes = recipe.CookTimeMinutes
+	translated.CaloriesPerServing = recipe.CaloriesPerServing
+	translated.Confidence = recipe.Confidence
+	translated.ConfidenceNotes = ""
+	translated.ImageURL = recipe.ImageURL
+	translated.Language = language
+	return prompt, translated, nil
+}
+
+// checkTranslation compares the markup of a recipe and its translation. The
+// model occasionally drops a reference, so a mismatch is retried.
+func checkTranslation(original, translated *ExtractedRecipe) error {
+	for _, field := range []struct {
+		name                 string
+		original, translated string
+	}{
+		{"ingredients", original.IngredientsMD, translated.IngredientsMD},
+		{"instructions", original.InstructionsMD, translated.InstructionsMD},
+	} {
+		if want, got := len(markdown.IngredientReferences(field.original)), len(markdown.IngredientReferences(field.translated)); want != got {
+			return technicalErrorf("translated %s have %d ingredient references, the original has %d", field.name, got, want)
+		}
+		want := wikilinkRegexp.FindAllString(field.original, -1)
+		if got := wikilinkRegexp.FindAllString(field.translated, -1); !slices.Equal(want, got) {
+			return technicalErrorf("translated %s link %v, the original links %v", field.name, got, want)
+		}
+	}
+	return nil
+}
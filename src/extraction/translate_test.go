@@ -0,0 +1,99 @@
+package extraction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// translationClient returns a client whose requests are answered with the
+// recipe JSON in reply.
+func translationClient(t *testing.T, reply string) *LLMClient {
+	t.Helper()
+	return NewLLMClientWithHTTPClient("test-key", &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := json.Marshal(map[string]any{
+				"choices": []any{map[string]any{"message": map[string]any{"content": reply}}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(string(body))),
+			}, nil
+		}),
+	})
+}
+
+func TestTranslateRecipe(t *testing.T) {
+	prepTime := 10
+	original := &ExtractedRecipe{
+		Title:           "Pfannkuchen",
+		IngredientsMD:   "- @ingredient{Mehl|250 g}\n- @ingredient{Milch|500 ml}",
+		InstructionsMD:  "1. Alles verrühren.\n2. Mit [[Apfelmus]] servieren.",
+		PrepTimeMinutes: &prepTime,
+		SuggestedTags:   []string{"süß"},
+		Language:        "de",
+	}
+
+	tests := []struct {
+		name      string
+		reply     string
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name:      "keeps markup",
+			reply:     `{"title": "Pancakes", "ingredients_md": "- @ingredient{flour|250 g}\n- @ingredient{milk|500 ml}", "instructions_md": "1. Mix everything.\n2. Serve with [[Apfelmus]].", "suggested_tags": ["sweet"]}`,
+			wantTitle: "Pancakes",
+		},
+		{
+			name:    "lost an ingredient reference",
+			reply:   `{"title": "Pancakes", "ingredients_md": "- flour (250 g)\n- @ingredient{milk|500 ml}", "instructions_md": "1. Mix everything.\n2. Serve with [[Apfelmus]].", "suggested_tags": ["sweet"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "translated a recipe link",
+			reply:   `{"title": "Pancakes", "ingredients_md": "- @ingredient{flour|250 g}\n- @ingredient{milk|500 ml}", "instructions_md": "1. Mix everything.\n2. Serve with [[Apple Sauce]].", "suggested_tags": ["sweet"]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, translated, err := translationClient(t, tt.reply).TranslateRecipe(context.Background(), original, "en")
+			if tt.wantErr {
+				var technical *TechnicalError
+				if !errors.As(err, &technical) {
+					t.Fatalf("err = %v, want a technical error so the job is retried", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TranslateRecipe() error = %v", err)
+			}
+			if translated.Title != tt.wantTitle || translated.Language != "en" {
+				t.Errorf("translated %q into %q", translated.Title, translated.Language)
+			}
+			if translated.PrepTimeMinutes == nil || *translated.PrepTimeMinutes != prepTime {
+				t.Errorf("prep time = %v, want the original's", translated.PrepTimeMinutes)
+			}
+		})
+	}
+}
+
+func TestTranslateRecipe_RejectsUnsupportedLanguages(t *testing.T) {
+	if _, _, err := translationClient(t, "{}").TranslateRecipe(context.Background(), &ExtractedRecipe{Title: "Pancakes"}, "fr"); err == nil {
+		t.Error("TranslateRecipe() into an unsupported language succeeded")
+	}
+}
@@ -1,6 +1,10 @@
 package extraction
 
-import "time"
+import (
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
 
 type JobType string
 
@@ -8,6 +12,8 @@ const (
 	JobTypeWebsite JobType = "website"
 	JobTypeVideo   JobType = "video"
 	JobTypeImage   JobType = "image"
+	JobTypePDF     JobType = "pdf"
+	JobTypeText    JobType = "text"
 )
 
 type JobStatus string
@@ -17,6 +23,14 @@ const (
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelled  JobStatus = "cancelled"
+)
+
+// Job priorities. Jobs with a higher priority are claimed first, so large
+// batches yield to jobs that were submitted one at a time.
+const (
+	PriorityDefault = 0
+	PriorityBatch   = -10
 )
 
 type FeedbackType string
@@ -33,7 +47,6 @@ type Job struct {
 	UserID       int
 	JobType      JobType
 	InputURL     *string
-	InputData    []byte
 	Status       JobStatus
 	ErrorMessage *string
 	LLMInput     *string
@@ -66,4 +79,59 @@ type ExtractedRecipe struct {
 	SuggestedTags      []string `json:"suggested_tags"`
 	Confidence         float64  `json:"confidence"`
 	ConfidenceNotes    string   `json:"confidence_notes"`
+
+	// ImageURL is the recipe image named by the source. It isn't asked from
+	// the LLM but filled in by the worker.
+	ImageURL string `json:"image_url,omitempty"`
+	// Language is the code of the language the recipe is written in, or "" if
+	// it couldn't be detected. Like ImageURL it is filled in by the worker.
+	Language string `json:"language,omitempty"`
+}
+
+// Recipe converts the extraction result into a recipe by authorID.
+func (r *ExtractedRecipe) Recipe(authorID int) models.Recipe {
+	recipe := models.Recipe{
+		Title:          r.Title,
+		Description:    r.Description,
+		IngredientsMD:  r.IngredientsMD,
+		InstructionsMD: r.InstructionsMD,
+		Language:       r.Language,
+		AuthorID:       authorID,
+	}
+	if r.PrepTimeMinutes != nil {
+		recipe.PrepTime = *r.PrepTimeMinutes
+	}
+	if r.CookTimeMinutes != nil {
+		recipe.CookTime = *r.CookTimeMinutes
+	}
+	if r.CaloriesPerServing != nil {
+		recipe.Calories = *r.CaloriesPerServing
+	}
+	return recipe
+}
+
+// NewExtractedRecipe converts a recipe back into the extraction format, e.g.
+// to compare a user's edits with what was extracted.
+func NewExtractedRecipe(recipe models.Recipe) *ExtractedRecipe {
+	extracted := &ExtractedRecipe{
+		Title:          recipe.Title,
+		Description:    recipe.Description,
+		IngredientsMD:  recipe.IngredientsMD,
+		InstructionsMD: recipe.InstructionsMD,
+		SuggestedTags:  []string{},
+		Language:       recipe.Language,
+	}
+	if recipe.PrepTime != 0 {
+		extracted.PrepTimeMinutes = &recipe.PrepTime
+	}
+	if recipe.CookTime != 0 {
+		extracted.CookTimeMinutes = &recipe.CookTime
+	}
+	if recipe.Calories != 0 {
+		extracted.CaloriesPerServing = &recipe.Calories
+	}
+	for _, tag := range recipe.Tags {
+		extracted.SuggestedTags = append(extracted.SuggestedTags, tag.Name)
+	}
+	return extracted
 }
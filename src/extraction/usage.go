@@ -0,0 +1,80 @@
+package extraction
+
+import "context"
+
+// Usage accumulates the token counts and cost of all LLM requests made while
+// processing a single job.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	AudioTokens      int
+	CostUSD          float64
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context that records the usage of every LLM request made
+// with it into the returned Usage.
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{}
+	return context.WithValue(ctx, usageKey{}, usage), usage
+}
+
+func recordUsage(ctx context.Context, model string, reported *chatUsage) {
+	usage, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok || reported == nil {
+		return
+	}
+
+	audioTokens := 0
+	if reported.PromptTokensDetails != nil {
+		audioTokens = reported.PromptTokensDetails.AudioTokens
+	}
+
+	cost := reported.Cost
+	if cost == 0 {
+		cost = estimateCost(model, reported.PromptTokens, reported.CompletionTokens, audioTokens)
+	}
+
+	usage.PromptTokens += reported.PromptTokens
+	usage.CompletionTokens += reported.CompletionTokens
+	usage.AudioTokens += audioTokens
+	usage.CostUSD += cost
+}
+
+// IsZero reports whether no usage has been recorded.
+func (u *Usage) IsZero() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0 && u.CostUSD == 0
+}
+
+// modelPrice is the price in USD per million tokens. Audio tokens are part of
+// the prompt tokens but billed at their own rate.
+type modelPrice struct {
+	prompt     float64
+	completion float64
+	audio      float64
+}
+
+// modelPrices is only used when OpenRouter doesn't report a cost, so it just
+// needs to be close enough for the admin overview and quotas.
+var modelPrices = map[string]modelPrice{
+	"google/gemini-2.5-flash-lite": {prompt: 0.10, completion: 0.40, audio: 0.30},
+	"google/gemini-2.5-flash":      {prompt: 0.30, completion: 2.50, audio: 1.00},
+	"google/gemini-2.5-pro":        {prompt: 1.25, completion: 10.00, audio: 1.25},
+}
+
+func estimateCost(model string, promptTokens, completionTokens, audioTokens int) float64 {
+	price, ok := modelPrices[model]
+	if !ok {
+		return 0
+	}
+
+	textTokens := promptTokens - audioTokens
+	if textTokens < 0 {
+		textTokens = 0
+	}
+
+	return (float64(textTokens)*price.prompt +
+		float64(audioTokens)*price.audio +
+		float64(completionTokens)*price.completion) / 1_000_000
+}
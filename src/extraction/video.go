@@ -0,0 +1,240 @@
+package extraction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var ErrUnsupportedVideo = errors.New("video platform is not supported")
+
+// videoPlatforms are the sites whose video links are extracted as videos.
+// Any other link with a video file extension is a direct link.
+var videoPlatforms = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"youtube", regexp.MustCompile(`^https?://(?:(?:www|m)\.)?(?:youtube\.com/(?:watch|embed/|v/|shorts/)|youtu\.be/)`)},
+	{"instagram", regexp.MustCompile(`^https?://(?:www\.)?instagram\.com/(?:[\w.]+/)?(?:reels?|tv)/[\w-]+`)},
+	{"tiktok", regexp.MustCompile(`^https?://(?:(?:www|m)\.tiktok\.com/@[\w.-]+/video/\d+|(?:vm|vt)\.tiktok\.com/\w+)`)},
+	{"vimeo", regexp.MustCompile(`^https?://(?:(?:www\.)?vimeo\.com/\d+|player\.vimeo\.com/video/\d+)`)},
+	{"facebook", regexp.MustCompile(`^https?://(?:(?:www|m)\.facebook\.com/(?:reel/\d+|watch/?\?v=\d+|[\w.]+/videos/\d+)|fb\.watch/\w+)`)},
+}
+
+var videoFileExtensions = map[string]bool{".mp4": true, ".m4v": true, ".mov": true, ".webm": true}
+
+// VideoPlatform returns the name of the platform a video link belongs to, or
+// "" if the link isn't a video that can be extracted.
+func VideoPlatform(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ""
+	}
+
+	for _, platform := range videoPlatforms {
+		if platform.pattern.MatchString(rawURL) {
+			return platform.name
+		}
+	}
+	if videoFileExtensions[strings.ToLower(path.Ext(parsed.Path))] {
+		return "direct"
+	}
+	return ""
+}
+
+// IsVideoURL reports whether rawURL is a video link that can be extracted.
+func IsVideoURL(rawURL string) bool {
+	return VideoPlatform(rawURL) != ""
+}
+
+// FetchVideoMetadata returns a video's title, description and thumbnail.
+// YouTube metadata is read from the watch page first since that is quicker;
+// other platforms, and YouTube videos whose page can't be read, use yt-dlp.
+func FetchVideoMetadata(ctx context.Context, videoURL string) (*VideoMetadata, error) {
+	// Direct links are plain video files, which have no description.
+	if VideoPlatform(videoURL) == "direct" {
+		return &VideoMetadata{Platform: "direct"}, nil
+	}
+
+	if _, err := ExtractVideoID(videoURL); err == nil {
+		metadata, err := fetchYouTubeMetadata(videoURL)
+		if err == nil || errors.Is(err, ErrVideoUnavailable) {
+			return metadata, err
+		}
+		slog.Warn("Failed to read YouTube watch page, falling back to yt-dlp", "url", videoURL, "error", err)
+	}
+
+	output, err := runYTDLP(ctx, "--dump-single-json", "--skip-download", "--no-playlist", "--no-warnings", videoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Thumbnail   string `json:"thumbnail"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp metadata: %w", err)
+	}
+
+	metadata := &VideoMetadata{
+		Platform:     VideoPlatform(videoURL),
+		Title:        info.Title,
+		Description:  info.Description,
+		ThumbnailURL: info.Thumbnail,
+	}
+	if metadata.Description != "" {
+		metadata.RecipeLinks = extractRecipeLinks(metadata.Description)
+	}
+	return metadata, nil
+}
+
+// FetchVideoTranscript returns a video's captions as text, preferring the
+// languages in preferredLangs. Like FetchVideoMetadata, YouTube captions are
+// read from the watch page first. It returns ErrNoCaptions if the video has
+// no captions in any of the languages, which is always the case for direct
+// links.
+func FetchVideoTranscript(ctx context.Context, videoURL string, preferredLangs []string) (string, error) {
+	if VideoPlatform(videoURL) == "direct" {
+		return "", ErrNoCaptions
+	}
+
+	if _, err := ExtractVideoID(videoURL); err == nil {
+		transcript, err := FetchYouTubeTranscript(videoURL, preferredLangs)
+		if err == nil || errors.Is(err, ErrNoCaptions) || errors.Is(err, ErrVideoUnavailable) {
+			return transcript, err
+		}
+		slog.Warn("Failed to read YouTube captions, falling back to yt-dlp", "url", videoURL, "error", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "video-subs-*")
+	if err != nil {
+		return "", technicalErrorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	subLangs := make([]string, len(preferredLangs))
+	for i, lang := range preferredLangs {
+		subLangs[i] = lang + ".*"
+	}
+
+	_, err = runYTDLP(ctx,
+		"--skip-download",
+		"--write-subs",
+		"--write-auto-subs",
+		"--sub-langs", strings.Join(subLangs, ","),
+		"--sub-format", "vtt/srt/best",
+		"-o", filepath.Join(tempDir, "video.%(ext)s"),
+		"--no-playlist",
+		"--no-warnings",
+		"--quiet",
+		videoURL,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	for _, lang := range preferredLangs {
+		for _, ext := range []string{"vtt", "srt"} {
+			files, _ := filepath.Glob(filepath.Join(tempDir, fmt.Sprintf("video.%s*.%s", lang, ext)))
+			if len(files) == 0 {
+				continue
+			}
+			data, err := os.ReadFile(files[0])
+			if err != nil {
+				return "", technicalErrorf("failed to read subtitles: %w", err)
+			}
+			if text := subtitlesToText(string(data)); text != "" {
+				return text, nil
+			}
+		}
+	}
+
+	return "", ErrNoCaptions
+}
+
+var (
+	subtitleTimingRegexp = regexp.MustCompile(`-->`)
+	subtitleCueIDRegexp  = regexp.MustCompile(`^\d+$`)
+	subtitleTagRegexp    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// subtitlesToText turns WebVTT or SRT subtitles into plain text. Automatic
+// captions repeat each line while it scrolls, so repeated lines are dropped.
+func subtitlesToText(subtitles string) string {
+	var lines []string
+	inHeader := false
+	for _, line := range strings.Split(strings.ReplaceAll(subtitles, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			inHeader = false
+			continue
+		case strings.HasPrefix(line, "WEBVTT"), strings.HasPrefix(line, "NOTE"), strings.HasPrefix(line, "STYLE"), strings.HasPrefix(line, "REGION"):
+			inHeader = true
+			continue
+		case inHeader, subtitleTimingRegexp.MatchString(line), subtitleCueIDRegexp.MatchString(line):
+			continue
+		}
+
+		text := strings.TrimSpace(decodeHTMLEntities(subtitleTagRegexp.ReplaceAllString(line, "")))
+		if text == "" || (len(lines) > 0 && lines[len(lines)-1] == text) {
+			continue
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, " ")
+}
+
+// unavailableVideoMessages are parts of yt-dlp errors for videos that are
+// gone or private, which retrying won't fix.
+var unavailableVideoMessages = []string{
+	"Video unavailable",
+	"Private video",
+	"This video is private",
+	"This video has been removed",
+	"HTTP Error 404",
+}
+
+// runYTDLP runs yt-dlp and returns its standard output. yt-dlp makes all its
+// requests through a fetchProxy, so it can't be sent to internal addresses.
+// Errors for unsupported links and unavailable videos are permanent;
+// everything else is treated as a technical error worth retrying.
+func runYTDLP(ctx context.Context, args ...string) ([]byte, error) {
+	proxyURL, stopProxy, err := startFetchProxy()
+	if err != nil {
+		return nil, technicalErrorf("failed to start fetch proxy: %w", err)
+	}
+	defer stopProxy()
+
+	cmd := exec.CommandContext(ctx, "yt-dlp", append([]string{"--proxy", proxyURL}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if strings.Contains(message, "Unsupported URL") {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedVideo, message)
+		}
+		for _, unavailable := range unavailableVideoMessages {
+			if strings.Contains(message, unavailable) {
+				return nil, fmt.Errorf("%w: %s", ErrVideoUnavailable, message)
+			}
+		}
+		return nil, technicalErrorf("yt-dlp failed: %w, output: %s", err, message)
+	}
+	return output, nil
+}
@@ -0,0 +1,258 @@
+package extraction
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ytdlpStub stands in for yt-dlp. It prints YTDLP_STUB_INFO for metadata
+// requests, writes YTDLP_STUB_SUBS as English subtitles, writes a fake audio
+// file and fails with YTDLP_STUB_ERROR on stderr if that is set. Its
+// arguments are appended to the file named by YTDLP_STUB_ARGS.
+const ytdlpStub = `#!/bin/sh
+echo "$@" >> "$YTDLP_STUB_ARGS"
+if [ -n "$YTDLP_STUB_ERROR" ]; then
+	echo "ERROR: $YTDLP_STUB_ERROR" >&2
+	exit 1
+fi
+output=""
+mode=""
+while [ $# -gt 0 ]; do
+	case "$1" in
+		-o) output="$2"; shift ;;
+		--dump-single-json) mode=info ;;
+		--write-subs) mode=subs ;;
+		-x) mode=audio ;;
+	esac
+	shift
+done
+case "$mode" in
+	info) printf '%s' "$YTDLP_STUB_INFO" ;;
+	subs) if [ -n "$YTDLP_STUB_SUBS" ]; then printf '%s' "$YTDLP_STUB_SUBS" > "${output%.%(ext)s}.en.vtt"; fi ;;
+	audio) printf 'ID3' > "$output" ;;
+esac
+`
+
+// stubYTDLP puts the yt-dlp stub first on the PATH and returns a function
+// that reads the arguments of all calls so far.
+func stubYTDLP(t *testing.T) func() string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "yt-dlp"), []byte(ytdlpStub), 0o755); err != nil {
+		t.Fatalf("failed to write yt-dlp stub: %v", err)
+	}
+	argsFile := filepath.Join(dir, "args")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("YTDLP_STUB_ARGS", argsFile)
+	t.Setenv("YTDLP_STUB_INFO", "")
+	t.Setenv("YTDLP_STUB_SUBS", "")
+	t.Setenv("YTDLP_STUB_ERROR", "")
+
+	return func() string {
+		data, _ := os.ReadFile(argsFile)
+		return string(data)
+	}
+}
+
+func TestVideoPlatform(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "youtube"},
+		{"https://youtube.com/shorts/dQw4w9WgXcQ", "youtube"},
+		{"https://youtu.be/dQw4w9WgXcQ?t=42", "youtube"},
+		{"https://www.instagram.com/reel/C1a2b3c4d5e/", "instagram"},
+		{"https://www.tiktok.com/@chef.anna/video/7301234567890123456", "tiktok"},
+		{"https://vm.tiktok.com/ZMabc123/", "tiktok"},
+		{"https://vimeo.com/123456789", "vimeo"},
+		{"https://www.facebook.com/reel/1234567890", "facebook"},
+		{"https://cdn.example.com/videos/pasta.MP4?token=abc", "direct"},
+		{"https://www.instagram.com/p/C1a2b3c4d5e/", ""},
+		{"https://example.com/recipes/pasta", ""},
+		{"ftp://example.com/pasta.mp4", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := VideoPlatform(tt.url); got != tt.want {
+				t.Errorf("VideoPlatform(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchVideoMetadata_UsesYTDLP(t *testing.T) {
+	args := stubYTDLP(t)
+	t.Setenv("YTDLP_STUB_INFO", `{"title": "Crispy Gnocchi", "description": "Full recipe: https://example.com/recipes/gnocchi", "thumbnail": "https://example.com/thumb.jpg"}`)
+
+	url := "https://www.tiktok.com/@chef.anna/video/7301234567890123456"
+	metadata, err := FetchVideoMetadata(context.Background(), url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if metadata.Platform != "tiktok" || metadata.Title != "Crispy Gnocchi" || metadata.ThumbnailURL != "https://example.com/thumb.jpg" {
+		t.Errorf("metadata = %+v", metadata)
+	}
+	if len(metadata.RecipeLinks) != 1 || metadata.RecipeLinks[0] != "https://example.com/recipes/gnocchi" {
+		t.Errorf("recipe links = %v, want the link from the description", metadata.RecipeLinks)
+	}
+	if !strings.Contains(args(), url) {
+		t.Errorf("yt-dlp was not called with the video URL, got %q", args())
+	}
+}
+
+func TestFetchVideoTranscript_ReadsSubtitles(t *testing.T) {
+	args := stubYTDLP(t)
+	t.Setenv("YTDLP_STUB_SUBS", `WEBVTT
+Kind: captions
+Language: en
+
+00:00:00.000 --> 00:00:02.000 align:start position:0%
+Boil the <c>potatoes</c>
+
+00:00:02.000 --> 00:00:04.000 align:start position:0%
+Boil the potatoes
+then mash them &amp; add flour
+`)
+
+	transcript, err := FetchVideoTranscript(context.Background(), "https://vimeo.com/123456789", []string{"en", "de"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "Boil the potatoes then mash them & add flour"; transcript != want {
+		t.Errorf("transcript = %q, want %q", transcript, want)
+	}
+	if !strings.Contains(args(), "--sub-langs en.*,de.*") {
+		t.Errorf("expected the preferred languages to be requested, got %q", args())
+	}
+}
+
+func TestFetchVideoTranscript_NoSubtitles(t *testing.T) {
+	stubYTDLP(t)
+
+	_, err := FetchVideoTranscript(context.Background(), "https://www.instagram.com/reel/C1a2b3c4d5e/", []string{"en"})
+	if !errors.Is(err, ErrNoCaptions) {
+		t.Errorf("expected ErrNoCaptions, got %v", err)
+	}
+}
+
+func TestRunYTDLP_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		message       string
+		wantErr       error
+		wantTechnical bool
+	}{
+		{"[vimeo] 123456789: Video unavailable", ErrVideoUnavailable, false},
+		{"Unsupported URL: https://example.com/video", ErrUnsupportedVideo, false},
+		{"[tiktok] Unable to download webpage: HTTP Error 503", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			stubYTDLP(t)
+			t.Setenv("YTDLP_STUB_ERROR", tt.message)
+
+			_, err := runYTDLP(context.Background(), "https://vimeo.com/123456789")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			var technical *TechnicalError
+			if errors.As(err, &technical) != tt.wantTechnical {
+				t.Errorf("technical = %v, want %v", !tt.wantTechnical, tt.wantTechnical)
+			}
+		})
+	}
+}
+
+func TestDownloadVideoAudio(t *testing.T) {
+	for _, url := range []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://www.facebook.com/reel/1234567890",
+	} {
+		t.Run(url, func(t *testing.T) {
+			stubYTDLP(t)
+
+			result, err := DownloadVideoAudio(context.Background(), url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := os.Stat(result.FilePath); err != nil {
+				t.Errorf("expected the audio file to exist: %v", err)
+			}
+			if err := result.Cleanup(); err != nil {
+				t.Errorf("cleanup failed: %v", err)
+			}
+			if _, err := os.Stat(result.FilePath); !os.IsNotExist(err) {
+				t.Error("expected cleanup to remove the audio file")
+			}
+		})
+	}
+}
+
+func TestRunYTDLP_UsesFetchProxy(t *testing.T) {
+	args := stubYTDLP(t)
+
+	if _, err := runYTDLP(context.Background(), "https://vimeo.com/123456789"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(args(), "--proxy http://127.0.0.1:") {
+		t.Errorf("expected yt-dlp to use the fetch proxy, got %q", args())
+	}
+}
+
+func TestDownloadVideoAudio_DirectLink(t *testing.T) {
+	args := stubYTDLP(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		io.WriteString(w, "video data")
+	}))
+	defer server.Close()
+	allowServer(t, server)
+
+	result, err := DownloadVideoAudio(context.Background(), server.URL+"/pasta.mp4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer result.Cleanup()
+
+	if strings.Contains(args(), server.URL) {
+		t.Errorf("expected yt-dlp not to fetch the link itself, got %q", args())
+	}
+	if !strings.Contains(args(), "--enable-file-urls file://") || !strings.Contains(args(), "video.mp4") {
+		t.Errorf("expected yt-dlp to read the downloaded file, got %q", args())
+	}
+}
+
+func TestDownloadVideoAudio_DirectLinkRedirectToPrivateAddress(t *testing.T) {
+	args := stubYTDLP(t)
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the redirect not to be followed")
+	}))
+	defer internal.Close()
+	server := httptest.NewServer(http.RedirectHandler(internal.URL+"/video.mp4", http.StatusFound))
+	defer server.Close()
+	allowServer(t, server)
+
+	_, err := DownloadVideoAudio(context.Background(), server.URL+"/pasta.mp4")
+	if !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("expected ErrBlockedURL, got %v", err)
+	}
+	if args() != "" {
+		t.Errorf("expected yt-dlp not to run, got %q", args())
+	}
+}
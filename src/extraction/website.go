@@ -1,6 +1,7 @@
 package extraction
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,7 +10,6 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
-	"time"
 )
 
 var (
@@ -21,72 +21,109 @@ var (
 
 const maxContentSize = 5 * 1024 * 1024 // 5MB
 
-func newHTTPClient() *http.Client {
-	return &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 5 {
-				return errors.New("too many redirects")
-			}
-			return nil
-		},
-	}
+// WebsitePage is a fetched recipe website.
+type WebsitePage struct {
+	// Content is the text of the page.
+	Content string
+	// URL is the URL that was actually used. It differs from the requested
+	// URL when the Wayback Machine fallback is used.
+	URL string
+	// ImageURL is the recipe image named by the page, or "".
+	ImageURL string
+	// Site is the name of the site extractor that handled the page, or "".
+	Site string
+	// Recipe is the recipe a site extractor read from the page's structured
+	// data. If it is set, the page needs no LLM extraction.
+	Recipe *ExtractedRecipe
 }
 
-// FetchWebsiteContent fetches and extracts text from the given URL.
-// If the direct fetch fails for any reason, it falls back to the most
-// recent Wayback Machine snapshot.
-// The second return value is the URL that was actually used (may differ from
-// the input when the Wayback Machine fallback is used).
-func FetchWebsiteContent(websiteURL string) (content string, usedURL string, err error) {
-	content, directErr := fetchURL(websiteURL)
-	if directErr == nil {
-		return content, websiteURL, nil
+// FetchWebsite fetches the given URL and extracts its text and recipe image.
+// If the direct fetch fails for any reason other than the URL being blocked,
+// it falls back to the most recent Wayback Machine snapshot. Pages of sites
+// with a site extractor are fetched and read by it.
+func FetchWebsite(ctx context.Context, websiteURL string) (*WebsitePage, error) {
+	site := siteExtractorFor(websiteURL)
+	fetchPage := fetchURL
+	if site != nil && site.fetch != nil {
+		fetchPage = site.fetch
 	}
 
-	// Try Wayback Machine as fallback.
-	archiveURL, archiveErr := lookupWaybackURL(websiteURL)
-	if archiveErr != nil {
-		return "", "", fmt.Errorf("%w (wayback lookup also failed: %v)", directErr, archiveErr)
+	body, directErr := fetchPage(ctx, websiteURL)
+	usedURL := websiteURL
+	if errors.Is(directErr, ErrBlockedURL) || errors.Is(directErr, ErrInvalidURL) {
+		return nil, directErr
 	}
+	if directErr != nil {
+		// Try Wayback Machine as fallback.
+		archiveURL, archiveErr := lookupWaybackURL(ctx, websiteURL)
+		if archiveErr != nil {
+			return nil, fmt.Errorf("%w (wayback lookup also failed: %v)", directErr, archiveErr)
+		}
 
-	content, archiveFetchErr := fetchURL(archiveURL)
-	if archiveFetchErr != nil {
-		return "", "", fmt.Errorf("%w (wayback fetch also failed: %v)", directErr, archiveFetchErr)
+		var archiveFetchErr error
+		body, archiveFetchErr = fetchURL(ctx, archiveURL)
+		if archiveFetchErr != nil {
+			return nil, fmt.Errorf("%w (wayback fetch also failed: %v)", directErr, archiveFetchErr)
+		}
+		usedURL = archiveURL
 	}
 
-	return content, archiveURL, nil
+	return readWebsitePage(site, body, usedURL), nil
 }
 
-// fetchURL performs the actual HTTP fetch of a single URL and returns
-// the extracted text content. All errors include the URL for context.
-func fetchURL(websiteURL string) (string, error) {
-	parsedURL, err := url.Parse(websiteURL)
-	if err != nil {
-		return "", ErrInvalidURL
+// readWebsitePage extracts the text and recipe image of the HTML page at
+// pageURL, using site's hooks if site isn't nil.
+func readWebsitePage(site *siteExtractor, body, pageURL string) *WebsitePage {
+	page := &WebsitePage{
+		URL:      pageURL,
+		ImageURL: FindImageURL(body, pageURL),
 	}
-
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return "", ErrInvalidURL
+	if site != nil {
+		page.Site = site.name
+		if site.recipe != nil {
+			page.Recipe = site.recipe(body)
+		}
+		if site.content != nil {
+			page.Content = site.content(body)
+		}
 	}
-
-	if parsedURL.Host == "" {
-		return "", ErrInvalidURL
+	if page.Content == "" {
+		page.Content = ExtractTextContent(body)
 	}
+	return page
+}
 
-	client := newHTTPClient()
-
-	req, err := http.NewRequest("GET", websiteURL, nil)
+// FetchWebsiteContent fetches and extracts text from the given URL, like
+// FetchWebsite. The second return value is the URL that was actually used.
+func FetchWebsiteContent(ctx context.Context, websiteURL string) (content string, usedURL string, err error) {
+	page, err := FetchWebsite(ctx, websiteURL)
 	if err != nil {
-		return "", fmt.Errorf("%w %s: %v", ErrFetchFailed, websiteURL, err)
+		return "", "", err
 	}
+	return page.Content, page.URL, nil
+}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9,de;q=0.8")
-
-	resp, err := client.Do(req)
-	if err != nil {
+// fetchURL performs the actual HTTP fetch of a single URL and returns
+// the HTML. All errors include the URL for context.
+func fetchURL(ctx context.Context, websiteURL string) (string, error) {
+	resp, err := fetch(ctx, fetchRequest{
+		URL: websiteURL,
+		Header: http.Header{
+			"User-Agent":      {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+			"Accept":          {"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+			"Accept-Language": {"en-US,en;q=0.9,de;q=0.8"},
+		},
+		ContentTypes: []string{"text/html", "application/xhtml+xml"},
+		MaxSize:      maxContentSize,
+	})
+	switch {
+	case errors.Is(err, ErrInvalidURL):
+		return "", err
+	case errors.Is(err, ErrBlockedURL), errors.Is(err, ErrContentTooLarge):
+		return "", fmt.Errorf("%w %s: %w", ErrFetchFailed, websiteURL, err)
+	case errors.Is(err, ErrUnexpectedContentType):
+		return "", fmt.Errorf("%w %s: not an HTML page (%w)", ErrFetchFailed, websiteURL, err)
+	case err != nil:
 		return "", technicalErrorf("%w %s: %v", ErrFetchFailed, websiteURL, err)
 	}
 	defer resp.Body.Close()
@@ -104,34 +141,30 @@ func fetchURL(websiteURL string) (string, error) {
 		return "", technicalErrorf("%w %s: status %d", ErrFetchFailed, urlContext, resp.StatusCode)
 	}
 
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.Contains(contentType, "text/html") && !strings.Contains(contentType, "application/xhtml") {
-		return "", fmt.Errorf("%w %s: not an HTML page (content-type: %s)", ErrFetchFailed, urlContext, contentType)
+	body, err := io.ReadAll(resp.Body)
+	if errors.Is(err, ErrContentTooLarge) {
+		return "", fmt.Errorf("%w %s: %w", ErrFetchFailed, urlContext, err)
 	}
-
-	limitedReader := io.LimitReader(resp.Body, maxContentSize+1)
-	body, err := io.ReadAll(limitedReader)
 	if err != nil {
 		return "", technicalErrorf("%w %s: %v", ErrFetchFailed, urlContext, err)
 	}
 
-	if len(body) > maxContentSize {
-		return "", ErrContentTooLarge
-	}
-
-	return ExtractTextContent(string(body)), nil
+	return string(body), nil
 }
 
 // lookupWaybackURL queries the Wayback Machine CDX API for the most recent
 // successful snapshot of the given URL and returns the playback URL.
-func lookupWaybackURL(originalURL string) (string, error) {
+func lookupWaybackURL(ctx context.Context, originalURL string) (string, error) {
 	cdxURL := fmt.Sprintf(
 		"https://web.archive.org/cdx/search/cdx?url=%s&output=json&limit=1&fl=timestamp,statuscode&filter=statuscode:200&from=20200101&to=&collapse=digest&fastLatest=true",
 		url.QueryEscape(originalURL),
 	)
 
-	client := newHTTPClient()
-	resp, err := client.Get(cdxURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", cdxURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CDX API request: %w", err)
+	}
+	resp, err := newHTTPClient().Do(req)
 	if err != nil {
 		return "", fmt.Errorf("CDX API request failed: %w", err)
 	}
@@ -2,29 +2,41 @@ package extraction
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/mr-flannery/go-recipe-book/src/jobs"
 	"github.com/mr-flannery/go-recipe-book/src/logging"
 	"github.com/mr-flannery/go-recipe-book/src/mail"
-	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 )
 
 var tracer = otel.Tracer("extraction")
 
 const (
-	maxAutoRetries  = 1
-	technicalRetry  = 1 * time.Hour
+	maxAutoRetries = 1
+	technicalRetry = 1 * time.Hour
+	resultCacheTTL = 30 * 24 * time.Hour
+
+	defaultMaxJobsPerUser = 1
+
+	// maxInterruptedAttempts is how many attempts a job gets before the reaper
+	// fails it instead of requeueing it, so a job that keeps crashing its
+	// worker doesn't loop forever.
+	maxInterruptedAttempts = 3
 )
 
 // TechnicalError wraps errors that are caused by transient infrastructure
@@ -42,153 +54,573 @@ func technicalErrorf(format string, args ...any) error {
 }
 
 type WorkerConfig struct {
-	Concurrency      int
-	PollInterval     time.Duration
+	Concurrency  int
+	PollInterval time.Duration
+	// MaxJobsPerUser is how many jobs of a single user may be processed at
+	// the same time, so one user's batch can't occupy every worker.
+	// Defaults to 1.
+	MaxJobsPerUser int
+	// HeartbeatInterval is how often claimed jobs are marked as alive, checked
+	// for cancellation, and stale jobs are reaped. Defaults to 10 seconds.
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long a processing job may go without a heartbeat
+	// before it is requeued. Defaults to 3 minutes.
+	StaleAfter time.Duration
+	// StopTimeout is how long Stop waits for in-flight jobs to finish before
+	// handing them back to the queue. Defaults to 30 seconds.
+	StopTimeout time.Duration
+	// ID identifies this process in the jobs it claims. Defaults to
+	// hostname and PID.
+	ID               string
 	OpenRouterAPIKey string
 	BaseURL          string
+	// Experiment decides which prompt version and model each job is
+	// extracted with. Defaults to DefaultPromptVersion and the default model
+	// for every job.
+	Experiment Experiment
 }
 
 type Worker struct {
 	config      WorkerConfig
 	jobStore    store.ExtractionJobStore
+	cacheStore  store.ExtractionCacheStore
 	recipeStore store.RecipeStore
 	tagStore    store.TagStore
 	authStore   store.AuthStore
-	mailClient  mail.MailClient
+	prefsStore  store.UserPreferencesStore
+	notifier    *notifications.Notifier
 	llmClient   *LLMClient
-	stopCh      chan struct{}
-	wg          sync.WaitGroup
+	runner      *jobs.Runner
 }
 
 func NewWorker(
 	config WorkerConfig,
 	jobStore store.ExtractionJobStore,
+	cacheStore store.ExtractionCacheStore,
 	recipeStore store.RecipeStore,
 	tagStore store.TagStore,
 	authStore store.AuthStore,
-	mailClient mail.MailClient,
+	prefsStore store.UserPreferencesStore,
+	notifier *notifications.Notifier,
 ) *Worker {
-	return &Worker{
+	if config.MaxJobsPerUser <= 0 {
+		config.MaxJobsPerUser = defaultMaxJobsPerUser
+	}
+	if config.ID == "" {
+		config.ID = jobs.DefaultWorkerID()
+	}
+	config.Experiment = config.Experiment.withDefaults()
+
+	w := &Worker{
 		config:      config,
 		jobStore:    jobStore,
+		cacheStore:  cacheStore,
 		recipeStore: recipeStore,
 		tagStore:    tagStore,
 		authStore:   authStore,
-		mailClient:  mailClient,
+		prefsStore:  prefsStore,
+		notifier:    notifier,
 		llmClient:   NewLLMClient(config.OpenRouterAPIKey),
-		stopCh:      make(chan struct{}),
 	}
+	w.runner = jobs.NewRunner(jobs.Config{
+		Name:              "extraction",
+		Concurrency:       config.Concurrency,
+		PollInterval:      config.PollInterval,
+		HeartbeatInterval: config.HeartbeatInterval,
+		StaleAfter:        config.StaleAfter,
+		StopTimeout:       config.StopTimeout,
+		MaxStaleAttempts:  maxInterruptedAttempts,
+		ID:                config.ID,
+	}, &jobQueue{store: jobStore, maxPerUser: config.MaxJobsPerUser})
+	w.runner.Register(runnerJobType, jobHandler{w}, jobs.RetryPolicy{MaxAttempts: maxAutoRetries + 1})
+	return w
+}
+
+// ID returns the identifier this worker records on the jobs it claims.
+func (w *Worker) ID() string {
+	return w.config.ID
 }
 
 func (w *Worker) Start() {
-	slog.Info("Starting extraction workers", "concurrency", w.config.Concurrency)
+	w.runner.Start()
+}
 
-	for i := 0; i < w.config.Concurrency; i++ {
-		w.wg.Add(1)
-		go w.workerLoop(i)
+// Stop stops claiming new jobs and waits up to StopTimeout for in-flight jobs
+// to finish. Jobs still running after that are interrupted and handed back to
+// the queue; anything that can't be handed back is left for the reaper.
+func (w *Worker) Stop() {
+	w.runner.Stop()
+}
+
+// jobHandler runs the extraction jobs the worker's runner claims.
+type jobHandler struct {
+	w *Worker
+}
+
+func (h jobHandler) Run(ctx context.Context, job *store.Job) error {
+	extractionJob, err := decodeJob(job)
+	if err != nil {
+		return jobs.Permanent(err)
 	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("extraction.job_type", extractionJob.JobType))
+
+	ctx, usage := WithUsage(ctx)
+	err = h.w.processJob(ctx, extractionJob)
+	h.w.recordJobUsage(context.WithoutCancel(ctx), extractionJob.ID, usage)
+
+	var techErr *TechnicalError
+	if errors.As(err, &techErr) {
+		return jobs.RetryAfter(technicalRetry, err)
+	}
+	return err
 }
 
-func (w *Worker) Stop() {
-	slog.Info("Stopping extraction workers...")
-	close(w.stopCh)
-	w.wg.Wait()
-	slog.Info("Extraction workers stopped")
+// Failed tells the user that their job failed. Tagging runs queue a job per
+// recipe, so their failures are only shown in the admin's job list instead
+// of one message each.
+func (h jobHandler) Failed(ctx context.Context, job *store.Job, err error) {
+	extractionJob, decodeErr := decodeJob(job)
+	if decodeErr != nil {
+		slog.Error("Failed to decode failed job", "job_id", job.ID, "error", decodeErr)
+		return
+	}
+	if extractionJob.JobType != "tagging" {
+		h.w.sendFailureNotification(ctx, extractionJob, err.Error())
+	}
 }
 
-func (w *Worker) workerLoop(workerID int) {
-	defer w.wg.Done()
+func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error {
+	if job.JobType == "translation" {
+		return w.processTranslation(ctx, job)
+	}
+	if job.JobType == "tagging" {
+		return w.processTagging(ctx, job)
+	}
+	if job.TargetRecipeID != nil {
+		return w.processReExtraction(ctx, job)
+	}
+
+	var inputs []store.ExtractionJobInput
+	if job.InputURL == nil {
+		var err error
+		inputs, err = w.jobStore.GetInputs(ctx, job.ID)
+		if err != nil {
+			return technicalErrorf("failed to load job inputs: %w", err)
+		}
+	}
+
+	// Without a key, the cache is neither read nor written. Candidate jobs
+	// skip it so that the experiment only compares what the candidate
+	// extracted, and so their results don't replace the control's.
+	variant := w.assignVariant(job)
+	var cacheKey string
+	if job.BypassCache || variant != w.config.Experiment.Control {
+		logging.Add(ctx, "extraction.bypass_cache", true)
+	} else {
+		cacheKey = jobCacheKey(job, inputs)
+	}
+	llmInput, recipe, cachedVariant, cached := w.lookupCachedRecipe(ctx, cacheKey)
+	if cached {
+		if cachedVariant != (Variant{}) {
+			w.recordVariant(ctx, job, cachedVariant)
+		}
+	} else {
+		var err error
+		llmInput, recipe, err = w.extractRecipe(w.withVariant(ctx, job, variant), job, inputs)
+		if err != nil {
+			return err
+		}
+	}
+	if recipe.Language == "" {
+		recipe.Language = detectRecipeLanguage(recipe)
+	}
+
+	llmOutput := fmt.Sprintf(`{"title": %q, "description": %q, "confidence": %.2f}`,
+		recipe.Title, recipe.Description, recipe.Confidence)
+	if err := w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, llmOutput); err != nil {
+		slog.Error("Failed to update LLM data", "job_id", job.ID, "error", err)
+	}
 
-	ticker := time.NewTicker(w.config.PollInterval)
-	defer ticker.Stop()
+	recipeModel := recipe.Recipe(job.UserID)
+	if job.InputURL != nil {
+		recipeModel.Source = *job.InputURL
+	}
+	recipeModel.Image = w.heroImage(ctx, job, inputs, recipe)
 
-	for {
-		select {
-		case <-w.stopCh:
-			return
-		case <-ticker.C:
-			w.processNextJob(workerID)
+	if claimed, err := w.stillClaimed(ctx, job); !claimed {
+		return err
+	}
+	saveCtx, saveSpan := tracer.Start(ctx, "extraction.save_recipe")
+	recipeID, err := w.recipeStore.Save(saveCtx, recipeModel)
+	saveSpan.End()
+	if err != nil {
+		return fmt.Errorf("failed to save recipe: %w", err)
+	}
+
+	logging.Add(ctx, "recipe.id", recipeID)
+	logging.Add(ctx, "recipe.title", recipe.Title)
+	logging.Add(ctx, "recipe.confidence", recipe.Confidence)
+	logging.Add(ctx, "recipe.language", recipe.Language)
+
+	if len(recipe.SuggestedTags) > 0 {
+		if err := w.tagStore.SetRecipeTags(ctx, recipeID, recipe.SuggestedTags); err != nil {
+			slog.Error("Failed to set recipe tags", "recipe_id", recipeID, "error", err)
 		}
 	}
+
+	if !cached {
+		w.storeCachedRecipe(ctx, cacheKey, recipe, recipeID, variant)
+	}
+
+	if snapshot, err := json.Marshal(recipe); err != nil {
+		slog.Error("Failed to encode extracted recipe", "job_id", job.ID, "error", err)
+	} else if err := w.jobStore.SetExtractedRecipe(ctx, job.ID, snapshot); err != nil {
+		slog.Error("Failed to store extracted recipe", "job_id", job.ID, "error", err)
+	}
+
+	if err := w.jobStore.SetRecipeID(ctx, job.ID, recipeID); err != nil {
+		slog.Error("Failed to set recipe ID on job", "job_id", job.ID, "error", err)
+	}
+
+	w.completeExtraction(ctx, job, recipe.Title, recipeID)
+	w.queueAutoTranslation(ctx, job, recipe, recipeID)
+
+	return nil
 }
 
-func (w *Worker) processNextJob(workerID int) {
-	ctx := context.Background()
+// stillClaimed re-checks that the job is processing by this worker right
+// before its recipe is published. A job cancelled or reaped since the last
+// heartbeat is dropped without an error, so it is neither retried nor failed.
+func (w *Worker) stillClaimed(ctx context.Context, job *store.ExtractionJob) (bool, error) {
+	err := w.jobStore.Heartbeat(ctx, job.ID, w.config.ID)
+	if errors.Is(err, store.ErrJobCancelled) || errors.Is(err, store.ErrJobNotClaimed) {
+		slog.Info("Job was withdrawn before its recipe was saved", "job_id", job.ID, "reason", err)
+		return false, nil
+	}
+	if err != nil {
+		return false, technicalErrorf("failed to check job status: %w", err)
+	}
+	return true, nil
+}
 
-	job, err := w.jobStore.ClaimPendingJob(ctx)
+// processTranslation translates an existing recipe and publishes the result
+// as a new recipe by the job's user, linked to the original. Translations of
+// translations are linked to the original they were made from, so all
+// versions of a recipe share one list.
+func (w *Worker) processTranslation(ctx context.Context, job *store.ExtractionJob) error {
+	if job.TargetRecipeID == nil || job.TargetLanguage == nil {
+		return errors.New("translation job missing recipe or language")
+	}
+
+	original, err := w.recipeStore.GetByID(ctx, strconv.Itoa(*job.TargetRecipeID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("the recipe to translate no longer exists")
+	}
 	if err != nil {
-		slog.Error("Failed to claim job", "worker", workerID, "error", err)
+		return technicalErrorf("failed to load recipe: %w", err)
+	}
+	tags, err := w.tagStore.GetByRecipeID(ctx, original.ID)
+	if err != nil {
+		return technicalErrorf("failed to load recipe tags: %w", err)
+	}
+	original.Tags = tags
+
+	logging.AddMany(ctx, map[string]any{
+		"translation.recipe_id":       original.ID,
+		"translation.source_language": original.Language,
+		"translation.target_language": *job.TargetLanguage,
+	})
+
+	llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_translate")
+	llmSpan.SetAttributes(attribute.String("translation.target_language", *job.TargetLanguage))
+	llmInput, translated, err := w.llmClient.TranslateRecipe(llmCtx, NewExtractedRecipe(original), *job.TargetLanguage)
+	llmSpan.End()
+	if err != nil {
+		if llmInput != "" {
+			_ = w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, "")
+		}
+		return fmt.Errorf("LLM translation failed: %w", err)
+	}
+
+	llmOutput := fmt.Sprintf(`{"title": %q, "description": %q, "language": %q}`,
+		translated.Title, translated.Description, translated.Language)
+	if err := w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, llmOutput); err != nil {
+		slog.Error("Failed to update LLM data", "job_id", job.ID, "error", err)
+	}
+
+	recipeModel := translated.Recipe(job.UserID)
+	recipeModel.Source = original.Source
+	recipeModel.Image = original.Image
+	originalID := original.ID
+	if original.TranslationOf != nil {
+		originalID = *original.TranslationOf
+	}
+	recipeModel.TranslationOf = &originalID
+
+	if claimed, err := w.stillClaimed(ctx, job); !claimed {
+		return err
+	}
+	saveCtx, saveSpan := tracer.Start(ctx, "extraction.save_recipe")
+	recipeID, err := w.recipeStore.Save(saveCtx, recipeModel)
+	saveSpan.End()
+	if err != nil {
+		return fmt.Errorf("failed to save recipe: %w", err)
+	}
+
+	logging.Add(ctx, "recipe.id", recipeID)
+	logging.Add(ctx, "recipe.title", translated.Title)
+
+	if len(translated.SuggestedTags) > 0 {
+		if err := w.tagStore.SetRecipeTags(ctx, recipeID, translated.SuggestedTags); err != nil {
+			slog.Error("Failed to set recipe tags", "recipe_id", recipeID, "error", err)
+		}
+	}
+
+	if err := w.jobStore.SetRecipeID(ctx, job.ID, recipeID); err != nil {
+		slog.Error("Failed to set recipe ID on job", "job_id", job.ID, "error", err)
+	}
+
+	w.completeTranslation(ctx, job, translated.Title, recipeID)
+
+	return nil
+}
+
+// queueAutoTranslation queues a translation of a freshly extracted recipe if
+// its user wants recipes in another language than the one it was detected in.
+// Recipes whose language is unknown are left alone.
+func (w *Worker) queueAutoTranslation(ctx context.Context, job *store.ExtractionJob, recipe *ExtractedRecipe, recipeID int) {
+	if recipe.Language == "" {
 		return
 	}
 
-	if job == nil {
+	prefs, err := w.prefsStore.Get(ctx, job.UserID)
+	if err != nil {
+		slog.Error("Failed to load user preferences", "job_id", job.ID, "error", err)
+		return
+	}
+	if prefs == nil || prefs.TranslationLanguage == "" || prefs.TranslationLanguage == recipe.Language {
 		return
 	}
 
-	ctx, span := tracer.Start(ctx, "extraction.process_job")
-	defer span.End()
+	translationJobID, err := w.jobStore.CreateTranslation(ctx, job.UserID, recipeID, prefs.TranslationLanguage)
+	if err != nil {
+		slog.Error("Failed to queue translation", "job_id", job.ID, "recipe_id", recipeID, "error", err)
+		return
+	}
+	logging.Add(ctx, "extraction.translation_job_id", translationJobID)
+}
+
+// taggingVocabularySize is the number of existing tags offered to the model
+// when suggesting tags.
+const taggingVocabularySize = 100
+
+// knownTags adds the existing tags that match a suggested tag outside the
+// vocabulary offered to the model, so suggestions snap onto every existing
+// tag rather than only the most used ones.
+func (w *Worker) knownTags(ctx context.Context, suggested, vocabulary []string) ([]string, error) {
+	offered := make(map[string]bool, len(vocabulary))
+	for _, name := range vocabulary {
+		offered[name] = true
+	}
+
+	known := slices.Clone(vocabulary)
+	for _, tag := range suggested {
+		if offered[tag] {
+			continue
+		}
+		matches, err := w.tagStore.Search(ctx, strings.TrimSuffix(tag, "s"))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			known = append(known, match.Name)
+		}
+	}
+	return known, nil
+}
 
-	span.SetAttributes(
-		attribute.Int("job.id", job.ID),
-		attribute.String("job.type", job.JobType),
-		attribute.Int("job.attempt", job.AttemptCount+1),
-		attribute.Int("worker.id", workerID),
-	)
+// processTagging suggests tags for an existing recipe and stores the ones it
+// doesn't have yet for an admin to review. The recipe itself is left alone.
+func (w *Worker) processTagging(ctx context.Context, job *store.ExtractionJob) error {
+	if job.TargetRecipeID == nil {
+		return errors.New("tagging job missing recipe")
+	}
 
-	slog.Info("Processing extraction job",
-		"worker", workerID,
-		"job_id", job.ID,
-		"job_type", job.JobType,
-		"attempt", job.AttemptCount+1,
-	)
+	recipe, err := w.recipeStore.GetByID(ctx, strconv.Itoa(*job.TargetRecipeID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("the recipe to tag no longer exists")
+	}
+	if err != nil {
+		return technicalErrorf("failed to load recipe: %w", err)
+	}
+	current, err := w.tagStore.GetByRecipeID(ctx, recipe.ID)
+	if err != nil {
+		return technicalErrorf("failed to load recipe tags: %w", err)
+	}
+	mostUsed, err := w.tagStore.GetMostUsed(ctx, taggingVocabularySize)
+	if err != nil {
+		return technicalErrorf("failed to load tags: %w", err)
+	}
+	vocabulary := make([]string, len(mostUsed))
+	for i, tag := range mostUsed {
+		vocabulary[i] = tag.Name
+	}
 
-	if err := w.jobStore.IncrementAttemptCount(ctx, job.ID); err != nil {
-		slog.Error("Failed to increment attempt count", "job_id", job.ID, "error", err)
+	llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_tagging")
+	llmInput, suggested, err := w.llmClient.SuggestTags(llmCtx, NewExtractedRecipe(recipe), vocabulary)
+	llmSpan.End()
+	if err != nil {
+		if llmInput != "" {
+			_ = w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, "")
+		}
+		return fmt.Errorf("LLM tagging failed: %w", err)
 	}
 
-	err = w.processJob(ctx, job)
+	known, err := w.knownTags(ctx, suggested, vocabulary)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		w.handleJobFailure(ctx, job, err)
-		return
+		return technicalErrorf("failed to look up suggested tags: %w", err)
 	}
 
-	span.SetStatus(codes.Ok, "")
-	slog.Info("Job completed successfully", "job_id", job.ID)
+	existing := make(map[string]bool, len(current))
+	for _, tag := range current {
+		existing[tag.Name] = true
+	}
+	var tags []string
+	for _, tag := range snapToVocabulary(suggested, known) {
+		if !existing[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	llmOutput, err := json.Marshal(map[string]any{"tags": suggested})
+	if err != nil {
+		return fmt.Errorf("failed to encode suggested tags: %w", err)
+	}
+	if err := w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, string(llmOutput)); err != nil {
+		slog.Error("Failed to update LLM data", "job_id", job.ID, "error", err)
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"recipe.id":          recipe.ID,
+		"tagging.suggested":  len(suggested),
+		"tagging.new_tags":   len(tags),
+		"tagging.vocabulary": len(vocabulary),
+	})
+
+	if len(tags) > 0 {
+		if err := w.tagStore.SaveSuggestion(ctx, job.ID, recipe.ID, tags); err != nil {
+			return technicalErrorf("failed to store tag suggestion: %w", err)
+		}
+	}
+
+	if err := w.jobStore.SetRecipeID(ctx, job.ID, recipe.ID); err != nil {
+		slog.Error("Failed to set recipe ID on job", "job_id", job.ID, "error", err)
+	}
+
+	if err := w.jobStore.MarkCompleted(ctx, job.ID, nil); err != nil {
+		slog.Error("Failed to mark job completed", "job_id", job.ID, "error", err)
+	}
+
+	return nil
 }
 
-func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error {
+// processReExtraction extracts an existing recipe's source again and stores
+// the result as a proposed update for the author to review. The cache is
+// skipped since the cached result is what the author wants to improve on.
+func (w *Worker) processReExtraction(ctx context.Context, job *store.ExtractionJob) error {
+	llmInput, recipe, err := w.extractRecipe(w.withVariant(ctx, job, w.assignVariant(job)), job, nil)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := json.Marshal(recipe)
+	if err != nil {
+		return fmt.Errorf("failed to encode proposed recipe: %w", err)
+	}
+	if err := w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, string(proposal)); err != nil {
+		slog.Error("Failed to update LLM data", "job_id", job.ID, "error", err)
+	}
+	if err := w.jobStore.SetProposedRecipe(ctx, job.ID, proposal); err != nil {
+		return technicalErrorf("failed to store proposed recipe: %w", err)
+	}
+
+	logging.Add(ctx, "recipe.id", *job.TargetRecipeID)
+	logging.Add(ctx, "recipe.confidence", recipe.Confidence)
+
+	w.completeReExtraction(ctx, job, recipe.Title)
+
+	return nil
+}
+
+// assignVariant picks the prompt version and model for a job.
+// Re-extractions keep the model the author chose.
+func (w *Worker) assignVariant(job *store.ExtractionJob) Variant {
+	variant := w.config.Experiment.Assign(job.ID)
+	if job.TargetRecipeID != nil && job.Model != nil {
+		variant.Model = *job.Model
+	}
+	return variant
+}
+
+// withVariant records variant on the job and returns a context that
+// extracts with it.
+func (w *Worker) withVariant(ctx context.Context, job *store.ExtractionJob, variant Variant) context.Context {
+	w.recordVariant(ctx, job, variant)
+	return WithPromptVersion(WithModel(ctx, variant.Model), variant.PromptVersion)
+}
+
+// recordVariant records the prompt version and model the job's recipe was
+// extracted with.
+func (w *Worker) recordVariant(ctx context.Context, job *store.ExtractionJob, variant Variant) {
+	logging.Add(ctx, "extraction.prompt_version", variant.PromptVersion)
+	logging.Add(ctx, "extraction.model", variant.Model)
+	if err := w.jobStore.SetVariant(ctx, job.ID, variant.PromptVersion, variant.Model); err != nil {
+		slog.Error("Failed to record prompt version and model", "job_id", job.ID, "error", err)
+	}
+}
+
+// extractRecipe fetches the job's source and runs it through the LLM.
+func (w *Worker) extractRecipe(ctx context.Context, job *store.ExtractionJob, inputs []store.ExtractionJobInput) (string, *ExtractedRecipe, error) {
 	var content string
 	var llmInput string
+	var imageURL string
 	var recipe *ExtractedRecipe
 	var err error
 
 	switch job.JobType {
 	case "website":
 		if job.InputURL == nil {
-			return errors.New("website job missing input URL")
+			return "", nil, errors.New("website job missing input URL")
 		}
 
 		_, fetchSpan := tracer.Start(ctx, "extraction.fetch_website")
-		var usedURL string
-		content, usedURL, err = FetchWebsiteContent(*job.InputURL)
+		page, fetchErr := FetchWebsite(ctx, *job.InputURL)
 		fetchSpan.End()
-		if err != nil {
-			return fmt.Errorf("failed to fetch website: %w", err)
+		if fetchErr != nil {
+			return "", nil, fmt.Errorf("failed to fetch website: %w", fetchErr)
+		}
+		content, imageURL = page.Content, page.ImageURL
+		if page.Site != "" {
+			logging.Add(ctx, "extraction.site", page.Site)
 		}
-		if usedURL != *job.InputURL {
+		if page.URL != *job.InputURL {
 			slog.Info("Used Wayback Machine archive for website extraction",
 				"job_id", job.ID,
 				"original_url", *job.InputURL,
-				"archive_url", usedURL,
+				"archive_url", page.URL,
 			)
 			logging.AddMany(ctx, map[string]any{
 				"extraction.wayback_fallback": true,
-				"extraction.archive_url":      usedURL,
+				"extraction.archive_url":      page.URL,
 			})
 		}
 
+		if page.Recipe != nil {
+			logging.Add(ctx, "extraction.structured_data", true)
+			recipe = page.Recipe
+			break
+		}
+
 		llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_extract")
 		llmSpan.SetAttributes(attribute.String("extraction.source", "website"))
 		llmInput, recipe, err = w.llmClient.ExtractRecipeFromText(llmCtx, "website", content)
@@ -196,11 +628,21 @@ func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error
 
 	case "video":
 		if job.InputURL == nil {
-			return errors.New("video job missing input URL")
+			return "", nil, errors.New("video job missing input URL")
+		}
+
+		platform := VideoPlatform(*job.InputURL)
+		logging.Add(ctx, "extraction.video_platform", platform)
+
+		// Reject blocked links before starting; every connection is checked
+		// again when it is made.
+		if err := CheckURL(ctx, *job.InputURL); err != nil {
+			return "", nil, err
 		}
 
-		_, metaSpan := tracer.Start(ctx, "extraction.fetch_video_metadata")
-		metadata, metaErr := FetchVideoMetadata(*job.InputURL)
+		metaCtx, metaSpan := tracer.Start(ctx, "extraction.fetch_video_metadata")
+		metaSpan.SetAttributes(attribute.String("extraction.video_platform", platform))
+		metadata, metaErr := FetchVideoMetadata(metaCtx, *job.InputURL)
 		metaSpan.End()
 		if metaErr != nil && !errors.Is(metaErr, ErrVideoUnavailable) {
 			slog.Warn("Failed to fetch video metadata", "job_id", job.ID, "error", metaErr)
@@ -209,10 +651,11 @@ func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error
 		var additionalContext string
 		if metadata != nil {
 			additionalContext = w.buildVideoContext(ctx, metadata)
+			imageURL = metadata.ThumbnailURL
 		}
 
-		_, transcriptSpan := tracer.Start(ctx, "extraction.fetch_transcript")
-		content, err = FetchYouTubeTranscript(*job.InputURL, []string{"en", "de"})
+		transcriptCtx, transcriptSpan := tracer.Start(ctx, "extraction.fetch_transcript")
+		content, err = FetchVideoTranscript(transcriptCtx, *job.InputURL, []string{"en", "de"})
 		transcriptSpan.End()
 		if err != nil {
 			if errors.Is(err, ErrNoCaptions) {
@@ -222,10 +665,10 @@ func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error
 				llmInput, recipe, err = w.extractFromAudio(audioCtx, job, additionalContext)
 				audioSpan.End()
 				if err != nil {
-					return fmt.Errorf("audio extraction failed: %w", err)
+					return "", nil, fmt.Errorf("audio extraction failed: %w", err)
 				}
 			} else {
-				return fmt.Errorf("failed to fetch transcript: %w", err)
+				return "", nil, fmt.Errorf("failed to fetch transcript: %w", err)
 			}
 		} else {
 			if additionalContext != "" {
@@ -238,143 +681,298 @@ func (w *Worker) processJob(ctx context.Context, job *store.ExtractionJob) error
 		}
 
 	case "image":
-		if len(job.InputData) == 0 {
-			return errors.New("image job missing input data")
+		if len(inputs) == 0 {
+			return "", nil, errors.New("image job missing input data")
+		}
+
+		llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_extract")
+		llmSpan.SetAttributes(
+			attribute.String("extraction.source", "image"),
+			attribute.Int("extraction.image_count", len(inputs)),
+		)
+		if len(inputs) == 1 {
+			llmInput, recipe, err = w.llmClient.ExtractRecipeFromImage(llmCtx, inputs[0].Data, inputs[0].ContentType)
+		} else {
+			images := make([][]byte, len(inputs))
+			for i, input := range inputs {
+				images[i] = input.Data
+			}
+			llmInput, recipe, err = w.llmClient.ExtractRecipeFromImages(llmCtx, "images", "", images)
+		}
+		llmSpan.End()
+
+	case "pdf":
+		if len(inputs) == 0 {
+			return "", nil, errors.New("pdf job missing input data")
+		}
+
+		pdfCtx, pdfSpan := tracer.Start(ctx, "extraction.read_pdf")
+		document, pdfErr := ReadPDF(pdfCtx, inputs[0].Data)
+		pdfSpan.End()
+		if pdfErr != nil {
+			return "", nil, fmt.Errorf("failed to read PDF: %w", pdfErr)
+		}
+		if document.Text == "" && len(document.PageImages) == 0 {
+			return "", nil, errors.New("PDF contains no readable pages")
+		}
+
+		logging.AddMany(ctx, map[string]any{
+			"extraction.pdf_pages":          document.PageCount,
+			"extraction.pdf_rendered_pages": len(document.PageImages),
+		})
+
+		llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_extract")
+		llmSpan.SetAttributes(
+			attribute.String("extraction.source", "pdf"),
+			attribute.Int("extraction.image_count", len(document.PageImages)),
+		)
+		if len(document.PageImages) == 0 {
+			llmInput, recipe, err = w.llmClient.ExtractRecipeFromText(llmCtx, "pdf", document.Text)
+		} else {
+			llmInput, recipe, err = w.llmClient.ExtractRecipeFromImages(llmCtx, "pdf", document.Text, document.PageImages)
 		}
+		llmSpan.End()
+
+	case "text":
+		if len(inputs) == 0 || len(inputs[0].Data) == 0 {
+			return "", nil, errors.New("text job missing input text")
+		}
+
 		llmCtx, llmSpan := tracer.Start(ctx, "extraction.llm_extract")
-		llmSpan.SetAttributes(attribute.String("extraction.source", "image"))
-		llmInput, recipe, err = w.llmClient.ExtractRecipeFromImage(llmCtx, job.InputData, "")
+		llmSpan.SetAttributes(attribute.String("extraction.source", "text"))
+		llmInput, recipe, err = w.llmClient.ExtractRecipeFromText(llmCtx, "text", string(inputs[0].Data))
 		llmSpan.End()
 
 	default:
-		return fmt.Errorf("unknown job type: %s", job.JobType)
+		return "", nil, fmt.Errorf("unknown job type: %s", job.JobType)
 	}
 
 	if err != nil {
 		if llmInput != "" {
 			_ = w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, "")
 		}
-		return fmt.Errorf("LLM extraction failed: %w", err)
+		return llmInput, nil, fmt.Errorf("LLM extraction failed: %w", err)
 	}
 
-	llmOutput := fmt.Sprintf(`{"title": %q, "description": %q, "confidence": %.2f}`,
-		recipe.Title, recipe.Description, recipe.Confidence)
-	if err := w.jobStore.UpdateLLMData(ctx, job.ID, llmInput, llmOutput); err != nil {
-		slog.Error("Failed to update LLM data", "job_id", job.ID, "error", err)
-	}
+	recipe.ImageURL = imageURL
+	return llmInput, recipe, nil
+}
+
+// heroImage returns the image for a new recipe: a crop of the first uploaded
+// photo for image jobs, otherwise the image named by the source. Images that
+// can't be used are logged and skipped rather than failing the job.
+func (w *Worker) heroImage(ctx context.Context, job *store.ExtractionJob, inputs []store.ExtractionJobInput, recipe *ExtractedRecipe) []byte {
+	ctx, span := tracer.Start(ctx, "extraction.hero_image")
+	defer span.End()
 
-	recipeModel := models.Recipe{
-		Title:          recipe.Title,
-		Description:    recipe.Description,
-		IngredientsMD:  recipe.IngredientsMD,
-		InstructionsMD: recipe.InstructionsMD,
-		AuthorID:       job.UserID,
+	var image []byte
+	var err error
+	switch {
+	case job.JobType == "image" && len(inputs) > 0:
+		image, err = PrepareHeroImage(inputs[0].Data)
+	case recipe.ImageURL != "":
+		span.SetAttributes(attribute.String("extraction.image_url", recipe.ImageURL))
+		image, err = FetchHeroImage(ctx, recipe.ImageURL)
+	default:
+		return nil
+	}
+	if err != nil {
+		slog.Warn("Failed to capture recipe image", "job_id", job.ID, "error", err)
+		logging.Add(ctx, "extraction.image_error", err.Error())
+		return nil
 	}
 
+	logging.Add(ctx, "extraction.image_bytes", len(image))
+	return image
+}
+
+// jobCacheKey returns the result cache key for a job, or "" if the job's input
+// can't be cached.
+func jobCacheKey(job *store.ExtractionJob, inputs []store.ExtractionJobInput) string {
 	if job.InputURL != nil {
-		recipeModel.Source = *job.InputURL
+		key, err := CacheKeyForURL(*job.InputURL)
+		if err != nil {
+			return ""
+		}
+		return key
 	}
+	if len(inputs) == 0 {
+		return ""
+	}
+	return CacheKeyForInputs(job.JobType, inputs)
+}
 
-	if recipe.PrepTimeMinutes != nil {
-		recipeModel.PrepTime = *recipe.PrepTimeMinutes
+// lookupCachedRecipe returns a previous extraction result for the same input
+// and the variant it was extracted with, so that the LLM isn't asked twice
+// for the same recipe.
+func (w *Worker) lookupCachedRecipe(ctx context.Context, cacheKey string) (string, *ExtractedRecipe, Variant, bool) {
+	if cacheKey == "" {
+		return "", nil, Variant{}, false
 	}
-	if recipe.CookTimeMinutes != nil {
-		recipeModel.CookTime = *recipe.CookTimeMinutes
+
+	entry, err := w.cacheStore.Get(ctx, cacheKey)
+	if err != nil {
+		slog.Warn("Failed to look up extraction cache", "cache_key", cacheKey, "error", err)
+		return "", nil, Variant{}, false
 	}
-	if recipe.CaloriesPerServing != nil {
-		recipeModel.Calories = *recipe.CaloriesPerServing
+	if entry == nil {
+		logging.Add(ctx, "extraction.cache_hit", false)
+		return "", nil, Variant{}, false
 	}
 
-	saveCtx, saveSpan := tracer.Start(ctx, "extraction.save_recipe")
-	recipeID, err := w.recipeStore.Save(saveCtx, recipeModel)
-	saveSpan.End()
-	if err != nil {
-		return fmt.Errorf("failed to save recipe: %w", err)
+	var recipe ExtractedRecipe
+	if err := json.Unmarshal(entry.Result, &recipe); err != nil {
+		slog.Warn("Failed to decode cached extraction result", "cache_key", cacheKey, "error", err)
+		return "", nil, Variant{}, false
 	}
 
-	logging.Add(ctx, "recipe.id", recipeID)
-	logging.Add(ctx, "recipe.title", recipe.Title)
-	logging.Add(ctx, "recipe.confidence", recipe.Confidence)
+	logging.AddMany(ctx, map[string]any{
+		"extraction.cache_hit": true,
+		"extraction.cache_key": cacheKey,
+	})
+	variant := Variant{PromptVersion: entry.PromptVersion, Model: entry.Model}
+	return fmt.Sprintf("(cached result from %s for %s)", entry.CreatedAt.Format(time.RFC3339), cacheKey), &recipe, variant, true
+}
 
-	if len(recipe.SuggestedTags) > 0 {
-		if err := w.tagStore.SetRecipeTags(ctx, recipeID, recipe.SuggestedTags); err != nil {
-			slog.Error("Failed to set recipe tags", "recipe_id", recipeID, "error", err)
-		}
+func (w *Worker) storeCachedRecipe(ctx context.Context, cacheKey string, recipe *ExtractedRecipe, recipeID int, variant Variant) {
+	if cacheKey == "" {
+		return
 	}
 
-	if err := w.jobStore.SetRecipeID(ctx, job.ID, recipeID); err != nil {
-		slog.Error("Failed to set recipe ID on job", "job_id", job.ID, "error", err)
+	result, err := json.Marshal(recipe)
+	if err != nil {
+		slog.Warn("Failed to encode extraction result for cache", "cache_key", cacheKey, "error", err)
+		return
 	}
 
-	if err := w.jobStore.MarkCompleted(ctx, job.ID); err != nil {
-		slog.Error("Failed to mark job completed", "job_id", job.ID, "error", err)
+	if err := w.cacheStore.Put(ctx, cacheKey, result, recipeID, variant.PromptVersion, variant.Model, resultCacheTTL); err != nil {
+		slog.Warn("Failed to store extraction result in cache", "cache_key", cacheKey, "error", err)
 	}
+}
 
-	w.sendSuccessNotification(ctx, job, recipe.Title, recipeID)
+func (w *Worker) recordJobUsage(ctx context.Context, jobID int, usage *Usage) {
+	if usage.IsZero() {
+		return
+	}
 
-	return nil
+	logging.AddMany(ctx, map[string]any{
+		"llm.prompt_tokens":     usage.PromptTokens,
+		"llm.completion_tokens": usage.CompletionTokens,
+		"llm.audio_tokens":      usage.AudioTokens,
+		"llm.cost_usd":          usage.CostUSD,
+	})
+
+	err := w.jobStore.AddUsage(ctx, jobID, store.ExtractionUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		AudioTokens:      usage.AudioTokens,
+		CostUSD:          usage.CostUSD,
+	})
+	if err != nil {
+		slog.Error("Failed to record job usage", "job_id", jobID, "error", err)
+	}
 }
 
-func (w *Worker) handleJobFailure(ctx context.Context, job *store.ExtractionJob, jobErr error) {
-	slog.Error("Job failed",
-		"job_id", job.ID,
-		"attempt", job.AttemptCount+1,
-		"error", jobErr,
-	)
+// mailRecipient returns who the emails about a job of userID go to. The
+// emails use the theme and language of the user's preferences if they can
+// be read.
+func (w *Worker) mailRecipient(ctx context.Context, userID int) (mail.Recipient, error) {
+	user, err := w.authStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return mail.Recipient{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	prefs, err := w.prefsStore.Get(ctx, userID)
+	if err != nil {
+		slog.Warn("Failed to get user preferences for email", "user_id", userID, "error", err)
+	}
+	return mail.RecipientFor(user.Email, user.Username, prefs), nil
+}
 
-	var techErr *TechnicalError
-	if errors.As(jobErr, &techErr) {
-		retryAt := time.Now().Add(technicalRetry)
-		slog.Info("Technical failure, scheduling hourly retry",
-			"job_id", job.ID,
-			"retry_at", retryAt,
-		)
-		if err := w.jobStore.ScheduleRetry(ctx, job.ID, retryAt); err != nil {
-			slog.Error("Failed to schedule retry", "job_id", job.ID, "error", err)
+// complete marks the job as completed and adds the notification to the
+// user's notification center. If the user wants emails about finished
+// extractions, the email from render is queued in the same transaction that
+// completes the job, so it goes out exactly when the job is completed.
+func (w *Worker) complete(ctx context.Context, job *store.ExtractionJob, notification store.Notification, render func(to mail.Recipient) (*store.OutgoingEmail, error)) {
+	var email *store.OutgoingEmail
+	if w.notifier.WantsEmail(ctx, job.UserID, notification.Type) {
+		to, err := w.mailRecipient(ctx, job.UserID)
+		if err == nil {
+			email, err = render(to)
+		}
+		if err != nil {
+			slog.Error("Failed to render notification email", "job_id", job.ID, "error", err)
 		}
-		return
 	}
 
-	currentAttempt := job.AttemptCount + 1
-
-	if currentAttempt <= maxAutoRetries {
-		slog.Info("Scheduling automatic retry", "job_id", job.ID, "attempt", currentAttempt)
-		if err := w.jobStore.ResetForRetry(ctx, job.ID); err != nil {
-			slog.Error("Failed to reset job for retry", "job_id", job.ID, "error", err)
-		}
+	if err := w.jobStore.MarkCompleted(ctx, job.ID, email); err != nil {
+		slog.Error("Failed to mark job completed", "job_id", job.ID, "error", err)
 		return
 	}
 
-	errMsg := jobErr.Error()
-	if err := w.jobStore.UpdateStatus(ctx, job.ID, "failed", &errMsg); err != nil {
-		slog.Error("Failed to update job status", "job_id", job.ID, "error", err)
+	if err := w.notifier.Notify(ctx, notification, nil); err != nil {
+		slog.Error("Failed to send notification", "job_id", job.ID, "error", err)
 	}
+}
 
-	w.sendFailureNotification(ctx, job, errMsg)
+func (w *Worker) completeExtraction(ctx context.Context, job *store.ExtractionJob, recipeTitle string, recipeID int) {
+	recipeURL := fmt.Sprintf("%s/recipes/%d", w.config.BaseURL, recipeID)
+	notification := store.Notification{
+		UserID: job.UserID,
+		Type:   notifications.TypeExtractionCompleted,
+		Title:  fmt.Sprintf("Recipe extracted: %s", recipeTitle),
+		Body:   "Your recipe has been extracted and published.",
+		Link:   fmt.Sprintf("/recipes/%d", recipeID),
+	}
+	w.complete(ctx, job, notification, func(to mail.Recipient) (*store.OutgoingEmail, error) {
+		return mail.ExtractionSuccessEmail(to, recipeTitle, recipeURL)
+	})
 }
 
-func (w *Worker) sendSuccessNotification(ctx context.Context, job *store.ExtractionJob, recipeTitle string, recipeID int) {
-	user, err := w.authStore.GetUserByID(ctx, job.UserID)
-	if err != nil {
-		slog.Error("Failed to get user for notification", "user_id", job.UserID, "error", err)
-		return
+func (w *Worker) completeReExtraction(ctx context.Context, job *store.ExtractionJob, recipeTitle string) {
+	reviewPath := fmt.Sprintf("/recipes/%d/re-extract/%d", *job.TargetRecipeID, job.ID)
+	notification := store.Notification{
+		UserID: job.UserID,
+		Type:   notifications.TypeExtractionCompleted,
+		Title:  fmt.Sprintf("Proposed changes ready: %s", recipeTitle),
+		Body:   "Your recipe has been extracted again. Review the changes before they are applied.",
+		Link:   reviewPath,
 	}
+	w.complete(ctx, job, notification, func(to mail.Recipient) (*store.OutgoingEmail, error) {
+		return mail.ReExtractionEmail(to, recipeTitle, w.config.BaseURL+reviewPath)
+	})
+}
 
+func (w *Worker) completeTranslation(ctx context.Context, job *store.ExtractionJob, recipeTitle string, recipeID int) {
 	recipeURL := fmt.Sprintf("%s/recipes/%d", w.config.BaseURL, recipeID)
-	if err := mail.SendExtractionSuccessNotification(ctx, w.mailClient, user.Email, user.Username, recipeTitle, recipeURL); err != nil {
-		slog.Error("Failed to send success notification", "job_id", job.ID, "error", err)
+	notification := store.Notification{
+		UserID: job.UserID,
+		Type:   notifications.TypeExtractionCompleted,
+		Title:  fmt.Sprintf("Recipe translated: %s", recipeTitle),
+		Body:   "Your translation has been published.",
+		Link:   fmt.Sprintf("/recipes/%d", recipeID),
 	}
+	w.complete(ctx, job, notification, func(to mail.Recipient) (*store.OutgoingEmail, error) {
+		return mail.TranslationEmail(to, recipeTitle, recipeURL)
+	})
 }
 
 func (w *Worker) sendFailureNotification(ctx context.Context, job *store.ExtractionJob, errorMessage string) {
-	user, err := w.authStore.GetUserByID(ctx, job.UserID)
-	if err != nil {
-		slog.Error("Failed to get user for notification", "user_id", job.UserID, "error", err)
-		return
-	}
-
 	jobURL := fmt.Sprintf("%s/account/jobs/%d", w.config.BaseURL, job.ID)
-	if err := mail.SendExtractionFailureNotification(ctx, w.mailClient, user.Email, user.Username, errorMessage, jobURL); err != nil {
+	notification := store.Notification{
+		UserID: job.UserID,
+		Type:   notifications.TypeExtractionFailed,
+		Title:  "Recipe extraction failed",
+		Body:   errorMessage,
+		Link:   fmt.Sprintf("/account/jobs/%d", job.ID),
+	}
+	err := w.notifier.Notify(ctx, notification, func(ctx context.Context, mc mail.MailClient) error {
+		to, err := w.mailRecipient(ctx, job.UserID)
+		if err != nil {
+			return err
+		}
+		return mail.SendExtractionFailureNotification(ctx, mc, to, errorMessage, jobURL)
+	})
+	if err != nil {
 		slog.Error("Failed to send failure notification", "job_id", job.ID, "error", err)
 	}
 }
@@ -388,7 +986,7 @@ func (w *Worker) buildVideoContext(ctx context.Context, metadata *VideoMetadata)
 
 	if len(metadata.RecipeLinks) > 0 {
 		for _, link := range metadata.RecipeLinks {
-			html, _, err := FetchWebsiteContent(link)
+			html, _, err := FetchWebsiteContent(ctx, link)
 			if err != nil {
 				slog.Warn("Failed to fetch recipe link", "url", link, "error", err)
 				continue
@@ -401,7 +999,7 @@ func (w *Worker) buildVideoContext(ctx context.Context, metadata *VideoMetadata)
 }
 
 func (w *Worker) extractFromAudio(ctx context.Context, job *store.ExtractionJob, additionalContext string) (string, *ExtractedRecipe, error) {
-	audioResult, err := DownloadYouTubeAudio(*job.InputURL)
+	audioResult, err := DownloadVideoAudio(ctx, *job.InputURL)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to download audio: %w", err)
 	}
@@ -0,0 +1,407 @@
+package extraction
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mailmocks "github.com/mr-flannery/go-recipe-book/src/mail/mocks"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+// blockingJobStore hands out a single text job whose inputs never finish
+// loading until the job's context is cancelled.
+type blockingJobStore struct {
+	store.ExtractionJobStore
+
+	mu           sync.Mutex
+	job          *store.ExtractionJob
+	claimed      bool
+	interrupted  bool
+	heartbeatErr error
+	heartbeats   int
+	released     []int
+	failed       []int
+}
+
+func (s *blockingJobStore) ClaimPendingJob(ctx context.Context, workerID string, maxPerUser int) (*store.ExtractionJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claimed {
+		return nil, nil
+	}
+	s.claimed = true
+	return s.job, nil
+}
+
+func (s *blockingJobStore) GetInputs(ctx context.Context, jobID int) ([]store.ExtractionJobInput, error) {
+	<-ctx.Done()
+	s.mu.Lock()
+	s.interrupted = true
+	s.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func (s *blockingJobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats++
+	return s.heartbeatErr
+}
+
+func (s *blockingJobStore) ReleaseJob(ctx context.Context, id int, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, id)
+	return nil
+}
+
+func (s *blockingJobStore) recordFailure(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, id)
+	return nil
+}
+
+func (s *blockingJobStore) IncrementAttemptCount(ctx context.Context, id int) error { return nil }
+func (s *blockingJobStore) ReapStaleJobs(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.ReapedJob, error) {
+	return nil, nil
+}
+func (s *blockingJobStore) UpdateStatus(ctx context.Context, id int, status string, errorMessage *string) error {
+	return s.recordFailure(id)
+}
+func (s *blockingJobStore) ResetForRetry(ctx context.Context, id int) error {
+	return s.recordFailure(id)
+}
+func (s *blockingJobStore) ScheduleRetry(ctx context.Context, id int, retryAfter time.Time) error {
+	return s.recordFailure(id)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWorkerStop_HandsBackJobsStillRunningAfterTimeout(t *testing.T) {
+	jobStore := &blockingJobStore{job: &store.ExtractionJob{ID: 7, JobType: "text"}}
+	worker := NewWorker(WorkerConfig{
+		Concurrency:       1,
+		PollInterval:      5 * time.Millisecond,
+		HeartbeatInterval: time.Hour,
+		StopTimeout:       20 * time.Millisecond,
+		ID:                "test-worker",
+	}, jobStore, nil, nil, nil, nil, nil, nil)
+
+	worker.Start()
+	waitFor(t, func() bool {
+		jobStore.mu.Lock()
+		defer jobStore.mu.Unlock()
+		return jobStore.claimed
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		worker.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after its timeout")
+	}
+
+	jobStore.mu.Lock()
+	defer jobStore.mu.Unlock()
+	if len(jobStore.released) != 1 || jobStore.released[0] != 7 {
+		t.Errorf("released jobs = %v, want [7]", jobStore.released)
+	}
+	if len(jobStore.failed) != 0 {
+		t.Errorf("expected the interrupted job not to be failed or retried, got %v", jobStore.failed)
+	}
+}
+
+func TestWorker_StopsJobThatWasCancelledOrReaped(t *testing.T) {
+	tests := []struct {
+		name         string
+		heartbeatErr error
+	}{
+		{"cancelled by the user", store.ErrJobCancelled},
+		{"reaped by another worker", store.ErrJobNotClaimed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &blockingJobStore{
+				job:          &store.ExtractionJob{ID: 9, JobType: "text"},
+				heartbeatErr: tt.heartbeatErr,
+			}
+			worker := NewWorker(WorkerConfig{
+				Concurrency:       1,
+				PollInterval:      5 * time.Millisecond,
+				HeartbeatInterval: 5 * time.Millisecond,
+				ID:                "test-worker",
+			}, jobStore, nil, nil, nil, nil, nil, nil)
+
+			worker.Start()
+			waitFor(t, func() bool {
+				jobStore.mu.Lock()
+				defer jobStore.mu.Unlock()
+				return jobStore.interrupted
+			})
+			worker.Stop()
+
+			jobStore.mu.Lock()
+			defer jobStore.mu.Unlock()
+			if jobStore.heartbeats == 0 {
+				t.Error("expected a heartbeat to be sent")
+			}
+			if len(jobStore.released) != 0 || len(jobStore.failed) != 0 {
+				t.Errorf("expected the job to be left alone, got released=%v failed=%v", jobStore.released, jobStore.failed)
+			}
+		})
+	}
+}
+
+// processingJobStore serves a job's inputs and records what processJob
+// stores for it.
+type processingJobStore struct {
+	store.ExtractionJobStore
+
+	inputs        []store.ExtractionJobInput
+	promptVersion string
+	model         string
+	completed     bool
+	email         *store.OutgoingEmail
+	heartbeatErr  error
+}
+
+func (s *processingJobStore) GetInputs(ctx context.Context, jobID int) ([]store.ExtractionJobInput, error) {
+	return s.inputs, nil
+}
+func (s *processingJobStore) UpdateLLMData(ctx context.Context, id int, llmInput, llmOutput string) error {
+	return nil
+}
+func (s *processingJobStore) SetVariant(ctx context.Context, id int, promptVersion, model string) error {
+	s.promptVersion, s.model = promptVersion, model
+	return nil
+}
+func (s *processingJobStore) SetExtractedRecipe(ctx context.Context, id int, recipe []byte) error {
+	return nil
+}
+func (s *processingJobStore) SetRecipeID(ctx context.Context, id int, recipeID int) error { return nil }
+func (s *processingJobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	return s.heartbeatErr
+}
+func (s *processingJobStore) MarkCompleted(ctx context.Context, id int, notification *store.OutgoingEmail) error {
+	s.completed, s.email = true, notification
+	return nil
+}
+
+// memoryCacheStore is an extraction cache in a map.
+type memoryCacheStore struct {
+	entries map[string]*store.ExtractionCacheEntry
+	puts    []string
+}
+
+func (s *memoryCacheStore) Get(ctx context.Context, key string) (*store.ExtractionCacheEntry, error) {
+	return s.entries[key], nil
+}
+func (s *memoryCacheStore) Put(ctx context.Context, key string, result []byte, recipeID int, promptVersion, model string, ttl time.Duration) error {
+	s.puts = append(s.puts, key)
+	s.entries[key] = &store.ExtractionCacheEntry{Key: key, Result: result, RecipeID: &recipeID, PromptVersion: promptVersion, Model: model, CreatedAt: time.Now()}
+	return nil
+}
+func (s *memoryCacheStore) DeleteExpired(ctx context.Context) (int64, error) { return 0, nil }
+
+// newProcessingWorker returns a worker whose LLM answers every request with
+// a pancake recipe and counts them in llmCalls.
+func newProcessingWorker(t *testing.T, config WorkerConfig, jobStore *processingJobStore, cacheStore *memoryCacheStore, llmCalls *int) *Worker {
+	t.Helper()
+
+	authStore := &mocks.MockAuthStore{
+		GetUserByIDFunc: func(ctx context.Context, userID int) (*store.AuthUser, error) {
+			return &store.AuthUser{ID: userID, Username: "cook", Email: "cook@example.com"}, nil
+		},
+	}
+	notifier := notifications.NewNotifier(&mocks.MockNotificationStore{}, &mailmocks.MockMailClient{})
+	worker := NewWorker(config, jobStore, cacheStore, &mocks.MockRecipeStore{}, &mocks.MockTagStore{}, authStore, &mocks.MockUserPreferencesStore{}, notifier)
+
+	llm := translationClient(t, `{"title": "Pancakes", "ingredients_md": "- @ingredient{flour|250 g}", "instructions_md": "1. Mix everything.", "confidence": 0.9}`)
+	transport := llm.httpClient.Transport
+	llm.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*llmCalls++
+		return transport.RoundTrip(req)
+	})
+	worker.llmClient = llm
+	return worker
+}
+
+func TestProcessJob_Cache(t *testing.T) {
+	inputs := []store.ExtractionJobInput{{ContentType: "text/plain", Data: []byte("Pancakes: mix flour, milk and eggs, then fry.")}}
+	cacheKey := CacheKeyForInputs("text", inputs)
+	cachedResult, _ := json.Marshal(ExtractedRecipe{Title: "Cached pancakes"})
+	cachedVariant := Variant{PromptVersion: "v0", Model: "cached-model"}
+	control := Experiment{}.withDefaults().Control
+	candidate := Variant{PromptVersion: control.PromptVersion, Model: "candidate-model"}
+
+	tests := []struct {
+		name         string
+		experiment   Experiment
+		bypassCache  bool
+		cached       bool
+		wantLLMCalls int
+		wantStored   bool
+		wantVariant  Variant
+	}{
+		{name: "uses the cached result and its variant", cached: true, wantVariant: cachedVariant},
+		{name: "stores new results with their variant", wantLLMCalls: 1, wantStored: true, wantVariant: control},
+		{name: "forced job asks the LLM", bypassCache: true, cached: true, wantLLMCalls: 1, wantVariant: control},
+		{
+			name:         "candidate job asks the LLM",
+			experiment:   Experiment{Candidate: candidate, CandidateShare: 100},
+			cached:       true,
+			wantLLMCalls: 1,
+			wantVariant:  candidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &processingJobStore{inputs: inputs}
+			cacheStore := &memoryCacheStore{entries: map[string]*store.ExtractionCacheEntry{}}
+			if tt.cached {
+				cacheStore.entries[cacheKey] = &store.ExtractionCacheEntry{
+					Key:           cacheKey,
+					Result:        cachedResult,
+					PromptVersion: cachedVariant.PromptVersion,
+					Model:         cachedVariant.Model,
+					CreatedAt:     time.Now(),
+				}
+			}
+			var llmCalls int
+			worker := newProcessingWorker(t, WorkerConfig{Experiment: tt.experiment}, jobStore, cacheStore, &llmCalls)
+
+			err := worker.processJob(context.Background(), &store.ExtractionJob{ID: 1, UserID: 2, JobType: "text", BypassCache: tt.bypassCache})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if llmCalls != tt.wantLLMCalls {
+				t.Errorf("LLM calls = %d, want %d", llmCalls, tt.wantLLMCalls)
+			}
+			if stored := len(cacheStore.puts) > 0; stored != tt.wantStored {
+				t.Errorf("stored in cache = %v, want %v", stored, tt.wantStored)
+			}
+			if tt.wantStored {
+				entry := cacheStore.entries[cacheKey]
+				if entry.PromptVersion != tt.wantVariant.PromptVersion || entry.Model != tt.wantVariant.Model {
+					t.Errorf("cached with %s/%s, want %+v", entry.PromptVersion, entry.Model, tt.wantVariant)
+				}
+			}
+			if got := (Variant{PromptVersion: jobStore.promptVersion, Model: jobStore.model}); got != tt.wantVariant {
+				t.Errorf("recorded variant %+v, want %+v", got, tt.wantVariant)
+			}
+			if !jobStore.completed {
+				t.Error("expected the job to be completed")
+			}
+		})
+	}
+}
+
+func TestProcessJob_QueuesSuccessEmailWithCompletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences map[string]bool
+		wantEmail   bool
+	}{
+		{name: "emails by default", wantEmail: true},
+		{name: "respects turned off emails", preferences: map[string]bool{notifications.TypeExtractionCompleted: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &processingJobStore{inputs: []store.ExtractionJobInput{{ContentType: "text/plain", Data: []byte("Pancakes: mix flour, milk and eggs, then fry.")}}}
+			var llmCalls int
+			worker := newProcessingWorker(t, WorkerConfig{BaseURL: "https://recipes.example.com"}, jobStore, &memoryCacheStore{entries: map[string]*store.ExtractionCacheEntry{}}, &llmCalls)
+
+			var sent []store.OutgoingEmail
+			var created []store.Notification
+			worker.notifier = notifications.NewNotifier(&mocks.MockNotificationStore{
+				CreateFunc: func(_ context.Context, n store.Notification) (int, error) {
+					created = append(created, n)
+					return 1, nil
+				},
+				GetEmailPreferencesFunc: func(_ context.Context, _ int) (map[string]bool, error) {
+					return tt.preferences, nil
+				},
+			}, &mailmocks.MockMailClient{
+				SendEmailFunc: func(_ context.Context, email store.OutgoingEmail) error {
+					sent = append(sent, email)
+					return nil
+				},
+			})
+
+			if err := worker.processJob(context.Background(), &store.ExtractionJob{ID: 1, UserID: 2, JobType: "text"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(sent) != 0 {
+				t.Errorf("expected the email to be queued with the completion, got %d sent directly", len(sent))
+			}
+			if got := jobStore.email != nil; got != tt.wantEmail {
+				t.Fatalf("queued email = %v, want %v", got, tt.wantEmail)
+			}
+			if tt.wantEmail && (jobStore.email.Subject != "Recipe extracted: Pancakes" || !strings.Contains(jobStore.email.Body, "https://recipes.example.com/recipes/")) {
+				t.Errorf("unexpected email %+v", jobStore.email)
+			}
+			if len(created) != 1 || created[0].Type != notifications.TypeExtractionCompleted {
+				t.Errorf("expected a completion notification, got %+v", created)
+			}
+		})
+	}
+}
+
+func TestProcessJob_DoesNotPublishWithdrawnJobs(t *testing.T) {
+	for _, heartbeatErr := range []error{store.ErrJobCancelled, store.ErrJobNotClaimed} {
+		t.Run(heartbeatErr.Error(), func(t *testing.T) {
+			jobStore := &processingJobStore{
+				inputs:       []store.ExtractionJobInput{{ContentType: "text/plain", Data: []byte("Pancakes: mix flour, milk and eggs, then fry.")}},
+				heartbeatErr: heartbeatErr,
+			}
+			var llmCalls int
+			worker := newProcessingWorker(t, WorkerConfig{}, jobStore, &memoryCacheStore{entries: map[string]*store.ExtractionCacheEntry{}}, &llmCalls)
+			var saved bool
+			worker.recipeStore = &mocks.MockRecipeStore{
+				SaveFunc: func(context.Context, models.Recipe) (int, error) {
+					saved = true
+					return 1, nil
+				},
+			}
+
+			if err := worker.processJob(context.Background(), &store.ExtractionJob{ID: 1, UserID: 2, JobType: "text"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if saved {
+				t.Error("expected no recipe to be saved")
+			}
+			if jobStore.completed {
+				t.Error("expected the job not to be completed")
+			}
+		})
+	}
+}
@@ -484,3 +484,66 @@ func (h *Handler) SetThemeHandler(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, "/account/theme?success=Theme updated.", http.StatusSeeOther)
 }
+
+type TranslationSettingsData struct {
+	UserInfo            *auth.UserInfo
+	TranslationLanguage string
+	Languages           []models.Language
+	Success             string
+	Error               string
+}
+
+func (h *Handler) GetTranslationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	var translationLanguage string
+	if prefs, err := h.UserPreferencesStore.Get(ctx, userInfo.UserID); err == nil {
+		translationLanguage = prefs.TranslationLanguage
+	}
+
+	data := TranslationSettingsData{
+		UserInfo:            userInfo,
+		TranslationLanguage: translationLanguage,
+		Languages:           models.Languages,
+		Success:             r.URL.Query().Get("success"),
+		Error:               r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "account-translation.gohtml", data)
+}
+
+// SetTranslationLanguageHandler sets the language extracted recipes are
+// translated into. An empty language turns automatic translation off.
+func (h *Handler) SetTranslationLanguageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+	if !userInfo.IsLoggedIn {
+		h.Renderer.RenderError(w, r, http.StatusUnauthorized, "You must be logged in to change the translation language.")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/account/translation?error=Invalid form data.", http.StatusSeeOther)
+		return
+	}
+
+	language := r.FormValue("language")
+	if language != "" && !models.IsLanguage(language) {
+		http.Redirect(w, r, "/account/translation?error=Invalid language selection.", http.StatusSeeOther)
+		return
+	}
+
+	err := h.UserPreferencesStore.SetTranslationLanguage(ctx, userInfo.UserID, language)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to save translation language preference")
+		http.Redirect(w, r, "/account/translation?error=Failed to save translation language.", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":               "account.translation.set",
+		"translation_language": language,
+	})
+
+	http.Redirect(w, r, "/account/translation?success=Translation language updated.", http.StatusSeeOther)
+}
@@ -19,10 +19,12 @@ import (
 )
 
 type MockUserPreferencesStore struct {
-	GetFunc         func(ctx context.Context, userID int) (*models.UserPreferences, error)
-	SetPageSizeFunc func(ctx context.Context, userID, pageSize int) error
-	SetViewModeFunc func(ctx context.Context, userID int, viewMode string) error
-	SetThemeFunc    func(ctx context.Context, userID int, theme string) error
+	GetFunc                    func(ctx context.Context, userID int) (*models.UserPreferences, error)
+	SetPageSizeFunc            func(ctx context.Context, userID, pageSize int) error
+	SetViewModeFunc            func(ctx context.Context, userID int, viewMode string) error
+	SetThemeFunc               func(ctx context.Context, userID int, theme string) error
+	SetTranslationLanguageFunc func(ctx context.Context, userID int, language string) error
+	SetDigestFunc              func(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error
 }
 
 func (m *MockUserPreferencesStore) Get(ctx context.Context, userID int) (*models.UserPreferences, error) {
@@ -53,6 +55,20 @@ func (m *MockUserPreferencesStore) SetTheme(ctx context.Context, userID int, the
 	return nil
 }
 
+func (m *MockUserPreferencesStore) SetTranslationLanguage(ctx context.Context, userID int, language string) error {
+	if m.SetTranslationLanguageFunc != nil {
+		return m.SetTranslationLanguageFunc(ctx, userID, language)
+	}
+	return nil
+}
+
+func (m *MockUserPreferencesStore) SetDigest(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error {
+	if m.SetDigestFunc != nil {
+		return m.SetDigestFunc(ctx, userID, enabled, day, hour)
+	}
+	return nil
+}
+
 func TestGetAccountSettingsHandler_RendersAccountSettingsPage(t *testing.T) {
 	var capturedTemplate string
 	var capturedData any
@@ -753,3 +769,51 @@ func TestDeleteOwnAccountHandler_RedirectsWhenDeleteFails(t *testing.T) {
 		t.Errorf("expected redirect to /account with error, got %s", location)
 	}
 }
+
+func TestSetTranslationLanguageHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		language     string
+		wantSaved    bool
+		wantLocation string
+	}{
+		{"sets a language", "de", true, "/account/translation?success="},
+		{"turns translation off", "", true, "/account/translation?success="},
+		{"rejects unknown languages", "fr", false, "/account/translation?error="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := false
+			h := &Handler{
+				UserPreferencesStore: &MockUserPreferencesStore{
+					SetTranslationLanguageFunc: func(_ context.Context, userID int, language string) error {
+						saved = true
+						if userID != 1 || language != tt.language {
+							t.Errorf("saved %q for user %d", language, userID)
+						}
+						return nil
+					},
+				},
+			}
+
+			form := url.Values{"language": {tt.language}}
+			req := httptest.NewRequest(http.MethodPost, "/account/translation", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+			rec := httptest.NewRecorder()
+
+			h.SetTranslationLanguageHandler(rec, req)
+
+			if rec.Code != http.StatusSeeOther {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("location = %q, want prefix %q", location, tt.wantLocation)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("saved = %v, want %v", saved, tt.wantSaved)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -33,6 +34,16 @@ type APIRecipeResponse struct {
 	RecipeID int    `json:"recipe_id,omitempty"`
 }
 
+type APIExtractTextRequest struct {
+	Text string `json:"text"`
+}
+
+type APIExtractionJobResponse struct {
+	Success bool   `json:"success"`
+	JobID   int    `json:"job_id"`
+	Status  string `json:"status"`
+}
+
 type APIErrorResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error"`
@@ -167,6 +178,70 @@ func (h *Handler) APICreateRecipeHandler(w http.ResponseWriter, r *http.Request)
 	sendJSONResponse(w, "Recipe created successfully", recipeID)
 }
 
+func (h *Handler) APIExtractTextHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// The body may be larger than the text it carries, since JSON escapes
+	// quotes, newlines and control characters.
+	var req APIExtractTextRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTextLength*2))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendJSONError(w, fmt.Sprintf("request body is too large (max %dKB)", tooLarge.Limit/1024), http.StatusRequestEntityTooLarge)
+			return
+		}
+		logging.AddError(ctx, err, "Failed to decode API extract text request")
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		sendJSONError(w, "text is required", http.StatusBadRequest)
+		return
+	}
+	if len(text) > maxTextLength {
+		sendJSONError(w, fmt.Sprintf("text is too long (%dKB, max %dKB)", (len(text)+1023)/1024, maxTextLength/1024), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	userID := auth.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		sendJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if message := h.extractionQuotaExceeded(ctx, userID); message != "" {
+		sendJSONError(w, message, http.StatusTooManyRequests)
+		return
+	}
+
+	jobID, err := h.createTextExtractionJob(ctx, userID, text)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to create extraction job via API")
+		sendJSONError(w, "Failed to create extraction job", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":      "api.extraction.submit",
+		"job_id":      jobID,
+		"job_type":    "text",
+		"text_length": len(text),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(APIExtractionJobResponse{
+		Success: true,
+		JobID:   jobID,
+		Status:  "pending",
+	})
+}
+
 func APIHealthHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		sendJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
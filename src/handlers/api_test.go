@@ -7,10 +7,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
 	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
 )
 
@@ -657,3 +659,100 @@ func TestAPICreateRecipeHandler_CreatesRecipeBasedOnInput(t *testing.T) {
 		}
 	})
 }
+
+func TestAPIExtractTextHandler_CreatesTextJob(t *testing.T) {
+	apiRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/extract/text", bytes.NewBufferString(body))
+		userInfo := &auth.UserInfo{IsLoggedIn: true, UserID: 1, Username: "apiuser"}
+		return req.WithContext(auth.ContextWithUserInfo(req.Context(), userInfo))
+	}
+
+	t.Run("returns error when JSON is invalid", func(t *testing.T) {
+		h := &Handler{}
+		rec := httptest.NewRecorder()
+
+		h.APIExtractTextHandler(rec, apiRequest("not json"))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("returns error when text is empty", func(t *testing.T) {
+		h := &Handler{}
+		rec := httptest.NewRecorder()
+
+		h.APIExtractTextHandler(rec, apiRequest(`{"text": "   "}`))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+
+		var response APIErrorResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		if response.Error != "text is required" {
+			t.Errorf("expected error 'text is required', got '%s'", response.Error)
+		}
+	})
+
+	t.Run("rejects bodies over the size limit", func(t *testing.T) {
+		h := &Handler{}
+		rec := httptest.NewRecorder()
+
+		h.APIExtractTextHandler(rec, apiRequest(`{"text": "`+strings.Repeat("a", maxTextLength*2)+`"}`))
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+		}
+
+		var response APIErrorResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		if !strings.Contains(response.Error, "request body is too large") {
+			t.Errorf("expected a body size error, got '%s'", response.Error)
+		}
+	})
+
+	t.Run("rejects text over the length limit", func(t *testing.T) {
+		h := &Handler{}
+		rec := httptest.NewRecorder()
+
+		h.APIExtractTextHandler(rec, apiRequest(`{"text": "`+strings.Repeat("a", maxTextLength+1)+`"}`))
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+		}
+
+		var response APIErrorResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		if !strings.Contains(response.Error, "text is too long") {
+			t.Errorf("expected a text length error, got '%s'", response.Error)
+		}
+	})
+
+	t.Run("creates job and returns ID when text is valid", func(t *testing.T) {
+		var capturedType string
+		jobStore := &mockExtractionJobStore{
+			createFunc: func(_ context.Context, userID int, jobType string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+				capturedType = jobType
+				return 55, nil
+			},
+		}
+		h := &Handler{ExtractionJobStore: jobStore}
+		rec := httptest.NewRecorder()
+
+		h.APIExtractTextHandler(rec, apiRequest(`{"text": "Pancakes: 2 eggs, 200g flour. Mix and fry."}`))
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+		}
+		if capturedType != "text" {
+			t.Errorf("expected job type 'text', got '%s'", capturedType)
+		}
+
+		var response APIExtractionJobResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		if !response.Success || response.JobID != 55 {
+			t.Errorf("unexpected response: %+v", response)
+		}
+	})
+}
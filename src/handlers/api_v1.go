@@ -0,0 +1,890 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+// The v1 API serves recipes, tags, user tags and comments as JSON to API
+// key holders. It applies the same ownership rules as the HTML handlers:
+// only a recipe's author can change or delete it and its tags, and only a
+// comment's author can change or delete the comment. Successful responses
+// are the resource itself; errors are an APIErrorResponse.
+
+const (
+	apiV1MaxPageSize = 100
+	apiV1MaxBodySize = 10 << 20
+)
+
+var filterOps = map[string]bool{"eq": true, "gt": true, "gte": true, "lt": true, "lte": true}
+
+type APIv1Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type APIv1Recipe struct {
+	ID             int        `json:"id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	IngredientsMD  string     `json:"ingredients_md"`
+	InstructionsMD string     `json:"instructions_md"`
+	PrepTime       int        `json:"prep_time"`
+	CookTime       int        `json:"cook_time"`
+	Calories       int        `json:"calories"`
+	Source         string     `json:"source"`
+	Language       string     `json:"language"`
+	AuthorID       int        `json:"author_id"`
+	ParentID       *int       `json:"parent_id,omitempty"`
+	TranslationOf  *int       `json:"translation_of,omitempty"`
+	Tags           []APIv1Tag `json:"tags"`
+	UserTags       []APIv1Tag `json:"user_tags"`
+	HasImage       bool       `json:"has_image"`
+	ImageBase64    string     `json:"image_base64,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type APIv1RecipeList struct {
+	Recipes []APIv1Recipe `json:"recipes"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// APIv1RecipeInput is the body of creating and updating a recipe. Creating
+// and full updates (PUT) treat missing fields as empty; partial updates
+// (PATCH) leave them unchanged. Tags replaces the recipe's tags, an empty
+// ImageBase64 removes the image and an empty Language is detected.
+type APIv1RecipeInput struct {
+	Title          *string   `json:"title"`
+	Description    *string   `json:"description"`
+	IngredientsMD  *string   `json:"ingredients_md"`
+	InstructionsMD *string   `json:"instructions_md"`
+	PrepTime       *int      `json:"prep_time"`
+	CookTime       *int      `json:"cook_time"`
+	Calories       *int      `json:"calories"`
+	Source         *string   `json:"source"`
+	Language       *string   `json:"language"`
+	Tags           *[]string `json:"tags"`
+	ImageBase64    *string   `json:"image_base64"`
+}
+
+type APIv1TagInput struct {
+	Name string `json:"name"`
+}
+
+type APIv1Comment struct {
+	ID         int       `json:"id"`
+	RecipeID   int       `json:"recipe_id"`
+	AuthorID   int       `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type APIv1CommentInput struct {
+	Content string `json:"content"`
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(value)
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, value any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiV1MaxBodySize))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
+
+func pathID(r *http.Request, name string) (int, error) {
+	return strconv.Atoi(r.PathValue(name))
+}
+
+func toAPIv1Tags(tags []models.Tag) []APIv1Tag {
+	result := make([]APIv1Tag, len(tags))
+	for i, tag := range tags {
+		result[i] = APIv1Tag{ID: tag.ID, Name: tag.Name}
+	}
+	return result
+}
+
+func toAPIv1UserTags(tags []models.UserTag) []APIv1Tag {
+	result := make([]APIv1Tag, len(tags))
+	for i, tag := range tags {
+		result[i] = APIv1Tag{ID: tag.ID, Name: tag.Name}
+	}
+	return result
+}
+
+func toAPIv1Recipe(recipe models.Recipe, withImage bool) APIv1Recipe {
+	result := APIv1Recipe{
+		ID:             recipe.ID,
+		Title:          recipe.Title,
+		Description:    recipe.Description,
+		IngredientsMD:  recipe.IngredientsMD,
+		InstructionsMD: recipe.InstructionsMD,
+		PrepTime:       recipe.PrepTime,
+		CookTime:       recipe.CookTime,
+		Calories:       recipe.Calories,
+		Source:         recipe.Source,
+		Language:       recipe.Language,
+		AuthorID:       recipe.AuthorID,
+		ParentID:       recipe.ParentID,
+		TranslationOf:  recipe.TranslationOf,
+		Tags:           toAPIv1Tags(recipe.Tags),
+		UserTags:       toAPIv1UserTags(recipe.UserTags),
+		HasImage:       len(recipe.Image) > 0,
+		CreatedAt:      recipe.CreatedAt,
+		UpdatedAt:      recipe.UpdatedAt,
+	}
+	if withImage {
+		result.ImageBase64 = recipe.ImageBase64()
+	}
+	return result
+}
+
+// parseFilterParams reads the recipe list's query parameters. They match
+// the recipe list page's, with limit and offset instead of pages.
+func parseFilterParams(query map[string][]string, userID int) (models.FilterParams, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	atoi := func(key string, fallback int) (int, error) {
+		value := get(key)
+		if value == "" {
+			return fallback, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%s must be a non-negative number", key)
+		}
+		return n, nil
+	}
+	list := func(key string) []string {
+		var items []string
+		for _, item := range strings.Split(get(key), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	params := models.FilterParams{
+		Search:   get("search"),
+		Tags:     list("tags"),
+		UserTags: list("user_tags"),
+	}
+	if len(params.UserTags) > 0 {
+		params.UserID = userID
+	}
+
+	var err error
+	if params.Limit, err = atoi("limit", models.DefaultPageSize); err != nil {
+		return params, err
+	}
+	if params.Limit == 0 || params.Limit > apiV1MaxPageSize {
+		return params, fmt.Errorf("limit must be between 1 and %d", apiV1MaxPageSize)
+	}
+	if params.Offset, err = atoi("offset", 0); err != nil {
+		return params, err
+	}
+	if params.AuthorID, err = atoi("author_id", 0); err != nil {
+		return params, err
+	}
+
+	for _, filter := range []struct {
+		name  string
+		op    *string
+		value *int
+	}{
+		{"calories", &params.CaloriesOp, &params.CaloriesValue},
+		{"prep_time", &params.PrepTimeOp, &params.PrepTimeValue},
+		{"cook_time", &params.CookTimeOp, &params.CookTimeValue},
+	} {
+		op := get(filter.name + "_op")
+		if op == "" {
+			continue
+		}
+		if !filterOps[op] {
+			return params, fmt.Errorf("%s_op must be one of eq, gt, gte, lt, lte", filter.name)
+		}
+		if *filter.value, err = atoi(filter.name+"_value", 0); err != nil {
+			return params, err
+		}
+		*filter.op = op
+	}
+
+	return params, nil
+}
+
+func (h *Handler) APIv1ListRecipesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.GetUserIDFromContext(ctx)
+
+	params, err := parseFilterParams(r.URL.Query(), userID)
+	if err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recipes, err := h.RecipeStore.GetFiltered(ctx, params)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipes via API")
+		sendJSONError(w, "Failed to fetch recipes", http.StatusInternalServerError)
+		return
+	}
+
+	countParams := params
+	countParams.Limit = 0
+	countParams.Offset = 0
+	total, err := h.RecipeStore.CountFiltered(ctx, countParams)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to count recipes via API")
+		sendJSONError(w, "Failed to fetch recipes", http.StatusInternalServerError)
+		return
+	}
+
+	recipeIDs := make([]int, len(recipes))
+	for i, recipe := range recipes {
+		recipeIDs[i] = recipe.ID
+	}
+	tagsMap, err := h.TagStore.GetForRecipes(ctx, recipeIDs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipe tags")
+	}
+	userTagsMap, err := h.UserTagStore.GetForRecipes(ctx, userID, recipeIDs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch user tags")
+	}
+
+	list := APIv1RecipeList{Recipes: make([]APIv1Recipe, len(recipes)), Total: total, Limit: params.Limit, Offset: params.Offset}
+	for i, recipe := range recipes {
+		recipe.Tags = tagsMap[recipe.ID]
+		recipe.UserTags = userTagsMap[recipe.ID]
+		list.Recipes[i] = toAPIv1Recipe(recipe, false)
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.recipe.list",
+		"result.count": len(recipes),
+		"result.total": total,
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+// loadRecipe returns the recipe with its tags and the user's tags, or writes
+// a 404 and returns false.
+func (h *Handler) loadRecipe(ctx context.Context, w http.ResponseWriter, recipeID, userID int) (models.Recipe, bool) {
+	recipe, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID))
+	if err != nil {
+		logging.AddError(ctx, err, "Recipe not found")
+		sendJSONError(w, "Recipe not found", http.StatusNotFound)
+		return models.Recipe{}, false
+	}
+	if recipe.Tags, err = h.TagStore.GetByRecipeID(ctx, recipeID); err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipe tags")
+	}
+	if recipe.UserTags, err = h.UserTagStore.GetByRecipeID(ctx, userID, recipeID); err != nil {
+		logging.AddError(ctx, err, "Failed to fetch user tags")
+	}
+	return recipe, true
+}
+
+// loadOwnRecipe is loadRecipe for changes, which only the recipe's author
+// may make. It writes a 403 for other users' recipes.
+func (h *Handler) loadOwnRecipe(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) (models.Recipe, bool) {
+	recipeID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid recipe ID", http.StatusBadRequest)
+		return models.Recipe{}, false
+	}
+	recipe, ok := h.loadRecipe(ctx, w, recipeID, userID)
+	if !ok {
+		return models.Recipe{}, false
+	}
+	if recipe.AuthorID != userID {
+		sendJSONError(w, "You can only change your own recipes", http.StatusForbidden)
+		return models.Recipe{}, false
+	}
+	return recipe, true
+}
+
+func (h *Handler) APIv1GetRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	recipeID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid recipe ID", http.StatusBadRequest)
+		return
+	}
+
+	recipe, ok := h.loadRecipe(ctx, w, recipeID, auth.GetUserIDFromContext(ctx))
+	if !ok {
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":    "api.v1.recipe.get",
+		"recipe.id": recipeID,
+	})
+	writeJSON(w, http.StatusOK, toAPIv1Recipe(recipe, true))
+}
+
+// applyRecipeInput copies the fields set in input onto recipe. Like the
+// recipe form, it detects the language if the recipe has none.
+func applyRecipeInput(recipe *models.Recipe, input APIv1RecipeInput) error {
+	setString := func(field *string, value *string) {
+		if value != nil {
+			*field = strings.TrimSpace(*value)
+		}
+	}
+	setString(&recipe.Title, input.Title)
+	setString(&recipe.Description, input.Description)
+	setString(&recipe.IngredientsMD, input.IngredientsMD)
+	setString(&recipe.InstructionsMD, input.InstructionsMD)
+	setString(&recipe.Source, input.Source)
+	if input.PrepTime != nil {
+		recipe.PrepTime = *input.PrepTime
+	}
+	if input.CookTime != nil {
+		recipe.CookTime = *input.CookTime
+	}
+	if input.Calories != nil {
+		recipe.Calories = *input.Calories
+	}
+
+	if input.ImageBase64 != nil {
+		image, err := decodeImage(*input.ImageBase64)
+		if err != nil {
+			return fmt.Errorf("invalid base64 image data")
+		}
+		recipe.Image = image
+	}
+
+	if input.Language != nil {
+		if *input.Language != "" && !models.IsLanguage(*input.Language) {
+			return fmt.Errorf("unknown language %q", *input.Language)
+		}
+		recipe.Language = *input.Language
+	}
+	if recipe.Language == "" {
+		recipe.Language = extraction.DetectLanguage(strings.Join([]string{recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD}, "\n"))
+	}
+
+	return validateRecipeRequest(APIRecipeRequest{
+		Title:          recipe.Title,
+		IngredientsMD:  recipe.IngredientsMD,
+		InstructionsMD: recipe.InstructionsMD,
+		PrepTime:       recipe.PrepTime,
+		CookTime:       recipe.CookTime,
+		Calories:       recipe.Calories,
+	})
+}
+
+func decodeImage(data string) ([]byte, error) {
+	if data == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(data, "data:image/") {
+		if commaIndex := strings.Index(data, ","); commaIndex != -1 {
+			data = data[commaIndex+1:]
+		}
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// saveRecipeTags replaces the recipe's tags if the input sets them, or
+// always for creates and full updates.
+func (h *Handler) saveRecipeTags(ctx context.Context, recipeID int, input APIv1RecipeInput, replace bool) {
+	if input.Tags == nil && !replace {
+		return
+	}
+	var tags []string
+	if input.Tags != nil {
+		tags = *input.Tags
+	}
+	if err := h.TagStore.SetRecipeTags(ctx, recipeID, tags); err != nil {
+		logging.AddError(ctx, err, "Failed to set recipe tags")
+	}
+}
+
+func (h *Handler) APIv1CreateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.GetUserIDFromContext(ctx)
+
+	var input APIv1RecipeInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		logging.AddError(ctx, err, "Failed to decode API recipe request")
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	recipe := models.Recipe{AuthorID: userID}
+	if err := applyRecipeInput(&recipe, input); err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recipeID, err := h.RecipeStore.Save(ctx, recipe)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to save recipe via API")
+		sendJSONError(w, "Failed to save recipe", http.StatusInternalServerError)
+		return
+	}
+	h.saveRecipeTags(ctx, recipeID, input, true)
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.recipe.create",
+		"recipe.id":    recipeID,
+		"recipe.title": recipe.Title,
+	})
+
+	saved, ok := h.loadRecipe(ctx, w, recipeID, userID)
+	if !ok {
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/recipes/%d", recipeID))
+	writeJSON(w, http.StatusCreated, toAPIv1Recipe(saved, true))
+}
+
+// APIv1UpdateRecipeHandler handles both PUT, which replaces the recipe, and
+// PATCH, which only changes the fields in the body.
+func (h *Handler) APIv1UpdateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.GetUserIDFromContext(ctx)
+	partial := r.Method == http.MethodPatch
+
+	existing, ok := h.loadOwnRecipe(ctx, w, r, userID)
+	if !ok {
+		return
+	}
+
+	var input APIv1RecipeInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		logging.AddError(ctx, err, "Failed to decode API recipe request")
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	recipe := existing
+	if !partial {
+		recipe = models.Recipe{ID: existing.ID, AuthorID: existing.AuthorID}
+	}
+	if err := applyRecipeInput(&recipe, input); err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.RecipeStore.Update(ctx, recipe); err != nil {
+		logging.AddError(ctx, err, "Failed to update recipe via API")
+		sendJSONError(w, "Failed to update recipe", http.StatusInternalServerError)
+		return
+	}
+	h.saveRecipeTags(ctx, recipe.ID, input, !partial)
+
+	logging.AddMany(ctx, map[string]any{
+		"action":         "api.v1.recipe.update",
+		"recipe.id":      recipe.ID,
+		"recipe.title":   recipe.Title,
+		"update.partial": partial,
+	})
+
+	updated, ok := h.loadRecipe(ctx, w, recipe.ID, userID)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIv1Recipe(updated, true))
+}
+
+func (h *Handler) APIv1DeleteRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recipe, ok := h.loadOwnRecipe(ctx, w, r, auth.GetUserIDFromContext(ctx))
+	if !ok {
+		return
+	}
+
+	if err := h.RecipeStore.Delete(ctx, strconv.Itoa(recipe.ID)); err != nil {
+		logging.AddError(ctx, err, "Failed to delete recipe via API")
+		sendJSONError(w, "Failed to delete recipe", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.recipe.delete",
+		"recipe.id":    recipe.ID,
+		"recipe.title": recipe.Title,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) APIv1SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tags, err := h.TagStore.Search(ctx, r.URL.Query().Get("q"))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to search tags via API")
+		sendJSONError(w, "Failed to search tags", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.tag.search",
+		"result.count": len(tags),
+	})
+	writeJSON(w, http.StatusOK, toAPIv1Tags(tags))
+}
+
+func (h *Handler) APIv1AddRecipeTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.GetUserIDFromContext(ctx)
+
+	recipe, ok := h.loadOwnRecipe(ctx, w, r, userID)
+	if !ok {
+		return
+	}
+
+	var input APIv1TagInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		sendJSONError(w, "Tag name is required", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.TagStore.GetOrCreate(ctx, name)
+	if err == nil {
+		err = h.TagStore.AddToRecipe(ctx, recipe.ID, tag.ID)
+	}
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to add tag via API")
+		sendJSONError(w, "Failed to add tag to recipe", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":    "api.v1.tag.add",
+		"recipe.id": recipe.ID,
+		"tag.id":    tag.ID,
+		"tag.name":  tag.Name,
+	})
+	writeJSON(w, http.StatusCreated, APIv1Tag{ID: tag.ID, Name: tag.Name})
+}
+
+func (h *Handler) APIv1RemoveRecipeTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := pathID(r, "tagId")
+	if err != nil {
+		sendJSONError(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+	recipe, ok := h.loadOwnRecipe(ctx, w, r, auth.GetUserIDFromContext(ctx))
+	if !ok {
+		return
+	}
+
+	if err := h.TagStore.RemoveFromRecipe(ctx, recipe.ID, tagID); err != nil {
+		logging.AddError(ctx, err, "Failed to remove tag via API")
+		sendJSONError(w, "Failed to remove tag from recipe", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":    "api.v1.tag.remove",
+		"recipe.id": recipe.ID,
+		"tag.id":    tagID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APIv1ListUserTagsHandler lists the user's own tags on all recipes.
+func (h *Handler) APIv1ListUserTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tags, err := h.UserTagStore.GetByUserID(ctx, auth.GetUserIDFromContext(ctx))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch user tags via API")
+		sendJSONError(w, "Failed to fetch user tags", http.StatusInternalServerError)
+		return
+	}
+
+	type userTag struct {
+		APIv1Tag
+		RecipeID int `json:"recipe_id"`
+	}
+	result := make([]userTag, len(tags))
+	for i, tag := range tags {
+		result[i] = userTag{APIv1Tag: APIv1Tag{ID: tag.ID, Name: tag.Name}, RecipeID: tag.RecipeID}
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.user_tag.list",
+		"result.count": len(tags),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// APIv1AddUserTagHandler tags any recipe for the user; user tags are only
+// visible to the user who added them.
+func (h *Handler) APIv1AddUserTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := auth.GetUserIDFromContext(ctx)
+
+	recipeID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid recipe ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID)); err != nil {
+		sendJSONError(w, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	var input APIv1TagInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		sendJSONError(w, "Tag name is required", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.UserTagStore.GetOrCreate(ctx, userID, recipeID, name)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to add user tag via API")
+		sendJSONError(w, "Failed to add user tag", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":        "api.v1.user_tag.add",
+		"recipe.id":     recipeID,
+		"user_tag.name": name,
+	})
+	writeJSON(w, http.StatusCreated, APIv1Tag{ID: tag.ID, Name: tag.Name})
+}
+
+func (h *Handler) APIv1RemoveUserTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UserTagStore.Remove(ctx, auth.GetUserIDFromContext(ctx), tagID); err != nil {
+		logging.AddError(ctx, err, "Failed to remove user tag via API")
+		sendJSONError(w, "Failed to remove user tag", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":      "api.v1.user_tag.remove",
+		"user_tag.id": tagID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) toAPIv1Comments(ctx context.Context, comments []models.Comment) []APIv1Comment {
+	usernames := make(map[int]string)
+	result := make([]APIv1Comment, len(comments))
+	for i, comment := range comments {
+		username, ok := usernames[comment.AuthorID]
+		if !ok {
+			var err error
+			if username, err = h.UserStore.GetUsernameByID(ctx, comment.AuthorID); err != nil {
+				username = "Unknown User"
+			}
+			usernames[comment.AuthorID] = username
+		}
+		result[i] = APIv1Comment{
+			ID:         comment.ID,
+			RecipeID:   comment.RecipeID,
+			AuthorID:   comment.AuthorID,
+			AuthorName: username,
+			Content:    comment.ContentMD,
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.UpdatedAt,
+		}
+	}
+	return result
+}
+
+func (h *Handler) APIv1ListCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recipeID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid recipe ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID)); err != nil {
+		sendJSONError(w, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	comments, err := h.CommentStore.GetByRecipeID(ctx, strconv.Itoa(recipeID))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch comments via API")
+		sendJSONError(w, "Failed to fetch comments", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":       "api.v1.comment.list",
+		"recipe.id":    recipeID,
+		"result.count": len(comments),
+	})
+	writeJSON(w, http.StatusOK, h.toAPIv1Comments(ctx, comments))
+}
+
+func (h *Handler) APIv1CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipeID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid recipe ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID)); err != nil {
+		sendJSONError(w, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	var input APIv1CommentInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		sendJSONError(w, "Comment content is required", http.StatusBadRequest)
+		return
+	}
+
+	err = h.CommentStore.Save(ctx, models.Comment{RecipeID: recipeID, AuthorID: userInfo.UserID, ContentMD: input.Content})
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to save comment via API")
+		sendJSONError(w, "Failed to save comment", http.StatusInternalServerError)
+		return
+	}
+	saved, err := h.CommentStore.GetLatestByUserAndRecipe(ctx, userInfo.UserID, recipeID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to retrieve saved comment")
+		sendJSONError(w, "Failed to retrieve saved comment", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":     "api.v1.comment.create",
+		"recipe.id":  recipeID,
+		"comment.id": saved.ID,
+	})
+
+	h.notifyRecipeComment(ctx, recipeID, userInfo.UserID, userInfo.Username, input.Content)
+
+	writeJSON(w, http.StatusCreated, h.toAPIv1Comments(ctx, []models.Comment{saved})[0])
+}
+
+// loadOwnComment returns the comment if the user wrote it, or writes an
+// error and returns false.
+func (h *Handler) loadOwnComment(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) (models.Comment, bool) {
+	commentID, err := pathID(r, "id")
+	if err != nil {
+		sendJSONError(w, "Invalid comment ID", http.StatusBadRequest)
+		return models.Comment{}, false
+	}
+	comment, err := h.CommentStore.GetByID(ctx, commentID)
+	if err != nil {
+		sendJSONError(w, "Comment not found", http.StatusNotFound)
+		return models.Comment{}, false
+	}
+	if comment.AuthorID != userID {
+		sendJSONError(w, "You can only change your own comments", http.StatusForbidden)
+		return models.Comment{}, false
+	}
+	return comment, true
+}
+
+func (h *Handler) APIv1UpdateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	comment, ok := h.loadOwnComment(ctx, w, r, auth.GetUserIDFromContext(ctx))
+	if !ok {
+		return
+	}
+
+	var input APIv1CommentInput
+	if err := decodeJSONBody(w, r, &input); err != nil {
+		sendJSONError(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		sendJSONError(w, "Comment content is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.CommentStore.Update(ctx, comment.ID, input.Content); err != nil {
+		logging.AddError(ctx, err, "Failed to update comment via API")
+		sendJSONError(w, "Failed to update comment", http.StatusInternalServerError)
+		return
+	}
+	updated, err := h.CommentStore.GetByID(ctx, comment.ID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to retrieve updated comment")
+		sendJSONError(w, "Failed to retrieve updated comment", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":     "api.v1.comment.update",
+		"comment.id": comment.ID,
+	})
+	writeJSON(w, http.StatusOK, h.toAPIv1Comments(ctx, []models.Comment{updated})[0])
+}
+
+func (h *Handler) APIv1DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	comment, ok := h.loadOwnComment(ctx, w, r, auth.GetUserIDFromContext(ctx))
+	if !ok {
+		return
+	}
+
+	if err := h.CommentStore.Delete(ctx, comment.ID); err != nil {
+		logging.AddError(ctx, err, "Failed to delete comment via API")
+		sendJSONError(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":     "api.v1.comment.delete",
+		"comment.id": comment.ID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+func apiV1Request(method, target, body string, userID int) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: userID, Username: "cook"}))
+}
+
+func TestParseFilterParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    models.FilterParams
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  models.FilterParams{Limit: models.DefaultPageSize},
+		},
+		{
+			name:  "all filters",
+			query: "search=soup&tags=vegan,%20quick&user_tags=favourite&author_id=3&calories_op=lt&calories_value=500&prep_time_op=lte&prep_time_value=15&cook_time_op=gt&cook_time_value=5&limit=50&offset=100",
+			want: models.FilterParams{
+				Search:        "soup",
+				Tags:          []string{"vegan", "quick"},
+				UserTags:      []string{"favourite"},
+				UserID:        7,
+				AuthorID:      3,
+				CaloriesOp:    "lt",
+				CaloriesValue: 500,
+				PrepTimeOp:    "lte",
+				PrepTimeValue: 15,
+				CookTimeOp:    "gt",
+				CookTimeValue: 5,
+				Limit:         50,
+				Offset:        100,
+			},
+		},
+		{name: "unknown operator", query: "calories_op=like&calories_value=5", wantErr: "calories_op must be one of eq, gt, gte, lt, lte"},
+		{name: "limit too large", query: "limit=101", wantErr: "limit must be between 1 and 100"},
+		{name: "negative offset", query: "offset=-1", wantErr: "offset must be a non-negative number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			got, err := parseFilterParams(query, 7)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIv1CreateRecipeHandler_SavesRecipeWithTags(t *testing.T) {
+	var saved models.Recipe
+	var savedTags []string
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			SaveFunc: func(_ context.Context, recipe models.Recipe) (int, error) {
+				saved = recipe
+				return 12, nil
+			},
+			GetByIDFunc: func(_ context.Context, id string) (models.Recipe, error) {
+				saved.ID = 12
+				return saved, nil
+			},
+		},
+		TagStore: &mocks.MockTagStore{
+			SetRecipeTagsFunc: func(_ context.Context, recipeID int, tagNames []string) error {
+				savedTags = tagNames
+				return nil
+			},
+		},
+		UserTagStore: &mocks.MockUserTagStore{},
+	}
+
+	body := `{"title": " Pancakes ", "ingredients_md": "- @flour{200g}", "instructions_md": "Mix the flour with the milk and the eggs, then fry the pancakes in a pan.", "prep_time": 10, "tags": ["breakfast"]}`
+	rec := httptest.NewRecorder()
+	h.APIv1CreateRecipeHandler(rec, apiV1Request(http.MethodPost, "/api/v1/recipes", body, 5))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if location := rec.Header().Get("Location"); location != "/api/v1/recipes/12" {
+		t.Errorf("location = %q", location)
+	}
+	if saved.AuthorID != 5 || saved.Title != "Pancakes" || saved.PrepTime != 10 || saved.Language != models.LanguageEnglish {
+		t.Errorf("unexpected saved recipe %+v", saved)
+	}
+	if !reflect.DeepEqual(savedTags, []string{"breakfast"}) {
+		t.Errorf("tags = %v, want [breakfast]", savedTags)
+	}
+
+	var response APIv1Recipe
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ID != 12 || response.Title != "Pancakes" {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestAPIv1CreateRecipeHandler_RejectsInvalidRecipes(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"missing title", `{"ingredients_md": "- flour", "instructions_md": "Bake"}`, "title is required"},
+		{"unknown field", `{"title": "Bread", "rating": 5}`, "Invalid JSON format"},
+		{"unknown language", `{"title": "Bread", "ingredients_md": "- flour", "instructions_md": "Bake", "language": "fr"}`, `unknown language "fr"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					SaveFunc: func(context.Context, models.Recipe) (int, error) {
+						t.Error("expected the recipe not to be saved")
+						return 0, nil
+					},
+				},
+			}
+
+			rec := httptest.NewRecorder()
+			h.APIv1CreateRecipeHandler(rec, apiV1Request(http.MethodPost, "/api/v1/recipes", tt.body, 5))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var response APIErrorResponse
+			json.NewDecoder(rec.Body).Decode(&response)
+			if response.Error != tt.wantErr {
+				t.Errorf("error = %q, want %q", response.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIv1UpdateRecipeHandler_PutReplacesAndPatchMerges(t *testing.T) {
+	existing := models.Recipe{
+		ID:             3,
+		Title:          "Bread",
+		Description:    "Crusty",
+		IngredientsMD:  "- flour",
+		InstructionsMD: "Bake",
+		PrepTime:       20,
+		Language:       models.LanguageEnglish,
+		AuthorID:       5,
+		Image:          []byte("image"),
+	}
+
+	tests := []struct {
+		method       string
+		body         string
+		wantRecipe   models.Recipe
+		wantTagsSet  bool
+		wantTagNames []string
+	}{
+		{
+			method: http.MethodPatch,
+			body:   `{"description": "Soft"}`,
+			wantRecipe: func() models.Recipe {
+				r := existing
+				r.Description = "Soft"
+				return r
+			}(),
+		},
+		{
+			method: http.MethodPut,
+			body:   `{"title": "Bread", "ingredients_md": "- flour", "instructions_md": "Bake", "language": "en"}`,
+			wantRecipe: models.Recipe{
+				ID:             3,
+				Title:          "Bread",
+				IngredientsMD:  "- flour",
+				InstructionsMD: "Bake",
+				Language:       models.LanguageEnglish,
+				AuthorID:       5,
+			},
+			wantTagsSet:  true,
+			wantTagNames: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var updated models.Recipe
+			tagsSet := false
+			var tagNames []string
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+						return existing, nil
+					},
+					UpdateFunc: func(_ context.Context, recipe models.Recipe) error {
+						updated = recipe
+						return nil
+					},
+				},
+				TagStore: &mocks.MockTagStore{
+					SetRecipeTagsFunc: func(_ context.Context, _ int, names []string) error {
+						tagsSet, tagNames = true, names
+						return nil
+					},
+				},
+				UserTagStore: &mocks.MockUserTagStore{},
+			}
+
+			req := apiV1Request(tt.method, "/api/v1/recipes/3", tt.body, 5)
+			req.SetPathValue("id", "3")
+			rec := httptest.NewRecorder()
+			h.APIv1UpdateRecipeHandler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if !reflect.DeepEqual(updated, tt.wantRecipe) {
+				t.Errorf("updated %+v, want %+v", updated, tt.wantRecipe)
+			}
+			if tagsSet != tt.wantTagsSet || !reflect.DeepEqual(tagNames, tt.wantTagNames) {
+				t.Errorf("tags set = %v %v, want %v %v", tagsSet, tagNames, tt.wantTagsSet, tt.wantTagNames)
+			}
+		})
+	}
+}
+
+func TestAPIv1_OnlyAuthorsChangeRecipes(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		handler func(h *Handler) http.HandlerFunc
+	}{
+		{"put", http.MethodPut, `{"title": "Mine now"}`, func(h *Handler) http.HandlerFunc { return h.APIv1UpdateRecipeHandler }},
+		{"patch", http.MethodPatch, `{"title": "Mine now"}`, func(h *Handler) http.HandlerFunc { return h.APIv1UpdateRecipeHandler }},
+		{"delete", http.MethodDelete, "", func(h *Handler) http.HandlerFunc { return h.APIv1DeleteRecipeHandler }},
+		{"add tag", http.MethodPost, `{"name": "spam"}`, func(h *Handler) http.HandlerFunc { return h.APIv1AddRecipeTagHandler }},
+		{"remove tag", http.MethodDelete, "", func(h *Handler) http.HandlerFunc { return h.APIv1RemoveRecipeTagHandler }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := func() { t.Error("expected the recipe not to change") }
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+						return models.Recipe{ID: 3, Title: "Bread", AuthorID: 9}, nil
+					},
+					UpdateFunc: func(context.Context, models.Recipe) error { changed(); return nil },
+					DeleteFunc: func(context.Context, string) error { changed(); return nil },
+				},
+				TagStore: &mocks.MockTagStore{
+					AddToRecipeFunc:      func(context.Context, int, int) error { changed(); return nil },
+					RemoveFromRecipeFunc: func(context.Context, int, int) error { changed(); return nil },
+					SetRecipeTagsFunc:    func(context.Context, int, []string) error { changed(); return nil },
+				},
+				UserTagStore: &mocks.MockUserTagStore{},
+			}
+
+			req := apiV1Request(tt.method, "/api/v1/recipes/3", tt.body, 5)
+			req.SetPathValue("id", "3")
+			req.SetPathValue("tagId", "1")
+			rec := httptest.NewRecorder()
+			tt.handler(h)(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestAPIv1DeleteRecipeHandler_ReportsMissingRecipe(t *testing.T) {
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+				return models.Recipe{}, errors.New("sql: no rows in result set")
+			},
+		},
+	}
+
+	req := apiV1Request(http.MethodDelete, "/api/v1/recipes/3", "", 5)
+	req.SetPathValue("id", "3")
+	rec := httptest.NewRecorder()
+	h.APIv1DeleteRecipeHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestAPIv1Comments_OnlyAuthorsChangeComments(t *testing.T) {
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			h := &Handler{
+				CommentStore: &mocks.MockCommentStore{
+					GetByIDFunc: func(context.Context, int) (models.Comment, error) {
+						return models.Comment{ID: 4, RecipeID: 3, AuthorID: 9, ContentMD: "Nice"}, nil
+					},
+					UpdateFunc: func(context.Context, int, string) error {
+						t.Error("expected the comment not to change")
+						return nil
+					},
+					DeleteFunc: func(context.Context, int) error {
+						t.Error("expected the comment not to be deleted")
+						return nil
+					},
+				},
+			}
+
+			req := apiV1Request(method, "/api/v1/comments/4", `{"content": "Edited"}`, 5)
+			req.SetPathValue("id", "4")
+			rec := httptest.NewRecorder()
+			if method == http.MethodPut {
+				h.APIv1UpdateCommentHandler(rec, req)
+			} else {
+				h.APIv1DeleteCommentHandler(rec, req)
+			}
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestAPIv1CreateCommentHandler_ReturnsComment(t *testing.T) {
+	var saved models.Comment
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+				return models.Recipe{ID: 3, AuthorID: 5}, nil
+			},
+		},
+		CommentStore: &mocks.MockCommentStore{
+			SaveFunc: func(_ context.Context, comment models.Comment) error {
+				saved = comment
+				return nil
+			},
+			GetLatestByUserAndRecipeFunc: func(context.Context, int, int) (models.Comment, error) {
+				saved.ID = 8
+				return saved, nil
+			},
+		},
+		UserStore: &mocks.MockUserStore{
+			GetUsernameByIDFunc: func(context.Context, int) (string, error) { return "cook", nil },
+		},
+	}
+
+	req := apiV1Request(http.MethodPost, "/api/v1/recipes/3/comments", `{"content": "Tasty"}`, 5)
+	req.SetPathValue("id", "3")
+	rec := httptest.NewRecorder()
+	h.APIv1CreateCommentHandler(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var response APIv1Comment
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ID != 8 || response.RecipeID != 3 || response.AuthorID != 5 || response.AuthorName != "cook" || response.Content != "Tasty" {
+		t.Errorf("unexpected comment %+v", response)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/mr-flannery/go-recipe-book/src/config"
 	"github.com/mr-flannery/go-recipe-book/src/logging"
 	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/utils"
 )
 
@@ -116,19 +118,23 @@ func (h *Handler) PostRegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := auth.CreateRegistrationRequest(ctx, h.AuthStore, username, email, password)
+	conf := config.GetConfig()
+	approvalURL := utils.GetAppBaseURL() + "/admin/registrations"
+	admin := mail.Recipient{Email: conf.DB.Admin.Email, Name: conf.DB.Admin.Username}
+	adminEmail, err := mail.NewRegistrationEmail(admin, username, email, approvalURL)
 	if err != nil {
-		logging.AddError(ctx, err, "Failed to create registration request")
-		data.Error = err.Error()
+		logging.AddError(ctx, err, "Failed to render registration email")
+		data.Error = "An error occurred. Please try again later."
 		h.Renderer.RenderPage(w, "register.gohtml", data)
 		return
 	}
 
-	conf := config.GetConfig()
-	approvalURL := utils.GetAppBaseURL() + "/admin/registrations"
-	err = mail.SendNewRegistrationNotification(ctx, h.MailClient, conf.DB.Admin.Email, conf.DB.Admin.Username, username, email, approvalURL)
+	err = auth.CreateRegistrationRequest(ctx, h.AuthStore, username, email, password, adminEmail)
 	if err != nil {
-		logging.AddError(ctx, err, "Failed to send admin notification email")
+		logging.AddError(ctx, err, "Failed to create registration request")
+		data.Error = err.Error()
+		h.Renderer.RenderPage(w, "register.gohtml", data)
+		return
 	}
 
 	logging.AddMany(ctx, map[string]any{
@@ -235,17 +241,38 @@ func (h *Handler) ApproveRegistrationHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = auth.ApproveRegistration(ctx, h.AuthStore, registrationID, user.ID)
+	// The new account has no email preferences yet, so it gets the approval
+	// email by default. It is queued with the approval rather than through
+	// the notifier, so the user hears about it even if the request fails
+	// afterwards.
+	loginURL := utils.GetAppBaseURL() + "/login"
+	approvalEmail, err := mail.RegistrationApprovedEmail(mail.Recipient{Email: regRequest.Email, Name: regRequest.Username}, loginURL)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to render approval email")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to approve registration. Please try again.")
+		return
+	}
+	err = auth.ApproveRegistration(ctx, h.AuthStore, registrationID, user.ID, approvalEmail)
 	if err != nil {
 		logging.AddError(ctx, err, "Failed to approve registration")
 		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to approve registration. Please try again.")
 		return
 	}
 
-	loginURL := utils.GetAppBaseURL() + "/login"
-	err = mail.SendRegistrationApprovedNotification(ctx, h.MailClient, regRequest.Email, regRequest.Username, loginURL)
+	newUserID, err := h.AuthStore.GetUserIDByUsername(ctx, regRequest.Username)
 	if err != nil {
-		logging.AddError(ctx, err, "Failed to send approval email")
+		logging.AddError(ctx, err, "Failed to look up approved user")
+	} else {
+		err = h.Notifier.Notify(ctx, store.Notification{
+			UserID: newUserID,
+			Type:   notifications.TypeRegistrationApproved,
+			Title:  "Welcome! Your registration was approved",
+			Body:   "You can now create, extract and comment on recipes.",
+			Link:   "/recipes",
+		}, nil)
+		if err != nil {
+			logging.AddError(ctx, err, "Failed to send approval notification")
+		}
 	}
 
 	logging.AddMany(ctx, map[string]any{
@@ -413,7 +440,10 @@ func (h *Handler) PostForgotPasswordHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	token, err := auth.CreatePasswordResetToken(ctx, h.AuthStore, user.ID)
+	to := h.mailRecipient(ctx, user.ID, user.Email, user.Username)
+	_, err = auth.CreatePasswordResetToken(ctx, h.AuthStore, user.ID, func(resetURL string) (*store.OutgoingEmail, error) {
+		return mail.PasswordResetEmail(to, resetURL)
+	})
 	if err != nil {
 		logging.AddError(ctx, err, "Failed to create password reset token")
 		data.Error = "An error occurred. Please try again later."
@@ -421,12 +451,6 @@ func (h *Handler) PostForgotPasswordHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	resetURL := auth.GetResetURL(token)
-	err = mail.SendPasswordResetEmail(ctx, h.MailClient, user.Email, user.Username, resetURL)
-	if err != nil {
-		logging.AddError(ctx, err, "Failed to send password reset email")
-	}
-
 	logging.AddMany(ctx, map[string]any{
 		"action":                      "auth.password_reset.request",
 		"password_reset.email":        email,
@@ -12,6 +12,8 @@ import (
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
 	mailmocks "github.com/mr-flannery/go-recipe-book/src/mail/mocks"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
 	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
@@ -730,16 +732,14 @@ func TestDeleteUserHandler_DeletesUserSuccessfully(t *testing.T) {
 func TestPostRegisterHandler_SendsNotificationEmailOnSuccess(t *testing.T) {
 	emailSent := false
 	var capturedRecipient string
-	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			emailSent = true
-			capturedRecipient = recipientEmail
-			return nil
-		},
-	}
+	mockMailClient := &mailmocks.MockMailClient{}
 
 	mockAuthStore := &mocks.MockAuthStore{
-		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string) error {
+		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
+			if notification != nil {
+				emailSent = true
+				capturedRecipient = notification.RecipientEmail
+			}
 			return nil
 		},
 	}
@@ -773,7 +773,7 @@ func TestPostRegisterHandler_SendsNotificationEmailOnSuccess(t *testing.T) {
 	h.PostRegisterHandler(rec, req)
 
 	if !emailSent {
-		t.Error("expected notification email to be sent")
+		t.Error("expected notification email to be queued")
 	}
 
 	if capturedRecipient == "" {
@@ -792,13 +792,13 @@ func TestPostRegisterHandler_SendsNotificationEmailOnSuccess(t *testing.T) {
 
 func TestPostRegisterHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
+		SendEmailFunc: func(ctx context.Context, email store.OutgoingEmail) error {
 			return errors.New("email service unavailable")
 		},
 	}
 
 	mockAuthStore := &mocks.MockAuthStore{
-		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string) error {
+		CreateRegistrationRequestFunc: func(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
 			return nil
 		},
 	}
@@ -844,13 +844,7 @@ func TestPostRegisterHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 func TestApproveRegistrationHandler_SendsApprovalEmailOnSuccess(t *testing.T) {
 	emailSent := false
 	var capturedRecipient string
-	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			emailSent = true
-			capturedRecipient = recipientEmail
-			return nil
-		},
-	}
+	mockMailClient := &mailmocks.MockMailClient{}
 
 	mockAuthStore := &mocks.MockAuthStore{
 		GetSessionFunc: func(ctx context.Context, sessionID string) (*store.Session, error) {
@@ -864,9 +858,24 @@ func TestApproveRegistrationHandler_SendsApprovalEmailOnSuccess(t *testing.T) {
 				{ID: 1, Username: "newuser", Email: "new@example.com", Status: "pending"},
 			}, nil
 		},
-		ApproveRegistrationFunc: func(ctx context.Context, requestID, adminID int) error {
+		ApproveRegistrationFunc: func(ctx context.Context, requestID, adminID int, notification *store.OutgoingEmail) error {
+			if notification != nil {
+				emailSent = true
+				capturedRecipient = notification.RecipientEmail
+			}
 			return nil
 		},
+		GetUserIDByUsernameFunc: func(ctx context.Context, username string) (int, error) {
+			return 2, nil
+		},
+	}
+
+	var notified []store.Notification
+	mockNotificationStore := &mocks.MockNotificationStore{
+		CreateFunc: func(ctx context.Context, notification store.Notification) (int, error) {
+			notified = append(notified, notification)
+			return 1, nil
+		},
 	}
 
 	mockRenderer := &tmocks.MockRenderer{}
@@ -875,6 +884,7 @@ func TestApproveRegistrationHandler_SendsApprovalEmailOnSuccess(t *testing.T) {
 		AuthStore:  mockAuthStore,
 		Renderer:   mockRenderer,
 		MailClient: mockMailClient,
+		Notifier:   notifications.NewNotifier(mockNotificationStore, mockMailClient),
 	}
 
 	req := httptest.NewRequest(http.MethodPost, "/admin/registrations/1/approve", nil)
@@ -891,17 +901,21 @@ func TestApproveRegistrationHandler_SendsApprovalEmailOnSuccess(t *testing.T) {
 	}
 
 	if !emailSent {
-		t.Error("expected approval email to be sent")
+		t.Error("expected approval email to be queued")
 	}
 
 	if capturedRecipient != "new@example.com" {
 		t.Errorf("expected recipient 'new@example.com', got '%s'", capturedRecipient)
 	}
+
+	if len(notified) != 1 || notified[0].UserID != 2 {
+		t.Errorf("expected a notification for the new user, got %+v", notified)
+	}
 }
 
 func TestApproveRegistrationHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
+		SendEmailFunc: func(ctx context.Context, email store.OutgoingEmail) error {
 			return errors.New("email service unavailable")
 		},
 	}
@@ -918,9 +932,20 @@ func TestApproveRegistrationHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 				{ID: 1, Username: "newuser", Email: "new@example.com", Status: "pending"},
 			}, nil
 		},
-		ApproveRegistrationFunc: func(ctx context.Context, requestID, adminID int) error {
+		ApproveRegistrationFunc: func(ctx context.Context, requestID, adminID int, notification *store.OutgoingEmail) error {
 			return nil
 		},
+		GetUserIDByUsernameFunc: func(ctx context.Context, username string) (int, error) {
+			return 2, nil
+		},
+	}
+
+	var notified []store.Notification
+	mockNotificationStore := &mocks.MockNotificationStore{
+		CreateFunc: func(ctx context.Context, notification store.Notification) (int, error) {
+			notified = append(notified, notification)
+			return 1, nil
+		},
 	}
 
 	mockRenderer := &tmocks.MockRenderer{}
@@ -929,6 +954,7 @@ func TestApproveRegistrationHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 		AuthStore:  mockAuthStore,
 		Renderer:   mockRenderer,
 		MailClient: mockMailClient,
+		Notifier:   notifications.NewNotifier(mockNotificationStore, mockMailClient),
 	}
 
 	req := httptest.NewRequest(http.MethodPost, "/admin/registrations/1/approve", nil)
@@ -1021,15 +1047,9 @@ func TestPostForgotPasswordHandler_ShowsSuccessWhenUserNotFound(t *testing.T) {
 
 func TestPostForgotPasswordHandler_SendsEmailWhenUserExists(t *testing.T) {
 	emailSent := false
-	var capturedRecipient string
+	var capturedRecipient, capturedSubject string
 
-	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			emailSent = true
-			capturedRecipient = recipientEmail
-			return nil
-		},
-	}
+	mockMailClient := &mailmocks.MockMailClient{}
 
 	mockAuthStore := &mocks.MockAuthStore{
 		GetUserByEmailFunc: func(ctx context.Context, email string) (*store.AuthUser, string, error) {
@@ -1039,7 +1059,12 @@ func TestPostForgotPasswordHandler_SendsEmailWhenUserExists(t *testing.T) {
 				Email:    email,
 			}, "hashedpassword", nil
 		},
-		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
+			if notification != nil {
+				emailSent = true
+				capturedRecipient = notification.RecipientEmail
+				capturedSubject = notification.Subject
+			}
 			return nil
 		},
 	}
@@ -1056,6 +1081,11 @@ func TestPostForgotPasswordHandler_SendsEmailWhenUserExists(t *testing.T) {
 		AuthStore:  mockAuthStore,
 		Renderer:   mockRenderer,
 		MailClient: mockMailClient,
+		UserPreferencesStore: &mocks.MockUserPreferencesStore{
+			GetFunc: func(ctx context.Context, userID int) (*models.UserPreferences, error) {
+				return &models.UserPreferences{UserID: userID, TranslationLanguage: models.LanguageGerman}, nil
+			},
+		},
 	}
 
 	form := url.Values{}
@@ -1070,13 +1100,17 @@ func TestPostForgotPasswordHandler_SendsEmailWhenUserExists(t *testing.T) {
 	h.PostForgotPasswordHandler(rec, req)
 
 	if !emailSent {
-		t.Error("expected password reset email to be sent")
+		t.Error("expected password reset email to be queued")
 	}
 
 	if capturedRecipient != "test@example.com" {
 		t.Errorf("expected recipient 'test@example.com', got '%s'", capturedRecipient)
 	}
 
+	if capturedSubject != "Passwort zurücksetzen - Recipe Book" {
+		t.Errorf("expected the email in the user's language, got subject '%s'", capturedSubject)
+	}
+
 	forgotData, ok := capturedData.(ForgotPasswordData)
 	if !ok {
 		t.Fatal("expected capturedData to be ForgotPasswordData")
@@ -1089,7 +1123,7 @@ func TestPostForgotPasswordHandler_SendsEmailWhenUserExists(t *testing.T) {
 
 func TestPostForgotPasswordHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 	mockMailClient := &mailmocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
+		SendEmailFunc: func(ctx context.Context, email store.OutgoingEmail) error {
 			return errors.New("email service unavailable")
 		},
 	}
@@ -1102,7 +1136,7 @@ func TestPostForgotPasswordHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 				Email:    email,
 			}, "hashedpassword", nil
 		},
-		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+		CreatePasswordResetTokenFunc: func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
 			return nil
 		},
 	}
@@ -1116,9 +1150,10 @@ func TestPostForgotPasswordHandler_SucceedsEvenWhenEmailFails(t *testing.T) {
 	}
 
 	h := &Handler{
-		AuthStore:  mockAuthStore,
-		Renderer:   mockRenderer,
-		MailClient: mockMailClient,
+		AuthStore:            mockAuthStore,
+		Renderer:             mockRenderer,
+		MailClient:           mockMailClient,
+		UserPreferencesStore: &mocks.MockUserPreferencesStore{},
 	}
 
 	form := url.Values{}
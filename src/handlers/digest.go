@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+)
+
+// digestWeekdays are the days the weekly digest can be sent on, starting
+// with Monday.
+var digestWeekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// DigestSettings is the weekly digest section of the notification settings.
+// Day and Hour are in the server's time zone.
+type DigestSettings struct {
+	Enabled bool
+	Day     time.Weekday
+	Hour    int
+}
+
+func (DigestSettings) Weekdays() []time.Weekday {
+	return digestWeekdays
+}
+
+func (DigestSettings) Hours() []int {
+	hours := make([]int, 24)
+	for i := range hours {
+		hours[i] = i
+	}
+	return hours
+}
+
+// TimeZone names the zone Day and Hour are in.
+func (DigestSettings) TimeZone() string {
+	zone, _ := time.Now().Zone()
+	return zone
+}
+
+// PostDigestSettingsHandler subscribes to or unsubscribes from the weekly
+// digest and sets when it is sent.
+func (h *Handler) PostDigestSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid form data.")
+		return
+	}
+
+	enabled := r.FormValue("digest_enabled") == "on"
+	day, err := strconv.Atoi(r.FormValue("digest_day"))
+	if err != nil || day < 0 || day > 6 {
+		http.Redirect(w, r, "/account/notifications?error=Please choose a day for the digest", http.StatusSeeOther)
+		return
+	}
+	hour, err := strconv.Atoi(r.FormValue("digest_hour"))
+	if err != nil || hour < 0 || hour > 23 {
+		http.Redirect(w, r, "/account/notifications?error=Please choose a time for the digest", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.UserPreferencesStore.SetDigest(ctx, userInfo.UserID, enabled, time.Weekday(day), hour); err != nil {
+		logging.AddError(ctx, err, "Failed to save digest preferences")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to save your settings. Please try again.")
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":         "digest.settings.update",
+		"digest.enabled": enabled,
+		"digest.day":     day,
+		"digest.hour":    hour,
+	})
+
+	http.Redirect(w, r, "/account/notifications?success=Digest settings saved", http.StatusSeeOther)
+}
+
+type DigestUnsubscribeData struct {
+	UserInfo     *auth.UserInfo
+	Token        string
+	InvalidToken bool
+	Unsubscribed bool
+}
+
+// GetDigestUnsubscribeHandler asks to confirm unsubscribing from the digest.
+// Unsubscribing needs a POST so that link scanners in mail clients can't
+// do it by opening the link.
+func (h *Handler) GetDigestUnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token := r.URL.Query().Get("token")
+
+	data := DigestUnsubscribeData{
+		UserInfo: auth.GetUserInfoFromContext(ctx),
+		Token:    token,
+	}
+	if _, err := h.UnsubscribeSigner.UserID(token); err != nil {
+		data.InvalidToken = true
+	}
+	h.Renderer.RenderPage(w, "digest-unsubscribe.gohtml", data)
+}
+
+// PostDigestUnsubscribeHandler unsubscribes the user the token was signed
+// for from the digest. It doesn't need a login.
+func (h *Handler) PostDigestUnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	r.ParseForm()
+	token := r.FormValue("token")
+
+	data := DigestUnsubscribeData{
+		UserInfo: auth.GetUserInfoFromContext(ctx),
+		Token:    token,
+	}
+
+	userID, err := h.UnsubscribeSigner.UserID(token)
+	if err != nil {
+		data.InvalidToken = true
+		w.WriteHeader(http.StatusBadRequest)
+		h.Renderer.RenderPage(w, "digest-unsubscribe.gohtml", data)
+		return
+	}
+
+	prefs, err := h.UserPreferencesStore.Get(ctx, userID)
+	if err == nil && prefs != nil {
+		err = h.UserPreferencesStore.SetDigest(ctx, userID, false, prefs.DigestDay, prefs.DigestHour)
+	}
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to unsubscribe from digest")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to unsubscribe. Please try again.")
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":         "digest.unsubscribe",
+		"digest.user_id": userID,
+	})
+
+	data.Unsubscribed = true
+	h.Renderer.RenderPage(w, "digest-unsubscribe.gohtml", data)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/digest"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestPostDigestSettingsHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		form        url.Values
+		wantSaved   bool
+		wantEnabled bool
+		wantDay     time.Weekday
+		wantHour    int
+		wantQuery   string
+	}{
+		{"subscribes", url.Values{"digest_enabled": {"on"}, "digest_day": {"5"}, "digest_hour": {"18"}}, true, true, time.Friday, 18, "success"},
+		{"unsubscribes", url.Values{"digest_day": {"0"}, "digest_hour": {"8"}}, true, false, time.Sunday, 8, "success"},
+		{"rejects an unknown day", url.Values{"digest_enabled": {"on"}, "digest_day": {"7"}, "digest_hour": {"8"}}, false, false, 0, 0, "error"},
+		{"rejects an unknown hour", url.Values{"digest_enabled": {"on"}, "digest_day": {"1"}, "digest_hour": {"24"}}, false, false, 0, 0, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved bool
+			var gotEnabled bool
+			var gotDay time.Weekday
+			var gotHour int
+			h := &Handler{
+				UserPreferencesStore: &MockUserPreferencesStore{
+					SetDigestFunc: func(_ context.Context, userID int, enabled bool, day time.Weekday, hour int) error {
+						if userID != 4 {
+							t.Errorf("saved for user %d", userID)
+						}
+						saved, gotEnabled, gotDay, gotHour = true, enabled, day, hour
+						return nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/account/notifications/digest", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 4}))
+			rec := httptest.NewRecorder()
+
+			h.PostDigestSettingsHandler(rec, req)
+
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/account/notifications?"+tt.wantQuery+"=") {
+				t.Errorf("location = %q, want a %s message", location, tt.wantQuery)
+			}
+			if saved != tt.wantSaved {
+				t.Fatalf("saved = %v, want %v", saved, tt.wantSaved)
+			}
+			if saved && (gotEnabled != tt.wantEnabled || gotDay != tt.wantDay || gotHour != tt.wantHour) {
+				t.Errorf("saved enabled=%v day=%v hour=%d, want enabled=%v day=%v hour=%d", gotEnabled, gotDay, gotHour, tt.wantEnabled, tt.wantDay, tt.wantHour)
+			}
+		})
+	}
+}
+
+func TestPostDigestUnsubscribeHandler(t *testing.T) {
+	signer := digest.NewSigner([]byte("secret"))
+
+	tests := []struct {
+		name      string
+		token     string
+		wantUser  int
+		wantCode  int
+		wantState func(DigestUnsubscribeData) bool
+	}{
+		{"valid token", signer.Token(7), 7, http.StatusOK, func(d DigestUnsubscribeData) bool { return d.Unsubscribed }},
+		{"forged token", digest.NewSigner([]byte("other")).Token(7), 0, http.StatusBadRequest, func(d DigestUnsubscribeData) bool { return d.InvalidToken }},
+		{"missing token", "", 0, http.StatusBadRequest, func(d DigestUnsubscribeData) bool { return d.InvalidToken }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var unsubscribed int
+			var data DigestUnsubscribeData
+			h := &Handler{
+				UnsubscribeSigner: signer,
+				UserPreferencesStore: &MockUserPreferencesStore{
+					GetFunc: func(_ context.Context, userID int) (*models.UserPreferences, error) {
+						return &models.UserPreferences{UserID: userID, DigestEnabled: true, DigestDay: time.Friday, DigestHour: 18}, nil
+					},
+					SetDigestFunc: func(_ context.Context, userID int, enabled bool, day time.Weekday, hour int) error {
+						if enabled || day != time.Friday || hour != 18 {
+							t.Errorf("expected the digest off with the schedule kept, got enabled=%v day=%v hour=%d", enabled, day, hour)
+						}
+						unsubscribed = userID
+						return nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{
+					RenderPageFunc: func(_ http.ResponseWriter, name string, d any) {
+						if name != "digest-unsubscribe.gohtml" {
+							t.Errorf("rendered %q", name)
+						}
+						data = d.(DigestUnsubscribeData)
+					},
+				},
+			}
+
+			form := url.Values{"token": {tt.token}}
+			req := httptest.NewRequest(http.MethodPost, "/digest/unsubscribe", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{}))
+			rec := httptest.NewRecorder()
+
+			h.PostDigestUnsubscribeHandler(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if unsubscribed != tt.wantUser {
+				t.Errorf("unsubscribed user %d, want %d", unsubscribed, tt.wantUser)
+			}
+			if !tt.wantState(data) {
+				t.Errorf("unexpected page data %+v", data)
+			}
+		})
+	}
+}
+
+func TestGetDigestUnsubscribeHandler_DoesNotUnsubscribe(t *testing.T) {
+	signer := digest.NewSigner([]byte("secret"))
+	var data DigestUnsubscribeData
+	h := &Handler{
+		UnsubscribeSigner: signer,
+		UserPreferencesStore: &MockUserPreferencesStore{
+			SetDigestFunc: func(context.Context, int, bool, time.Weekday, int) error {
+				t.Error("expected GET not to change the settings")
+				return nil
+			},
+		},
+		Renderer: &tmocks.MockRenderer{
+			RenderPageFunc: func(_ http.ResponseWriter, _ string, d any) {
+				data = d.(DigestUnsubscribeData)
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/digest/unsubscribe?token="+url.QueryEscape(signer.Token(7)), nil)
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{}))
+	h.GetDigestUnsubscribeHandler(httptest.NewRecorder(), req)
+
+	if data.InvalidToken || data.Unsubscribed || data.Token != signer.Token(7) {
+		t.Errorf("expected a confirmation page for the token, got %+v", data)
+	}
+}
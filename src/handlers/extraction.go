@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -9,11 +13,18 @@ import (
 	"time"
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
 	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 )
 
-const maxImageSize = 10 * 1024 * 1024 // 10MB
+const (
+	maxImageSize    = 10 * 1024 * 1024 // 10MB
+	maxImagesPerJob = 10
+	maxPDFSize      = 20 * 1024 * 1024 // 20MB
+	maxTextLength   = 100 * 1024       // 100KB
+)
 
 type ExtractPageData struct {
 	UserInfo *auth.UserInfo
@@ -21,6 +32,13 @@ type ExtractPageData struct {
 	Success  string
 }
 
+type ExtractDuplicateData struct {
+	UserInfo *auth.UserInfo
+	Recipe   models.Recipe
+	URL      string
+	Action   string
+}
+
 func (h *Handler) GetExtractHandler(w http.ResponseWriter, r *http.Request) {
 	userInfo := auth.GetUserInfoFromContext(r.Context())
 	data := ExtractPageData{
@@ -35,6 +53,11 @@ func (h *Handler) PostExtractWebsiteHandler(w http.ResponseWriter, r *http.Reque
 	ctx := r.Context()
 	userInfo := auth.GetUserInfoFromContext(ctx)
 
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
 	if err := r.ParseForm(); err != nil {
 		http.Redirect(w, r, "/extract?error=Invalid form data", http.StatusSeeOther)
 		return
@@ -52,7 +75,25 @@ func (h *Handler) PostExtractWebsiteHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	jobID, err := h.ExtractionJobStore.Create(ctx, userInfo.UserID, "website", &websiteURL, nil)
+	force := r.FormValue("force") == "1"
+	if !force {
+		if recipe := h.findExtractedRecipe(ctx, websiteURL); recipe != nil {
+			logging.AddMany(ctx, map[string]any{
+				"action":              "extraction.duplicate",
+				"job_type":            "website",
+				"duplicate.recipe_id": recipe.ID,
+			})
+			h.Renderer.RenderPage(w, "extract-duplicate.gohtml", ExtractDuplicateData{
+				UserInfo: userInfo,
+				Recipe:   *recipe,
+				URL:      websiteURL,
+				Action:   "/extract/website",
+			})
+			return
+		}
+	}
+
+	jobID, err := h.createLinkJob(ctx, userInfo.UserID, "website", websiteURL, force)
 	if err != nil {
 		logging.AddError(ctx, err, "Failed to create extraction job")
 		http.Redirect(w, r, "/extract?error=Failed to create extraction job", http.StatusSeeOther)
@@ -72,6 +113,11 @@ func (h *Handler) PostExtractVideoHandler(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 	userInfo := auth.GetUserInfoFromContext(ctx)
 
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
 	if err := r.ParseForm(); err != nil {
 		http.Redirect(w, r, "/extract?error=Invalid form data", http.StatusSeeOther)
 		return
@@ -79,16 +125,34 @@ func (h *Handler) PostExtractVideoHandler(w http.ResponseWriter, r *http.Request
 
 	videoURL := strings.TrimSpace(r.FormValue("url"))
 	if videoURL == "" {
-		http.Redirect(w, r, "/extract?error=Please enter a YouTube URL", http.StatusSeeOther)
+		http.Redirect(w, r, "/extract?error=Please enter a video URL", http.StatusSeeOther)
 		return
 	}
 
-	if !isYouTubeURL(videoURL) {
-		http.Redirect(w, r, "/extract?error=Please enter a valid YouTube URL", http.StatusSeeOther)
+	if !extraction.IsVideoURL(videoURL) {
+		http.Redirect(w, r, "/extract?error=Please enter a link to a YouTube, Instagram, TikTok, Vimeo or Facebook video, or to a video file", http.StatusSeeOther)
 		return
 	}
 
-	jobID, err := h.ExtractionJobStore.Create(ctx, userInfo.UserID, "video", &videoURL, nil)
+	force := r.FormValue("force") == "1"
+	if !force {
+		if recipe := h.findExtractedRecipe(ctx, videoURL); recipe != nil {
+			logging.AddMany(ctx, map[string]any{
+				"action":              "extraction.duplicate",
+				"job_type":            "video",
+				"duplicate.recipe_id": recipe.ID,
+			})
+			h.Renderer.RenderPage(w, "extract-duplicate.gohtml", ExtractDuplicateData{
+				UserInfo: userInfo,
+				Recipe:   *recipe,
+				URL:      videoURL,
+				Action:   "/extract/video",
+			})
+			return
+		}
+	}
+
+	jobID, err := h.createLinkJob(ctx, userInfo.UserID, "video", videoURL, force)
 	if err != nil {
 		logging.AddError(ctx, err, "Failed to create extraction job")
 		http.Redirect(w, r, "/extract?error=Failed to create extraction job", http.StatusSeeOther)
@@ -108,36 +172,107 @@ func (h *Handler) PostExtractImageHandler(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 	userInfo := auth.GetUserInfoFromContext(ctx)
 
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
 	if err := r.ParseMultipartForm(maxImageSize); err != nil {
 		http.Redirect(w, r, "/extract?error=Image too large (max 10MB)", http.StatusSeeOther)
 		return
 	}
 
-	file, header, err := r.FormFile("image")
-	if err != nil {
+	files := r.MultipartForm.File["image"]
+	if len(files) == 0 {
 		http.Redirect(w, r, "/extract?error=Please select an image file", http.StatusSeeOther)
 		return
 	}
-	defer file.Close()
 
-	contentType := header.Header.Get("Content-Type")
-	if !isAllowedImageType(contentType) {
-		http.Redirect(w, r, "/extract?error=Please upload a JPEG, PNG, GIF, or WebP image", http.StatusSeeOther)
+	if len(files) > maxImagesPerJob {
+		http.Redirect(w, r, "/extract?error=Please upload at most "+strconv.Itoa(maxImagesPerJob)+" images per recipe", http.StatusSeeOther)
 		return
 	}
 
-	imageData, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	inputs := make([]store.ExtractionJobInput, 0, len(files))
+	totalSize := 0
+	for _, header := range files {
+		contentType := header.Header.Get("Content-Type")
+		if !isAllowedImageType(contentType) {
+			http.Redirect(w, r, "/extract?error=Please upload JPEG, PNG, GIF, or WebP images", http.StatusSeeOther)
+			return
+		}
+
+		imageData, err := readUploadedFile(header, maxImageSize)
+		if err != nil {
+			http.Redirect(w, r, "/extract?error=Failed to read image", http.StatusSeeOther)
+			return
+		}
+
+		if len(imageData) > maxImageSize {
+			http.Redirect(w, r, "/extract?error=Image too large (max 10MB)", http.StatusSeeOther)
+			return
+		}
+
+		inputs = append(inputs, store.ExtractionJobInput{ContentType: contentType, Data: imageData})
+		totalSize += len(imageData)
+	}
+
+	jobID, err := h.ExtractionJobStore.Create(ctx, userInfo.UserID, "image", nil, inputs)
 	if err != nil {
-		http.Redirect(w, r, "/extract?error=Failed to read image", http.StatusSeeOther)
+		logging.AddError(ctx, err, "Failed to create extraction job")
+		http.Redirect(w, r, "/extract?error=Failed to create extraction job", http.StatusSeeOther)
 		return
 	}
 
-	if len(imageData) > maxImageSize {
-		http.Redirect(w, r, "/extract?error=Image too large (max 10MB)", http.StatusSeeOther)
+	logging.AddMany(ctx, map[string]any{
+		"action":      "extraction.submit",
+		"job_id":      jobID,
+		"job_type":    "image",
+		"image_count": len(inputs),
+		"image_size":  totalSize,
+	})
+
+	http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(jobID), http.StatusSeeOther)
+}
+
+func (h *Handler) PostExtractPDFHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxPDFSize); err != nil {
+		http.Redirect(w, r, "/extract?error=PDF too large (max 20MB)", http.StatusSeeOther)
 		return
 	}
 
-	jobID, err := h.ExtractionJobStore.Create(ctx, userInfo.UserID, "image", nil, imageData)
+	_, header, err := r.FormFile("pdf")
+	if err != nil {
+		http.Redirect(w, r, "/extract?error=Please select a PDF file", http.StatusSeeOther)
+		return
+	}
+
+	pdfData, err := readUploadedFile(header, maxPDFSize)
+	if err != nil {
+		http.Redirect(w, r, "/extract?error=Failed to read PDF", http.StatusSeeOther)
+		return
+	}
+
+	if len(pdfData) > maxPDFSize {
+		http.Redirect(w, r, "/extract?error=PDF too large (max 20MB)", http.StatusSeeOther)
+		return
+	}
+
+	if !bytes.HasPrefix(pdfData, []byte("%PDF-")) {
+		http.Redirect(w, r, "/extract?error=Please upload a PDF file", http.StatusSeeOther)
+		return
+	}
+
+	inputs := []store.ExtractionJobInput{{ContentType: "application/pdf", Data: pdfData}}
+	jobID, err := h.ExtractionJobStore.Create(ctx, userInfo.UserID, "pdf", nil, inputs)
 	if err != nil {
 		logging.AddError(ctx, err, "Failed to create extraction job")
 		http.Redirect(w, r, "/extract?error=Failed to create extraction job", http.StatusSeeOther)
@@ -145,18 +280,152 @@ func (h *Handler) PostExtractImageHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	logging.AddMany(ctx, map[string]any{
-		"action":     "extraction.submit",
-		"job_id":     jobID,
-		"job_type":   "image",
-		"image_size": len(imageData),
+		"action":   "extraction.submit",
+		"job_id":   jobID,
+		"job_type": "pdf",
+		"pdf_size": len(pdfData),
 	})
 
 	http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(jobID), http.StatusSeeOther)
 }
 
+func (h *Handler) PostExtractTextHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/extract?error=Invalid form data", http.StatusSeeOther)
+		return
+	}
+
+	text := strings.TrimSpace(r.FormValue("text"))
+	if text == "" {
+		http.Redirect(w, r, "/extract?error=Please paste the recipe text", http.StatusSeeOther)
+		return
+	}
+
+	if len(text) > maxTextLength {
+		http.Redirect(w, r, "/extract?error=Text too long (max 100KB)", http.StatusSeeOther)
+		return
+	}
+
+	jobID, err := h.createTextExtractionJob(ctx, userInfo.UserID, text)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to create extraction job")
+		http.Redirect(w, r, "/extract?error=Failed to create extraction job", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":      "extraction.submit",
+		"job_id":      jobID,
+		"job_type":    "text",
+		"text_length": len(text),
+	})
+
+	http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(jobID), http.StatusSeeOther)
+}
+
+func (h *Handler) createTextExtractionJob(ctx context.Context, userID int, text string) (int, error) {
+	inputs := []store.ExtractionJobInput{{ContentType: "text/plain; charset=utf-8", Data: []byte(text)}}
+	return h.ExtractionJobStore.Create(ctx, userID, "text", nil, inputs)
+}
+
+// createLinkJob queues the extraction of a link. Links the user chose to
+// extract again bypass the cache, which holds the result they want to
+// replace.
+func (h *Handler) createLinkJob(ctx context.Context, userID int, jobType, link string, force bool) (int, error) {
+	if force {
+		return h.ExtractionJobStore.CreateForced(ctx, userID, jobType, link)
+	}
+	return h.ExtractionJobStore.Create(ctx, userID, jobType, &link, nil)
+}
+
+// findExtractedRecipe returns the recipe that was already extracted from the
+// same URL (after normalisation), if it still exists.
+func (h *Handler) findExtractedRecipe(ctx context.Context, rawURL string) *models.Recipe {
+	cacheKey, err := extraction.CacheKeyForURL(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	entry, err := h.ExtractionCacheStore.Get(ctx, cacheKey)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to look up extraction cache")
+		return nil
+	}
+	if entry == nil || entry.RecipeID == nil {
+		return nil
+	}
+
+	recipe, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(*entry.RecipeID))
+	if err != nil {
+		return nil
+	}
+	return &recipe
+}
+
+// extractionQuotaExceeded returns a user-facing message if the user has used
+// up their monthly extraction quota, or "" if they may submit another job.
+// Quota lookups that fail are logged and let the job through.
+func (h *Handler) extractionQuotaExceeded(ctx context.Context, userID int) string {
+	return h.extractionQuotaExceededBy(ctx, userID, 1)
+}
+
+// extractionQuotaExceededBy is like extractionQuotaExceeded, but checks
+// whether newJobs more jobs still fit into the monthly job limit.
+func (h *Handler) extractionQuotaExceededBy(ctx context.Context, userID int, newJobs int) string {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	usage, err := h.ExtractionJobStore.GetUsageSince(ctx, userID, monthStart)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to check extraction quota")
+		return ""
+	}
+	if usage == nil {
+		return ""
+	}
+
+	resetsOn := monthStart.AddDate(0, 1, 0).Format("January 2")
+	quota := usage.Quota
+	if quota.MonthlyJobLimit != nil && usage.JobCount+newJobs > *quota.MonthlyJobLimit {
+		logging.Add(ctx, "extraction.quota_exceeded", "jobs")
+		remaining := *quota.MonthlyJobLimit - usage.JobCount
+		if newJobs > 1 && remaining > 0 {
+			return fmt.Sprintf("This would exceed your monthly limit of %d extraction jobs (%d left). It resets on %s.", *quota.MonthlyJobLimit, remaining, resetsOn)
+		}
+		return fmt.Sprintf("You have reached your monthly limit of %d extraction jobs. It resets on %s.", *quota.MonthlyJobLimit, resetsOn)
+	}
+	if quota.MonthlyCostLimitUSD != nil && usage.Usage.CostUSD >= *quota.MonthlyCostLimitUSD {
+		logging.Add(ctx, "extraction.quota_exceeded", "cost")
+		return fmt.Sprintf("You have reached your monthly extraction budget. It resets on %s.", resetsOn)
+	}
+
+	return ""
+}
+
+// readUploadedFile reads at most maxSize+1 bytes so callers can detect
+// oversized uploads without buffering the whole file.
+func readUploadedFile(header *multipart.FileHeader, maxSize int) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, int64(maxSize)+1))
+}
+
 type JobsListData struct {
 	UserInfo   *auth.UserInfo
 	Jobs       []store.ExtractionJob
+	Batches    []store.ExtractionBatch
 	TotalCount int
 	Page       int
 	PageSize   int
@@ -193,9 +462,15 @@ func (h *Handler) GetAccountJobsHandler(w http.ResponseWriter, r *http.Request)
 		totalPages = 1
 	}
 
+	batches, err := h.ExtractionJobStore.GetBatchesByUserID(ctx, userInfo.UserID, 5)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch batches")
+	}
+
 	data := JobsListData{
 		UserInfo:   userInfo,
 		Jobs:       jobs,
+		Batches:    batches,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
@@ -356,20 +631,48 @@ func (h *Handler) PostJobRetryHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/account/jobs/"+jobIDStr+"?success=Job queued for retry", http.StatusSeeOther)
 }
 
-func isYouTubeURL(urlStr string) bool {
-	patterns := []string{
-		"youtube.com/watch",
-		"youtu.be/",
-		"youtube.com/embed/",
-		"youtube.com/v/",
-	}
-	urlLower := strings.ToLower(urlStr)
-	for _, pattern := range patterns {
-		if strings.Contains(urlLower, pattern) {
-			return true
-		}
+// PostJobCancelHandler cancels a pending or processing job. A worker that is
+// processing the job aborts it on its next heartbeat.
+func (h *Handler) PostJobCancelHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	jobIDStr := r.PathValue("id")
+	jobID, err := strconv.Atoi(jobIDStr)
+	if err != nil {
+		http.Redirect(w, r, "/account/jobs", http.StatusSeeOther)
+		return
 	}
-	return false
+
+	job, err := h.ExtractionJobStore.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		http.Redirect(w, r, "/account/jobs", http.StatusSeeOther)
+		return
+	}
+
+	if job.UserID != userInfo.UserID && !userInfo.IsAdmin {
+		http.Redirect(w, r, "/account/jobs", http.StatusSeeOther)
+		return
+	}
+
+	cancelled, err := h.ExtractionJobStore.Cancel(ctx, jobID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to cancel job")
+		http.Redirect(w, r, "/account/jobs/"+jobIDStr+"?error=Failed to cancel job", http.StatusSeeOther)
+		return
+	}
+	if !cancelled {
+		http.Redirect(w, r, "/account/jobs/"+jobIDStr+"?error=Only pending or processing jobs can be cancelled", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":          "extraction.cancel",
+		"job_id":          jobID,
+		"previous_status": job.Status,
+	})
+
+	http.Redirect(w, r, "/account/jobs/"+jobIDStr+"?success=Job cancelled", http.StatusSeeOther)
 }
 
 func isAllowedImageType(contentType string) bool {
@@ -383,12 +686,16 @@ func isAllowedImageType(contentType string) bool {
 }
 
 type AdminJobsData struct {
-	UserInfo   *auth.UserInfo
-	Jobs       []store.ExtractionJob
-	TotalCount int
-	Page       int
-	PageSize   int
-	TotalPages int
+	UserInfo     *auth.UserInfo
+	Jobs         []store.ExtractionJob
+	TotalCount   int
+	Page         int
+	PageSize     int
+	TotalPages   int
+	MonthlyUsage []store.UserExtractionUsage
+	UsageMonth   string
+	Success      string
+	Error        string
 }
 
 func (h *Handler) GetAdminJobsHandler(w http.ResponseWriter, r *http.Request) {
@@ -421,20 +728,78 @@ func (h *Handler) GetAdminJobsHandler(w http.ResponseWriter, r *http.Request) {
 		totalPages = 1
 	}
 
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthlyUsage, err := h.ExtractionJobStore.GetAllUsageSince(ctx, monthStart)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch extraction usage")
+	}
+
 	data := AdminJobsData{
-		UserInfo:   userInfo,
-		Jobs:       jobs,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
+		UserInfo:     userInfo,
+		Jobs:         jobs,
+		TotalCount:   totalCount,
+		Page:         page,
+		PageSize:     pageSize,
+		TotalPages:   totalPages,
+		MonthlyUsage: monthlyUsage,
+		UsageMonth:   monthStart.Format("January 2006"),
+		Success:      r.URL.Query().Get("success"),
+		Error:        r.URL.Query().Get("error"),
 	}
 	h.Renderer.RenderPage(w, "admin-jobs.gohtml", data)
 }
 
+func (h *Handler) PostAdminExtractionQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Redirect(w, r, "/admin/jobs?error=Invalid user ID", http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/admin/jobs?error=Invalid form data", http.StatusSeeOther)
+		return
+	}
+
+	var quota store.ExtractionQuota
+	if v := strings.TrimSpace(r.FormValue("monthly_job_limit")); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Redirect(w, r, "/admin/jobs?error=Job limit must be a non-negative number", http.StatusSeeOther)
+			return
+		}
+		quota.MonthlyJobLimit = &limit
+	}
+	if v := strings.TrimSpace(r.FormValue("monthly_cost_limit_usd")); v != "" {
+		limit, err := strconv.ParseFloat(v, 64)
+		if err != nil || limit < 0 {
+			http.Redirect(w, r, "/admin/jobs?error=Cost limit must be a non-negative amount", http.StatusSeeOther)
+			return
+		}
+		quota.MonthlyCostLimitUSD = &limit
+	}
+
+	if err := h.ExtractionJobStore.SetQuota(ctx, userID, quota); err != nil {
+		logging.AddError(ctx, err, "Failed to set extraction quota")
+		http.Redirect(w, r, "/admin/jobs?error=Failed to save quota", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":         "admin.extraction_quota.set",
+		"target_user_id": userID,
+	})
+
+	http.Redirect(w, r, "/admin/jobs?success=Quota saved", http.StatusSeeOther)
+}
+
 type AdminFeedbackData struct {
 	UserInfo   *auth.UserInfo
 	Feedback   []store.ExtractionFeedback
+	Variants   []store.ExtractionFeedbackSummary
 	TotalCount int
 	Page       int
 	PageSize   int
@@ -466,6 +831,11 @@ func (h *Handler) GetAdminFeedbackHandler(w http.ResponseWriter, r *http.Request
 		logging.AddError(ctx, err, "Failed to count feedback")
 	}
 
+	variants, err := h.ExtractionFeedbackStore.SummarizeByVariant(ctx)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to summarize feedback")
+	}
+
 	totalPages := (totalCount + pageSize - 1) / pageSize
 	if totalPages < 1 {
 		totalPages = 1
@@ -474,6 +844,7 @@ func (h *Handler) GetAdminFeedbackHandler(w http.ResponseWriter, r *http.Request
 	data := AdminFeedbackData{
 		UserInfo:   userInfo,
 		Feedback:   feedback,
+		Variants:   variants,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+const (
+	maxBatchFileSize = 5 * 1024 * 1024 // 5MB
+	maxBatchLinks    = 500
+)
+
+type BatchPageData struct {
+	UserInfo *auth.UserInfo
+	Batch    *store.ExtractionBatch
+	Jobs     []store.ExtractionJob
+}
+
+// PostExtractBatchHandler creates one extraction job per recipe link found in
+// a pasted URL list or an uploaded URL list / bookmarks export. Links the
+// user already submitted are skipped.
+func (h *Handler) PostExtractBatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if err := r.ParseMultipartForm(maxBatchFileSize); err != nil {
+		http.Redirect(w, r, "/extract?error=File too large (max 5MB)", http.StatusSeeOther)
+		return
+	}
+
+	links := extraction.ParseURLList([]byte(r.FormValue("urls")))
+	name := "Pasted links"
+
+	if _, header, err := r.FormFile("file"); err == nil {
+		data, err := readUploadedFile(header, maxBatchFileSize)
+		if err != nil {
+			http.Redirect(w, r, "/extract?error=Failed to read file", http.StatusSeeOther)
+			return
+		}
+		if len(data) > maxBatchFileSize {
+			http.Redirect(w, r, "/extract?error=File too large (max 5MB)", http.StatusSeeOther)
+			return
+		}
+
+		if extraction.IsBookmarksExport(data) {
+			links = append(links, extraction.ParseBookmarks(data)...)
+		} else {
+			links = append(links, extraction.ParseURLList(data)...)
+		}
+		name = batchNameFromFilename(header.Filename)
+	}
+
+	links, skipped := h.dedupeBatchLinks(ctx, userInfo.UserID, links)
+	if len(links) == 0 {
+		message := "No recipe links found"
+		if skipped > 0 {
+			message = "All links have already been submitted"
+		}
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+	if len(links) > maxBatchLinks {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape("Too many links (max "+strconv.Itoa(maxBatchLinks)+" per batch)"), http.StatusSeeOther)
+		return
+	}
+
+	if message := h.extractionQuotaExceededBy(ctx, userInfo.UserID, len(links)); message != "" {
+		http.Redirect(w, r, "/extract?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
+	now := time.Now()
+	queued, err := h.ExtractionJobStore.GetScheduledJobs(ctx, now)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch scheduled extraction jobs")
+	}
+	schedule := extraction.ScheduleBatch(links, now, queued)
+	jobs := make([]store.ExtractionBatchJob, len(links))
+	for i, link := range links {
+		jobs[i] = store.ExtractionBatchJob{
+			JobType:   string(link.JobType),
+			InputURL:  link.URL,
+			Priority:  extraction.PriorityBatch,
+			NotBefore: schedule[i],
+		}
+	}
+
+	batchID, err := h.ExtractionJobStore.CreateBatch(ctx, userInfo.UserID, name, skipped, jobs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to create extraction batch")
+		http.Redirect(w, r, "/extract?error=Failed to create extraction jobs", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":        "extraction.batch.submit",
+		"batch_id":      batchID,
+		"batch.jobs":    len(jobs),
+		"batch.skipped": skipped,
+	})
+
+	http.Redirect(w, r, "/account/batches/"+strconv.Itoa(batchID), http.StatusSeeOther)
+}
+
+// dedupeBatchLinks drops links that point to the same recipe as an earlier
+// link in the list or as one of the user's own jobs, and returns how many
+// links were dropped. Recipes other users extracted from a link don't count:
+// the user gets their own copy, which the extraction cache makes cheap.
+func (h *Handler) dedupeBatchLinks(ctx context.Context, userID int, links []extraction.BatchLink) ([]extraction.BatchLink, int) {
+	seen := make(map[string]bool)
+	submitted, err := h.ExtractionJobStore.GetSubmittedURLs(ctx, userID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to get submitted extraction URLs")
+	}
+	for _, submittedURL := range submitted {
+		if cacheKey, err := extraction.CacheKeyForURL(submittedURL); err == nil {
+			seen[cacheKey] = true
+		}
+	}
+
+	var unique []extraction.BatchLink
+	for _, link := range links {
+		cacheKey, err := extraction.CacheKeyForURL(link.URL)
+		if err != nil {
+			continue
+		}
+		if seen[cacheKey] {
+			continue
+		}
+		seen[cacheKey] = true
+		unique = append(unique, link)
+	}
+
+	return unique, len(links) - len(unique)
+}
+
+func (h *Handler) GetBatchHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := h.loadBatchPageData(w, r)
+	if !ok {
+		return
+	}
+	h.Renderer.RenderPage(w, "account-batch.gohtml", data)
+}
+
+// GetBatchProgressHandler renders the progress fragment that the batch page
+// polls while jobs are still running.
+func (h *Handler) GetBatchProgressHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := h.loadBatchPageData(w, r)
+	if !ok {
+		return
+	}
+	h.Renderer.RenderFragment(w, "batch-progress", data)
+}
+
+func (h *Handler) loadBatchPageData(w http.ResponseWriter, r *http.Request) (*BatchPageData, bool) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	batchID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid batch ID")
+		return nil, false
+	}
+
+	batch, err := h.ExtractionJobStore.GetBatch(ctx, batchID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to get extraction batch")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to load batch")
+		return nil, false
+	}
+	if batch == nil {
+		h.Renderer.RenderError(w, r, http.StatusNotFound, "Batch not found")
+		return nil, false
+	}
+	if batch.UserID != userInfo.UserID && !userInfo.IsAdmin {
+		h.Renderer.RenderError(w, r, http.StatusForbidden, "You don't have access to this batch")
+		return nil, false
+	}
+
+	jobs, err := h.ExtractionJobStore.GetBatchJobs(ctx, batchID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to get batch jobs")
+		jobs = []store.ExtractionJob{}
+	}
+
+	return &BatchPageData{
+		UserInfo: userInfo,
+		Batch:    batch,
+		Jobs:     jobs,
+	}, true
+}
+
+// batchNameFromFilename strips the directory a browser may send along with
+// the filename.
+func batchNameFromFilename(filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+	return filename
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// maxCorrectionJobs limits how many of the newest extracted recipes are
+// compared with their current version.
+const maxCorrectionJobs = 500
+
+// ExtractionCorrection is an extracted recipe that its author edited
+// afterwards. Together with the job's LLM input, the extracted and the
+// corrected recipe make a sample for the extraction eval.
+type ExtractionCorrection struct {
+	Job       store.ExtractionJob
+	Extracted *extraction.ExtractedRecipe
+	Corrected *extraction.ExtractedRecipe
+	Changes   []RecipeFieldChange
+	Feedback  *store.ExtractionFeedback
+}
+
+// SampleName is the name of the correction's files in the eval dataset. It
+// starts with the job type so the eval picks the matching source type.
+func (c ExtractionCorrection) SampleName() string {
+	return fmt.Sprintf("%s-job-%d", c.Job.JobType, c.Job.ID)
+}
+
+type AdminCorrectionsData struct {
+	UserInfo    *auth.UserInfo
+	Corrections []ExtractionCorrection
+}
+
+// extractionCorrections compares the newest extracted recipes with their
+// current version and returns those that were edited.
+func (h *Handler) extractionCorrections(ctx context.Context) ([]ExtractionCorrection, error) {
+	jobs, err := h.ExtractionJobStore.GetWithExtractedRecipe(ctx, maxCorrectionJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	recipeIDs := make([]int, 0, len(jobs))
+	jobIDs := make([]int, 0, len(jobs))
+	for _, job := range jobs {
+		recipeIDs = append(recipeIDs, *job.RecipeID)
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	recipes, err := h.RecipeStore.GetByIDs(ctx, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := h.TagStore.GetForRecipes(ctx, recipeIDs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipe tags")
+	}
+	feedback, err := h.ExtractionFeedbackStore.GetByJobIDs(ctx, jobIDs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch job feedback")
+	}
+
+	var corrections []ExtractionCorrection
+	for _, job := range jobs {
+		recipe, ok := recipes[*job.RecipeID]
+		if !ok {
+			continue
+		}
+
+		var extracted extraction.ExtractedRecipe
+		if err := json.Unmarshal(job.ExtractedRecipe, &extracted); err != nil {
+			logging.AddError(ctx, err, "Failed to decode extracted recipe")
+			continue
+		}
+
+		changes := diffRecipe(extracted.Recipe(recipe.AuthorID), recipe)
+		if len(changes) == 0 {
+			continue
+		}
+
+		recipe.Tags = tags[recipe.ID]
+		corrected := extraction.NewExtractedRecipe(recipe)
+		corrected.Confidence = 1.0
+
+		corrections = append(corrections, ExtractionCorrection{
+			Job:       job,
+			Extracted: &extracted,
+			Corrected: corrected,
+			Changes:   changes,
+			Feedback:  feedback[job.ID],
+		})
+	}
+	return corrections, nil
+}
+
+func (h *Handler) GetAdminCorrectionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	corrections, err := h.extractionCorrections(ctx)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch extraction corrections")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to load corrections. Please try again.")
+		return
+	}
+
+	data := AdminCorrectionsData{
+		UserInfo:    userInfo,
+		Corrections: corrections,
+	}
+	h.Renderer.RenderPage(w, "admin-corrections.gohtml", data)
+}
+
+// evalManifestEntry describes one sample of the eval dataset export.
+type evalManifestEntry struct {
+	Name          string   `json:"name"`
+	JobID         int      `json:"job_id"`
+	JobType       string   `json:"job_type"`
+	SourceURL     *string  `json:"source_url,omitempty"`
+	Sample        string   `json:"sample,omitempty"`
+	PromptVersion *string  `json:"prompt_version"`
+	Model         *string  `json:"model"`
+	ChangedFields []string `json:"changed_fields"`
+	Rating        *int     `json:"rating,omitempty"`
+	FeedbackType  string   `json:"feedback_type,omitempty"`
+	Comment       *string  `json:"comment,omitempty"`
+}
+
+// GetAdminCorrectionsExportHandler exports the corrections as a zip archive
+// laid out like test/llm-extraction: expected/ holds the corrected recipes,
+// outputs/ what the LLM extracted and inputs/ the prompts it was sent.
+// Uploaded text and single images are added to samples/; the sources of URL
+// jobs are listed in manifest.json to be fetched by hand.
+func (h *Handler) GetAdminCorrectionsExportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	corrections, err := h.extractionCorrections(ctx)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch extraction corrections")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to export corrections. Please try again.")
+		return
+	}
+
+	filename := "extraction-eval-" + time.Now().Format("2006-01-02") + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	archive := zip.NewWriter(w)
+	manifest := []evalManifestEntry{}
+	for _, correction := range corrections {
+		entry, err := h.writeEvalSample(ctx, archive, correction)
+		if err != nil {
+			logging.AddError(ctx, err, "Failed to write eval sample")
+			return
+		}
+		manifest = append(manifest, entry)
+	}
+
+	if err := writeZipJSON(archive, "manifest.json", manifest); err != nil {
+		logging.AddError(ctx, err, "Failed to write eval manifest")
+		return
+	}
+	if err := archive.Close(); err != nil {
+		logging.AddError(ctx, err, "Failed to finish eval export")
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":              "admin.extraction_eval.export",
+		"export.sample_count": len(manifest),
+	})
+}
+
+func (h *Handler) writeEvalSample(ctx context.Context, archive *zip.Writer, correction ExtractionCorrection) (evalManifestEntry, error) {
+	job := correction.Job
+	name := correction.SampleName()
+	entry := evalManifestEntry{
+		Name:          name,
+		JobID:         job.ID,
+		JobType:       job.JobType,
+		SourceURL:     job.InputURL,
+		PromptVersion: job.PromptVersion,
+		Model:         job.Model,
+		ChangedFields: []string{},
+	}
+	for _, change := range correction.Changes {
+		entry.ChangedFields = append(entry.ChangedFields, change.Field)
+	}
+	if correction.Feedback != nil {
+		entry.Rating = &correction.Feedback.Rating
+		entry.FeedbackType = correction.Feedback.FeedbackType
+		entry.Comment = correction.Feedback.Comment
+	}
+
+	if err := writeZipJSON(archive, "expected/"+name+".json", correction.Corrected); err != nil {
+		return entry, err
+	}
+	if err := writeZipJSON(archive, "outputs/"+name+".json", correction.Extracted); err != nil {
+		return entry, err
+	}
+	if job.LLMInput != nil {
+		if err := writeZipFile(archive, "inputs/"+name+".txt", []byte(*job.LLMInput)); err != nil {
+			return entry, err
+		}
+	}
+
+	if job.InputURL == nil && job.InputCount == 1 {
+		inputs, err := h.ExtractionJobStore.GetInputs(ctx, job.ID)
+		if err != nil {
+			return entry, err
+		}
+		if len(inputs) == 1 {
+			if ext := evalSampleExtension(job.JobType, inputs[0].ContentType); ext != "" {
+				entry.Sample = "samples/" + name + ext
+				if err := writeZipFile(archive, entry.Sample, inputs[0].Data); err != nil {
+					return entry, err
+				}
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// evalSampleExtension is the file extension under which the eval recognizes
+// an uploaded input, or "" if it can't extract from it.
+func evalSampleExtension(jobType, contentType string) string {
+	switch {
+	case jobType == "text":
+		return ".txt"
+	case jobType == "image" && contentType == "image/jpeg":
+		return ".jpg"
+	case jobType == "image" && contentType == "image/png":
+		return ".png"
+	default:
+		return ""
+	}
+}
+
+func writeZipJSON(archive *zip.Writer, name string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipFile(archive, name, append(data, '\n'))
+}
+
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestGetAdminCorrectionsExportHandler_ExportsEditedRecipes(t *testing.T) {
+	editedID, untouchedID := 7, 8
+	prompt := "Extract the recipe.\n\n## Source Content\n\nPancakes: flour, eggs, milk."
+	promptVersion, model := "v1", "google/gemini-2.5-flash-lite"
+	extracted := `{"title": "Pancakes", "description": "", "ingredients_md": "- flour\n- eggs", "instructions_md": "1. Mix.", "prep_time_minutes": 10, "cook_time_minutes": null, "calories_per_serving": null, "suggested_tags": ["breakfast"], "confidence": 0.8, "confidence_notes": ""}`
+	recipes := map[int]models.Recipe{
+		7: {ID: 7, Title: "Pancakes", IngredientsMD: "- flour\n- eggs\n- milk", InstructionsMD: "1. Mix.", PrepTime: 10, AuthorID: 1},
+		8: {ID: 8, Title: "Pancakes", IngredientsMD: "- flour\n- eggs", InstructionsMD: "1. Mix.", PrepTime: 10, AuthorID: 1},
+	}
+	comment := "Forgot the milk"
+
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDsFunc: func(_ context.Context, ids []int) (map[int]models.Recipe, error) { return recipes, nil },
+		},
+		TagStore: &mocks.MockTagStore{
+			GetForRecipesFunc: func(context.Context, []int) (map[int][]models.Tag, error) {
+				return map[int][]models.Tag{7: {{Name: "breakfast"}}, 8: {{Name: "breakfast"}}}, nil
+			},
+		},
+		ExtractionJobStore: &mockExtractionJobStore{
+			extractedJobs: []store.ExtractionJob{
+				{ID: 3, JobType: "text", InputCount: 1, LLMInput: &prompt, RecipeID: &editedID, PromptVersion: &promptVersion, Model: &model, ExtractedRecipe: []byte(extracted)},
+				{ID: 4, JobType: "text", InputCount: 1, LLMInput: &prompt, RecipeID: &untouchedID, ExtractedRecipe: []byte(extracted)},
+			},
+			inputs: []store.ExtractionJobInput{{ContentType: "text/plain", Data: []byte("Pancakes: flour, eggs, milk.")}},
+		},
+		ExtractionFeedbackStore: &feedbackByJobStore{feedback: map[int]*store.ExtractionFeedback{
+			3: {JobID: 3, Rating: 2, FeedbackType: "missing_info", Comment: &comment},
+		}},
+		Renderer: &tmocks.MockRenderer{},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/corrections/export", nil)
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1, IsAdmin: true}))
+	rec := httptest.NewRecorder()
+
+	h.GetAdminCorrectionsExportHandler(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("got %d with %q, want a zip archive", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	files := readZip(t, rec.Body.Bytes())
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	wantNames := []string{"expected/text-job-3.json", "inputs/text-job-3.txt", "manifest.json", "outputs/text-job-3.json", "samples/text-job-3.txt"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("files = %v, want %v", names, wantNames)
+	}
+
+	var expected extraction.ExtractedRecipe
+	if err := json.Unmarshal(files["expected/text-job-3.json"], &expected); err != nil {
+		t.Fatalf("expected recipe is not valid JSON: %v", err)
+	}
+	if expected.IngredientsMD != "- flour\n- eggs\n- milk" || expected.PrepTimeMinutes == nil || *expected.PrepTimeMinutes != 10 {
+		t.Errorf("expected recipe = %+v, want the edited recipe", expected)
+	}
+	if !reflect.DeepEqual(expected.SuggestedTags, []string{"breakfast"}) {
+		t.Errorf("expected tags = %v, want the recipe's tags", expected.SuggestedTags)
+	}
+	if string(files["inputs/text-job-3.txt"]) != prompt {
+		t.Errorf("input = %q, want the LLM prompt", files["inputs/text-job-3.txt"])
+	}
+
+	var manifest []evalManifestEntry
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(manifest) != 1 {
+		t.Fatalf("manifest has %d entries, want 1", len(manifest))
+	}
+	entry := manifest[0]
+	if entry.JobID != 3 || entry.Sample != "samples/text-job-3.txt" || !reflect.DeepEqual(entry.ChangedFields, []string{"ingredients"}) {
+		t.Errorf("manifest entry = %+v", entry)
+	}
+	if entry.Rating == nil || *entry.Rating != 2 || entry.Comment == nil || *entry.Comment != comment {
+		t.Errorf("manifest entry feedback = %v, %v, want the job's feedback", entry.Rating, entry.Comment)
+	}
+}
+
+// feedbackByJobStore returns the feedback on jobs from a map.
+type feedbackByJobStore struct {
+	store.ExtractionFeedbackStore
+	feedback map[int]*store.ExtractionFeedback
+}
+
+func (s *feedbackByJobStore) GetByJobIDs(ctx context.Context, jobIDs []int) (map[int]*store.ExtractionFeedback, error) {
+	return s.feedback, nil
+}
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("response is not a zip archive: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		files[f.Name] = content
+	}
+	return files
+}
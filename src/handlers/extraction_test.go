@@ -1,21 +1,45 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/models"
 	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
 	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
 )
 
 type mockExtractionJobStore struct {
-	getByIDFunc    func(ctx context.Context, id int) (*store.ExtractionJob, error)
-	resetForRetry  func(ctx context.Context, id int) error
-	resetCallCount int
+	getByIDFunc     func(ctx context.Context, id int) (*store.ExtractionJob, error)
+	createFunc      func(ctx context.Context, userID int, jobType string, inputURL *string, inputs []store.ExtractionJobInput) (int, error)
+	createForced    func(ctx context.Context, userID int, jobType, inputURL string) (int, error)
+	resetForRetry   func(ctx context.Context, id int) error
+	resetCallCount  int
+	cancelFunc      func(ctx context.Context, id int) (bool, error)
+	cancelCallCount int
+	usageFunc       func(ctx context.Context, userID int, since time.Time) (*store.UserExtractionUsage, error)
+	setQuotaFunc    func(ctx context.Context, userID int, quota store.ExtractionQuota) error
+	createBatch     func(ctx context.Context, userID int, name string, skippedCount int, jobs []store.ExtractionBatchJob) (int, error)
+	batch           *store.ExtractionBatch
+	createReExtract func(ctx context.Context, userID, recipeID int, jobType, inputURL string, model *string) (int, error)
+	createTranslate func(ctx context.Context, userID, recipeID int, language string) (int, error)
+	createTagging   func(ctx context.Context, userID int, recipeIDs []int, priority int) error
+	extractedJobs   []store.ExtractionJob
+	inputs          []store.ExtractionJobInput
+	submittedURLs   []string
+	scheduledJobs   []store.ExtractionBatchJob
+	appliedJobIDs   []int
 }
 
 func (m *mockExtractionJobStore) GetByID(ctx context.Context, id int) (*store.ExtractionJob, error) {
@@ -28,20 +52,72 @@ func (m *mockExtractionJobStore) ResetForRetry(ctx context.Context, id int) erro
 	}
 	return nil
 }
-func (m *mockExtractionJobStore) Create(ctx context.Context, userID int, jobType string, inputURL *string, inputData []byte) (int, error) {
+func (m *mockExtractionJobStore) Create(ctx context.Context, userID int, jobType string, inputURL *string, inputs []store.ExtractionJobInput) (int, error) {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, userID, jobType, inputURL, inputs)
+	}
+	return 0, nil
+}
+func (m *mockExtractionJobStore) CreateForced(ctx context.Context, userID int, jobType, inputURL string) (int, error) {
+	if m.createForced != nil {
+		return m.createForced(ctx, userID, jobType, inputURL)
+	}
+	return 0, nil
+}
+func (m *mockExtractionJobStore) CreateReExtraction(ctx context.Context, userID, recipeID int, jobType, inputURL string, model *string) (int, error) {
+	if m.createReExtract != nil {
+		return m.createReExtract(ctx, userID, recipeID, jobType, inputURL, model)
+	}
+	return 0, nil
+}
+func (m *mockExtractionJobStore) CreateTranslation(ctx context.Context, userID, recipeID int, language string) (int, error) {
+	if m.createTranslate != nil {
+		return m.createTranslate(ctx, userID, recipeID, language)
+	}
 	return 0, nil
 }
+func (m *mockExtractionJobStore) CreateTagging(ctx context.Context, userID int, recipeIDs []int, priority int) error {
+	if m.createTagging != nil {
+		return m.createTagging(ctx, userID, recipeIDs, priority)
+	}
+	return nil
+}
+func (m *mockExtractionJobStore) GetInputs(ctx context.Context, jobID int) ([]store.ExtractionJobInput, error) {
+	return m.inputs, nil
+}
 func (m *mockExtractionJobStore) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]store.ExtractionJob, error) {
 	return nil, nil
 }
 func (m *mockExtractionJobStore) CountByUserID(ctx context.Context, userID int) (int, error) {
 	return 0, nil
 }
+func (m *mockExtractionJobStore) GetSubmittedURLs(ctx context.Context, userID int) ([]string, error) {
+	return m.submittedURLs, nil
+}
+func (m *mockExtractionJobStore) GetScheduledJobs(ctx context.Context, after time.Time) ([]store.ExtractionBatchJob, error) {
+	return m.scheduledJobs, nil
+}
 func (m *mockExtractionJobStore) GetAll(ctx context.Context, limit, offset int) ([]store.ExtractionJob, error) {
 	return nil, nil
 }
 func (m *mockExtractionJobStore) CountAll(ctx context.Context) (int, error) { return 0, nil }
-func (m *mockExtractionJobStore) ClaimPendingJob(ctx context.Context) (*store.ExtractionJob, error) {
+func (m *mockExtractionJobStore) ClaimPendingJob(ctx context.Context, workerID string, maxPerUser int) (*store.ExtractionJob, error) {
+	return nil, nil
+}
+func (m *mockExtractionJobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	return nil
+}
+func (m *mockExtractionJobStore) ReleaseJob(ctx context.Context, id int, workerID string) error {
+	return nil
+}
+func (m *mockExtractionJobStore) Cancel(ctx context.Context, id int) (bool, error) {
+	m.cancelCallCount++
+	if m.cancelFunc != nil {
+		return m.cancelFunc(ctx, id)
+	}
+	return true, nil
+}
+func (m *mockExtractionJobStore) ReapStaleJobs(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.ReapedJob, error) {
 	return nil, nil
 }
 func (m *mockExtractionJobStore) UpdateStatus(ctx context.Context, id int, status string, errorMessage *string) error {
@@ -53,13 +129,77 @@ func (m *mockExtractionJobStore) UpdateLLMData(ctx context.Context, id int, llmI
 func (m *mockExtractionJobStore) SetRecipeID(ctx context.Context, id int, recipeID int) error {
 	return nil
 }
-func (m *mockExtractionJobStore) MarkCompleted(ctx context.Context, id int) error { return nil }
+func (m *mockExtractionJobStore) SetProposedRecipe(ctx context.Context, id int, proposal []byte) error {
+	return nil
+}
+func (m *mockExtractionJobStore) MarkProposalApplied(ctx context.Context, id int) error {
+	m.appliedJobIDs = append(m.appliedJobIDs, id)
+	return nil
+}
+func (m *mockExtractionJobStore) SetVariant(ctx context.Context, id int, promptVersion, model string) error {
+	return nil
+}
+func (m *mockExtractionJobStore) SetExtractedRecipe(ctx context.Context, id int, recipe []byte) error {
+	return nil
+}
+func (m *mockExtractionJobStore) GetWithExtractedRecipe(ctx context.Context, limit int) ([]store.ExtractionJob, error) {
+	return m.extractedJobs, nil
+}
+func (m *mockExtractionJobStore) MarkCompleted(ctx context.Context, id int, notification *store.OutgoingEmail) error {
+	return nil
+}
 func (m *mockExtractionJobStore) IncrementAttemptCount(ctx context.Context, id int) error {
 	return nil
 }
 func (m *mockExtractionJobStore) ScheduleRetry(ctx context.Context, id int, retryAfter time.Time) error {
 	return nil
 }
+func (m *mockExtractionJobStore) AddUsage(ctx context.Context, id int, usage store.ExtractionUsage) error {
+	return nil
+}
+func (m *mockExtractionJobStore) GetUsageSince(ctx context.Context, userID int, since time.Time) (*store.UserExtractionUsage, error) {
+	if m.usageFunc != nil {
+		return m.usageFunc(ctx, userID, since)
+	}
+	return nil, nil
+}
+func (m *mockExtractionJobStore) GetAllUsageSince(ctx context.Context, since time.Time) ([]store.UserExtractionUsage, error) {
+	return nil, nil
+}
+func (m *mockExtractionJobStore) SetQuota(ctx context.Context, userID int, quota store.ExtractionQuota) error {
+	if m.setQuotaFunc != nil {
+		return m.setQuotaFunc(ctx, userID, quota)
+	}
+	return nil
+}
+
+func (m *mockExtractionJobStore) CreateBatch(ctx context.Context, userID int, name string, skippedCount int, jobs []store.ExtractionBatchJob) (int, error) {
+	if m.createBatch != nil {
+		return m.createBatch(ctx, userID, name, skippedCount, jobs)
+	}
+	return 0, nil
+}
+func (m *mockExtractionJobStore) GetBatch(ctx context.Context, id int) (*store.ExtractionBatch, error) {
+	return m.batch, nil
+}
+func (m *mockExtractionJobStore) GetBatchesByUserID(ctx context.Context, userID int, limit int) ([]store.ExtractionBatch, error) {
+	return nil, nil
+}
+func (m *mockExtractionJobStore) GetBatchJobs(ctx context.Context, batchID int) ([]store.ExtractionJob, error) {
+	return nil, nil
+}
+
+type mockExtractionCacheStore struct {
+	entries map[string]*store.ExtractionCacheEntry
+}
+
+func (m *mockExtractionCacheStore) Get(ctx context.Context, key string) (*store.ExtractionCacheEntry, error) {
+	return m.entries[key], nil
+}
+func (m *mockExtractionCacheStore) Put(ctx context.Context, key string, result []byte, recipeID int, promptVersion, model string, ttl time.Duration) error {
+	return nil
+}
+func (m *mockExtractionCacheStore) DeleteExpired(ctx context.Context) (int64, error) { return 0, nil }
 
 func TestPostJobRetryHandler_StatusGuard(t *testing.T) {
 	userInfo := &auth.UserInfo{IsLoggedIn: true, UserID: 1}
@@ -151,3 +291,634 @@ func TestPostJobRetryHandler_StatusGuard(t *testing.T) {
 		})
 	}
 }
+
+func TestPostJobCancelHandler(t *testing.T) {
+	tests := []struct {
+		name             string
+		job              *store.ExtractionJob
+		cancelled        bool
+		wantCancelCall   bool
+		wantRedirectPath string
+	}{
+		{
+			name:             "pending job is cancelled",
+			job:              &store.ExtractionJob{ID: 1, UserID: 1, Status: "pending"},
+			cancelled:        true,
+			wantCancelCall:   true,
+			wantRedirectPath: "/account/jobs/1?success=Job cancelled",
+		},
+		{
+			name:             "finished job cannot be cancelled",
+			job:              &store.ExtractionJob{ID: 1, UserID: 1, Status: "completed"},
+			cancelled:        false,
+			wantCancelCall:   true,
+			wantRedirectPath: "/account/jobs/1?error=Only pending or processing jobs can be cancelled",
+		},
+		{
+			name:             "other user's job is not cancelled",
+			job:              &store.ExtractionJob{ID: 1, UserID: 2, Status: "processing"},
+			wantRedirectPath: "/account/jobs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &mockExtractionJobStore{
+				getByIDFunc: func(_ context.Context, _ int) (*store.ExtractionJob, error) {
+					return tt.job, nil
+				},
+				cancelFunc: func(_ context.Context, _ int) (bool, error) {
+					return tt.cancelled, nil
+				},
+			}
+			h := &Handler{ExtractionJobStore: jobStore}
+
+			req := httptest.NewRequest(http.MethodPost, "/account/jobs/1/cancel", nil)
+			req.SetPathValue("id", "1")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+			rec := httptest.NewRecorder()
+
+			h.PostJobCancelHandler(rec, req)
+
+			if tt.wantCancelCall != (jobStore.cancelCallCount == 1) {
+				t.Errorf("Cancel called %d times, want call: %v", jobStore.cancelCallCount, tt.wantCancelCall)
+			}
+			if location := rec.Header().Get("Location"); location != tt.wantRedirectPath {
+				t.Errorf("redirect location = %q, want %q", location, tt.wantRedirectPath)
+			}
+		})
+	}
+}
+
+type testUpload struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
+func newUploadRequest(t *testing.T, target, field string, uploads []testUpload) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, upload := range uploads {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, upload.filename))
+		header.Set("Content-Type", upload.contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatalf("failed to create multipart part: %v", err)
+		}
+		part.Write(upload.data)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	userInfo := &auth.UserInfo{IsLoggedIn: true, UserID: 1}
+	return req.WithContext(auth.ContextWithUserInfo(req.Context(), userInfo))
+}
+
+func TestPostExtractImageHandler_CreatesSingleJobForMultipleImages(t *testing.T) {
+	var capturedType string
+	var capturedInputs []store.ExtractionJobInput
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, jobType string, _ *string, inputs []store.ExtractionJobInput) (int, error) {
+			capturedType = jobType
+			capturedInputs = inputs
+			return 42, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	req := newUploadRequest(t, "/extract/image", "image", []testUpload{
+		{filename: "page1.jpg", contentType: "image/jpeg", data: []byte("first page")},
+		{filename: "page2.png", contentType: "image/png", data: []byte("second page")},
+	})
+	rec := httptest.NewRecorder()
+
+	h.PostExtractImageHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); location != "/account/jobs/42" {
+		t.Errorf("redirect location = %q, want %q", location, "/account/jobs/42")
+	}
+	if capturedType != "image" {
+		t.Errorf("job type = %q, want %q", capturedType, "image")
+	}
+	if len(capturedInputs) != 2 {
+		t.Fatalf("expected 2 inputs, got %d", len(capturedInputs))
+	}
+	if capturedInputs[0].ContentType != "image/jpeg" || string(capturedInputs[0].Data) != "first page" {
+		t.Errorf("unexpected first input: %+v", capturedInputs[0])
+	}
+	if capturedInputs[1].ContentType != "image/png" || string(capturedInputs[1].Data) != "second page" {
+		t.Errorf("unexpected second input: %+v", capturedInputs[1])
+	}
+}
+
+func TestPostExtractImageHandler_RejectsTooManyImages(t *testing.T) {
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+			t.Fatal("expected no job to be created")
+			return 0, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	uploads := make([]testUpload, maxImagesPerJob+1)
+	for i := range uploads {
+		uploads[i] = testUpload{filename: fmt.Sprintf("page%d.jpg", i), contentType: "image/jpeg", data: []byte("page")}
+	}
+	req := newUploadRequest(t, "/extract/image", "image", uploads)
+	rec := httptest.NewRecorder()
+
+	h.PostExtractImageHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/extract?error=") {
+		t.Errorf("expected redirect to extract page with error, got %q", location)
+	}
+}
+
+func TestPostExtractPDFHandler_CreatesPDFJob(t *testing.T) {
+	var capturedType string
+	var capturedInputs []store.ExtractionJobInput
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, jobType string, _ *string, inputs []store.ExtractionJobInput) (int, error) {
+			capturedType = jobType
+			capturedInputs = inputs
+			return 7, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	req := newUploadRequest(t, "/extract/pdf", "pdf", []testUpload{
+		{filename: "booklet.pdf", contentType: "application/pdf", data: []byte("%PDF-1.7 booklet")},
+	})
+	rec := httptest.NewRecorder()
+
+	h.PostExtractPDFHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); location != "/account/jobs/7" {
+		t.Errorf("redirect location = %q, want %q", location, "/account/jobs/7")
+	}
+	if capturedType != "pdf" {
+		t.Errorf("job type = %q, want %q", capturedType, "pdf")
+	}
+	if len(capturedInputs) != 1 || capturedInputs[0].ContentType != "application/pdf" {
+		t.Errorf("unexpected inputs: %+v", capturedInputs)
+	}
+}
+
+func TestPostExtractPDFHandler_RejectsNonPDFFile(t *testing.T) {
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+			t.Fatal("expected no job to be created")
+			return 0, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	req := newUploadRequest(t, "/extract/pdf", "pdf", []testUpload{
+		{filename: "recipe.pdf", contentType: "application/pdf", data: []byte("not really a pdf")},
+	})
+	rec := httptest.NewRecorder()
+
+	h.PostExtractPDFHandler(rec, req)
+
+	want := "/extract?error=Please upload a PDF file"
+	if location := rec.Header().Get("Location"); location != want {
+		t.Errorf("redirect location = %q, want %q", location, want)
+	}
+}
+
+func TestPostExtractTextHandler_CreatesTextJob(t *testing.T) {
+	var capturedType string
+	var capturedInputs []store.ExtractionJobInput
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, jobType string, _ *string, inputs []store.ExtractionJobInput) (int, error) {
+			capturedType = jobType
+			capturedInputs = inputs
+			return 9, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	form := url.Values{"text": {"  Grandma's pancakes\n2 eggs, 200g flour  "}}
+	req := httptest.NewRequest(http.MethodPost, "/extract/text", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.PostExtractTextHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); location != "/account/jobs/9" {
+		t.Errorf("redirect location = %q, want %q", location, "/account/jobs/9")
+	}
+	if capturedType != "text" {
+		t.Errorf("job type = %q, want %q", capturedType, "text")
+	}
+	if len(capturedInputs) != 1 || string(capturedInputs[0].Data) != "Grandma's pancakes\n2 eggs, 200g flour" {
+		t.Errorf("unexpected inputs: %+v", capturedInputs)
+	}
+}
+
+func TestPostExtractTextHandler_RejectsEmptyText(t *testing.T) {
+	jobStore := &mockExtractionJobStore{
+		createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+			t.Fatal("expected no job to be created")
+			return 0, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore}
+
+	form := url.Values{"text": {"   \n  "}}
+	req := httptest.NewRequest(http.MethodPost, "/extract/text", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.PostExtractTextHandler(rec, req)
+
+	want := "/extract?error=Please paste the recipe text"
+	if location := rec.Header().Get("Location"); location != want {
+		t.Errorf("redirect location = %q, want %q", location, want)
+	}
+}
+
+func TestPostExtractWebsiteHandler_RejectsWhenQuotaExceeded(t *testing.T) {
+	jobLimit := 3
+	costLimit := 1.0
+	tests := []struct {
+		name      string
+		usage     store.UserExtractionUsage
+		wantError string
+	}{
+		{
+			name:      "job limit reached",
+			usage:     store.UserExtractionUsage{JobCount: 3, Quota: store.ExtractionQuota{MonthlyJobLimit: &jobLimit}},
+			wantError: "You have reached your monthly limit of 3 extraction jobs.",
+		},
+		{
+			name:      "cost limit reached",
+			usage:     store.UserExtractionUsage{JobCount: 1, Usage: store.ExtractionUsage{CostUSD: 1.25}, Quota: store.ExtractionQuota{MonthlyCostLimitUSD: &costLimit}},
+			wantError: "You have reached your monthly extraction budget.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobStore := &mockExtractionJobStore{
+				usageFunc: func(_ context.Context, _ int, since time.Time) (*store.UserExtractionUsage, error) {
+					if since.Day() != 1 {
+						t.Errorf("expected usage since the start of the month, got %v", since)
+					}
+					return &tt.usage, nil
+				},
+				createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+					t.Fatal("expected no job to be created")
+					return 0, nil
+				},
+			}
+			h := &Handler{ExtractionJobStore: jobStore}
+
+			form := url.Values{"url": {"https://example.com/recipe"}}
+			req := httptest.NewRequest(http.MethodPost, "/extract/website", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+			rec := httptest.NewRecorder()
+
+			h.PostExtractWebsiteHandler(rec, req)
+
+			location, err := url.Parse(rec.Header().Get("Location"))
+			if err != nil || location.Path != "/extract" {
+				t.Fatalf("expected redirect to /extract, got %q", rec.Header().Get("Location"))
+			}
+			if got := location.Query().Get("error"); !strings.HasPrefix(got, tt.wantError) {
+				t.Errorf("error = %q, want prefix %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPostExtractWebsiteHandler_AllowsJobsBelowQuota(t *testing.T) {
+	jobLimit := 3
+	created := false
+	jobStore := &mockExtractionJobStore{
+		usageFunc: func(_ context.Context, _ int, _ time.Time) (*store.UserExtractionUsage, error) {
+			return &store.UserExtractionUsage{JobCount: 2, Quota: store.ExtractionQuota{MonthlyJobLimit: &jobLimit}}, nil
+		},
+		createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+			created = true
+			return 4, nil
+		},
+	}
+	h := &Handler{ExtractionJobStore: jobStore, ExtractionCacheStore: &mockExtractionCacheStore{}}
+
+	form := url.Values{"url": {"https://example.com/recipe"}}
+	req := httptest.NewRequest(http.MethodPost, "/extract/website", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.PostExtractWebsiteHandler(rec, req)
+
+	if !created {
+		t.Error("expected job to be created")
+	}
+	if location := rec.Header().Get("Location"); location != "/account/jobs/4" {
+		t.Errorf("redirect location = %q, want %q", location, "/account/jobs/4")
+	}
+}
+
+func TestPostAdminExtractionQuotaHandler_SavesQuota(t *testing.T) {
+	tests := []struct {
+		name         string
+		form         url.Values
+		wantSaved    bool
+		wantJobLimit *int
+		wantCost     *float64
+	}{
+		{
+			name:         "sets both limits",
+			form:         url.Values{"monthly_job_limit": {"20"}, "monthly_cost_limit_usd": {"2.50"}},
+			wantSaved:    true,
+			wantJobLimit: intPtr(20),
+			wantCost:     floatPtr(2.5),
+		},
+		{
+			name:      "empty fields clear the quota",
+			form:      url.Values{"monthly_job_limit": {""}, "monthly_cost_limit_usd": {""}},
+			wantSaved: true,
+		},
+		{
+			name: "rejects negative job limit",
+			form: url.Values{"monthly_job_limit": {"-1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *store.ExtractionQuota
+			jobStore := &mockExtractionJobStore{
+				setQuotaFunc: func(_ context.Context, userID int, quota store.ExtractionQuota) error {
+					if userID != 42 {
+						t.Errorf("userID = %d, want 42", userID)
+					}
+					saved = &quota
+					return nil
+				},
+			}
+			h := &Handler{ExtractionJobStore: jobStore}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/users/42/extraction-quota", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", "42")
+			rec := httptest.NewRecorder()
+
+			h.PostAdminExtractionQuotaHandler(rec, req)
+
+			if (saved != nil) != tt.wantSaved {
+				t.Fatalf("saved = %v, want %v", saved != nil, tt.wantSaved)
+			}
+			if !tt.wantSaved {
+				return
+			}
+			if fmt.Sprint(deref(saved.MonthlyJobLimit)) != fmt.Sprint(deref(tt.wantJobLimit)) {
+				t.Errorf("job limit = %v, want %v", deref(saved.MonthlyJobLimit), deref(tt.wantJobLimit))
+			}
+			if fmt.Sprint(deref(saved.MonthlyCostLimitUSD)) != fmt.Sprint(deref(tt.wantCost)) {
+				t.Errorf("cost limit = %v, want %v", deref(saved.MonthlyCostLimitUSD), deref(tt.wantCost))
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func TestPostExtractWebsiteHandler_WarnsAboutAlreadyExtractedURL(t *testing.T) {
+	recipeID := 123
+	cacheStore := &mockExtractionCacheStore{entries: map[string]*store.ExtractionCacheEntry{
+		"url:example.com/recipes/pancakes": {Key: "url:example.com/recipes/pancakes", RecipeID: &recipeID},
+	}}
+	recipeStore := &mocks.MockRecipeStore{
+		GetByIDFunc: func(_ context.Context, id string) (models.Recipe, error) {
+			return models.Recipe{ID: 123, Title: "Pancakes"}, nil
+		},
+	}
+
+	tests := []struct {
+		name          string
+		form          url.Values
+		wantDuplicate bool
+		wantForced    bool
+	}{
+		{
+			name:          "same URL with tracking parameters",
+			form:          url.Values{"url": {"https://www.example.com/recipes/pancakes/?utm_source=newsletter"}},
+			wantDuplicate: true,
+		},
+		{
+			name:       "forced extraction skips the check",
+			form:       url.Values{"url": {"https://example.com/recipes/pancakes"}, "force": {"1"}},
+			wantForced: true,
+		},
+		{
+			name: "different URL",
+			form: url.Values{"url": {"https://example.com/recipes/waffles"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rendered string
+			var renderedData any
+			created, forced := false, false
+			h := &Handler{
+				ExtractionJobStore: &mockExtractionJobStore{
+					createFunc: func(_ context.Context, _ int, _ string, _ *string, _ []store.ExtractionJobInput) (int, error) {
+						created = true
+						return 5, nil
+					},
+					createForced: func(_ context.Context, _ int, _, _ string) (int, error) {
+						created, forced = true, true
+						return 5, nil
+					},
+				},
+				ExtractionCacheStore: cacheStore,
+				RecipeStore:          recipeStore,
+				Renderer: &tmocks.MockRenderer{
+					RenderPageFunc: func(_ http.ResponseWriter, name string, data any) {
+						rendered = name
+						renderedData = data
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/extract/website", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+			rec := httptest.NewRecorder()
+
+			h.PostExtractWebsiteHandler(rec, req)
+
+			if tt.wantDuplicate {
+				if created {
+					t.Error("expected no job to be created")
+				}
+				if rendered != "extract-duplicate.gohtml" {
+					t.Fatalf("rendered %q, want extract-duplicate.gohtml", rendered)
+				}
+				if data := renderedData.(ExtractDuplicateData); data.Recipe.ID != 123 || data.Action != "/extract/website" {
+					t.Errorf("unexpected duplicate data: %+v", data)
+				}
+				return
+			}
+
+			if !created {
+				t.Error("expected job to be created")
+			}
+			if forced != tt.wantForced {
+				t.Errorf("forced = %v, want %v", forced, tt.wantForced)
+			}
+			if rendered != "" {
+				t.Errorf("expected no page to be rendered, got %q", rendered)
+			}
+		})
+	}
+}
+
+func TestPostExtractBatchHandler_CreatesJobsForNewRecipeLinks(t *testing.T) {
+	recipeID := 9
+	cacheStore := &mockExtractionCacheStore{entries: map[string]*store.ExtractionCacheEntry{
+		"url:example.com/recipes/known": {Key: "url:example.com/recipes/known", RecipeID: &recipeID},
+	}}
+
+	var capturedName string
+	var capturedSkipped int
+	var capturedJobs []store.ExtractionBatchJob
+	queuedUntil := time.Now().Add(time.Hour)
+	h := &Handler{
+		ExtractionJobStore: &mockExtractionJobStore{
+			submittedURLs: []string{"https://example.com/recipes/mine?utm_source=x"},
+			scheduledJobs: []store.ExtractionBatchJob{{JobType: "website", InputURL: "https://example.com/recipes/queued", NotBefore: queuedUntil}},
+			createBatch: func(_ context.Context, _ int, name string, skippedCount int, jobs []store.ExtractionBatchJob) (int, error) {
+				capturedName = name
+				capturedSkipped = skippedCount
+				capturedJobs = jobs
+				return 3, nil
+			},
+		},
+		ExtractionCacheStore: cacheStore,
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDFunc: func(_ context.Context, id string) (models.Recipe, error) {
+				return models.Recipe{ID: 9, Title: "Known", AuthorID: 2}, nil
+			},
+		},
+	}
+
+	bookmarks := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><A HREF="https://example.com/recipes/new">New</A>
+    <DT><A HREF="https://www.example.com/recipes/new/?utm_source=x">New again</A>
+    <DT><A HREF="https://example.com/recipes/mine">Mine</A>
+    <DT><A HREF="https://example.com/recipes/known">Extracted by someone else</A>
+    <DT><A HREF="https://www.youtube.com/watch?v=dQw4w9WgXcQ">Video</A>
+    <DT><A HREF="https://example.com/news">News</A>
+</DL><p>`
+	req := newUploadRequest(t, "/extract/batch", "file", []testUpload{
+		{filename: "bookmarks.html", contentType: "text/html", data: []byte(bookmarks)},
+	})
+	rec := httptest.NewRecorder()
+
+	h.PostExtractBatchHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); location != "/account/batches/3" {
+		t.Fatalf("redirect location = %q, want %q", location, "/account/batches/3")
+	}
+	if capturedName != "bookmarks.html" {
+		t.Errorf("batch name = %q, want %q", capturedName, "bookmarks.html")
+	}
+	if capturedSkipped != 2 {
+		t.Errorf("skipped = %d, want 2", capturedSkipped)
+	}
+	if len(capturedJobs) != 3 {
+		t.Fatalf("expected 3 jobs, got %d: %+v", len(capturedJobs), capturedJobs)
+	}
+	if capturedJobs[0].JobType != "website" || capturedJobs[0].InputURL != "https://example.com/recipes/new" {
+		t.Errorf("unexpected first job: %+v", capturedJobs[0])
+	}
+	if !capturedJobs[0].NotBefore.After(queuedUntil) {
+		t.Errorf("first job starts at %v, want after the job already queued for the domain", capturedJobs[0].NotBefore)
+	}
+	if capturedJobs[1].JobType != "website" || capturedJobs[1].InputURL != "https://example.com/recipes/known" {
+		t.Errorf("expected a job for the link another user extracted, got %+v", capturedJobs[1])
+	}
+	if capturedJobs[2].JobType != "video" {
+		t.Errorf("unexpected third job: %+v", capturedJobs[2])
+	}
+}
+
+func TestPostExtractBatchHandler_RejectsBatchExceedingQuota(t *testing.T) {
+	jobLimit := 5
+	h := &Handler{
+		ExtractionJobStore: &mockExtractionJobStore{
+			usageFunc: func(_ context.Context, _ int, _ time.Time) (*store.UserExtractionUsage, error) {
+				return &store.UserExtractionUsage{JobCount: 4, Quota: store.ExtractionQuota{MonthlyJobLimit: &jobLimit}}, nil
+			},
+			createBatch: func(_ context.Context, _ int, _ string, _ int, _ []store.ExtractionBatchJob) (int, error) {
+				t.Fatal("expected no batch to be created")
+				return 0, nil
+			},
+		},
+		ExtractionCacheStore: &mockExtractionCacheStore{},
+	}
+
+	list := "https://example.com/recipes/a\nhttps://example.com/recipes/b\n"
+	req := newUploadRequest(t, "/extract/batch", "file", []testUpload{
+		{filename: "links.txt", contentType: "text/plain", data: []byte(list)},
+	})
+	rec := httptest.NewRecorder()
+
+	h.PostExtractBatchHandler(rec, req)
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil || location.Path != "/extract" {
+		t.Fatalf("expected redirect to /extract, got %q", rec.Header().Get("Location"))
+	}
+	if got := location.Query().Get("error"); !strings.Contains(got, "(1 left)") {
+		t.Errorf("error = %q, want remaining job count", got)
+	}
+}
+
+func TestGetBatchHandler_RejectsOtherUsers(t *testing.T) {
+	var statusCode int
+	h := &Handler{
+		ExtractionJobStore: &mockExtractionJobStore{
+			batch: &store.ExtractionBatch{ID: 3, UserID: 2},
+		},
+		Renderer: &tmocks.MockRenderer{
+			RenderErrorFunc: func(_ http.ResponseWriter, _ *http.Request, code int, _ string) {
+				statusCode = code
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/account/batches/3", nil)
+	req.SetPathValue("id", "3")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.GetBatchHandler(rec, req)
+
+	if statusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", statusCode, http.StatusForbidden)
+	}
+}
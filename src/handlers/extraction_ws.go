@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,32 +14,47 @@ import (
 	"github.com/mr-flannery/go-recipe-book/src/store"
 )
 
+// jobStreamResyncInterval is how often open streams re-check their jobs, in
+// case an event was missed while the listener was reconnecting. It also keeps
+// idle connections from being closed by proxies.
+const jobStreamResyncInterval = 30 * time.Second
+
 type jobStatusFragment struct {
 	Job      *store.ExtractionJob
 	Feedback *store.ExtractionFeedback
 }
 
 // GetJobStatusSSEHandler streams job status updates to the client via
-// Server-Sent Events. It sends an event whenever the status changes and
-// closes the stream once the job reaches a terminal state.
+// Server-Sent Events. With a job ID it sends the job's status fragment
+// whenever the status changes and closes the stream once the job reaches a
+// terminal state. Without one it streams a "job" event with the updated jobs
+// list row for every job of the current user until the client disconnects.
 func (h *Handler) GetJobStatusSSEHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userInfo := auth.GetUserInfoFromContext(ctx)
 
-	jobIDStr := r.PathValue("id")
-	jobID, err := strconv.Atoi(jobIDStr)
-	if err != nil {
-		http.Error(w, "Invalid job ID", http.StatusBadRequest)
-		return
-	}
+	var job *store.ExtractionJob
+	if jobIDStr := r.PathValue("id"); jobIDStr != "" {
+		jobID, err := strconv.Atoi(jobIDStr)
+		if err != nil {
+			http.Error(w, "Invalid job ID", http.StatusBadRequest)
+			return
+		}
 
-	job, err := h.ExtractionJobStore.GetByID(ctx, jobID)
-	if err != nil || job == nil {
-		http.Error(w, "Job not found", http.StatusNotFound)
-		return
+		job, err = h.ExtractionJobStore.GetByID(ctx, jobID)
+		if err != nil || job == nil {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		if job.UserID != userInfo.UserID && !userInfo.IsAdmin {
+			http.Error(w, "Access denied", http.StatusForbidden)
+			return
+		}
 	}
-	if job.UserID != userInfo.UserID && !userInfo.IsAdmin {
-		http.Error(w, "Access denied", http.StatusForbidden)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
@@ -47,19 +63,42 @@ func (h *Handler) GetJobStatusSSEHandler(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("X-Accel-Buffering", "no") // disable nginx buffering
 	w.WriteHeader(http.StatusOK)
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
-		return
+	stream := &jobEventStream{w: w, flusher: flusher}
+	if job == nil {
+		h.streamUserJobs(ctx, stream, userInfo.UserID)
+	} else {
+		h.streamJobStatus(ctx, stream, job)
 	}
+}
 
+// subscribeJobEvents subscribes to the events of the user's jobs. Without a
+// broker the returned channel never receives and streams fall back to
+// re-checking on the resync interval.
+func (h *Handler) subscribeJobEvents(userID int) (<-chan store.ExtractionJobEvent, func()) {
+	if h.JobEvents == nil {
+		return nil, func() {}
+	}
+	return h.JobEvents.Subscribe(userID)
+}
+
+func (h *Handler) streamJobStatus(ctx context.Context, stream *jobEventStream, job *store.ExtractionJob) {
+	jobID := job.ID
 	logging.AddMany(ctx, map[string]any{
 		"action": "job.sse.connect",
 		"job_id": jobID,
 	})
 
+	events, unsubscribe := h.subscribeJobEvents(job.UserID)
+	defer unsubscribe()
+
+	// Read the job again now that we are subscribed, so a change made since
+	// the access check isn't missed.
+	if latest, err := h.ExtractionJobStore.GetByID(ctx, jobID); err == nil && latest != nil {
+		job = latest
+	}
+
 	isTerminal := func(s string) bool {
-		return s == "completed" || s == "failed"
+		return s == "completed" || s == "failed" || s == "cancelled"
 	}
 
 	sendFragment := func(current *jobStatusFragment) error {
@@ -67,15 +106,7 @@ func (h *Handler) GetJobStatusSSEHandler(w http.ResponseWriter, r *http.Request)
 		if err := h.Renderer.Render(&buf, "job-status-fragment", current); err != nil {
 			return err
 		}
-		// SSE format: each line of data prefixed with "data: ", terminated by two newlines.
-		for _, line := range bytes.Split(buf.Bytes(), []byte("\n")) {
-			if _, err := fmt.Fprintf(w, "data: %s\n", line); err != nil {
-				return err
-			}
-		}
-		_, err := fmt.Fprint(w, "\n")
-		flusher.Flush()
-		return err
+		return stream.send("", buf.Bytes())
 	}
 
 	lastStatus := job.Status
@@ -89,42 +120,133 @@ func (h *Handler) GetJobStatusSSEHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+	resync := time.NewTicker(jobStreamResyncInterval)
+	defer resync.Stop()
 
 	for {
 		select {
-		case <-r.Context().Done():
+		case <-ctx.Done():
 			logging.AddMany(ctx, map[string]any{
 				"action": "job.sse.disconnect",
 				"job_id": jobID,
 			})
 			return
 
-		case <-ticker.C:
-			latest, err := h.ExtractionJobStore.GetByID(ctx, jobID)
-			if err != nil || latest == nil {
-				slog.Warn("SSE: failed to fetch job", "job_id", jobID, "error", err)
+		case event := <-events:
+			if event.JobID != jobID || event.Status == lastStatus {
 				continue
 			}
 
-			if latest.Status != lastStatus {
-				lastStatus = latest.Status
-				feedback, _ := h.ExtractionFeedbackStore.GetByJobID(ctx, jobID)
-				if err := sendFragment(&jobStatusFragment{Job: latest, Feedback: feedback}); err != nil {
-					slog.Debug("SSE: write failed", "job_id", jobID, "error", err)
-					return
-				}
-				logging.AddMany(ctx, map[string]any{
-					"action":     "job.sse.update",
-					"job_id":     jobID,
-					"new_status": latest.Status,
-				})
+		case <-resync.C:
+		}
+
+		latest, err := h.ExtractionJobStore.GetByID(ctx, jobID)
+		if err != nil || latest == nil {
+			slog.Warn("SSE: failed to fetch job", "job_id", jobID, "error", err)
+			continue
+		}
+
+		if latest.Status != lastStatus {
+			lastStatus = latest.Status
+			feedback, _ := h.ExtractionFeedbackStore.GetByJobID(ctx, jobID)
+			if err := sendFragment(&jobStatusFragment{Job: latest, Feedback: feedback}); err != nil {
+				slog.Debug("SSE: write failed", "job_id", jobID, "error", err)
+				return
 			}
+			logging.AddMany(ctx, map[string]any{
+				"action":     "job.sse.update",
+				"job_id":     jobID,
+				"new_status": latest.Status,
+			})
+		} else if err := stream.keepAlive(); err != nil {
+			return
+		}
 
-			if isTerminal(lastStatus) {
+		if isTerminal(lastStatus) {
+			return
+		}
+	}
+}
+
+func (h *Handler) streamUserJobs(ctx context.Context, stream *jobEventStream, userID int) {
+	logging.AddMany(ctx, map[string]any{
+		"action": "job.sse.connect",
+		"scope":  "user",
+	})
+
+	events, unsubscribe := h.subscribeJobEvents(userID)
+	defer unsubscribe()
+
+	// Let the client know the stream is open before the first event.
+	if err := stream.keepAlive(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(jobStreamResyncInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.AddMany(ctx, map[string]any{
+				"action": "job.sse.disconnect",
+				"scope":  "user",
+			})
+			return
+
+		case <-keepAlive.C:
+			if err := stream.keepAlive(); err != nil {
+				return
+			}
+
+		case event := <-events:
+			job, err := h.ExtractionJobStore.GetByID(ctx, event.JobID)
+			if err != nil || job == nil {
+				slog.Warn("SSE: failed to fetch job", "job_id", event.JobID, "error", err)
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := h.Renderer.Render(&buf, "job-row", job); err != nil {
+				slog.Warn("SSE: failed to render job row", "job_id", job.ID, "error", err)
+				continue
+			}
+			if err := stream.send("job", buf.Bytes()); err != nil {
+				slog.Debug("SSE: write failed", "job_id", job.ID, "error", err)
 				return
 			}
 		}
 	}
 }
+
+// jobEventStream writes Server-Sent Events to a response.
+type jobEventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// send writes an event of the given name; an empty name sends a plain
+// message event.
+func (s *jobEventStream) send(event string, data []byte) error {
+	if event != "" {
+		if _, err := fmt.Fprintf(s.w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+	// SSE format: each line of data prefixed with "data: ", terminated by two newlines.
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if _, err := fmt.Fprintf(s.w, "data: %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(s.w, "\n")
+	s.flusher.Flush()
+	return err
+}
+
+// keepAlive writes a comment line, which clients ignore.
+func (s *jobEventStream) keepAlive() error {
+	_, err := fmt.Fprint(s.w, ": keep-alive\n\n")
+	s.flusher.Flush()
+	return err
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+type mockExtractionFeedbackStore struct {
+	store.ExtractionFeedbackStore
+}
+
+func (m *mockExtractionFeedbackStore) GetByJobID(ctx context.Context, jobID int) (*store.ExtractionFeedback, error) {
+	return nil, nil
+}
+
+// newJobEventsServer serves the job event streams for user 1, with jobs whose
+// status can be changed through the returned function.
+func newJobEventsServer(t *testing.T, broker *extraction.JobEventBroker) (*httptest.Server, func(id int, status string)) {
+	t.Helper()
+
+	var mu sync.Mutex
+	statuses := map[int]string{1: "pending", 2: "pending"}
+	setStatus := func(id int, status string) {
+		mu.Lock()
+		statuses[id] = status
+		mu.Unlock()
+	}
+
+	h := &Handler{
+		ExtractionJobStore: &mockExtractionJobStore{
+			getByIDFunc: func(_ context.Context, id int) (*store.ExtractionJob, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return &store.ExtractionJob{ID: id, UserID: 1, Status: statuses[id]}, nil
+			},
+		},
+		ExtractionFeedbackStore: &mockExtractionFeedbackStore{},
+		JobEvents:               broker,
+		Renderer: &tmocks.MockRenderer{
+			RenderFunc: func(w io.Writer, name string, data any) error {
+				job, ok := data.(*store.ExtractionJob)
+				if !ok {
+					job = data.(*jobStatusFragment).Job
+				}
+				_, err := fmt.Fprintf(w, "%s job %d %s", name, job.ID, job.Status)
+				return err
+			},
+		},
+	}
+
+	withUser := func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(auth.ContextWithUserInfo(r.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+		h.GetJobStatusSSEHandler(w, r)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /account/jobs/ws", withUser)
+	mux.HandleFunc("GET /account/jobs/{id}/ws", withUser)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, setStatus
+}
+
+// readEvents opens the stream and returns its data lines. The handler only
+// flushes once it is subscribed, so events published after this returns are
+// delivered.
+func readEvents(t *testing.T, url string) <-chan string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer resp.Body.Close()
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				lines <- data
+			}
+		}
+	}()
+	return lines
+}
+
+func nextEvent(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line, ok := <-lines:
+		if !ok {
+			t.Fatal("stream closed before the next event")
+		}
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return ""
+	}
+}
+
+func TestGetJobStatusSSEHandler_PushesStatusChangesOfTheJob(t *testing.T) {
+	broker := extraction.NewJobEventBroker()
+	server, setStatus := newJobEventsServer(t, broker)
+
+	lines := readEvents(t, server.URL+"/account/jobs/1/ws")
+	if got := nextEvent(t, lines); got != "job-status-fragment job 1 pending" {
+		t.Fatalf("initial event = %q", got)
+	}
+
+	setStatus(2, "processing")
+	broker.Publish(store.ExtractionJobEvent{JobID: 2, UserID: 1, Status: "processing"})
+	setStatus(1, "completed")
+	broker.Publish(store.ExtractionJobEvent{JobID: 1, UserID: 1, Status: "completed"})
+
+	if got := nextEvent(t, lines); got != "job-status-fragment job 1 completed" {
+		t.Errorf("update event = %q, want the completed job 1", got)
+	}
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("expected the stream to close after the job finished, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected the stream to close after the job finished")
+	}
+}
+
+func TestGetJobStatusSSEHandler_StreamsRowsForAllJobsOfTheUser(t *testing.T) {
+	broker := extraction.NewJobEventBroker()
+	server, setStatus := newJobEventsServer(t, broker)
+
+	lines := readEvents(t, server.URL+"/account/jobs/ws")
+
+	setStatus(2, "failed")
+	broker.Publish(store.ExtractionJobEvent{JobID: 2, UserID: 1, Status: "failed"})
+	broker.Publish(store.ExtractionJobEvent{JobID: 3, UserID: 2, Status: "pending"})
+	setStatus(1, "processing")
+	broker.Publish(store.ExtractionJobEvent{JobID: 1, UserID: 1, Status: "processing"})
+
+	if got := nextEvent(t, lines); got != "job-row job 2 failed" {
+		t.Errorf("first event = %q, want the row of job 2", got)
+	}
+	if got := nextEvent(t, lines); got != "job-row job 1 processing" {
+		t.Errorf("second event = %q, want the row of job 1", got)
+	}
+}
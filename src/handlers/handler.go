@@ -3,7 +3,10 @@ package handlers
 import (
 	"database/sql"
 
+	"github.com/mr-flannery/go-recipe-book/src/digest"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
 	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/templates"
 )
@@ -21,13 +24,20 @@ type Handler struct {
 	APIKeyStore             store.APIKeyStore
 	ExtractionJobStore      store.ExtractionJobStore
 	ExtractionFeedbackStore store.ExtractionFeedbackStore
+	ExtractionCacheStore    store.ExtractionCacheStore
+	JobStore                store.JobStore
+	EmailOutboxStore        store.EmailOutboxStore
+	JobEvents               *extraction.JobEventBroker
+	NotificationStore       store.NotificationStore
+	Notifier                *notifications.Notifier
 	Renderer                templates.Renderer
 	MailClient              mail.MailClient
+	UnsubscribeSigner       *digest.Signer
 	APIEncryptionKey        []byte
 	BaseURL                 string
 }
 
-func NewHandler(db *sql.DB, recipeStore store.RecipeStore, tagStore store.TagStore, userTagStore store.UserTagStore, commentStore store.CommentStore, userStore store.UserStore, authStore store.AuthStore, ingredientStore store.IngredientStore, userPreferencesStore store.UserPreferencesStore, apiKeyStore store.APIKeyStore, extractionJobStore store.ExtractionJobStore, extractionFeedbackStore store.ExtractionFeedbackStore, renderer templates.Renderer, mailClient mail.MailClient, apiEncryptionKey []byte, baseURL string) *Handler {
+func NewHandler(db *sql.DB, recipeStore store.RecipeStore, tagStore store.TagStore, userTagStore store.UserTagStore, commentStore store.CommentStore, userStore store.UserStore, authStore store.AuthStore, ingredientStore store.IngredientStore, userPreferencesStore store.UserPreferencesStore, apiKeyStore store.APIKeyStore, extractionJobStore store.ExtractionJobStore, extractionFeedbackStore store.ExtractionFeedbackStore, extractionCacheStore store.ExtractionCacheStore, jobStore store.JobStore, emailOutboxStore store.EmailOutboxStore, jobEvents *extraction.JobEventBroker, notificationStore store.NotificationStore, notifier *notifications.Notifier, renderer templates.Renderer, mailClient mail.MailClient, unsubscribeSigner *digest.Signer, apiEncryptionKey []byte, baseURL string) *Handler {
 	return &Handler{
 		DB:                      db,
 		RecipeStore:             recipeStore,
@@ -41,8 +51,15 @@ func NewHandler(db *sql.DB, recipeStore store.RecipeStore, tagStore store.TagSto
 		APIKeyStore:             apiKeyStore,
 		ExtractionJobStore:      extractionJobStore,
 		ExtractionFeedbackStore: extractionFeedbackStore,
+		ExtractionCacheStore:    extractionCacheStore,
+		JobStore:                jobStore,
+		EmailOutboxStore:        emailOutboxStore,
+		JobEvents:               jobEvents,
+		NotificationStore:       notificationStore,
+		Notifier:                notifier,
 		Renderer:                renderer,
 		MailClient:              mailClient,
+		UnsubscribeSigner:       unsubscribeSigner,
 		APIEncryptionKey:        apiEncryptionKey,
 		BaseURL:                 baseURL,
 	}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// jobStatuses are the statuses background jobs can be filtered by.
+var jobStatuses = []string{"pending", "processing", "completed", "failed"}
+
+// JobTypeSummary counts the background jobs of one type by status.
+type JobTypeSummary struct {
+	Type   string
+	Counts map[string]int
+}
+
+type AdminQueueData struct {
+	UserInfo   *auth.UserInfo
+	Summaries  []JobTypeSummary
+	Statuses   []string
+	Status     string
+	Jobs       []store.Job
+	TotalCount int
+	Page       int
+	TotalPages int
+	Success    string
+	Error      string
+}
+
+// GetAdminQueueHandler shows the generic job queue: how many jobs of each
+// type are in each status, and the most recently updated jobs.
+func (h *Handler) GetAdminQueueHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	status := r.URL.Query().Get("status")
+	if !slices.Contains(jobStatuses, status) {
+		status = ""
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 50
+	offset := (page - 1) * pageSize
+
+	jobs, err := h.JobStore.List(ctx, status, pageSize, offset)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch jobs")
+		jobs = []store.Job{}
+	}
+
+	totalCount, err := h.JobStore.Count(ctx, status)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to count jobs")
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	counts, err := h.JobStore.CountByTypeAndStatus(ctx)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to count jobs by type")
+	}
+	var summaries []JobTypeSummary
+	for _, count := range counts {
+		if len(summaries) == 0 || summaries[len(summaries)-1].Type != count.Type {
+			summaries = append(summaries, JobTypeSummary{Type: count.Type, Counts: make(map[string]int)})
+		}
+		summaries[len(summaries)-1].Counts[count.Status] = count.Count
+	}
+
+	data := AdminQueueData{
+		UserInfo:   userInfo,
+		Summaries:  summaries,
+		Statuses:   jobStatuses,
+		Status:     status,
+		Jobs:       jobs,
+		TotalCount: totalCount,
+		Page:       page,
+		TotalPages: totalPages,
+		Success:    r.URL.Query().Get("success"),
+		Error:      r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "admin-queue.gohtml", data)
+}
+
+// PostAdminQueueRetryHandler runs a failed or waiting job again right away,
+// with a fresh set of attempts.
+func (h *Handler) PostAdminQueueRetryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Redirect(w, r, "/admin/queue?error=Invalid job ID", http.StatusSeeOther)
+		return
+	}
+
+	retried, err := h.JobStore.RetryNow(ctx, id)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to retry job")
+		http.Redirect(w, r, "/admin/queue?error=Failed to retry job", http.StatusSeeOther)
+		return
+	}
+	if !retried {
+		http.Redirect(w, r, "/admin/queue?error=Only failed or waiting jobs can be retried", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action": "jobs.retry",
+		"job_id": id,
+	})
+
+	http.Redirect(w, r, "/admin/queue?success=Job "+strconv.Itoa(id)+" queued again", http.StatusSeeOther)
+}
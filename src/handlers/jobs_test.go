@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestGetAdminQueueHandler_SummarizesJobsByType(t *testing.T) {
+	var listedStatus string
+	var data AdminQueueData
+	h := &Handler{
+		JobStore: &mocks.MockJobStore{
+			ListFunc: func(_ context.Context, status string, limit, offset int) ([]store.Job, error) {
+				listedStatus = status
+				return []store.Job{{ID: 3, Type: "mail", Status: "failed"}}, nil
+			},
+			CountFunc: func(context.Context, string) (int, error) { return 1, nil },
+			CountByTypeAndStatusFunc: func(context.Context) ([]store.JobCount, error) {
+				return []store.JobCount{
+					{Type: "digest", Status: "completed", Count: 4},
+					{Type: "mail", Status: "failed", Count: 1},
+					{Type: "mail", Status: "pending", Count: 2},
+				}, nil
+			},
+		},
+		Renderer: &tmocks.MockRenderer{
+			RenderPageFunc: func(_ http.ResponseWriter, _ string, d any) { data = d.(AdminQueueData) },
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/queue?status=failed", nil)
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, IsAdmin: true}))
+	h.GetAdminQueueHandler(httptest.NewRecorder(), req)
+
+	if listedStatus != "failed" {
+		t.Errorf("listed status %q, want failed", listedStatus)
+	}
+	if len(data.Summaries) != 2 {
+		t.Fatalf("expected 2 job types, got %+v", data.Summaries)
+	}
+	if mail := data.Summaries[1]; mail.Type != "mail" || mail.Counts["failed"] != 1 || mail.Counts["pending"] != 2 {
+		t.Errorf("unexpected mail summary %+v", mail)
+	}
+	if len(data.Jobs) != 1 || data.Status != "failed" {
+		t.Errorf("unexpected jobs %+v in status %q", data.Jobs, data.Status)
+	}
+}
+
+func TestPostAdminQueueRetryHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		retried      bool
+		wantLocation string
+	}{
+		{"queues the job again", true, "/admin/queue?success="},
+		{"reports jobs that can't be retried", false, "/admin/queue?error="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID int
+			h := &Handler{
+				JobStore: &mocks.MockJobStore{
+					RetryNowFunc: func(_ context.Context, id int) (bool, error) {
+						gotID = id
+						return tt.retried, nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/queue/12/retry", nil)
+			req.SetPathValue("id", "12")
+			rec := httptest.NewRecorder()
+			h.PostAdminQueueRetryHandler(rec, req)
+
+			if gotID != 12 {
+				t.Errorf("retried job %d, want 12", gotID)
+			}
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("location = %q, want prefix %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
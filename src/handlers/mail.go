@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// mailRecipient returns the recipient of an email to the user, themed and
+// localised by their preferences. Emails still go out with the defaults if
+// the preferences can't be read.
+func (h *Handler) mailRecipient(ctx context.Context, userID int, email, name string) mail.Recipient {
+	prefs, err := h.UserPreferencesStore.Get(ctx, userID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to get user preferences for email")
+	}
+	return mail.RecipientFor(email, name, prefs)
+}
+
+type AdminMailPreviewData struct {
+	UserInfo  *auth.UserInfo
+	Previews  []mail.Preview
+	Themes    []ThemeOption
+	Languages []string
+	Type      string
+	Theme     string
+	Language  string
+	Email     *store.OutgoingEmail
+	Error     string
+}
+
+// GetAdminMailPreviewHandler renders an email type with sample data in the
+// chosen theme and language, so template changes can be checked without
+// sending anything.
+func (h *Handler) GetAdminMailPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+	query := r.URL.Query()
+
+	data := AdminMailPreviewData{
+		UserInfo:  userInfo,
+		Previews:  mail.Previews,
+		Themes:    AvailableThemes,
+		Languages: mail.Languages(),
+		Type:      query.Get("type"),
+		Theme:     query.Get("theme"),
+		Language:  query.Get("lang"),
+	}
+	if data.Type == "" {
+		data.Type = mail.Previews[0].Name
+	}
+	if data.Theme == "" {
+		data.Theme = models.DefaultTheme
+	}
+	if data.Language == "" {
+		data.Language = mail.DefaultLanguage
+	}
+
+	preview, ok := mail.FindPreview(data.Type)
+	if !ok {
+		data.Error = "Unknown email type."
+		h.Renderer.RenderPage(w, "admin-mail-preview.gohtml", data)
+		return
+	}
+
+	email, err := preview.Render(mail.Recipient{
+		Email:    "cook@example.com",
+		Name:     userInfo.Username,
+		Theme:    data.Theme,
+		Language: data.Language,
+	})
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to render mail preview")
+		data.Error = "Failed to render email: " + err.Error()
+	}
+	data.Email = email
+
+	h.Renderer.RenderPage(w, "admin-mail-preview.gohtml", data)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestGetAdminMailPreviewHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantType    string
+		wantSubject string
+		wantError   bool
+	}{
+		{"defaults to the first email", "", "registration-request", "New Registration Request - Recipe Book", false},
+		{"renders the chosen email and language", "?type=recipe-comment&lang=de&theme=" + models.ThemeNightowl, "recipe-comment", "Neuer Kommentar zu Käsespätzle", false},
+		{"reports unknown emails", "?type=nope", "nope", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data AdminMailPreviewData
+			h := &Handler{
+				Renderer: &tmocks.MockRenderer{
+					RenderPageFunc: func(_ http.ResponseWriter, name string, d any) {
+						if name != "admin-mail-preview.gohtml" {
+							t.Errorf("rendered %q", name)
+						}
+						data = d.(AdminMailPreviewData)
+					},
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/mail/preview"+tt.query, nil)
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, IsAdmin: true, Username: "admin"}))
+			h.GetAdminMailPreviewHandler(httptest.NewRecorder(), req)
+
+			if data.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", data.Type, tt.wantType)
+			}
+			if (data.Error != "") != tt.wantError {
+				t.Errorf("error = %q, wantError %v", data.Error, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if data.Email == nil || data.Email.Subject != tt.wantSubject {
+				t.Fatalf("expected subject %q, got %+v", tt.wantSubject, data.Email)
+			}
+			if data.Email.Body == "" || data.Email.HTMLBody == "" {
+				t.Error("expected both the text and the HTML part")
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type NotificationsData struct {
+	UserInfo      *auth.UserInfo
+	Notifications []store.Notification
+	Page          int
+	TotalPages    int
+}
+
+func (h *Handler) GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	offset := (page - 1) * pageSize
+
+	notificationList, err := h.NotificationStore.GetByUserID(ctx, userInfo.UserID, pageSize, offset)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch notifications")
+		notificationList = []store.Notification{}
+	}
+
+	totalCount, err := h.NotificationStore.CountByUserID(ctx, userInfo.UserID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to count notifications")
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	data := NotificationsData{
+		UserInfo:      userInfo,
+		Notifications: notificationList,
+		Page:          page,
+		TotalPages:    totalPages,
+	}
+	h.Renderer.RenderPage(w, "notifications.gohtml", data)
+}
+
+// PostNotificationReadHandler marks a notification as read and opens the page
+// it is about.
+func (h *Handler) PostNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid notification ID.")
+		return
+	}
+
+	notification, err := h.NotificationStore.MarkRead(ctx, userInfo.UserID, id)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to mark notification as read")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to open notification. Please try again.")
+		return
+	}
+	if notification == nil {
+		h.Renderer.RenderError(w, r, http.StatusNotFound, "Notification not found.")
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":            "notification.read",
+		"notification.id":   id,
+		"notification.type": notification.Type,
+	})
+
+	// Only follow links within the site.
+	target := "/notifications"
+	if strings.HasPrefix(notification.Link, "/") && !strings.HasPrefix(notification.Link, "//") {
+		target = notification.Link
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func (h *Handler) PostNotificationsReadAllHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if err := h.NotificationStore.MarkAllRead(ctx, userInfo.UserID); err != nil {
+		logging.AddError(ctx, err, "Failed to mark notifications as read")
+	}
+
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+type NotificationSettingOption struct {
+	notifications.EventType
+	Email bool
+}
+
+type NotificationSettingsData struct {
+	UserInfo *auth.UserInfo
+	Options  []NotificationSettingOption
+	Digest   DigestSettings
+	Success  string
+	Error    string
+}
+
+func (h *Handler) GetNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	preferences, err := h.NotificationStore.GetEmailPreferences(ctx, userInfo.UserID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch notification preferences")
+	}
+
+	options := make([]NotificationSettingOption, len(notifications.EventTypes))
+	for i, eventType := range notifications.EventTypes {
+		email, ok := preferences[eventType.Type]
+		if !ok {
+			email = eventType.EmailByDefault
+		}
+		options[i] = NotificationSettingOption{EventType: eventType, Email: email}
+	}
+
+	digestSettings := DigestSettings{Day: models.DefaultDigestDay, Hour: models.DefaultDigestHour}
+	if prefs, err := h.UserPreferencesStore.Get(ctx, userInfo.UserID); err != nil {
+		logging.AddError(ctx, err, "Failed to fetch digest preferences")
+	} else if prefs != nil {
+		digestSettings = DigestSettings{Enabled: prefs.DigestEnabled, Day: prefs.DigestDay, Hour: prefs.DigestHour}
+	}
+
+	data := NotificationSettingsData{
+		UserInfo: userInfo,
+		Options:  options,
+		Digest:   digestSettings,
+		Success:  r.URL.Query().Get("success"),
+		Error:    r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "account-notifications.gohtml", data)
+}
+
+// PostNotificationSettingsHandler saves the email choice of every
+// notification type; unchecked types turn emails off.
+func (h *Handler) PostNotificationSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid form data.")
+		return
+	}
+
+	enabled := make(map[string]bool)
+	for _, notificationType := range r.Form["email"] {
+		enabled[notificationType] = true
+	}
+
+	for _, eventType := range notifications.EventTypes {
+		if err := h.NotificationStore.SetEmailPreference(ctx, userInfo.UserID, eventType.Type, enabled[eventType.Type]); err != nil {
+			logging.AddError(ctx, err, "Failed to save notification preference")
+			h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to save your settings. Please try again.")
+			return
+		}
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action": "notification.settings.update",
+	})
+
+	http.Redirect(w, r, "/account/notifications?success=Notification settings saved", http.StatusSeeOther)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestPostNotificationReadHandler_RedirectsToLinkWithinTheSite(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification *store.Notification
+		wantStatus   int
+		wantLocation string
+	}{
+		{"local link", &store.Notification{ID: 1, Link: "/recipes/5"}, http.StatusSeeOther, "/recipes/5"},
+		{"external link", &store.Notification{ID: 1, Link: "//evil.example.com"}, http.StatusSeeOther, "/notifications"},
+		{"other user's notification", nil, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var markedFor int
+			h := &Handler{
+				NotificationStore: &mocks.MockNotificationStore{
+					MarkReadFunc: func(_ context.Context, userID, id int) (*store.Notification, error) {
+						markedFor = userID
+						return tt.notification, nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/notifications/1/read", nil)
+			req.SetPathValue("id", "1")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 3}))
+			rec := httptest.NewRecorder()
+
+			h.PostNotificationReadHandler(rec, req)
+
+			if markedFor != 3 {
+				t.Errorf("expected notification to be marked for user 3, got %d", markedFor)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if location := rec.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("location = %q, want %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestPostNotificationSettingsHandler_SavesChoiceForEveryType(t *testing.T) {
+	saved := make(map[string]bool)
+	h := &Handler{
+		NotificationStore: &mocks.MockNotificationStore{
+			SetEmailPreferenceFunc: func(_ context.Context, _ int, notificationType string, email bool) error {
+				saved[notificationType] = email
+				return nil
+			},
+		},
+		Renderer: &tmocks.MockRenderer{},
+	}
+
+	form := url.Values{"email": {notifications.TypeRecipeComment}}
+	req := httptest.NewRequest(http.MethodPost, "/account/notifications", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.PostNotificationSettingsHandler(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+	if len(saved) != len(notifications.EventTypes) {
+		t.Errorf("expected a choice for every notification type, got %v", saved)
+	}
+	if !saved[notifications.TypeRecipeComment] || saved[notifications.TypeExtractionCompleted] {
+		t.Errorf("expected only comment emails to be on, got %v", saved)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// emailStatuses are the statuses outbox emails can be filtered by.
+var emailStatuses = []string{"pending", "sent", "failed"}
+
+type AdminOutboxData struct {
+	UserInfo   *auth.UserInfo
+	Statuses   []string
+	Status     string
+	Emails     []store.OutboxEmail
+	TotalCount int
+	Page       int
+	TotalPages int
+	Success    string
+	Error      string
+}
+
+// GetAdminOutboxHandler lists the emails in the outbox, newest first.
+func (h *Handler) GetAdminOutboxHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	status := r.URL.Query().Get("status")
+	if !slices.Contains(emailStatuses, status) {
+		status = ""
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 50
+	offset := (page - 1) * pageSize
+
+	emails, err := h.EmailOutboxStore.List(ctx, status, pageSize, offset)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch outbox emails")
+		emails = []store.OutboxEmail{}
+	}
+
+	totalCount, err := h.EmailOutboxStore.Count(ctx, status)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to count outbox emails")
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	data := AdminOutboxData{
+		UserInfo:   userInfo,
+		Statuses:   emailStatuses,
+		Status:     status,
+		Emails:     emails,
+		TotalCount: totalCount,
+		Page:       page,
+		TotalPages: totalPages,
+		Success:    r.URL.Query().Get("success"),
+		Error:      r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "admin-outbox.gohtml", data)
+}
+
+// PostAdminOutboxResendHandler queues an email for delivery again, e.g. one
+// that failed during a provider outage.
+func (h *Handler) PostAdminOutboxResendHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Redirect(w, r, "/admin/outbox?error=Invalid email ID", http.StatusSeeOther)
+		return
+	}
+
+	resent, err := h.EmailOutboxStore.Resend(ctx, id)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to resend email")
+		http.Redirect(w, r, "/admin/outbox?error=Failed to resend email", http.StatusSeeOther)
+		return
+	}
+	if !resent {
+		http.Redirect(w, r, "/admin/outbox?error=Email not found or can't be resent", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":   "outbox.resend",
+		"email_id": id,
+	})
+
+	http.Redirect(w, r, "/admin/outbox?success=Email "+strconv.Itoa(id)+" queued again", http.StatusSeeOther)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestGetAdminOutboxHandler_FiltersByStatus(t *testing.T) {
+	var listedStatus string
+	var data AdminOutboxData
+	h := &Handler{
+		EmailOutboxStore: &mocks.MockEmailOutboxStore{
+			ListFunc: func(_ context.Context, status string, limit, offset int) ([]store.OutboxEmail, error) {
+				listedStatus = status
+				return []store.OutboxEmail{{ID: 4, Status: "failed"}}, nil
+			},
+			CountFunc: func(context.Context, string) (int, error) { return 1, nil },
+		},
+		Renderer: &tmocks.MockRenderer{
+			RenderPageFunc: func(_ http.ResponseWriter, _ string, d any) { data = d.(AdminOutboxData) },
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/outbox?status=failed", nil)
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, IsAdmin: true}))
+	h.GetAdminOutboxHandler(httptest.NewRecorder(), req)
+
+	if listedStatus != "failed" {
+		t.Errorf("listed status %q, want failed", listedStatus)
+	}
+	if len(data.Emails) != 1 || data.TotalPages != 1 {
+		t.Errorf("unexpected page data %+v", data)
+	}
+}
+
+func TestPostAdminOutboxResendHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		resent       bool
+		wantLocation string
+	}{
+		{"queues the email again", true, "/admin/outbox?success="},
+		{"reports unknown emails", false, "/admin/outbox?error="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID int
+			h := &Handler{
+				EmailOutboxStore: &mocks.MockEmailOutboxStore{
+					ResendFunc: func(_ context.Context, id int) (bool, error) {
+						gotID = id
+						return tt.resent, nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/outbox/9/resend", nil)
+			req.SetPathValue("id", "9")
+			rec := httptest.NewRecorder()
+			h.PostAdminOutboxResendHandler(rec, req)
+
+			if gotID != 9 {
+				t.Errorf("resent email %d, want 9", gotID)
+			}
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("location = %q, want prefix %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
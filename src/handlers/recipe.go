@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -9,15 +10,22 @@ import (
 	"strings"
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
 	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/mail"
 	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/utils"
 )
 
 func (h *Handler) GetCreateRecipeHandler(w http.ResponseWriter, r *http.Request) {
 	data := struct {
-		UserInfo *auth.UserInfo
+		UserInfo  *auth.UserInfo
+		Languages []models.Language
 	}{
-		UserInfo: auth.GetUserInfoFromContext(r.Context()),
+		UserInfo:  auth.GetUserInfoFromContext(r.Context()),
+		Languages: models.Languages,
 	}
 	h.Renderer.RenderPage(w, "create.gohtml", data)
 }
@@ -112,6 +120,7 @@ func (h *Handler) PostCreateRecipeHandler(w http.ResponseWriter, r *http.Request
 		Image:          imageData,
 		AuthorID:       user.ID,
 	}
+	recipe.Language = formLanguage(r, recipe)
 
 	recipeID, err := h.RecipeStore.Save(ctx, recipe)
 	if err != nil {
@@ -136,6 +145,15 @@ func (h *Handler) PostCreateRecipeHandler(w http.ResponseWriter, r *http.Request
 	http.Redirect(w, r, fmt.Sprintf("/recipes/%d", recipeID), http.StatusSeeOther)
 }
 
+// formLanguage returns the language chosen in a recipe form, or detects it
+// from the recipe's text if the author left it to be detected.
+func formLanguage(r *http.Request, recipe models.Recipe) string {
+	if language := r.FormValue("language"); models.IsLanguage(language) {
+		return language
+	}
+	return extraction.DetectLanguage(strings.Join([]string{recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD}, "\n"))
+}
+
 func (h *Handler) ListRecipesHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userInfo := auth.GetUserInfoFromContext(ctx)
@@ -318,11 +336,13 @@ func (h *Handler) GetUpdateRecipeHandler(w http.ResponseWriter, r *http.Request)
 	recipe.Tags, _ = h.TagStore.GetByRecipeID(ctx, recipeIDInt)
 
 	data := struct {
-		Recipe   models.Recipe
-		UserInfo *auth.UserInfo
+		Recipe    models.Recipe
+		UserInfo  *auth.UserInfo
+		Languages []models.Language
 	}{
-		Recipe:   recipe,
-		UserInfo: auth.GetUserInfoFromContext(ctx),
+		Recipe:    recipe,
+		UserInfo:  auth.GetUserInfoFromContext(ctx),
+		Languages: models.Languages,
 	}
 
 	h.Renderer.RenderPage(w, "update.gohtml", data)
@@ -437,6 +457,7 @@ func (h *Handler) PostUpdateRecipeHandler(w http.ResponseWriter, r *http.Request
 		Image:          imageData,
 		AuthorID:       user.ID,
 	}
+	updatedRecipe.Language = formLanguage(r, updatedRecipe)
 
 	if err := h.RecipeStore.Update(ctx, updatedRecipe); err != nil {
 		logging.AddError(ctx, err, "Failed to update recipe")
@@ -564,6 +585,11 @@ func (h *Handler) ViewRecipeHandler(w http.ResponseWriter, r *http.Request) {
 
 	isRecipeAuthor := isLoggedIn && currentUser.ID == recipe.AuthorID
 
+	translations, err := h.RecipeStore.GetTranslations(ctx, originalRecipeID(recipe))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to load translations")
+	}
+
 	var commentsWithUsernames []CommentTemplateData
 	if len(commentsRes.comments) > 0 {
 		authorIDs := make([]int, 0, len(commentsRes.comments))
@@ -602,6 +628,12 @@ func (h *Handler) ViewRecipeHandler(w http.ResponseWriter, r *http.Request) {
 		CurrentUser *auth.User
 		IsAuthor    bool
 		UserInfo    *auth.UserInfo
+		// Translations lists the original and all translations of the
+		// recipe, the original first. It is empty for recipes that were
+		// never translated.
+		Translations []models.RecipeTranslation
+		Languages    []models.Language
+		Error        string
 	}{
 		Recipe:      recipe,
 		UserTags:    userTags,
@@ -610,6 +642,11 @@ func (h *Handler) ViewRecipeHandler(w http.ResponseWriter, r *http.Request) {
 		CurrentUser: currentUser,
 		IsAuthor:    isRecipeAuthor,
 		UserInfo:    userInfo,
+		Languages:   models.Languages,
+		Error:       r.URL.Query().Get("error"),
+	}
+	if len(translations) > 1 {
+		data.Translations = translations
 	}
 
 	h.Renderer.RenderPage(w, "view.gohtml", data)
@@ -675,6 +712,8 @@ func (h *Handler) CommentHTMXHandler(w http.ResponseWriter, r *http.Request) {
 		"comment.id": savedComment.ID,
 	})
 
+	h.notifyRecipeComment(ctx, recipeIDInt, user.ID, user.Username, commentContent)
+
 	commentData := CommentTemplateData{
 		Comment:  savedComment,
 		Username: user.Username,
@@ -684,6 +723,38 @@ func (h *Handler) CommentHTMXHandler(w http.ResponseWriter, r *http.Request) {
 	h.Renderer.RenderFragment(w, "comment.gohtml", commentData)
 }
 
+// notifyRecipeComment tells the recipe's author about a comment by someone
+// else. Failures are only logged, the comment is saved either way.
+func (h *Handler) notifyRecipeComment(ctx context.Context, recipeID, commenterID int, commenterName, comment string) {
+	recipe, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipe for comment notification")
+		return
+	}
+	if recipe.AuthorID == commenterID || recipe.AuthorID == 0 {
+		return
+	}
+
+	recipeURL := fmt.Sprintf("%s/recipes/%d", utils.GetAppBaseURL(), recipeID)
+	err = h.Notifier.Notify(ctx, store.Notification{
+		UserID: recipe.AuthorID,
+		Type:   notifications.TypeRecipeComment,
+		Title:  fmt.Sprintf("%s commented on %s", commenterName, recipe.Title),
+		Body:   comment,
+		Link:   fmt.Sprintf("/recipes/%d", recipeID),
+	}, func(ctx context.Context, mc mail.MailClient) error {
+		author, err := h.AuthStore.GetUserByID(ctx, recipe.AuthorID)
+		if err != nil {
+			return fmt.Errorf("failed to get recipe author: %w", err)
+		}
+		to := h.mailRecipient(ctx, recipe.AuthorID, author.Email, author.Username)
+		return mail.SendRecipeCommentNotification(ctx, mc, to, commenterName, recipe.Title, comment, recipeURL)
+	})
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to send comment notification")
+	}
+}
+
 type CommentTemplateData struct {
 	models.Comment
 	Username string
@@ -880,6 +951,62 @@ func (h *Handler) DeleteRecipeHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Recipe deleted successfully"))
 }
 
+// ForkRecipeHandler copies a recipe into the current user's recipes, so they
+// can edit it without touching the original.
+func (h *Handler) ForkRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid recipe ID", http.StatusBadRequest)
+		return
+	}
+
+	original, err := h.RecipeStore.GetByID(ctx, strconv.Itoa(recipeID))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to find recipe to fork")
+		http.Error(w, "Recipe not found", http.StatusNotFound)
+		return
+	}
+
+	fork := original
+	fork.ID = 0
+	fork.AuthorID = userInfo.UserID
+	fork.ParentID = &original.ID
+	// A fork is a recipe of its own rather than another version of the
+	// original's translations.
+	fork.TranslationOf = nil
+
+	forkID, err := h.RecipeStore.Save(ctx, fork)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to save forked recipe")
+		http.Error(w, "Failed to fork recipe", http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.TagStore.GetByRecipeID(ctx, original.ID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to get tags of forked recipe")
+	} else if len(tags) > 0 {
+		tagNames := make([]string, len(tags))
+		for i, tag := range tags {
+			tagNames[i] = tag.Name
+		}
+		if err := h.TagStore.SetRecipeTags(ctx, forkID, tagNames); err != nil {
+			logging.AddError(ctx, err, "Failed to copy tags to forked recipe")
+		}
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":           "recipe.fork",
+		"recipe.id":        forkID,
+		"recipe.parent_id": original.ID,
+	})
+
+	http.Redirect(w, r, fmt.Sprintf("/recipes/%d", forkID), http.StatusSeeOther)
+}
+
 func (h *Handler) RandomRecipeHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	recipeID, err := h.RecipeStore.GetRandomID(ctx)
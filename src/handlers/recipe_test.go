@@ -13,6 +13,7 @@ import (
 
 	"github.com/mr-flannery/go-recipe-book/src/auth"
 	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
 	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
@@ -599,10 +600,26 @@ func TestCommentHTMXHandler_AddsCommentWhenAuthenticated(t *testing.T) {
 		},
 	}
 
+	mockRecipeStore := &mocks.MockRecipeStore{
+		GetByIDFunc: func(ctx context.Context, id string) (models.Recipe, error) {
+			return models.Recipe{ID: 1, Title: "Goulash", AuthorID: 2}, nil
+		},
+	}
+
+	var notified []store.Notification
+	mockNotificationStore := &mocks.MockNotificationStore{
+		CreateFunc: func(ctx context.Context, notification store.Notification) (int, error) {
+			notified = append(notified, notification)
+			return 1, nil
+		},
+	}
+
 	h := &Handler{
 		AuthStore:    mockAuthStore,
 		CommentStore: mockCommentStore,
+		RecipeStore:  mockRecipeStore,
 		Renderer:     mockRenderer,
+		Notifier:     notifications.NewNotifier(mockNotificationStore, nil),
 	}
 
 	form := url.Values{}
@@ -627,6 +644,10 @@ func TestCommentHTMXHandler_AddsCommentWhenAuthenticated(t *testing.T) {
 	if capturedComment.RecipeID != 1 {
 		t.Errorf("expected recipe ID 1, got %d", capturedComment.RecipeID)
 	}
+
+	if len(notified) != 1 || notified[0].UserID != 2 || notified[0].Type != notifications.TypeRecipeComment {
+		t.Errorf("expected the recipe author to be notified, got %+v", notified)
+	}
 }
 
 func TestCommentHTMXHandler_ReturnsUnauthorizedWhenNotLoggedIn(t *testing.T) {
@@ -1695,3 +1716,51 @@ func TestSetViewModeHandler_ReturnsUnauthorizedWhenNotLoggedIn(t *testing.T) {
 		t.Error("SetViewMode should not be called when user is not logged in")
 	}
 }
+
+func TestForkRecipeHandler_CopiesRecipeAndTagsForCurrentUser(t *testing.T) {
+	var saved models.Recipe
+	var copiedTags []string
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDFunc: func(ctx context.Context, id string) (models.Recipe, error) {
+				return models.Recipe{ID: 7, Title: "Pancakes", IngredientsMD: "- 2 eggs", AuthorID: 2, Source: "https://example.com"}, nil
+			},
+			SaveFunc: func(ctx context.Context, recipe models.Recipe) (int, error) {
+				saved = recipe
+				return 99, nil
+			},
+		},
+		TagStore: &mocks.MockTagStore{
+			GetByRecipeIDFunc: func(ctx context.Context, recipeID int) ([]models.Tag, error) {
+				return []models.Tag{{ID: 1, Name: "breakfast"}}, nil
+			},
+			SetRecipeTagsFunc: func(ctx context.Context, recipeID int, tagNames []string) error {
+				if recipeID != 99 {
+					t.Errorf("expected tags to be set on recipe 99, got %d", recipeID)
+				}
+				copiedTags = tagNames
+				return nil
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/recipes/7/fork", nil)
+	req.SetPathValue("id", "7")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 5}))
+	rec := httptest.NewRecorder()
+
+	h.ForkRecipeHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); location != "/recipes/99" {
+		t.Errorf("expected redirect to /recipes/99, got %q", location)
+	}
+	if saved.AuthorID != 5 || saved.Title != "Pancakes" || saved.Source != "https://example.com" {
+		t.Errorf("unexpected forked recipe: %+v", saved)
+	}
+	if saved.ParentID == nil || *saved.ParentID != 7 {
+		t.Errorf("expected parent ID 7, got %v", saved.ParentID)
+	}
+	if len(copiedTags) != 1 || copiedTags[0] != "breakfast" {
+		t.Errorf("expected tags to be copied, got %v", copiedTags)
+	}
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type ReExtractPageData struct {
+	UserInfo *auth.UserInfo
+	Recipe   models.Recipe
+	Models   []extraction.Model
+	Error    string
+}
+
+type ReExtractReviewData struct {
+	UserInfo *auth.UserInfo
+	Recipe   models.Recipe
+	Job      *store.ExtractionJob
+	Changes  []RecipeFieldChange
+	Error    string
+}
+
+// RecipeFieldChange is a recipe field whose re-extracted value differs from
+// the current one. Multi-line fields also come with a line diff.
+type RecipeFieldChange struct {
+	Field    string
+	Label    string
+	Current  string
+	Proposed string
+	Lines    []DiffLine
+}
+
+// DiffLine is one line of a line diff. Op is "+" for added lines, "-" for
+// removed lines and "" for lines both versions share.
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+type recipeField struct {
+	name      string
+	label     string
+	multiline bool
+	get       func(recipe models.Recipe) string
+	set       func(dst *models.Recipe, src models.Recipe)
+}
+
+// reExtractFields are the fields a re-extraction can propose changes for, in
+// the order they are shown.
+var reExtractFields = []recipeField{
+	{"title", "Title", false,
+		func(r models.Recipe) string { return r.Title },
+		func(dst *models.Recipe, src models.Recipe) { dst.Title = src.Title }},
+	{"description", "Description", true,
+		func(r models.Recipe) string { return r.Description },
+		func(dst *models.Recipe, src models.Recipe) { dst.Description = src.Description }},
+	{"ingredients", "Ingredients", true,
+		func(r models.Recipe) string { return r.IngredientsMD },
+		func(dst *models.Recipe, src models.Recipe) { dst.IngredientsMD = src.IngredientsMD }},
+	{"instructions", "Instructions", true,
+		func(r models.Recipe) string { return r.InstructionsMD },
+		func(dst *models.Recipe, src models.Recipe) { dst.InstructionsMD = src.InstructionsMD }},
+	{"preptime", "Prep time (minutes)", false,
+		func(r models.Recipe) string { return optionalNumber(r.PrepTime) },
+		func(dst *models.Recipe, src models.Recipe) { dst.PrepTime = src.PrepTime }},
+	{"cooktime", "Cook time (minutes)", false,
+		func(r models.Recipe) string { return optionalNumber(r.CookTime) },
+		func(dst *models.Recipe, src models.Recipe) { dst.CookTime = src.CookTime }},
+	{"calories", "Calories per serving", false,
+		func(r models.Recipe) string { return optionalNumber(r.Calories) },
+		func(dst *models.Recipe, src models.Recipe) { dst.Calories = src.Calories }},
+}
+
+func optionalNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// diffRecipe lists the fields in which proposed differs from current.
+func diffRecipe(current, proposed models.Recipe) []RecipeFieldChange {
+	var changes []RecipeFieldChange
+	for _, field := range reExtractFields {
+		currentValue := strings.TrimSpace(field.get(current))
+		proposedValue := strings.TrimSpace(field.get(proposed))
+		if currentValue == proposedValue {
+			continue
+		}
+
+		change := RecipeFieldChange{
+			Field:    field.name,
+			Label:    field.label,
+			Current:  currentValue,
+			Proposed: proposedValue,
+		}
+		if field.multiline {
+			change.Lines = diffLines(currentValue, proposedValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// applyRecipeFields returns current with the named fields taken from
+// proposed. Unknown field names are ignored.
+func applyRecipeFields(current, proposed models.Recipe, fields []string) models.Recipe {
+	selected := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		selected[name] = true
+	}
+
+	updated := current
+	for _, field := range reExtractFields {
+		if selected[field.name] {
+			field.set(&updated, proposed)
+		}
+	}
+	return updated
+}
+
+// diffLines computes a line diff from the longest common subsequence of the
+// lines. Recipes are short enough for the quadratic table.
+func diffLines(current, proposed string) []DiffLine {
+	a := strings.Split(current, "\n")
+	b := strings.Split(proposed, "\n")
+
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Text: a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, DiffLine{Op: "-", Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "+", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: "-", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: "+", Text: b[j]})
+	}
+	return lines
+}
+
+// reExtractableRecipe loads the recipe of the request and checks that the
+// current user wrote it and that it has a source URL to extract from. It
+// renders an error and returns false otherwise.
+func (h *Handler) reExtractableRecipe(w http.ResponseWriter, r *http.Request) (models.Recipe, bool) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipe, err := h.RecipeStore.GetByID(ctx, r.PathValue("id"))
+	if err != nil {
+		h.Renderer.RenderError(w, r, http.StatusNotFound, "The recipe you're looking for doesn't exist or has been removed.")
+		return models.Recipe{}, false
+	}
+
+	if recipe.AuthorID != userInfo.UserID {
+		h.Renderer.RenderError(w, r, http.StatusForbidden, "You can only re-extract your own recipes.")
+		return models.Recipe{}, false
+	}
+
+	source, err := url.Parse(recipe.Source)
+	if recipe.Source == "" || err != nil || (source.Scheme != "http" && source.Scheme != "https") {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "This recipe has no source URL to extract it from.")
+		return models.Recipe{}, false
+	}
+
+	return recipe, true
+}
+
+func (h *Handler) GetRecipeReExtractHandler(w http.ResponseWriter, r *http.Request) {
+	recipe, ok := h.reExtractableRecipe(w, r)
+	if !ok {
+		return
+	}
+
+	data := ReExtractPageData{
+		UserInfo: auth.GetUserInfoFromContext(r.Context()),
+		Recipe:   recipe,
+		Models:   extraction.Models,
+		Error:    r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "re-extract.gohtml", data)
+}
+
+// PostRecipeReExtractHandler queues a job that extracts the recipe from its
+// source again. The result is only proposed; nothing changes until the author
+// reviews it.
+func (h *Handler) PostRecipeReExtractHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipe, ok := h.reExtractableRecipe(w, r)
+	if !ok {
+		return
+	}
+	pagePath := "/recipes/" + strconv.Itoa(recipe.ID) + "/re-extract"
+
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, pagePath+"?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, pagePath+"?error=Invalid form data", http.StatusSeeOther)
+		return
+	}
+
+	var model *string
+	if m := r.FormValue("model"); m != "" {
+		if !extraction.IsSelectableModel(m) {
+			http.Redirect(w, r, pagePath+"?error=Please choose one of the listed models", http.StatusSeeOther)
+			return
+		}
+		model = &m
+	}
+
+	jobType := "website"
+	if extraction.IsVideoURL(recipe.Source) {
+		jobType = "video"
+	}
+
+	jobID, err := h.ExtractionJobStore.CreateReExtraction(ctx, userInfo.UserID, recipe.ID, jobType, recipe.Source, model)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to create re-extraction job")
+		http.Redirect(w, r, pagePath+"?error=Failed to create extraction job", http.StatusSeeOther)
+		return
+	}
+
+	fields := map[string]any{
+		"action":    "extraction.re_extract",
+		"job_id":    jobID,
+		"job_type":  jobType,
+		"recipe.id": recipe.ID,
+	}
+	if model != nil {
+		fields["extraction.model"] = *model
+	}
+	logging.AddMany(ctx, fields)
+
+	http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(jobID), http.StatusSeeOther)
+}
+
+// reExtractionProposal loads the recipe and the finished re-extraction job of
+// the request and decodes the proposed recipe. It responds and returns false
+// if the job doesn't belong to the recipe or has no proposal yet.
+func (h *Handler) reExtractionProposal(w http.ResponseWriter, r *http.Request) (models.Recipe, *store.ExtractionJob, models.Recipe, bool) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipe, ok := h.reExtractableRecipe(w, r)
+	if !ok {
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+
+	jobID, err := strconv.Atoi(r.PathValue("jobId"))
+	if err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid job ID")
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+
+	job, err := h.ExtractionJobStore.GetByID(ctx, jobID)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch job")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to load job")
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+	if job == nil || job.UserID != userInfo.UserID || job.TargetRecipeID == nil || *job.TargetRecipeID != recipe.ID {
+		h.Renderer.RenderError(w, r, http.StatusNotFound, "Job not found")
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+
+	if job.Status != "completed" || job.ProposedRecipe == nil {
+		http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(job.ID), http.StatusSeeOther)
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+
+	var extracted extraction.ExtractedRecipe
+	if err := json.Unmarshal(job.ProposedRecipe, &extracted); err != nil {
+		logging.AddError(ctx, err, "Failed to decode proposed recipe")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to load the proposed changes")
+		return models.Recipe{}, nil, models.Recipe{}, false
+	}
+
+	return recipe, job, extracted.Recipe(recipe.AuthorID), true
+}
+
+func (h *Handler) GetRecipeReExtractReviewHandler(w http.ResponseWriter, r *http.Request) {
+	recipe, job, proposed, ok := h.reExtractionProposal(w, r)
+	if !ok {
+		return
+	}
+
+	data := ReExtractReviewData{
+		UserInfo: auth.GetUserInfoFromContext(r.Context()),
+		Recipe:   recipe,
+		Job:      job,
+		Changes:  diffRecipe(recipe, proposed),
+		Error:    r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "re-extract-review.gohtml", data)
+}
+
+// PostRecipeReExtractApplyHandler copies the selected fields of the proposal
+// into the recipe and leaves all other fields as they are.
+func (h *Handler) PostRecipeReExtractApplyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recipe, job, proposed, ok := h.reExtractionProposal(w, r)
+	if !ok {
+		return
+	}
+	recipePath := "/recipes/" + strconv.Itoa(recipe.ID)
+
+	if err := r.ParseForm(); err != nil {
+		h.Renderer.RenderError(w, r, http.StatusBadRequest, "Invalid form data.")
+		return
+	}
+
+	fields := r.Form["field"]
+	if len(fields) == 0 {
+		http.Redirect(w, r, recipePath+"/re-extract/"+strconv.Itoa(job.ID)+"?error=Select at least one change to apply", http.StatusSeeOther)
+		return
+	}
+
+	updated := applyRecipeFields(recipe, proposed, fields)
+	if err := h.RecipeStore.Update(ctx, updated); err != nil {
+		logging.AddError(ctx, err, "Failed to apply re-extracted fields")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to update the recipe. Please try again.")
+		return
+	}
+	if err := h.ExtractionJobStore.MarkProposalApplied(ctx, job.ID); err != nil {
+		logging.AddError(ctx, err, "Failed to mark the re-extraction as applied")
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":        "extraction.re_extract.apply",
+		"job_id":        job.ID,
+		"recipe.id":     recipe.ID,
+		"recipe.fields": strings.Join(fields, ","),
+	})
+
+	http.Redirect(w, r, recipePath, http.StatusSeeOther)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestDiffRecipe_ListsOnlyChangedFields(t *testing.T) {
+	current := models.Recipe{
+		Title:         "Pancakes",
+		IngredientsMD: "- 200 g flour\n- 2 eggs\n- milk",
+		PrepTime:      10,
+	}
+	proposed := models.Recipe{
+		Title:         "Pancakes",
+		IngredientsMD: "- 200 g flour\n- 2 eggs\n- 300 ml milk",
+		PrepTime:      10,
+		CookTime:      15,
+	}
+
+	changes := diffRecipe(current, proposed)
+
+	var fields []string
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	if !reflect.DeepEqual(fields, []string{"ingredients", "cooktime"}) {
+		t.Fatalf("changed fields = %v, want [ingredients cooktime]", fields)
+	}
+
+	wantLines := []DiffLine{
+		{Text: "- 200 g flour"},
+		{Text: "- 2 eggs"},
+		{Op: "-", Text: "- milk"},
+		{Op: "+", Text: "- 300 ml milk"},
+	}
+	if !reflect.DeepEqual(changes[0].Lines, wantLines) {
+		t.Errorf("ingredient lines = %+v, want %+v", changes[0].Lines, wantLines)
+	}
+	if changes[1].Lines != nil || changes[1].Current != "" || changes[1].Proposed != "15" {
+		t.Errorf("cook time change = %+v, want empty to 15 without line diff", changes[1])
+	}
+}
+
+func TestPostRecipeReExtractApplyHandler_UpdatesOnlySelectedFields(t *testing.T) {
+	current := models.Recipe{
+		ID:             7,
+		Title:          "Pancakes",
+		Description:    "My family recipe.",
+		IngredientsMD:  "- flour",
+		InstructionsMD: "Mix.",
+		Source:         "https://example.com/pancakes",
+		AuthorID:       1,
+	}
+	targetID := 7
+	job := &store.ExtractionJob{
+		ID:             3,
+		UserID:         1,
+		Status:         "completed",
+		TargetRecipeID: &targetID,
+		ProposedRecipe: []byte(`{"title": "Fluffy Pancakes", "description": "From the blog.", "ingredients_md": "- 200 g flour", "instructions_md": "Whisk, then fry."}`),
+	}
+
+	var updated *models.Recipe
+	jobStore := &mockExtractionJobStore{
+		getByIDFunc: func(context.Context, int) (*store.ExtractionJob, error) { return job, nil },
+	}
+	h := &Handler{
+		RecipeStore: &mocks.MockRecipeStore{
+			GetByIDFunc: func(context.Context, string) (models.Recipe, error) { return current, nil },
+			UpdateFunc: func(_ context.Context, recipe models.Recipe) error {
+				updated = &recipe
+				return nil
+			},
+		},
+		ExtractionJobStore: jobStore,
+		Renderer:           &tmocks.MockRenderer{},
+	}
+
+	form := url.Values{"field": {"title", "ingredients"}}
+	req := httptest.NewRequest(http.MethodPost, "/recipes/7/re-extract/3", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetPathValue("id", "7")
+	req.SetPathValue("jobId", "3")
+	req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 1}))
+	rec := httptest.NewRecorder()
+
+	h.PostRecipeReExtractApplyHandler(rec, req)
+
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/recipes/7" {
+		t.Fatalf("got %d to %q, want redirect to the recipe", rec.Code, rec.Header().Get("Location"))
+	}
+	if updated == nil {
+		t.Fatal("expected the recipe to be updated")
+	}
+	want := current
+	want.Title = "Fluffy Pancakes"
+	want.IngredientsMD = "- 200 g flour"
+	if !reflect.DeepEqual(*updated, want) {
+		t.Errorf("updated recipe = %+v, want %+v", *updated, want)
+	}
+	if !reflect.DeepEqual(jobStore.appliedJobIDs, []int{3}) {
+		t.Errorf("marked jobs %v as applied, want [3]", jobStore.appliedJobIDs)
+	}
+}
+
+func TestPostRecipeReExtractHandler_QueuesJobForOwnRecipeOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int
+		model      string
+		wantStatus int
+		wantJob    bool
+		wantModel  string
+	}{
+		{"author with default model", 1, "", http.StatusSeeOther, true, ""},
+		{"author with other model", 1, "google/gemini-2.5-pro", http.StatusSeeOther, true, "google/gemini-2.5-pro"},
+		{"author with unknown model", 1, "some/expensive-model", http.StatusSeeOther, false, ""},
+		{"other user", 2, "", http.StatusForbidden, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created bool
+			var gotModel string
+			jobStore := &mockExtractionJobStore{
+				createReExtract: func(_ context.Context, userID, recipeID int, jobType, inputURL string, model *string) (int, error) {
+					created = true
+					if model != nil {
+						gotModel = *model
+					}
+					if recipeID != 7 || jobType != "video" || inputURL != "https://youtu.be/abc" {
+						t.Errorf("job for recipe %d, %s %s", recipeID, jobType, inputURL)
+					}
+					return 11, nil
+				},
+			}
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+						return models.Recipe{ID: 7, Source: "https://youtu.be/abc", AuthorID: 1}, nil
+					},
+				},
+				ExtractionJobStore: jobStore,
+				Renderer:           &tmocks.MockRenderer{},
+			}
+
+			form := url.Values{"model": {tt.model}}
+			req := httptest.NewRequest(http.MethodPost, "/recipes/7/re-extract", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", "7")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: tt.userID}))
+			rec := httptest.NewRecorder()
+
+			h.PostRecipeReExtractHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if created != tt.wantJob {
+				t.Errorf("job created = %v, want %v", created, tt.wantJob)
+			}
+			if gotModel != tt.wantModel {
+				t.Errorf("model = %q, want %q", gotModel, tt.wantModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+// TagSuggestionView is a pending tag suggestion with the tags its recipe
+// currently has.
+type TagSuggestionView struct {
+	models.TagSuggestion
+	CurrentTags []models.Tag
+}
+
+type AdminTaggingData struct {
+	UserInfo    *auth.UserInfo
+	Suggestions []TagSuggestionView
+	Success     string
+	Error       string
+}
+
+func (h *Handler) GetAdminTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	suggestions, err := h.TagStore.GetPendingSuggestions(ctx)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch tag suggestions")
+		h.Renderer.RenderError(w, r, http.StatusInternalServerError, "Failed to load tag suggestions. Please try again.")
+		return
+	}
+
+	recipeIDs := make([]int, len(suggestions))
+	for i, suggestion := range suggestions {
+		recipeIDs[i] = suggestion.RecipeID
+	}
+	currentTags, err := h.TagStore.GetForRecipes(ctx, recipeIDs)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch recipe tags")
+	}
+
+	views := make([]TagSuggestionView, len(suggestions))
+	for i, suggestion := range suggestions {
+		views[i] = TagSuggestionView{
+			TagSuggestion: suggestion,
+			CurrentTags:   currentTags[suggestion.RecipeID],
+		}
+	}
+
+	data := AdminTaggingData{
+		UserInfo:    userInfo,
+		Suggestions: views,
+		Success:     r.URL.Query().Get("success"),
+		Error:       r.URL.Query().Get("error"),
+	}
+	h.Renderer.RenderPage(w, "admin-tagging.gohtml", data)
+}
+
+// PostAdminTaggingHandler queues a tagging job for each recipe in the chosen
+// scope: the recipes without tags, all recipes, or a list of recipe IDs. The
+// jobs run at batch priority so they don't hold up users' extractions.
+func (h *Handler) PostAdminTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/admin/tagging?error=Invalid form data.", http.StatusSeeOther)
+		return
+	}
+
+	scope := r.FormValue("scope")
+	var recipeIDs []int
+	var err error
+	switch scope {
+	case "untagged", "all":
+		recipeIDs, err = h.RecipeStore.GetIDs(ctx, scope == "untagged")
+		if err != nil {
+			logging.AddError(ctx, err, "Failed to fetch recipe IDs")
+			http.Redirect(w, r, "/admin/tagging?error=Failed to start tagging run.", http.StatusSeeOther)
+			return
+		}
+	case "selected":
+		recipeIDs, err = parseRecipeIDs(r.FormValue("recipe_ids"))
+		if err != nil {
+			http.Redirect(w, r, "/admin/tagging?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+	default:
+		http.Redirect(w, r, "/admin/tagging?error=Invalid scope.", http.StatusSeeOther)
+		return
+	}
+
+	if len(recipeIDs) == 0 {
+		http.Redirect(w, r, "/admin/tagging?error=No recipes to tag.", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.ExtractionJobStore.CreateTagging(ctx, userInfo.UserID, recipeIDs, extraction.PriorityBatch); err != nil {
+		logging.AddError(ctx, err, "Failed to create tagging jobs")
+		http.Redirect(w, r, "/admin/tagging?error=Failed to start tagging run.", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":          "tagging.start",
+		"tagging.scope":   scope,
+		"tagging.recipes": len(recipeIDs),
+	})
+
+	message := fmt.Sprintf("Queued tagging for %d recipes. Suggestions show up here as the jobs finish.", len(recipeIDs))
+	http.Redirect(w, r, "/admin/tagging?success="+url.QueryEscape(message), http.StatusSeeOther)
+}
+
+// parseRecipeIDs parses a list of recipe IDs separated by commas or spaces.
+func parseRecipeIDs(value string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}) {
+		id, err := strconv.Atoi(field)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%q is not a recipe ID.", field)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// PostAdminTaggingApplyHandler applies or dismisses the submitted
+// suggestions. Applying adds the checked tags of each suggestion to the
+// recipe's current tags; unchecked ones are dropped.
+func (h *Handler) PostAdminTaggingApplyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, "/admin/tagging?error=Invalid form data.", http.StatusSeeOther)
+		return
+	}
+
+	var ids []int
+	for _, value := range r.Form["suggestion"] {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			http.Redirect(w, r, "/admin/tagging?error=Invalid suggestion.", http.StatusSeeOther)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		http.Redirect(w, r, "/admin/tagging?error=No suggestions selected.", http.StatusSeeOther)
+		return
+	}
+
+	action := r.FormValue("action")
+	var done string
+	switch action {
+	case "dismiss":
+		done = "dismissed"
+		if err := h.TagStore.ResolveSuggestions(ctx, ids, models.TagSuggestionDismissed); err != nil {
+			logging.AddError(ctx, err, "Failed to dismiss tag suggestions")
+			http.Redirect(w, r, "/admin/tagging?error=Failed to dismiss suggestions.", http.StatusSeeOther)
+			return
+		}
+	case "apply":
+		done = "applied"
+		suggestions, err := h.TagStore.GetPendingSuggestions(ctx)
+		if err != nil {
+			logging.AddError(ctx, err, "Failed to fetch tag suggestions")
+			http.Redirect(w, r, "/admin/tagging?error=Failed to apply suggestions.", http.StatusSeeOther)
+			return
+		}
+		byID := make(map[int]models.TagSuggestion, len(suggestions))
+		for _, suggestion := range suggestions {
+			byID[suggestion.ID] = suggestion
+		}
+
+		var applied []int
+		for _, id := range ids {
+			suggestion, ok := byID[id]
+			if !ok {
+				continue
+			}
+			if err := h.applyTagSuggestion(r, suggestion); err != nil {
+				logging.AddError(ctx, err, "Failed to apply tag suggestion")
+				continue
+			}
+			applied = append(applied, id)
+		}
+		if err := h.TagStore.ResolveSuggestions(ctx, applied, models.TagSuggestionApplied); err != nil {
+			logging.AddError(ctx, err, "Failed to resolve tag suggestions")
+		}
+		if len(applied) < len(ids) {
+			http.Redirect(w, r, "/admin/tagging?error="+url.QueryEscape(fmt.Sprintf("Applied %d of %d suggestions.", len(applied), len(ids))), http.StatusSeeOther)
+			return
+		}
+	default:
+		http.Redirect(w, r, "/admin/tagging?error=Invalid action.", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":              "tagging." + action,
+		"tagging.suggestions": len(ids),
+	})
+
+	http.Redirect(w, r, "/admin/tagging?success="+url.QueryEscape(fmt.Sprintf("%d suggestions %s.", len(ids), done)), http.StatusSeeOther)
+}
+
+// applyTagSuggestion adds the tags checked for suggestion in the form to its
+// recipe, keeping the tags the recipe already has.
+func (h *Handler) applyTagSuggestion(r *http.Request, suggestion models.TagSuggestion) error {
+	ctx := r.Context()
+
+	chosen := r.Form["tags-"+strconv.Itoa(suggestion.ID)]
+	if len(chosen) == 0 {
+		return nil
+	}
+
+	current, err := h.TagStore.GetByRecipeID(ctx, suggestion.RecipeID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch recipe tags: %w", err)
+	}
+	names := make([]string, 0, len(current)+len(chosen))
+	seen := make(map[string]bool)
+	for _, tag := range current {
+		seen[tag.Name] = true
+		names = append(names, tag.Name)
+	}
+	for _, name := range chosen {
+		// Only tags from the suggestion can be applied.
+		if !seen[name] && slices.Contains(suggestion.Tags, name) {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return h.TagStore.SetRecipeTags(ctx, suggestion.RecipeID, names)
+}
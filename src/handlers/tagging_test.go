@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestPostAdminTaggingHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		form          url.Values
+		wantUntagged  bool
+		wantRecipeIDs []int
+		wantLocation  string
+	}{
+		{
+			name:          "queues untagged recipes",
+			form:          url.Values{"scope": {"untagged"}},
+			wantUntagged:  true,
+			wantRecipeIDs: []int{1, 2},
+			wantLocation:  "/admin/tagging?success=",
+		},
+		{
+			name:          "queues all recipes",
+			form:          url.Values{"scope": {"all"}},
+			wantRecipeIDs: []int{1, 2},
+			wantLocation:  "/admin/tagging?success=",
+		},
+		{
+			name:          "queues selected recipes",
+			form:          url.Values{"scope": {"selected"}, "recipe_ids": {"5, 9 5"}},
+			wantRecipeIDs: []int{5, 9},
+			wantLocation:  "/admin/tagging?success=",
+		},
+		{
+			name:         "rejects invalid recipe IDs",
+			form:         url.Values{"scope": {"selected"}, "recipe_ids": {"5, soup"}},
+			wantLocation: "/admin/tagging?error=",
+		},
+		{
+			name:         "rejects unknown scopes",
+			form:         url.Values{"scope": {"everything"}},
+			wantLocation: "/admin/tagging?error=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUntagged bool
+			var gotRecipeIDs []int
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					GetIDsFunc: func(_ context.Context, untaggedOnly bool) ([]int, error) {
+						gotUntagged = untaggedOnly
+						return []int{1, 2}, nil
+					},
+				},
+				ExtractionJobStore: &mockExtractionJobStore{
+					createTagging: func(_ context.Context, userID int, recipeIDs []int, priority int) error {
+						gotRecipeIDs = recipeIDs
+						if userID != 2 || priority != extraction.PriorityBatch {
+							t.Errorf("tagging jobs for user %d at priority %d", userID, priority)
+						}
+						return nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/tagging", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, IsAdmin: true, UserID: 2}))
+			rec := httptest.NewRecorder()
+
+			h.PostAdminTaggingHandler(rec, req)
+
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("location = %q, want prefix %q", location, tt.wantLocation)
+			}
+			if gotUntagged != tt.wantUntagged {
+				t.Errorf("untaggedOnly = %v, want %v", gotUntagged, tt.wantUntagged)
+			}
+			if !slices.Equal(gotRecipeIDs, tt.wantRecipeIDs) {
+				t.Errorf("queued recipes %v, want %v", gotRecipeIDs, tt.wantRecipeIDs)
+			}
+		})
+	}
+}
+
+func TestPostAdminTaggingApplyHandler_MergesCheckedTags(t *testing.T) {
+	var setTags []string
+	var resolved []int
+	var status string
+	h := &Handler{
+		TagStore: &mocks.MockTagStore{
+			GetPendingSuggestionsFunc: func(context.Context) ([]models.TagSuggestion, error) {
+				return []models.TagSuggestion{{ID: 4, RecipeID: 7, Tags: []string{"soup", "vegan", "winter"}}}, nil
+			},
+			GetByRecipeIDFunc: func(context.Context, int) ([]models.Tag, error) {
+				return []models.Tag{{Name: "dinner"}, {Name: "soup"}}, nil
+			},
+			SetRecipeTagsFunc: func(_ context.Context, recipeID int, tagNames []string) error {
+				if recipeID != 7 {
+					t.Errorf("set tags of recipe %d, want 7", recipeID)
+				}
+				setTags = tagNames
+				return nil
+			},
+			ResolveSuggestionsFunc: func(_ context.Context, ids []int, s string) error {
+				resolved, status = ids, s
+				return nil
+			},
+		},
+		Renderer: &tmocks.MockRenderer{},
+	}
+
+	form := url.Values{
+		"suggestion": {"4"},
+		"tags-4":     {"soup", "vegan", "not-suggested"},
+		"action":     {"apply"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/tagging/apply", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+
+	h.PostAdminTaggingApplyHandler(rec, req)
+
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/admin/tagging?success=") {
+		t.Errorf("location = %q, want success redirect", location)
+	}
+	if want := []string{"dinner", "soup", "vegan"}; !slices.Equal(setTags, want) {
+		t.Errorf("set tags %v, want %v", setTags, want)
+	}
+	if !slices.Equal(resolved, []int{4}) || status != models.TagSuggestionApplied {
+		t.Errorf("resolved %v as %q, want [4] as applied", resolved, status)
+	}
+}
+
+func TestPostAdminTaggingApplyHandler_Dismisses(t *testing.T) {
+	var resolved []int
+	var status string
+	h := &Handler{
+		TagStore: &mocks.MockTagStore{
+			SetRecipeTagsFunc: func(context.Context, int, []string) error {
+				t.Error("dismissing must not change recipe tags")
+				return nil
+			},
+			ResolveSuggestionsFunc: func(_ context.Context, ids []int, s string) error {
+				resolved, status = ids, s
+				return nil
+			},
+		},
+		Renderer: &tmocks.MockRenderer{},
+	}
+
+	form := url.Values{"suggestion": {"4", "6"}, "action": {"dismiss"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/tagging/apply", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+
+	h.PostAdminTaggingApplyHandler(rec, req)
+
+	if !slices.Equal(resolved, []int{4, 6}) || status != models.TagSuggestionDismissed {
+		t.Errorf("resolved %v as %q, want [4 6] as dismissed", resolved, status)
+	}
+}
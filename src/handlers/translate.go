@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/logging"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+// originalRecipeID returns the ID of the recipe all versions of recipe are
+// linked to: its original if it is a translation, otherwise its own.
+func originalRecipeID(recipe models.Recipe) int {
+	if recipe.TranslationOf != nil {
+		return *recipe.TranslationOf
+	}
+	return recipe.ID
+}
+
+// PostRecipeTranslateHandler queues a job that translates a recipe into
+// another language. The translation is published as a new recipe by the
+// current user and linked to the original. If the recipe already has a
+// translation into that language, the user is sent there instead.
+func (h *Handler) PostRecipeTranslateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userInfo := auth.GetUserInfoFromContext(ctx)
+
+	recipe, err := h.RecipeStore.GetByID(ctx, r.PathValue("id"))
+	if err != nil {
+		h.Renderer.RenderError(w, r, http.StatusNotFound, "The recipe you're looking for doesn't exist or has been removed.")
+		return
+	}
+	recipePath := "/recipes/" + strconv.Itoa(recipe.ID)
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, recipePath+"?error=Invalid form data", http.StatusSeeOther)
+		return
+	}
+
+	language := r.FormValue("language")
+	if !models.IsLanguage(language) {
+		http.Redirect(w, r, recipePath+"?error=Please choose one of the listed languages", http.StatusSeeOther)
+		return
+	}
+	if language == recipe.Language {
+		http.Redirect(w, r, recipePath+"?error="+url.QueryEscape("This recipe is already written in "+models.LanguageName(language)), http.StatusSeeOther)
+		return
+	}
+
+	translations, err := h.RecipeStore.GetTranslations(ctx, originalRecipeID(recipe))
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to fetch translations")
+		http.Redirect(w, r, recipePath+"?error=Failed to create translation job", http.StatusSeeOther)
+		return
+	}
+	for _, translation := range translations {
+		if translation.Language == language {
+			http.Redirect(w, r, "/recipes/"+strconv.Itoa(translation.ID), http.StatusSeeOther)
+			return
+		}
+	}
+
+	if message := h.extractionQuotaExceeded(ctx, userInfo.UserID); message != "" {
+		http.Redirect(w, r, recipePath+"?error="+url.QueryEscape(message), http.StatusSeeOther)
+		return
+	}
+
+	jobID, err := h.ExtractionJobStore.CreateTranslation(ctx, userInfo.UserID, recipe.ID, language)
+	if err != nil {
+		logging.AddError(ctx, err, "Failed to create translation job")
+		http.Redirect(w, r, recipePath+"?error=Failed to create translation job", http.StatusSeeOther)
+		return
+	}
+
+	logging.AddMany(ctx, map[string]any{
+		"action":               "extraction.translate",
+		"job_id":               jobID,
+		"recipe.id":            recipe.ID,
+		"translation.language": language,
+	})
+
+	http.Redirect(w, r, "/account/jobs/"+strconv.Itoa(jobID), http.StatusSeeOther)
+}
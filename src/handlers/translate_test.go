@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/auth"
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+	tmocks "github.com/mr-flannery/go-recipe-book/src/templates/mocks"
+)
+
+func TestPostRecipeTranslateHandler(t *testing.T) {
+	original := 3
+	tests := []struct {
+		name         string
+		recipe       models.Recipe
+		language     string
+		translations []models.RecipeTranslation
+		wantOriginal int
+		wantJob      bool
+		wantLocation string
+	}{
+		{
+			name:         "queues a translation",
+			recipe:       models.Recipe{ID: 7, Language: models.LanguageGerman},
+			language:     models.LanguageEnglish,
+			wantOriginal: 7,
+			wantJob:      true,
+			wantLocation: "/account/jobs/11",
+		},
+		{
+			name:         "redirects to an existing translation",
+			recipe:       models.Recipe{ID: 7, Language: models.LanguageGerman, TranslationOf: &original},
+			language:     models.LanguageEnglish,
+			translations: []models.RecipeTranslation{{ID: 3, Language: models.LanguageEnglish, IsOriginal: true}, {ID: 7, Language: models.LanguageGerman}},
+			wantOriginal: 3,
+			wantLocation: "/recipes/3",
+		},
+		{
+			name:         "rejects the recipe's own language",
+			recipe:       models.Recipe{ID: 7, Language: models.LanguageGerman},
+			language:     models.LanguageGerman,
+			wantLocation: "/recipes/7?error=",
+		},
+		{
+			name:         "rejects unknown languages",
+			recipe:       models.Recipe{ID: 7},
+			language:     "fr",
+			wantLocation: "/recipes/7?error=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOriginal int
+			var created bool
+			h := &Handler{
+				RecipeStore: &mocks.MockRecipeStore{
+					GetByIDFunc: func(context.Context, string) (models.Recipe, error) {
+						return tt.recipe, nil
+					},
+					GetTranslationsFunc: func(_ context.Context, originalID int) ([]models.RecipeTranslation, error) {
+						gotOriginal = originalID
+						return tt.translations, nil
+					},
+				},
+				ExtractionJobStore: &mockExtractionJobStore{
+					createTranslate: func(_ context.Context, userID, recipeID int, language string) (int, error) {
+						created = true
+						if userID != 2 || recipeID != 7 || language != tt.language {
+							t.Errorf("translation job for user %d, recipe %d, language %q", userID, recipeID, language)
+						}
+						return 11, nil
+					},
+				},
+				Renderer: &tmocks.MockRenderer{},
+			}
+
+			form := url.Values{"language": {tt.language}}
+			req := httptest.NewRequest(http.MethodPost, "/recipes/7/translate", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", "7")
+			req = req.WithContext(auth.ContextWithUserInfo(req.Context(), &auth.UserInfo{IsLoggedIn: true, UserID: 2}))
+			rec := httptest.NewRecorder()
+
+			h.PostRecipeTranslateHandler(rec, req)
+
+			if rec.Code != http.StatusSeeOther {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if location := rec.Header().Get("Location"); !strings.HasPrefix(location, tt.wantLocation) {
+				t.Errorf("location = %q, want prefix %q", location, tt.wantLocation)
+			}
+			if created != tt.wantJob {
+				t.Errorf("job created = %v, want %v", created, tt.wantJob)
+			}
+			if gotOriginal != tt.wantOriginal {
+				t.Errorf("looked up translations of %d, want %d", gotOriginal, tt.wantOriginal)
+			}
+		})
+	}
+}
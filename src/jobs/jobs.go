@@ -0,0 +1,121 @@
+// Package jobs runs background jobs. Handlers are registered on a Runner by
+// job type; the Runner claims due jobs of those types from its Queue, keeps
+// them alive with heartbeats while they run, and retries or fails them
+// according to the handler's retry policy.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// Handler runs jobs of one type. A returned error fails the attempt; whether
+// the job is retried is up to the RetryPolicy it was registered with, unless
+// the error was made with RetryAfter or Permanent.
+type Handler interface {
+	Run(ctx context.Context, job *store.Job) error
+}
+
+// FailureHandler is implemented by handlers that need to know when one of
+// their jobs failed for good, e.g. to tell a user about it.
+type FailureHandler interface {
+	Handler
+	Failed(ctx context.Context, job *store.Job, err error)
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ctx context.Context, job *store.Job) error
+
+func (f HandlerFunc) Run(ctx context.Context, job *store.Job) error { return f(ctx, job) }
+
+// Typed returns a Handler that decodes the JSON payload of each job into a T
+// and passes it to fn. Payloads that can't be decoded fail the job without
+// retrying it.
+func Typed[T any](fn func(ctx context.Context, payload T) error) Handler {
+	return HandlerFunc(func(ctx context.Context, job *store.Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("failed to decode %s payload: %w", job.Type, err))
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Enqueuer is the part of the job store that adds jobs.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload []byte, runAfter time.Time) (int, error)
+}
+
+// Enqueue adds a job of the given type that runs as soon as a worker is free.
+func Enqueue(ctx context.Context, queue Enqueuer, jobType string, payload any) (int, error) {
+	return EnqueueAt(ctx, queue, jobType, payload, time.Time{})
+}
+
+// EnqueueAt adds a job of the given type that runs once runAfter has passed.
+func EnqueueAt(ctx context.Context, queue Enqueuer, jobType string, payload any, runAfter time.Time) (int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+	}
+	return queue.Enqueue(ctx, jobType, data, runAfter)
+}
+
+// RetryPolicy decides how often and when a failed job is run again.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a job is run before it fails for good.
+	// Defaults to 1, i.e. no retries.
+	MaxAttempts int
+	// Backoff is how long to wait before the first retry. It doubles with
+	// every further retry, up to MaxBackoff if that is set. Zero retries
+	// right away.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// delay returns how long to wait before running a job again after its
+// attempt-th attempt failed.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay > 0; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+type retryAfterError struct {
+	delay time.Duration
+	cause error
+}
+
+func (e *retryAfterError) Error() string { return e.cause.Error() }
+func (e *retryAfterError) Unwrap() error { return e.cause }
+
+// RetryAfter wraps an error caused by something that is expected to pass,
+// like a provider outage. The job runs again after delay however many
+// attempts its retry policy allows.
+func RetryAfter(delay time.Duration, err error) error {
+	return &retryAfterError{delay: delay, cause: err}
+}
+
+type permanentError struct {
+	cause error
+}
+
+func (e *permanentError) Error() string { return e.cause.Error() }
+func (e *permanentError) Unwrap() error { return e.cause }
+
+// Permanent wraps an error that retrying won't fix, so the job fails right
+// away.
+func Permanent(err error) error {
+	return &permanentError{cause: err}
+}
@@ -0,0 +1,445 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+var tracer = otel.Tracer("jobs")
+
+const (
+	defaultConcurrency       = 1
+	defaultPollInterval      = 5 * time.Second
+	defaultHeartbeatInterval = 10 * time.Second
+	defaultStaleAfter        = 3 * time.Minute
+	defaultStopTimeout       = 30 * time.Second
+
+	// defaultMaxStaleAttempts is how many attempts a job gets before the
+	// reaper fails it instead of requeueing it, so a job that keeps crashing
+	// its worker doesn't loop forever.
+	defaultMaxStaleAttempts = 3
+
+	// releaseTimeout bounds how long Stop waits for interrupted jobs to be
+	// handed back after their context was cancelled.
+	releaseTimeout = 5 * time.Second
+)
+
+var (
+	errRunnerStopping = errors.New("job runner is shutting down")
+	errClaimLost      = errors.New("job was reaped while running")
+	errJobCancelled   = errors.New("job was cancelled")
+)
+
+// Queue is where a Runner takes its jobs from. store.JobStore is the generic
+// queue; other tables can be run by adapting them to it.
+type Queue interface {
+	// Claim marks the next due job of one of jobTypes as processing on
+	// workerID, with the attempt counted, or returns nil if there is none.
+	Claim(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error)
+	// Heartbeat keeps a claim alive. It returns store.ErrJobNotClaimed if the
+	// job was taken from the worker and store.ErrJobCancelled if it was
+	// cancelled.
+	Heartbeat(ctx context.Context, id int, workerID string) error
+	Complete(ctx context.Context, id int) error
+	Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error
+	Fail(ctx context.Context, id int, lastError string) error
+	Release(ctx context.Context, id int, workerID string) error
+	ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error)
+}
+
+type Config struct {
+	// Name identifies the runner in logs. Defaults to "jobs".
+	Name         string
+	Concurrency  int
+	PollInterval time.Duration
+	// HeartbeatInterval is how often running jobs are marked as alive,
+	// checked for cancellation, and stale jobs are reaped. Defaults to 10
+	// seconds.
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long a running job may go without a heartbeat
+	// before it is requeued. Defaults to 3 minutes.
+	StaleAfter time.Duration
+	// StopTimeout is how long Stop waits for running jobs to finish before
+	// handing them back to the queue. Defaults to 30 seconds.
+	StopTimeout time.Duration
+	// MaxStaleAttempts is how many attempts a job may have had before the
+	// reaper fails it instead of requeueing it. Defaults to 3.
+	MaxStaleAttempts int
+	// ID identifies this process in the jobs it claims. Defaults to
+	// hostname and PID.
+	ID string
+}
+
+type registration struct {
+	handler Handler
+	policy  RetryPolicy
+}
+
+// Runner runs the jobs of a queue with the handlers registered for their
+// types. Handlers must be registered before Start.
+type Runner struct {
+	config   Config
+	queue    Queue
+	handlers map[string]registration
+	types    []string
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+
+	// ctx is the parent of all job contexts. Stop cancels it once the stop
+	// timeout has passed, which interrupts running jobs.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+func NewRunner(config Config, queue Queue) *Runner {
+	if config.Name == "" {
+		config.Name = "jobs"
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultConcurrency
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = defaultStaleAfter
+	}
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = defaultStopTimeout
+	}
+	if config.MaxStaleAttempts <= 0 {
+		config.MaxStaleAttempts = defaultMaxStaleAttempts
+	}
+	if config.ID == "" {
+		config.ID = DefaultWorkerID()
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Runner{
+		config:   config,
+		queue:    queue,
+		handlers: make(map[string]registration),
+		stopCh:   make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// DefaultWorkerID identifies this process by hostname and PID.
+func DefaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// ID returns the identifier this runner records on the jobs it claims.
+func (r *Runner) ID() string {
+	return r.config.ID
+}
+
+// Register makes the runner claim jobs of jobType and run them with handler.
+// It panics if the type already has a handler.
+func (r *Runner) Register(jobType string, handler Handler, policy RetryPolicy) {
+	if _, ok := r.handlers[jobType]; ok {
+		panic(fmt.Sprintf("jobs: handler for %q registered twice", jobType))
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	r.handlers[jobType] = registration{handler: handler, policy: policy}
+	r.types = append(r.types, jobType)
+	slices.Sort(r.types)
+}
+
+// Types returns the job types the runner has handlers for.
+func (r *Runner) Types() []string {
+	return slices.Clone(r.types)
+}
+
+func (r *Runner) Start() {
+	slog.Info("Starting job runner", "runner", r.config.Name, "concurrency", r.config.Concurrency, "worker_id", r.config.ID, "job_types", r.types)
+
+	for i := 0; i < r.config.Concurrency; i++ {
+		r.wg.Add(1)
+		go r.workerLoop(i)
+	}
+
+	r.wg.Add(1)
+	go r.reaperLoop()
+}
+
+// Stop stops claiming new jobs and waits up to StopTimeout for running jobs
+// to finish. Jobs still running after that are interrupted and handed back to
+// the queue; anything that can't be handed back is left for the reaper.
+func (r *Runner) Stop() {
+	slog.Info("Stopping job runner...", "runner", r.config.Name, "timeout", r.config.StopTimeout)
+	close(r.stopCh)
+	defer r.cancel(nil)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Job runner stopped", "runner", r.config.Name)
+		return
+	case <-time.After(r.config.StopTimeout):
+	}
+
+	slog.Warn("Jobs still running after stop timeout, handing them back", "runner", r.config.Name)
+	r.cancel(errRunnerStopping)
+
+	select {
+	case <-done:
+		slog.Info("Job runner stopped", "runner", r.config.Name)
+	case <-time.After(releaseTimeout):
+		slog.Error("Job runner did not stop in time, its jobs will be reaped", "runner", r.config.Name)
+	}
+}
+
+func (r *Runner) workerLoop(workerID int) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.runNextJob(workerID)
+		}
+	}
+}
+
+func (r *Runner) reaperLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.reapStaleJobs()
+		}
+	}
+}
+
+// reapStaleJobs requeues jobs whose worker died without finishing or handing
+// them back, e.g. after a crash or an OOM kill.
+func (r *Runner) reapStaleJobs() {
+	ctx := r.ctx
+
+	reaped, err := r.queue.ReapStale(ctx, time.Now().Add(-r.config.StaleAfter), r.config.MaxStaleAttempts)
+	if err != nil {
+		slog.Error("Failed to reap stale jobs", "runner", r.config.Name, "error", err)
+		return
+	}
+
+	for _, job := range reaped {
+		if job.Status != "failed" {
+			slog.Warn("Requeued stale job", "job_id", job.ID, "job_type", job.Type, "previous_worker", job.WorkerID)
+			continue
+		}
+
+		slog.Warn("Failed stale job after too many attempts", "job_id", job.ID, "job_type", job.Type, "previous_worker", job.WorkerID)
+		errMsg := "The job was interrupted too many times"
+		if job.LastError != nil {
+			errMsg = *job.LastError
+		}
+		r.notifyFailure(ctx, &job, errors.New(errMsg))
+	}
+}
+
+func (r *Runner) runNextJob(workerID int) {
+	ctx := r.ctx
+
+	job, err := r.queue.Claim(ctx, r.config.ID, r.types)
+	if err != nil {
+		slog.Error("Failed to claim job", "runner", r.config.Name, "worker", workerID, "error", err)
+		return
+	}
+
+	if job == nil {
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "jobs.run")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+		attribute.Int("job.attempt", job.AttemptCount),
+		attribute.Int("worker.id", workerID),
+	)
+
+	slog.Info("Running job",
+		"runner", r.config.Name,
+		"worker", workerID,
+		"job_id", job.ID,
+		"job_type", job.Type,
+		"attempt", job.AttemptCount,
+	)
+
+	reg, ok := r.handlers[job.Type]
+	if !ok {
+		err := fmt.Errorf("no handler for job type %q", job.Type)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if err := r.queue.Fail(ctx, job.ID, err.Error()); err != nil {
+			slog.Error("Failed to fail job", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+
+	jobCtx, stopHeartbeat := r.startHeartbeat(ctx, job.ID)
+	defer stopHeartbeat()
+
+	err = reg.handler.Run(jobCtx, job)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if cause := context.Cause(jobCtx); cause != nil {
+			r.handleInterruptedJob(ctx, job, cause)
+			return
+		}
+		r.handleJobFailure(ctx, job, reg.policy, err)
+		return
+	}
+
+	if err := r.queue.Complete(ctx, job.ID); err != nil {
+		slog.Error("Failed to mark job completed", "job_id", job.ID, "error", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	slog.Info("Job completed successfully", "job_id", job.ID, "job_type", job.Type)
+}
+
+// startHeartbeat keeps the claim on a job alive until the returned stop
+// function is called. The returned context is cancelled when the runner is
+// stopping, or when the job was cancelled or reaped in the meantime.
+func (r *Runner) startHeartbeat(ctx context.Context, jobID int) (context.Context, func()) {
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(r.config.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				err := r.queue.Heartbeat(jobCtx, jobID, r.config.ID)
+				if errors.Is(err, store.ErrJobCancelled) {
+					slog.Info("Job was cancelled, aborting it", "job_id", jobID)
+					cancel(errJobCancelled)
+					return
+				}
+				if errors.Is(err, store.ErrJobNotClaimed) {
+					slog.Warn("Lost claim on job, abandoning it", "job_id", jobID)
+					cancel(errClaimLost)
+					return
+				}
+				if err != nil {
+					slog.Error("Failed to send job heartbeat", "job_id", jobID, "error", err)
+				}
+			}
+		}
+	}()
+
+	return jobCtx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
+
+// handleInterruptedJob hands a job back to the queue when the runner is
+// stopping. Jobs that were cancelled or reaped are left alone.
+func (r *Runner) handleInterruptedJob(ctx context.Context, job *store.Job, cause error) {
+	if errors.Is(cause, errJobCancelled) {
+		slog.Info("Stopped cancelled job", "job_id", job.ID)
+		return
+	}
+	if errors.Is(cause, errClaimLost) {
+		slog.Warn("Abandoned reaped job", "job_id", job.ID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	slog.Info("Handing interrupted job back to the queue", "job_id", job.ID, "reason", cause)
+	if err := r.queue.Release(ctx, job.ID, r.config.ID); err != nil {
+		slog.Error("Failed to release job", "job_id", job.ID, "error", err)
+	}
+}
+
+func (r *Runner) handleJobFailure(ctx context.Context, job *store.Job, policy RetryPolicy, jobErr error) {
+	slog.Error("Job failed",
+		"job_id", job.ID,
+		"job_type", job.Type,
+		"attempt", job.AttemptCount,
+		"error", jobErr,
+	)
+
+	var retryAfter *retryAfterError
+	var permanent *permanentError
+	var retryAt time.Time
+	switch {
+	case errors.As(jobErr, &permanent):
+	case errors.As(jobErr, &retryAfter):
+		retryAt = time.Now().Add(retryAfter.delay)
+	case job.AttemptCount < policy.MaxAttempts:
+		retryAt = time.Now().Add(policy.delay(job.AttemptCount))
+	}
+
+	if !retryAt.IsZero() {
+		slog.Info("Scheduling job retry", "job_id", job.ID, "attempt", job.AttemptCount, "retry_at", retryAt)
+		if err := r.queue.Retry(ctx, job.ID, retryAt, jobErr.Error()); err != nil {
+			slog.Error("Failed to schedule retry", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+
+	if err := r.queue.Fail(ctx, job.ID, jobErr.Error()); err != nil {
+		slog.Error("Failed to fail job", "job_id", job.ID, "error", err)
+	}
+	r.notifyFailure(ctx, job, jobErr)
+}
+
+// notifyFailure tells the job's handler that the job failed for good, if it
+// wants to know.
+func (r *Runner) notifyFailure(ctx context.Context, job *store.Job, err error) {
+	if handler, ok := r.handlers[job.Type].handler.(FailureHandler); ok {
+		handler.Failed(ctx, job, err)
+	}
+}
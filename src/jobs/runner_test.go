@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// fakeQueue hands out a single job and records what the runner did with it.
+type fakeQueue struct {
+	job       *store.Job
+	reaped    []store.Job
+	claimed   []string
+	completed []int
+	retried   map[int]time.Time
+	failed    map[int]string
+}
+
+func (q *fakeQueue) Claim(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error) {
+	q.claimed = jobTypes
+	job := q.job
+	q.job = nil
+	return job, nil
+}
+func (q *fakeQueue) Heartbeat(ctx context.Context, id int, workerID string) error { return nil }
+func (q *fakeQueue) Complete(ctx context.Context, id int) error {
+	q.completed = append(q.completed, id)
+	return nil
+}
+func (q *fakeQueue) Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error {
+	q.retried[id] = retryAfter
+	return nil
+}
+func (q *fakeQueue) Fail(ctx context.Context, id int, lastError string) error {
+	q.failed[id] = lastError
+	return nil
+}
+func (q *fakeQueue) Release(ctx context.Context, id int, workerID string) error { return nil }
+func (q *fakeQueue) ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error) {
+	return q.reaped, nil
+}
+
+// recordingHandler returns err from every run and records failures.
+type recordingHandler struct {
+	err    error
+	failed []int
+}
+
+func (h *recordingHandler) Run(ctx context.Context, job *store.Job) error { return h.err }
+func (h *recordingHandler) Failed(ctx context.Context, job *store.Job, err error) {
+	h.failed = append(h.failed, job.ID)
+}
+
+func newFakeQueue(job *store.Job) *fakeQueue {
+	return &fakeQueue{job: job, retried: make(map[int]time.Time), failed: make(map[int]string)}
+}
+
+func TestRunner_AppliesRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Minute}
+	tests := []struct {
+		name         string
+		attempt      int
+		err          error
+		wantComplete bool
+		wantRetryIn  time.Duration
+		wantFailed   bool
+	}{
+		{name: "completes successful jobs", attempt: 1, wantComplete: true},
+		{name: "retries after the backoff", attempt: 1, err: errors.New("boom"), wantRetryIn: time.Minute},
+		{name: "doubles the backoff", attempt: 2, err: errors.New("boom"), wantRetryIn: 2 * time.Minute},
+		{name: "fails after the last attempt", attempt: 3, err: errors.New("boom"), wantFailed: true},
+		{name: "fails permanent errors right away", attempt: 1, err: Permanent(errors.New("bad input")), wantFailed: true},
+		{name: "retries retry-after errors past the last attempt", attempt: 5, err: RetryAfter(time.Hour, errors.New("down")), wantRetryIn: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newFakeQueue(&store.Job{ID: 4, Type: "test", AttemptCount: tt.attempt})
+			handler := &recordingHandler{err: tt.err}
+			runner := NewRunner(Config{ID: "test-worker"}, queue)
+			runner.Register("test", handler, policy)
+
+			start := time.Now()
+			runner.runNextJob(0)
+
+			if got := len(queue.completed) == 1; got != tt.wantComplete {
+				t.Errorf("completed = %v, want %v", got, tt.wantComplete)
+			}
+			retryAt, retried := queue.retried[4]
+			if retried != (tt.wantRetryIn > 0) {
+				t.Errorf("retried = %v, want %v", retried, tt.wantRetryIn > 0)
+			}
+			if retried {
+				if delay := retryAt.Sub(start); delay < tt.wantRetryIn || delay > tt.wantRetryIn+time.Second {
+					t.Errorf("retry in %v, want %v", delay, tt.wantRetryIn)
+				}
+			}
+			_, failed := queue.failed[4]
+			if failed != tt.wantFailed {
+				t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+			if notified := len(handler.failed) == 1; notified != tt.wantFailed {
+				t.Errorf("failure handler called = %v, want %v", notified, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestRunner_ClaimsRegisteredTypes(t *testing.T) {
+	queue := newFakeQueue(nil)
+	runner := NewRunner(Config{}, queue)
+	runner.Register("mail", &recordingHandler{}, RetryPolicy{})
+	runner.Register("digest", &recordingHandler{}, RetryPolicy{})
+
+	runner.runNextJob(0)
+
+	if len(queue.claimed) != 2 || queue.claimed[0] != "digest" || queue.claimed[1] != "mail" {
+		t.Errorf("claimed types %v, want [digest mail]", queue.claimed)
+	}
+}
+
+func TestRunner_NotifiesHandlerOfReapedFailures(t *testing.T) {
+	message := "The job was interrupted too many times"
+	queue := newFakeQueue(nil)
+	queue.reaped = []store.Job{
+		{ID: 1, Type: "test", Status: "pending"},
+		{ID: 2, Type: "test", Status: "failed", LastError: &message},
+	}
+	handler := &recordingHandler{}
+	runner := NewRunner(Config{}, queue)
+	runner.Register("test", handler, RetryPolicy{})
+
+	runner.reapStaleJobs()
+
+	if len(handler.failed) != 1 || handler.failed[0] != 2 {
+		t.Errorf("failure handler called for %v, want [2]", handler.failed)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	type payload struct {
+		Email string `json:"email"`
+	}
+	var got payload
+	handler := Typed(func(ctx context.Context, p payload) error {
+		got = p
+		return nil
+	})
+
+	if err := handler.Run(context.Background(), &store.Job{Type: "mail", Payload: []byte(`{"email":"a@example.com"}`)}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got.Email != "a@example.com" {
+		t.Errorf("payload = %+v", got)
+	}
+
+	err := handler.Run(context.Background(), &store.Job{Type: "mail", Payload: []byte(`not json`)})
+	var permanent *permanentError
+	if !errors.As(err, &permanent) {
+		t.Errorf("expected a permanent error for an invalid payload, got %v", err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Minute, MaxBackoff: 5 * time.Minute}
+	for attempt, want := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 5 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		if got := policy.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+	if got := (RetryPolicy{}).delay(3); got != 0 {
+		t.Errorf("delay without backoff = %v, want 0", got)
+	}
+}
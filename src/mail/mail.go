@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/maileroo/maileroo-go-sdk/maileroo"
+	"github.com/mr-flannery/go-recipe-book/src/store"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -13,8 +14,20 @@ import (
 
 var tracer = otel.Tracer("mail")
 
+// MailClient delivers emails. Emails with an HTMLBody are sent as
+// multipart messages with Body as the plain text alternative.
 type MailClient interface {
-	SendEmail(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error
+	SendEmail(ctx context.Context, email store.OutgoingEmail) error
+}
+
+func startSendSpan(ctx context.Context, email store.OutgoingEmail) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "mail.send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("mail.recipient", email.RecipientEmail),
+			attribute.String("mail.subject", email.Subject),
+		),
+	)
 }
 
 type mailerooClient struct {
@@ -30,25 +43,23 @@ func NewMailClient(apiKey, domain string) (MailClient, error) {
 	return &mailerooClient{client: client, domain: domain}, nil
 }
 
-func (m *mailerooClient) SendEmail(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-	ctx, span := tracer.Start(ctx, "mail.send",
-		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(
-			attribute.String("mail.recipient", recipientEmail),
-			attribute.String("mail.subject", subject),
-		),
-	)
+func (m *mailerooClient) SendEmail(ctx context.Context, email store.OutgoingEmail) error {
+	ctx, span := startSendSpan(ctx, email)
 	defer span.End()
 
-	_, err := m.client.SendBasicEmail(ctx, maileroo.BasicEmailData{
+	data := maileroo.BasicEmailData{
 		From: maileroo.NewEmail("recipe-book@"+m.domain, "Recipe Book"),
 		To: []maileroo.EmailAddress{
-			maileroo.NewEmail(recipientEmail, recipientName),
+			maileroo.NewEmail(email.RecipientEmail, email.RecipientName),
 		},
-		Subject: subject,
-		Plain:   &plainContent,
-	})
+		Subject: email.Subject,
+		Plain:   &email.Body,
+	}
+	if email.HTMLBody != "" {
+		data.HTML = &email.HTMLBody
+	}
 
+	_, err := m.client.SendBasicEmail(ctx, data)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -58,109 +69,111 @@ func (m *mailerooClient) SendEmail(ctx context.Context, recipientEmail, recipien
 	return nil
 }
 
-func SendNewRegistrationNotification(ctx context.Context, mc MailClient, adminEmail, adminName, username, userEmail, approvalURL string) error {
-	subject := "New Registration Request - Recipe Book"
-	content := fmt.Sprintf(`Hello %s,
-
-A new user has requested to register for the Recipe Book application.
-
-User Details:
-- Username: %s
-- Email: %s
-
-Please review and approve or deny this registration request by visiting:
-%s
-
-Best regards,
-Recipe Book System`, adminName, username, userEmail, approvalURL)
-
-	return mc.SendEmail(ctx, adminEmail, adminName, subject, content)
-}
-
-func SendRegistrationApprovedNotification(ctx context.Context, mc MailClient, userEmail, username, loginURL string) error {
-	subject := "Registration Approved - Recipe Book"
-	content := fmt.Sprintf(`Hello %s,
-
-Great news! Your registration request for the Recipe Book application has been approved.
-
-You can now log in to your account:
-%s
-
-Best regards,
-Recipe Book Team`, username, loginURL)
-
-	return mc.SendEmail(ctx, userEmail, username, subject, content)
-}
-
-func SendPasswordResetEmail(ctx context.Context, mc MailClient, userEmail, username, resetURL string) error {
-	subject := "Password Reset Request - Recipe Book"
-	content := fmt.Sprintf(`Hello %s,
-
-We received a request to reset your password for the Recipe Book application.
-
-Click the link below to set a new password:
-%s
-
-This link will expire in 24 hours.
-
-If you didn't request this, you can safely ignore this email.
-
-Best regards,
-Recipe Book`, username, resetURL)
-
-	return mc.SendEmail(ctx, userEmail, username, subject, content)
-}
-
 type loggingMailClient struct{}
 
 func NewLoggingMailClient() MailClient {
 	return &loggingMailClient{}
 }
 
-func (l *loggingMailClient) SendEmail(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-	_, span := tracer.Start(ctx, "mail.send",
-		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(
-			attribute.String("mail.recipient", recipientEmail),
-			attribute.String("mail.subject", subject),
-		),
-	)
+func (l *loggingMailClient) SendEmail(ctx context.Context, email store.OutgoingEmail) error {
+	_, span := startSendSpan(ctx, email)
 	defer span.End()
 
-	fmt.Printf("[DEV] Email not sent - To: %s <%s>, Subject: %s\n", recipientName, recipientEmail, subject)
-	fmt.Printf("[DEV] Email body:\n%s\n", plainContent)
+	fmt.Printf("[DEV] Email not sent - To: %s <%s>, Subject: %s\n", email.RecipientName, email.RecipientEmail, email.Subject)
+	fmt.Printf("[DEV] Email body:\n%s\n", email.Body)
+	if email.HTMLBody != "" {
+		fmt.Printf("[DEV] Email HTML body: %d bytes\n", len(email.HTMLBody))
+	}
 	span.SetStatus(codes.Ok, "email logged (dev mode)")
 	return nil
 }
 
-func SendExtractionSuccessNotification(ctx context.Context, mc MailClient, userEmail, username, recipeTitle, recipeURL string) error {
-	subject := fmt.Sprintf("Recipe extracted: %s", recipeTitle)
-	content := fmt.Sprintf(`Hello %s,
+// send renders an email with one of the message functions below and
+// delivers it.
+func send(ctx context.Context, mc MailClient, email *store.OutgoingEmail, err error) error {
+	if err != nil {
+		return err
+	}
+	return mc.SendEmail(ctx, *email)
+}
 
-Your recipe has been extracted and published:
-%s
+func NewRegistrationEmail(admin Recipient, username, userEmail, approvalURL string) (*store.OutgoingEmail, error) {
+	return render("registration-request", admin, map[string]any{
+		"Username":    username,
+		"UserEmail":   userEmail,
+		"ApprovalURL": approvalURL,
+	})
+}
+
+func RegistrationApprovedEmail(to Recipient, loginURL string) (*store.OutgoingEmail, error) {
+	return render("registration-approved", to, map[string]any{"LoginURL": loginURL})
+}
 
-If something doesn't look right, you can edit the recipe or leave feedback on the extraction job.
+// PasswordResetEmail is sensitive: the link lets anyone reading it reset the
+// password.
+func PasswordResetEmail(to Recipient, resetURL string) (*store.OutgoingEmail, error) {
+	email, err := render("password-reset", to, map[string]any{"ResetURL": resetURL})
+	if err != nil {
+		return nil, err
+	}
+	email.Sensitive = true
+	return email, nil
+}
 
-Best regards,
-Recipe Book`, username, recipeURL)
+func ExtractionSuccessEmail(to Recipient, recipeTitle, recipeURL string) (*store.OutgoingEmail, error) {
+	return render("extraction-completed", to, map[string]any{
+		"RecipeTitle": recipeTitle,
+		"RecipeURL":   recipeURL,
+	})
+}
 
-	return mc.SendEmail(ctx, userEmail, username, subject, content)
+func ReExtractionEmail(to Recipient, recipeTitle, reviewURL string) (*store.OutgoingEmail, error) {
+	return render("re-extraction-ready", to, map[string]any{
+		"RecipeTitle": recipeTitle,
+		"ReviewURL":   reviewURL,
+	})
 }
 
-func SendExtractionFailureNotification(ctx context.Context, mc MailClient, userEmail, username, errorMessage, jobURL string) error {
-	subject := "Recipe extraction failed"
-	content := fmt.Sprintf(`Hello %s,
+func TranslationEmail(to Recipient, recipeTitle, recipeURL string) (*store.OutgoingEmail, error) {
+	return render("translation-completed", to, map[string]any{
+		"RecipeTitle": recipeTitle,
+		"RecipeURL":   recipeURL,
+	})
+}
 
-We couldn't extract a recipe from your submission.
+func ExtractionFailureEmail(to Recipient, errorMessage, jobURL string) (*store.OutgoingEmail, error) {
+	return render("extraction-failed", to, map[string]any{
+		"ErrorMessage": errorMessage,
+		"JobURL":       jobURL,
+	})
+}
 
-Error: %s
+func SendExtractionFailureNotification(ctx context.Context, mc MailClient, to Recipient, errorMessage, jobURL string) error {
+	email, err := ExtractionFailureEmail(to, errorMessage, jobURL)
+	return send(ctx, mc, email, err)
+}
 
-You can retry or try with different input:
-%s
+func RecipeCommentEmail(to Recipient, commenterName, recipeTitle, comment, recipeURL string) (*store.OutgoingEmail, error) {
+	return render("recipe-comment", to, map[string]any{
+		"CommenterName": commenterName,
+		"RecipeTitle":   recipeTitle,
+		"Comment":       comment,
+		"RecipeURL":     recipeURL,
+	})
+}
 
-Best regards,
-Recipe Book`, username, errorMessage, jobURL)
+func SendRecipeCommentNotification(ctx context.Context, mc MailClient, to Recipient, commenterName, recipeTitle, comment, recipeURL string) error {
+	email, err := RecipeCommentEmail(to, commenterName, recipeTitle, comment, recipeURL)
+	return send(ctx, mc, email, err)
+}
 
-	return mc.SendEmail(ctx, userEmail, username, subject, content)
+// WeeklyDigestEmail lists what happened in a week. unsubscribeURL must
+// work without logging in.
+func WeeklyDigestEmail(to Recipient, content *store.DigestContent, baseURL, unsubscribeURL string) (*store.OutgoingEmail, error) {
+	return render("weekly-digest", to, map[string]any{
+		"Content":        content,
+		"BaseURL":        baseURL,
+		"SettingsURL":    baseURL + "/account/notifications",
+		"UnsubscribeURL": unsubscribeURL,
+	})
 }
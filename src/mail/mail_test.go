@@ -2,180 +2,168 @@ package mail
 
 import (
 	"context"
-	"errors"
 	"strings"
 	"testing"
 
 	"github.com/mr-flannery/go-recipe-book/src/mail/mocks"
+	"github.com/mr-flannery/go-recipe-book/src/store"
 )
 
-func TestSendNewRegistrationNotification_SendsCorrectEmailContent(t *testing.T) {
-	var capturedEmail, capturedName, capturedSubject, capturedContent string
-	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			capturedEmail = recipientEmail
-			capturedName = recipientName
-			capturedSubject = subject
-			capturedContent = plainContent
-			return nil
-		},
-	}
-
-	err := SendNewRegistrationNotification(context.Background(), mockClient, "admin@test.com", "Admin", "newuser", "new@test.com", "http://example.com/approve")
+func TestNewRegistrationEmail_ContainsRequestDetails(t *testing.T) {
+	email, err := NewRegistrationEmail(Recipient{Email: "admin@test.com", Name: "Admin"}, "newuser", "new@test.com", "http://example.com/approve")
 
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if capturedEmail != "admin@test.com" {
-		t.Errorf("expected recipient email 'admin@test.com', got '%s'", capturedEmail)
+	if email.RecipientEmail != "admin@test.com" {
+		t.Errorf("expected recipient email 'admin@test.com', got '%s'", email.RecipientEmail)
 	}
 
-	if capturedName != "Admin" {
-		t.Errorf("expected recipient name 'Admin', got '%s'", capturedName)
+	if email.RecipientName != "Admin" {
+		t.Errorf("expected recipient name 'Admin', got '%s'", email.RecipientName)
 	}
 
-	if capturedSubject != "New Registration Request - Recipe Book" {
-		t.Errorf("expected subject 'New Registration Request - Recipe Book', got '%s'", capturedSubject)
+	if email.Subject != "New Registration Request - Recipe Book" {
+		t.Errorf("expected subject 'New Registration Request - Recipe Book', got '%s'", email.Subject)
 	}
 
-	if !strings.Contains(capturedContent, "newuser") {
+	if !strings.Contains(email.Body, "newuser") {
 		t.Error("expected content to contain username 'newuser'")
 	}
 
-	if !strings.Contains(capturedContent, "new@test.com") {
+	if !strings.Contains(email.Body, "new@test.com") {
 		t.Error("expected content to contain user email 'new@test.com'")
 	}
 
-	if !strings.Contains(capturedContent, "http://example.com/approve") {
+	if !strings.Contains(email.Body, "http://example.com/approve") {
 		t.Error("expected content to contain approval URL")
 	}
 }
 
-func TestSendNewRegistrationNotification_ReturnsErrorWhenSendFails(t *testing.T) {
-	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			return errors.New("send failed")
-		},
-	}
-
-	err := SendNewRegistrationNotification(context.Background(), mockClient, "admin@test.com", "Admin", "newuser", "new@test.com", "http://example.com/approve")
-
-	if err == nil {
-		t.Error("expected error, got nil")
-	}
-}
-
-func TestSendRegistrationApprovedNotification_SendsCorrectEmailContent(t *testing.T) {
-	var capturedEmail, capturedName, capturedSubject, capturedContent string
-	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			capturedEmail = recipientEmail
-			capturedName = recipientName
-			capturedSubject = subject
-			capturedContent = plainContent
-			return nil
-		},
-	}
-
-	err := SendRegistrationApprovedNotification(context.Background(), mockClient, "user@test.com", "testuser", "http://example.com/login")
+func TestRegistrationApprovedEmail_ContainsLoginLink(t *testing.T) {
+	email, err := RegistrationApprovedEmail(Recipient{Email: "user@test.com", Name: "testuser"}, "http://example.com/login")
 
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if capturedEmail != "user@test.com" {
-		t.Errorf("expected recipient email 'user@test.com', got '%s'", capturedEmail)
-	}
-
-	if capturedName != "testuser" {
-		t.Errorf("expected recipient name 'testuser', got '%s'", capturedName)
+	if email.RecipientEmail != "user@test.com" {
+		t.Errorf("expected recipient email 'user@test.com', got '%s'", email.RecipientEmail)
 	}
 
-	if capturedSubject != "Registration Approved - Recipe Book" {
-		t.Errorf("expected subject 'Registration Approved - Recipe Book', got '%s'", capturedSubject)
+	if email.Subject != "Registration Approved - Recipe Book" {
+		t.Errorf("expected subject 'Registration Approved - Recipe Book', got '%s'", email.Subject)
 	}
 
-	if !strings.Contains(capturedContent, "testuser") {
+	if !strings.Contains(email.Body, "testuser") {
 		t.Error("expected content to contain username 'testuser'")
 	}
 
-	if !strings.Contains(capturedContent, "approved") {
+	if !strings.Contains(email.Body, "approved") {
 		t.Error("expected content to mention approval")
 	}
 
-	if !strings.Contains(capturedContent, "http://example.com/login") {
+	if !strings.Contains(email.Body, "http://example.com/login") {
 		t.Error("expected content to contain login URL")
 	}
 }
 
-func TestSendRegistrationApprovedNotification_ReturnsErrorWhenSendFails(t *testing.T) {
-	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			return errors.New("send failed")
-		},
-	}
-
-	err := SendRegistrationApprovedNotification(context.Background(), mockClient, "user@test.com", "testuser", "http://example.com/login")
-
-	if err == nil {
-		t.Error("expected error, got nil")
-	}
-}
-
-func TestSendPasswordResetEmail_SendsCorrectEmailContent(t *testing.T) {
-	var capturedEmail, capturedName, capturedSubject, capturedContent string
-	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			capturedEmail = recipientEmail
-			capturedName = recipientName
-			capturedSubject = subject
-			capturedContent = plainContent
-			return nil
-		},
-	}
-
-	err := SendPasswordResetEmail(context.Background(), mockClient, "user@test.com", "testuser", "http://example.com/reset?token=abc123")
+func TestPasswordResetEmail_IsSensitive(t *testing.T) {
+	email, err := PasswordResetEmail(Recipient{Email: "user@test.com", Name: "testuser"}, "http://example.com/reset?token=abc123")
 
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if capturedEmail != "user@test.com" {
-		t.Errorf("expected recipient email 'user@test.com', got '%s'", capturedEmail)
+	if email.RecipientEmail != "user@test.com" {
+		t.Errorf("expected recipient email 'user@test.com', got '%s'", email.RecipientEmail)
 	}
 
-	if capturedName != "testuser" {
-		t.Errorf("expected recipient name 'testuser', got '%s'", capturedName)
+	if email.Subject != "Password Reset Request - Recipe Book" {
+		t.Errorf("expected subject 'Password Reset Request - Recipe Book', got '%s'", email.Subject)
 	}
 
-	if capturedSubject != "Password Reset Request - Recipe Book" {
-		t.Errorf("expected subject 'Password Reset Request - Recipe Book', got '%s'", capturedSubject)
+	if !strings.Contains(email.Body, "http://example.com/reset?token=abc123") {
+		t.Error("expected content to contain reset URL")
 	}
 
-	if !strings.Contains(capturedContent, "testuser") {
-		t.Error("expected content to contain username 'testuser'")
+	if !strings.Contains(email.Body, "24 hours") {
+		t.Error("expected content to mention expiration time")
 	}
 
-	if !strings.Contains(capturedContent, "http://example.com/reset?token=abc123") {
-		t.Error("expected content to contain reset URL")
+	if !email.Sensitive {
+		t.Error("expected the reset email to be sensitive")
 	}
+}
 
-	if !strings.Contains(capturedContent, "24 hours") {
-		t.Error("expected content to mention expiration time")
+func TestExtractionEmails_LinkToTheResult(t *testing.T) {
+	to := Recipient{Email: "cook@test.com", Name: "cook"}
+	tests := []struct {
+		name   string
+		render func() (*store.OutgoingEmail, error)
+		link   string
+	}{
+		{"extraction", func() (*store.OutgoingEmail, error) {
+			return ExtractionSuccessEmail(to, "Goulash", "http://example.com/recipes/1")
+		}, "http://example.com/recipes/1"},
+		{"re-extraction", func() (*store.OutgoingEmail, error) {
+			return ReExtractionEmail(to, "Goulash", "http://example.com/recipes/1/re-extract/2")
+		}, "http://example.com/recipes/1/re-extract/2"},
+		{"translation", func() (*store.OutgoingEmail, error) {
+			return TranslationEmail(to, "Goulash", "http://example.com/recipes/3")
+		}, "http://example.com/recipes/3"},
+		{"failure", func() (*store.OutgoingEmail, error) {
+			return ExtractionFailureEmail(to, "The page had no recipe", "http://example.com/account/jobs/4")
+		}, "http://example.com/account/jobs/4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email, err := tt.render()
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if email.RecipientEmail != "cook@test.com" || email.Subject == "" {
+				t.Errorf("unexpected email %+v", email)
+			}
+			if !strings.Contains(email.Body, tt.link) || !strings.Contains(email.HTMLBody, tt.link) {
+				t.Errorf("expected both bodies to contain %s", tt.link)
+			}
+		})
 	}
 }
 
-func TestSendPasswordResetEmail_ReturnsErrorWhenSendFails(t *testing.T) {
+func TestSendRecipeCommentNotification_SendsCorrectEmailContent(t *testing.T) {
+	var capturedEmail, capturedSubject, capturedContent string
 	mockClient := &mocks.MockMailClient{
-		SendEmailFunc: func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
-			return errors.New("send failed")
+		SendEmailFunc: func(ctx context.Context, email store.OutgoingEmail) error {
+			capturedEmail = email.RecipientEmail
+			capturedSubject = email.Subject
+			capturedContent = email.Body
+			return nil
 		},
 	}
 
-	err := SendPasswordResetEmail(context.Background(), mockClient, "user@test.com", "testuser", "http://example.com/reset")
+	err := SendRecipeCommentNotification(context.Background(), mockClient, Recipient{Email: "author@test.com", Name: "author"}, "commenter", "Goulash", "Needs more paprika!", "http://example.com/recipes/1")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if capturedEmail != "author@test.com" {
+		t.Errorf("expected recipient email 'author@test.com', got '%s'", capturedEmail)
+	}
+
+	if capturedSubject != "New comment on Goulash" {
+		t.Errorf("expected subject 'New comment on Goulash', got '%s'", capturedSubject)
+	}
+
+	if !strings.Contains(capturedContent, "commenter") || !strings.Contains(capturedContent, "Needs more paprika!") {
+		t.Error("expected content to contain the commenter and the comment")
+	}
 
-	if err == nil {
-		t.Error("expected error, got nil")
+	if !strings.Contains(capturedContent, "http://example.com/recipes/1") {
+		t.Error("expected content to contain recipe URL")
 	}
 }
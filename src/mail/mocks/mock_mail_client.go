@@ -1,14 +1,18 @@
 package mocks
 
-import "context"
+import (
+	"context"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
 
 type MockMailClient struct {
-	SendEmailFunc func(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error
+	SendEmailFunc func(ctx context.Context, email store.OutgoingEmail) error
 }
 
-func (m *MockMailClient) SendEmail(ctx context.Context, recipientEmail, recipientName, subject, plainContent string) error {
+func (m *MockMailClient) SendEmail(ctx context.Context, email store.OutgoingEmail) error {
 	if m.SendEmailFunc != nil {
-		return m.SendEmailFunc(ctx, recipientEmail, recipientName, subject, plainContent)
+		return m.SendEmailFunc(ctx, email)
 	}
 	return nil
 }
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/jobs"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// DispatchRetryPolicy retries an email for about four hours before giving
+// up on it, so a provider outage delays emails rather than losing them.
+var DispatchRetryPolicy = jobs.RetryPolicy{
+	MaxAttempts: 8,
+	Backoff:     time.Minute,
+	MaxBackoff:  time.Hour,
+}
+
+type outboxClient struct {
+	outbox store.EmailOutboxStore
+}
+
+// NewOutboxClient returns a MailClient that adds emails to the outbox
+// instead of sending them. The Dispatcher delivers them in the background.
+func NewOutboxClient(outbox store.EmailOutboxStore) MailClient {
+	return &outboxClient{outbox: outbox}
+}
+
+func (o *outboxClient) SendEmail(ctx context.Context, email store.OutgoingEmail) error {
+	_, err := o.outbox.Add(ctx, email)
+	return err
+}
+
+type emailJob struct {
+	EmailID int `json:"email_id"`
+}
+
+// Dispatcher delivers the emails in the outbox. It handles the
+// store.EmailJobType jobs that are queued with every email.
+type Dispatcher struct {
+	outbox store.EmailOutboxStore
+	client MailClient
+}
+
+func NewDispatcher(outbox store.EmailOutboxStore, client MailClient) *Dispatcher {
+	return &Dispatcher{outbox: outbox, client: client}
+}
+
+func (d *Dispatcher) Run(ctx context.Context, job *store.Job) error {
+	var payload emailJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to decode email job: %w", err))
+	}
+
+	email, err := d.outbox.GetByID(ctx, payload.EmailID)
+	if err != nil {
+		return err
+	}
+	if email == nil || email.Status != "pending" {
+		// Deleted, or already delivered by an earlier attempt whose job
+		// didn't get to complete.
+		return nil
+	}
+
+	if err := d.client.SendEmail(ctx, store.OutgoingEmail{
+		RecipientEmail: email.RecipientEmail,
+		RecipientName:  email.RecipientName,
+		Subject:        email.Subject,
+		Body:           email.Body,
+		HTMLBody:       email.HTMLBody,
+	}); err != nil {
+		if recordErr := d.outbox.RecordFailedAttempt(ctx, email.ID, err.Error()); recordErr != nil {
+			slog.Error("Failed to record failed email attempt", "email_id", email.ID, "error", recordErr)
+		}
+		return err
+	}
+
+	return d.outbox.MarkSent(ctx, email.ID)
+}
+
+// Failed marks the email as failed once its job ran out of attempts, so it
+// shows up for a resend on the admin page.
+func (d *Dispatcher) Failed(ctx context.Context, job *store.Job, err error) {
+	var payload emailJob
+	if decodeErr := json.Unmarshal(job.Payload, &payload); decodeErr != nil {
+		return
+	}
+	slog.Warn("Giving up on email", "email_id", payload.EmailID, "job_id", job.ID, "error", err)
+	if markErr := d.outbox.MarkFailed(ctx, payload.EmailID); markErr != nil {
+		slog.Error("Failed to mark email as failed", "email_id", payload.EmailID, "error", markErr)
+	}
+}
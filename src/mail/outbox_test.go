@@ -0,0 +1,110 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/mail/mocks"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	storemocks "github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+func TestOutboxClient_AddsEmailToOutbox(t *testing.T) {
+	var added store.OutgoingEmail
+	outbox := &storemocks.MockEmailOutboxStore{
+		AddFunc: func(ctx context.Context, email store.OutgoingEmail) (int, error) {
+			added = email
+			return 1, nil
+		},
+	}
+
+	err := NewOutboxClient(outbox).SendEmail(context.Background(), store.OutgoingEmail{RecipientEmail: "user@test.com", Subject: "Hi", Body: "Hello"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if added.RecipientEmail != "user@test.com" || added.Subject != "Hi" {
+		t.Errorf("unexpected email %+v", added)
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	pending := &store.OutboxEmail{ID: 7, RecipientEmail: "user@test.com", RecipientName: "testuser", Subject: "Hi", Body: "Hello", HTMLBody: "<p>Hello</p>", Status: "pending"}
+
+	tests := []struct {
+		name        string
+		email       *store.OutboxEmail
+		sendErr     error
+		wantSent    bool
+		wantErr     bool
+		wantMarked  bool
+		wantFailure string
+	}{
+		{name: "delivers pending emails", email: pending, wantSent: true, wantMarked: true},
+		{name: "records failed attempts", email: pending, sendErr: errors.New("provider down"), wantSent: true, wantErr: true, wantFailure: "provider down"},
+		{name: "skips delivered emails", email: &store.OutboxEmail{ID: 7, Status: "sent"}},
+		{name: "skips deleted emails", email: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent, marked bool
+			var failure string
+			outbox := &storemocks.MockEmailOutboxStore{
+				GetByIDFunc: func(ctx context.Context, id int) (*store.OutboxEmail, error) {
+					if id != 7 {
+						t.Errorf("loaded email %d, want 7", id)
+					}
+					return tt.email, nil
+				},
+				MarkSentFunc: func(ctx context.Context, id int) error {
+					marked = true
+					return nil
+				},
+				RecordFailedAttemptFunc: func(ctx context.Context, id int, lastError string) error {
+					failure = lastError
+					return nil
+				},
+			}
+			client := &mocks.MockMailClient{
+				SendEmailFunc: func(ctx context.Context, email store.OutgoingEmail) error {
+					sent = true
+					if email.Body != pending.Body || email.HTMLBody != pending.HTMLBody {
+						t.Errorf("sent bodies %q and %q, want those of the outbox email", email.Body, email.HTMLBody)
+					}
+					return tt.sendErr
+				},
+			}
+
+			err := NewDispatcher(outbox, client).Run(context.Background(), &store.Job{ID: 1, Type: store.EmailJobType, Payload: []byte(`{"email_id":7}`)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+			}
+			if marked != tt.wantMarked {
+				t.Errorf("marked as sent = %v, want %v", marked, tt.wantMarked)
+			}
+			if failure != tt.wantFailure {
+				t.Errorf("recorded failure %q, want %q", failure, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func TestDispatcher_FailedMarksEmailAsFailed(t *testing.T) {
+	var failedID int
+	outbox := &storemocks.MockEmailOutboxStore{
+		MarkFailedFunc: func(ctx context.Context, id int) error {
+			failedID = id
+			return nil
+		},
+	}
+
+	NewDispatcher(outbox, &mocks.MockMailClient{}).Failed(context.Background(), &store.Job{ID: 1, Payload: []byte(`{"email_id":7}`)}, errors.New("provider down"))
+
+	if failedID != 7 {
+		t.Errorf("marked email %d as failed, want 7", failedID)
+	}
+}
@@ -0,0 +1,114 @@
+package mail
+
+import "github.com/mr-flannery/go-recipe-book/src/store"
+
+// Preview is an email type rendered with sample data, for checking how the
+// templates look without triggering the email.
+type Preview struct {
+	Name  string
+	Label string
+	build func(to Recipient) (*store.OutgoingEmail, error)
+}
+
+// Render builds the sample email for the recipient.
+func (p Preview) Render(to Recipient) (*store.OutgoingEmail, error) {
+	return p.build(to)
+}
+
+const (
+	sampleBaseURL     = "https://recipes.example.com"
+	sampleRecipeTitle = "Käsespätzle"
+)
+
+// Previews lists every email the application sends.
+var Previews = []Preview{
+	{
+		Name:  "registration-request",
+		Label: "Registration request (to admin)",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return NewRegistrationEmail(to, "newcook", "newcook@example.com", sampleBaseURL+"/admin/registrations")
+		},
+	},
+	{
+		Name:  "registration-approved",
+		Label: "Registration approved",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return RegistrationApprovedEmail(to, sampleBaseURL+"/login")
+		},
+	},
+	{
+		Name:  "password-reset",
+		Label: "Password reset",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return PasswordResetEmail(to, sampleBaseURL+"/reset-password?token=sample")
+		},
+	},
+	{
+		Name:  "extraction-completed",
+		Label: "Extraction completed",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return ExtractionSuccessEmail(to, sampleRecipeTitle, sampleBaseURL+"/recipes/42")
+		},
+	},
+	{
+		Name:  "re-extraction-ready",
+		Label: "Re-extraction ready for review",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return ReExtractionEmail(to, sampleRecipeTitle, sampleBaseURL+"/recipes/42/re-extract/7")
+		},
+	},
+	{
+		Name:  "translation-completed",
+		Label: "Translation completed",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return TranslationEmail(to, "Cheese Spaetzle", sampleBaseURL+"/recipes/43")
+		},
+	},
+	{
+		Name:  "extraction-failed",
+		Label: "Extraction failed",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return ExtractionFailureEmail(to, "The page did not contain a recipe.", sampleBaseURL+"/account/jobs/7")
+		},
+	},
+	{
+		Name:  "recipe-comment",
+		Label: "Comment on a recipe",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return RecipeCommentEmail(to, "anna", sampleRecipeTitle, "Made this yesterday.\nI used a bit more cheese <3", sampleBaseURL+"/recipes/42")
+		},
+	},
+	{
+		Name:  "weekly-digest",
+		Label: "Weekly digest",
+		build: func(to Recipient) (*store.OutgoingEmail, error) {
+			return WeeklyDigestEmail(to, sampleDigest, sampleBaseURL, sampleBaseURL+"/digest/unsubscribe?token=sample")
+		},
+	},
+}
+
+var sampleDigest = &store.DigestContent{
+	NewRecipes: []store.DigestRecipe{
+		{ID: 44, Title: "Maultaschen", AuthorName: "anna"},
+		{ID: 45, Title: "Zwiebelrostbraten", AuthorName: "ben"},
+	},
+	PopularRecipes: []store.DigestRecipe{
+		{ID: 42, Title: sampleRecipeTitle, AuthorName: "cook", CommentCount: 3},
+		{ID: 44, Title: "Maultaschen", AuthorName: "anna", CommentCount: 1},
+	},
+	ExtractionsCompleted: 4,
+	ExtractionsFailed:    1,
+	Comments: []store.DigestComment{
+		{RecipeID: 42, RecipeTitle: sampleRecipeTitle, CommenterName: "anna", Content: "Made this yesterday, delicious!"},
+	},
+}
+
+// FindPreview returns the preview called name, or false if there is none.
+func FindPreview(name string) (Preview, bool) {
+	for _, preview := range Previews {
+		if preview.Name == name {
+			return preview, true
+		}
+	}
+	return Preview{}, false
+}
@@ -0,0 +1,206 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// smtpTimeout bounds a whole delivery when the context has no deadline.
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig is the server emails are relayed through. Username and
+// Password are optional; servers like MailHog accept mail without them.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address, e.g. recipe-book@example.com.
+	From string
+}
+
+type smtpClient struct {
+	config SMTPConfig
+}
+
+// NewSMTPClient returns a MailClient that relays emails through an SMTP
+// server. The connection is upgraded with STARTTLS when the server offers
+// it.
+func NewSMTPClient(config SMTPConfig) (MailClient, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("failed to create SMTP client: no host configured")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if _, err := netmail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("failed to create SMTP client: invalid sender %q: %w", config.From, err)
+	}
+	return &smtpClient{config: config}, nil
+}
+
+func (s *smtpClient) SendEmail(ctx context.Context, email store.OutgoingEmail) error {
+	ctx, span := startSendSpan(ctx, email)
+	defer span.End()
+
+	if err := s.send(ctx, email); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	span.SetStatus(codes.Ok, "email sent")
+	return nil
+}
+
+func (s *smtpClient) send(ctx context.Context, email store.OutgoingEmail) error {
+	message, err := buildMessage(s.config.From, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+			return err
+		}
+	}
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.RecipientEmail); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage formats the email as a MIME message. Emails with an HTML
+// body become multipart/alternative with the plain text part first, so
+// clients prefer the HTML.
+func buildMessage(from string, email store.OutgoingEmail, date time.Time) ([]byte, error) {
+	sender, err := netmail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+	if sender.Name == "" {
+		sender.Name = "Recipe Book"
+	}
+	recipient := netmail.Address{Name: email.RecipientName, Address: email.RecipientEmail}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", sender.String())
+	header("To", recipient.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(sender.Address))
+	header("MIME-Version", "1.0")
+
+	if email.HTMLBody == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, email.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", `multipart/alternative; boundary="`+parts.Boundary()+`"`)
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, content string }{
+		{`text/plain; charset="utf-8"`, email.Body},
+		{`text/html; charset="utf-8"`, email.HTMLBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func messageID(senderAddress string) string {
+	domain := "localhost"
+	if at := strings.LastIndexByte(senderAddress, '@'); at >= 0 {
+		domain = senderAddress[at+1:]
+	}
+	random := make([]byte, 16)
+	rand.Read(random)
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
@@ -0,0 +1,213 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+func TestBuildMessage_MultipartAlternative(t *testing.T) {
+	email := store.OutgoingEmail{
+		RecipientEmail: "cook@example.com",
+		RecipientName:  "Jörg",
+		Subject:        "Rezept übersetzt: Käsespätzle",
+		Body:           "Hallo Jörg,\n\nplain",
+		HTMLBody:       "<p>Hallo Jörg</p>",
+	}
+
+	raw, err := buildMessage("recipe-book@example.com", email, time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	msg, err := netmail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != email.Subject {
+		t.Errorf("expected subject %q, got %q (%v)", email.Subject, subject, err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != "Jörg" || to[0].Address != "cook@example.com" {
+		t.Errorf("unexpected To header %q (%v)", msg.Header.Get("To"), err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", mediaType, err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	var types []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("failed to decode part: %v", err)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+		parts = append(parts, strings.ReplaceAll(string(content), "\r\n", "\n"))
+	}
+
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(parts))
+	}
+	if !strings.HasPrefix(types[0], "text/plain") || parts[0] != email.Body {
+		t.Errorf("expected the text part first, got %q: %q", types[0], parts[0])
+	}
+	if !strings.HasPrefix(types[1], "text/html") || parts[1] != email.HTMLBody {
+		t.Errorf("expected the HTML part second, got %q: %q", types[1], parts[1])
+	}
+}
+
+func TestBuildMessage_PlainTextOnly(t *testing.T) {
+	raw, err := buildMessage("recipe-book@example.com", store.OutgoingEmail{RecipientEmail: "cook@example.com", Subject: "Hi", Body: "Hello"}, time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	msg, err := netmail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(msg.Header.Get("Content-Type")); mediaType != "text/plain" {
+		t.Errorf("expected text/plain, got %q", mediaType)
+	}
+}
+
+func TestNewSMTPClient_ValidatesConfig(t *testing.T) {
+	if _, err := NewSMTPClient(SMTPConfig{From: "recipe-book@example.com"}); err == nil {
+		t.Error("expected an error without host")
+	}
+	if _, err := NewSMTPClient(SMTPConfig{Host: "localhost", From: "not an address"}); err == nil {
+		t.Error("expected an error for an invalid sender")
+	}
+}
+
+// fakeSMTPServer accepts one message the way MailHog does: no TLS, no
+// authentication. It returns the envelope recipient and the message.
+func fakeSMTPServer(t *testing.T) (port int, received <-chan [2]string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	ch := make(chan [2]string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		var rcpt string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); {
+			case cmd == "EHLO" || cmd == "HELO":
+				reply("250 localhost")
+			case cmd == "MAIL":
+				reply("250 OK")
+			case cmd == "RCPT":
+				rcpt = strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">")
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				ch <- [2]string{rcpt, data.String()}
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSMTPClient_SendEmail(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+
+	client, err := NewSMTPClient(SMTPConfig{Host: "127.0.0.1", Port: port, From: "recipe-book@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	email, err := PasswordResetEmail(Recipient{Email: "cook@example.com", Name: "cook"}, "http://example.com/reset")
+	if err != nil {
+		t.Fatalf("failed to render email: %v", err)
+	}
+	if err := client.SendEmail(context.Background(), *email); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got[0] != "cook@example.com" {
+			t.Errorf("expected recipient cook@example.com, got %q", got[0])
+		}
+		if !strings.Contains(got[1], "multipart/alternative") {
+			t.Errorf("expected a multipart message, got %q", got[1])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server received no message")
+	}
+}
+
+func TestSMTPClient_SendEmailFailsWhenServerIsDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	client, err := NewSMTPClient(SMTPConfig{Host: "127.0.0.1", Port: port, From: "recipe-book@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err = client.SendEmail(context.Background(), store.OutgoingEmail{RecipientEmail: "cook@example.com", Subject: "Hi", Body: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), strconv.Itoa(port)) {
+		t.Errorf("expected a connection error, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package mail
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/mr-flannery/go-recipe-book/src/models"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/utils"
+)
+
+// DefaultLanguage is the language emails are written in when the recipient's
+// language has no templates.
+const DefaultLanguage = models.LanguageEnglish
+
+// layoutFile holds the layout-text and layout-html templates of a language,
+// which wrap the text and html templates of every email.
+const layoutFile = "layout.tmpl"
+
+// Recipient is who an email goes to. Theme and Language choose the look and
+// language of the email; empty or unknown values use the defaults.
+type Recipient struct {
+	Email    string
+	Name     string
+	Theme    string
+	Language string
+}
+
+// RecipientFor returns the recipient for a user with the given preferences,
+// which may be nil. Emails follow the user's site theme and are written in
+// the language they have recipes translated to.
+func RecipientFor(email, name string, prefs *models.UserPreferences) Recipient {
+	to := Recipient{Email: email, Name: name}
+	if prefs != nil {
+		to.Theme = prefs.Theme
+		to.Language = prefs.TranslationLanguage
+	}
+	return to
+}
+
+// Theme holds the colors and font of a site theme, inlined into the HTML
+// part of emails since mail clients ignore stylesheets.
+type Theme struct {
+	Paper      htmltemplate.CSS
+	Ink        htmltemplate.CSS
+	Accent     htmltemplate.CSS
+	ButtonText htmltemplate.CSS
+	Muted      htmltemplate.CSS
+	Rule       htmltemplate.CSS
+	Font       htmltemplate.CSS
+}
+
+const (
+	serifFont = "Georgia, 'Times New Roman', serif"
+	sansFont  = "Helvetica, Arial, sans-serif"
+)
+
+var themes = map[string]Theme{
+	models.ThemeEditorial:   {Paper: "#fafaf8", Ink: "#1a1a1a", Accent: "#8b0000", ButtonText: "#ffffff", Muted: "#6b6b6b", Rule: "#d4d4d4", Font: serifFont},
+	models.ThemeNightowl:    {Paper: "#0d1b2a", Ink: "#fefae0", Accent: "#ffb703", ButtonText: "#0d1b2a", Muted: "#9ca3af", Rule: "#374151", Font: sansFont},
+	models.ThemeBistro:      {Paper: "#fdfcfa", Ink: "#1a1a1a", Accent: "#722f37", ButtonText: "#ffffff", Muted: "#6b6b6b", Rule: "#d4d4d4", Font: serifFont},
+	models.ThemeSpeakeasy:   {Paper: "#fafaf7", Ink: "#1a1a1a", Accent: "#c9a227", ButtonText: "#0d0d0d", Muted: "#888888", Rule: "#3a3a3a", Font: sansFont},
+	models.ThemePizzeriaV3:  {Paper: "#f5f7f2", Ink: "#2d3e2f", Accent: "#4a7c4e", ButtonText: "#ffffff", Muted: "#6b8c6e", Rule: "#c5d4c7", Font: sansFont},
+	models.ThemePizzeriaV4:  {Paper: "#fef6e8", Ink: "#4a3728", Accent: "#e07830", ButtonText: "#ffffff", Muted: "#9c7a5c", Rule: "#e8d4bc", Font: sansFont},
+	models.ThemePizzeriaV8:  {Paper: "#1a1a2e", Ink: "#e8e6f0", Accent: "#6c9bcf", ButtonText: "#1a1a2e", Muted: "#8a8aa0", Rule: "#2d2d44", Font: sansFont},
+	models.ThemePizzeriaV9:  {Paper: "#faf4ef", Ink: "#4a3632", Accent: "#c46a52", ButtonText: "#ffffff", Muted: "#8c7268", Rule: "#e0d2c8", Font: sansFont},
+	models.ThemePizzeriaV10: {Paper: "#fffef5", Ink: "#3a3a28", Accent: "#d4a832", ButtonText: "#3a3a28", Muted: "#7a7a5a", Rule: "#e8e4c8", Font: sansFont},
+}
+
+func themeFor(name string) Theme {
+	if theme, ok := themes[name]; ok {
+		return theme
+	}
+	return themes[models.DefaultTheme]
+}
+
+// button is the data of the layout's button template, a call to action
+// link in the theme's accent color.
+type button struct {
+	Theme Theme
+	URL   string
+	Label string
+}
+
+func newButton(theme Theme, url, label string) button {
+	return button{Theme: theme, URL: url, Label: label}
+}
+
+// The text templates parse the html blocks too, so they need the same
+// functions even though they never call them.
+var (
+	htmlFuncs = htmltemplate.FuncMap{"button": newButton}
+	textFuncs = texttemplate.FuncMap{"button": newButton}
+)
+
+// emailTemplates are the templates of one email in one language. Both are
+// parsed from the same file: the text template uses its subject and text
+// blocks, the HTML template its html block.
+type emailTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// mailTemplates holds the email templates by language and name. A language
+// is a directory in src/templates/mail with a layout.tmpl and one template
+// per email; emails missing from a language fall back to DefaultLanguage.
+var mailTemplates = mustLoadMailTemplates(filepath.Join(utils.GetBasePath(), "src", "templates", "mail"))
+
+func mustLoadMailTemplates(root string) map[string]map[string]emailTemplates {
+	loaded, err := loadMailTemplates(root)
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+func loadMailTemplates(root string) (map[string]map[string]emailTemplates, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]map[string]emailTemplates)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		language := entry.Name()
+		dir := filepath.Join(root, language)
+		files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		layout := filepath.Join(dir, layoutFile)
+		loaded[language] = make(map[string]emailTemplates)
+		for _, file := range files {
+			if filepath.Base(file) == layoutFile {
+				continue
+			}
+			name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+			text, err := texttemplate.New(name).Option("missingkey=error").Funcs(textFuncs).ParseFiles(layout, file)
+			if err != nil {
+				return nil, fmt.Errorf("mail template %s/%s: %w", language, name, err)
+			}
+			html, err := htmltemplate.New(name).Option("missingkey=error").Funcs(htmlFuncs).ParseFiles(layout, file)
+			if err != nil {
+				return nil, fmt.Errorf("mail template %s/%s: %w", language, name, err)
+			}
+			loaded[language][name] = emailTemplates{text: text, html: html}
+		}
+	}
+
+	if len(loaded[DefaultLanguage]) == 0 {
+		return nil, fmt.Errorf("no mail templates for %s in %s", DefaultLanguage, root)
+	}
+	return loaded, nil
+}
+
+// Languages lists the languages emails can be written in.
+func Languages() []string {
+	var languages []string
+	for language := range mailTemplates {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// render builds the email called name for the recipient. The templates get
+// data together with the recipient's Name, Theme and Language.
+func render(name string, to Recipient, data map[string]any) (*store.OutgoingEmail, error) {
+	language := to.Language
+	tmpl, ok := mailTemplates[language][name]
+	if !ok {
+		language = DefaultLanguage
+		tmpl, ok = mailTemplates[language][name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	values := map[string]any{
+		"Name":     to.Name,
+		"Theme":    themeFor(to.Theme),
+		"Language": language,
+	}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	var subject, text, html strings.Builder
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s email: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "layout-text", values); err != nil {
+		return nil, fmt.Errorf("failed to render text of %s email: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout-html", values); err != nil {
+		return nil, fmt.Errorf("failed to render HTML of %s email: %w", name, err)
+	}
+
+	return &store.OutgoingEmail{
+		RecipientEmail: to.Email,
+		RecipientName:  to.Name,
+		Subject:        strings.TrimSpace(subject.String()),
+		Body:           strings.TrimSpace(text.String()),
+		HTMLBody:       html.String(),
+	}, nil
+}
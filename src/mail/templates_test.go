@@ -0,0 +1,92 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/models"
+)
+
+func TestPreviews_RenderInEveryLanguageAndTheme(t *testing.T) {
+	if len(Languages()) < 2 {
+		t.Fatalf("expected templates for several languages, got %v", Languages())
+	}
+
+	for _, preview := range Previews {
+		for _, language := range Languages() {
+			for theme := range themes {
+				email, err := preview.Render(Recipient{Email: "cook@example.com", Name: "cook", Theme: theme, Language: language})
+				if err != nil {
+					t.Fatalf("%s in %s/%s: %v", preview.Name, language, theme, err)
+				}
+				if email.Subject == "" || email.Body == "" || email.HTMLBody == "" {
+					t.Errorf("%s in %s/%s: expected subject, text and HTML, got %+v", preview.Name, language, theme, email)
+				}
+				if !strings.Contains(email.HTMLBody, string(themes[theme].Accent)) {
+					t.Errorf("%s in %s/%s: expected the theme's accent color in the HTML", preview.Name, language, theme)
+				}
+			}
+		}
+	}
+}
+
+func TestEveryTemplateHasAPreview(t *testing.T) {
+	for language, emails := range mailTemplates {
+		for name := range emails {
+			if _, ok := FindPreview(name); !ok {
+				t.Errorf("template %s/%s has no preview", language, name)
+			}
+		}
+	}
+}
+
+func TestRender_UsesRecipientLanguage(t *testing.T) {
+	email, err := PasswordResetEmail(Recipient{Email: "cook@example.com", Name: "cook", Language: models.LanguageGerman}, "http://example.com/reset")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(email.Body, "Hallo cook,") {
+		t.Errorf("expected a German greeting, got %q", email.Body)
+	}
+	if email.Subject != "Passwort zurücksetzen - Recipe Book" {
+		t.Errorf("unexpected subject %q", email.Subject)
+	}
+}
+
+func TestRender_FallsBackToDefaults(t *testing.T) {
+	email, err := PasswordResetEmail(Recipient{Email: "cook@example.com", Name: "cook", Theme: "unknown", Language: "fr"}, "http://example.com/reset")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(email.Body, "Hello cook,") {
+		t.Errorf("expected an English greeting, got %q", email.Body)
+	}
+	if !strings.Contains(email.HTMLBody, string(themes[models.DefaultTheme].Accent)) {
+		t.Error("expected the default theme's accent color in the HTML")
+	}
+}
+
+func TestRender_EscapesHTMLButNotText(t *testing.T) {
+	email, err := RecipeCommentEmail(Recipient{Email: "cook@example.com", Name: "cook"}, "anna", "Pie", "<script>alert(1)</script>", "http://example.com/recipes/1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(email.HTMLBody, "<script>") {
+		t.Error("expected the comment to be escaped in the HTML")
+	}
+	if !strings.Contains(email.Body, "<script>alert(1)</script>") {
+		t.Error("expected the comment as is in the text")
+	}
+}
+
+func TestRecipientFor(t *testing.T) {
+	to := RecipientFor("cook@example.com", "cook", &models.UserPreferences{Theme: models.ThemeBistro, TranslationLanguage: models.LanguageGerman})
+	if to.Theme != models.ThemeBistro || to.Language != models.LanguageGerman {
+		t.Errorf("expected theme and language from the preferences, got %+v", to)
+	}
+
+	to = RecipientFor("cook@example.com", "cook", nil)
+	if to.Theme != "" || to.Language != "" || to.Email != "cook@example.com" {
+		t.Errorf("expected defaults without preferences, got %+v", to)
+	}
+}
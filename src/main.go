@@ -2,21 +2,29 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/honeycombio/otel-config-go/otelconfig"
 	"github.com/mr-flannery/go-recipe-book/src/auth"
 	"github.com/mr-flannery/go-recipe-book/src/config"
 	"github.com/mr-flannery/go-recipe-book/src/db"
+	"github.com/mr-flannery/go-recipe-book/src/digest"
 	"github.com/mr-flannery/go-recipe-book/src/extraction"
 	"github.com/mr-flannery/go-recipe-book/src/handlers"
+	"github.com/mr-flannery/go-recipe-book/src/jobs"
 	"github.com/mr-flannery/go-recipe-book/src/logging"
 	"github.com/mr-flannery/go-recipe-book/src/mail"
 	"github.com/mr-flannery/go-recipe-book/src/middleware"
+	"github.com/mr-flannery/go-recipe-book/src/notifications"
+	"github.com/mr-flannery/go-recipe-book/src/store"
 	"github.com/mr-flannery/go-recipe-book/src/store/postgres"
 	"github.com/mr-flannery/go-recipe-book/src/templates"
 	"github.com/mr-flannery/go-recipe-book/src/utils"
@@ -25,6 +33,16 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+const (
+	// completedJobRetention is how long completed background jobs are kept
+	// for the admin queue page before they are deleted.
+	completedJobRetention = 14 * 24 * time.Hour
+	// sentEmailRetention is how long delivered emails stay in the outbox.
+	sentEmailRetention = 30 * 24 * time.Hour
+	// digestInterval is how often due weekly digests are looked for.
+	digestInterval = 15 * time.Minute
+)
+
 func main() {
 	ctx := context.Background()
 
@@ -67,6 +85,9 @@ func main() {
 	defer db.ClosePool()
 
 	authStore := postgres.NewAuthStore(database)
+	extractionCacheStore := postgres.NewExtractionCacheStore(database)
+	jobStore := postgres.NewJobStore(database)
+	emailOutboxStore := postgres.NewEmailOutboxStore(database)
 
 	slog.Info("Creating seed admin account...")
 	err = auth.CreateSeedAdmin(context.Background(), authStore, config.DB.Admin.Username, config.DB.Admin.Email, config.DB.Admin.Password)
@@ -84,6 +105,15 @@ func main() {
 			if err := auth.CleanupExpiredPasswordResetTokens(context.Background(), authStore); err != nil {
 				slog.Error("Failed to cleanup expired password reset tokens", "error", err)
 			}
+			if _, err := extractionCacheStore.DeleteExpired(context.Background()); err != nil {
+				slog.Error("Failed to cleanup expired extraction cache entries", "error", err)
+			}
+			if _, err := jobStore.DeleteCompletedBefore(context.Background(), time.Now().Add(-completedJobRetention)); err != nil {
+				slog.Error("Failed to cleanup completed jobs", "error", err)
+			}
+			if _, err := emailOutboxStore.DeleteSentBefore(context.Background(), time.Now().Add(-sentEmailRetention)); err != nil {
+				slog.Error("Failed to cleanup sent emails", "error", err)
+			}
 		}
 	}()
 
@@ -97,13 +127,35 @@ func main() {
 	apiKeyStore := postgres.NewAPIKeyStore(database)
 	extractionJobStore := postgres.NewExtractionJobStore(database)
 	extractionFeedbackStore := postgres.NewExtractionFeedbackStore(database)
+	notificationStore := postgres.NewNotificationStore(database)
 	renderer := templates.NewRenderer(templates.Templates)
 
 	var mailClient mail.MailClient
-	if config.Environment.Mode == "development" {
+	switch {
+	case config.Mail.Provider == "smtp":
+		slog.Info("Using SMTP mail client", "host", config.Mail.SMTP.Host, "port", config.Mail.SMTP.Port)
+		// Local stand-ins like MailHog accept any sender, so the domain
+		// may be left unset in development.
+		domain := config.Mail.Domain
+		if domain == "" {
+			domain = "localhost"
+		}
+		var err error
+		mailClient, err = mail.NewSMTPClient(mail.SMTPConfig{
+			Host:     config.Mail.SMTP.Host,
+			Port:     config.Mail.SMTP.Port,
+			Username: config.Mail.SMTP.Username,
+			Password: config.Mail.SMTP.Password,
+			From:     "recipe-book@" + domain,
+		})
+		if err != nil {
+			slog.Error("Failed to create mail client", "error", err)
+			panic(err)
+		}
+	case config.Environment.Mode == "development":
 		slog.Info("Using logging mail client (dev mode)")
 		mailClient = mail.NewLoggingMailClient()
-	} else {
+	default:
 		var err error
 		mailClient, err = mail.NewMailClient(config.Mail.ApiKey, config.Mail.Domain)
 		if err != nil {
@@ -112,6 +164,16 @@ func main() {
 		}
 	}
 
+	// Emails are only written to the outbox while handling requests; the
+	// email jobs deliver them with mailClient and retry them if that fails.
+	outboxMailClient := mail.NewOutboxClient(emailOutboxStore)
+	jobRunner := jobs.NewRunner(jobs.Config{}, jobStore)
+	jobRunner.Register(store.EmailJobType, mail.NewDispatcher(emailOutboxStore, mailClient), mail.DispatchRetryPolicy)
+	jobRunner.Start()
+	defer jobRunner.Stop()
+
+	notifier := notifications.NewNotifier(notificationStore, outboxMailClient)
+
 	var apiEncryptionKey []byte
 	if config.Api.EncryptionKey != "" {
 		apiEncryptionKey = []byte(config.Api.EncryptionKey)
@@ -126,24 +188,67 @@ func main() {
 		baseURL = "http://localhost:8080"
 	}
 
-	h := handlers.NewHandler(database, recipeStore, tagStore, userTagStore, commentStore, userStore, authStore, ingredientStore, userPreferencesStore, apiKeyStore, extractionJobStore, extractionFeedbackStore, renderer, mailClient, apiEncryptionKey, baseURL)
+	// A random key would break the unsubscribe links in every digest sent
+	// before a restart, so it is only good enough for development.
+	unsubscribeKey := []byte(config.Mail.UnsubscribeKey)
+	if len(unsubscribeKey) == 0 {
+		if config.Environment.Mode != "development" {
+			slog.Error("MAIL_UNSUBSCRIBE_KEY must be set outside development mode")
+			panic("MAIL_UNSUBSCRIBE_KEY must be set")
+		}
+		slog.Warn("MAIL_UNSUBSCRIBE_KEY is not set, using a random key; unsubscribe links in digests stop working after a restart")
+		unsubscribeKey = make([]byte, 32)
+		if _, err := rand.Read(unsubscribeKey); err != nil {
+			panic(err)
+		}
+	}
+	unsubscribeSigner := digest.NewSigner(unsubscribeKey)
+
+	digestCtx, stopDigests := context.WithCancel(ctx)
+	defer stopDigests()
+	go digest.NewSender(postgres.NewDigestStore(database), unsubscribeSigner, utils.GetAppBaseURL()).Run(digestCtx, digestInterval)
+
+	// A single listener per process fans job status changes out to the open
+	// status pages, whichever process changed the job.
+	jobEvents := extraction.NewJobEventBroker()
+	listenerCtx, stopListener := context.WithCancel(ctx)
+	defer stopListener()
+	go func() {
+		if err := postgres.ListenExtractionJobEvents(listenerCtx, db.ConnectionString(), jobEvents.Publish); err != nil {
+			slog.Error("Failed to listen for extraction job events, status pages will only refresh periodically", "error", err)
+		}
+	}()
 
-	if config.Extraction.OpenRouterAPIKey != "" {
+	h := handlers.NewHandler(database, recipeStore, tagStore, userTagStore, commentStore, userStore, authStore, ingredientStore, userPreferencesStore, apiKeyStore, extractionJobStore, extractionFeedbackStore, extractionCacheStore, jobStore, emailOutboxStore, jobEvents, notificationStore, notifier, renderer, outboxMailClient, unsubscribeSigner, apiEncryptionKey, baseURL)
+
+	if config.Extraction.OpenRouterAPIKey == "" {
+		slog.Warn("OPENROUTER_API_KEY not set, extraction worker disabled")
+	} else if config.Extraction.DisableInProcessWorker {
+		slog.Info("In-process extraction worker disabled, jobs are processed by cmd/worker")
+	} else {
+		concurrency := config.Extraction.Concurrency
+		if concurrency <= 0 {
+			concurrency = 2
+		}
 		workerConfig := extraction.WorkerConfig{
-			Concurrency:      2,
+			Concurrency:      concurrency,
 			PollInterval:     5 * time.Second,
+			MaxJobsPerUser:   config.Extraction.MaxJobsPerUser,
 			OpenRouterAPIKey: config.Extraction.OpenRouterAPIKey,
 			BaseURL:          baseURL,
+			Experiment: extraction.Experiment{
+				Control:        extraction.Variant{PromptVersion: config.Extraction.PromptVersion, Model: config.Extraction.Model},
+				Candidate:      extraction.Variant{PromptVersion: config.Extraction.Experiment.PromptVersion, Model: config.Extraction.Experiment.Model},
+				CandidateShare: config.Extraction.Experiment.Share,
+			},
 		}
-		extractionWorker := extraction.NewWorker(workerConfig, extractionJobStore, recipeStore, tagStore, authStore, mailClient)
+		extractionWorker := extraction.NewWorker(workerConfig, extractionJobStore, extractionCacheStore, recipeStore, tagStore, authStore, userPreferencesStore, notifier)
 		extractionWorker.Start()
 		defer extractionWorker.Stop()
 		slog.Info("Extraction worker started")
-	} else {
-		slog.Warn("OPENROUTER_API_KEY not set, extraction worker disabled")
 	}
 
-	userContext := auth.UserContextMiddleware(authStore, userPreferencesStore)
+	userContext := auth.UserContextMiddleware(authStore, userPreferencesStore, notificationStore)
 	requireAuth := auth.RequireAuth()
 	requireAPIKey := auth.RequireAPIKey(apiKeyStore, authStore)
 
@@ -181,6 +286,8 @@ func main() {
 	mux.Handle("POST /forgot-password", userContext(http.HandlerFunc(h.PostForgotPasswordHandler)))
 	mux.Handle("GET /reset-password", userContext(http.HandlerFunc(h.GetResetPasswordHandler)))
 	mux.Handle("POST /reset-password", userContext(http.HandlerFunc(h.PostResetPasswordHandler)))
+	mux.Handle("GET /digest/unsubscribe", userContext(http.HandlerFunc(h.GetDigestUnsubscribeHandler)))
+	mux.Handle("POST /digest/unsubscribe", userContext(http.HandlerFunc(h.PostDigestUnsubscribeHandler)))
 
 	mux.Handle("GET /account",
 		userContext(
@@ -222,10 +329,22 @@ func main() {
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.SetThemeHandler))))
+	mux.Handle("GET /account/translation",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetTranslationSettingsHandler))))
+	mux.Handle("POST /account/translation",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.SetTranslationLanguageHandler))))
 	mux.Handle("GET /account/jobs",
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.GetAccountJobsHandler))))
+	mux.Handle("GET /account/jobs/ws",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetJobStatusSSEHandler))))
 	mux.Handle("GET /account/jobs/{id}",
 		userContext(
 			requireAuth(
@@ -238,10 +357,47 @@ func main() {
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.PostJobRetryHandler))))
+	mux.Handle("POST /account/jobs/{id}/cancel",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostJobCancelHandler))))
 	mux.Handle("GET /account/jobs/{id}/ws",
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.GetJobStatusSSEHandler))))
+	mux.Handle("GET /account/batches/{id}",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetBatchHandler))))
+	mux.Handle("GET /account/batches/{id}/progress",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetBatchProgressHandler))))
+
+	mux.Handle("GET /notifications",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetNotificationsHandler))))
+	mux.Handle("POST /notifications/{id}/read",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostNotificationReadHandler))))
+	mux.Handle("POST /notifications/read-all",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostNotificationsReadAllHandler))))
+	mux.Handle("GET /account/notifications",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetNotificationSettingsHandler))))
+	mux.Handle("POST /account/notifications",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostNotificationSettingsHandler))))
+	mux.Handle("POST /account/notifications/digest",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostDigestSettingsHandler))))
 
 	mux.Handle("GET /extract",
 		userContext(
@@ -259,6 +415,18 @@ func main() {
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.PostExtractImageHandler))))
+	mux.Handle("POST /extract/pdf",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostExtractPDFHandler))))
+	mux.Handle("POST /extract/text",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostExtractTextHandler))))
+	mux.Handle("POST /extract/batch",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostExtractBatchHandler))))
 
 	requireAdminAuth := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -306,11 +474,66 @@ func main() {
 			requireAuth(
 				requireAdminAuth(
 					http.HandlerFunc(h.GetAdminJobsHandler)))))
+	mux.Handle("POST /admin/users/{id}/extraction-quota",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.PostAdminExtractionQuotaHandler)))))
 	mux.Handle("GET /admin/feedback",
 		userContext(
 			requireAuth(
 				requireAdminAuth(
 					http.HandlerFunc(h.GetAdminFeedbackHandler)))))
+	mux.Handle("GET /admin/corrections",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminCorrectionsHandler)))))
+	mux.Handle("GET /admin/corrections/export",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminCorrectionsExportHandler)))))
+	mux.Handle("GET /admin/queue",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminQueueHandler)))))
+	mux.Handle("POST /admin/queue/{id}/retry",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.PostAdminQueueRetryHandler)))))
+	mux.Handle("GET /admin/outbox",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminOutboxHandler)))))
+	mux.Handle("POST /admin/outbox/{id}/resend",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.PostAdminOutboxResendHandler)))))
+	mux.Handle("GET /admin/mail/preview",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminMailPreviewHandler)))))
+	mux.Handle("GET /admin/tagging",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.GetAdminTaggingHandler)))))
+	mux.Handle("POST /admin/tagging",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.PostAdminTaggingHandler)))))
+	mux.Handle("POST /admin/tagging/apply",
+		userContext(
+			requireAuth(
+				requireAdminAuth(
+					http.HandlerFunc(h.PostAdminTaggingApplyHandler)))))
 
 	mux.Handle("GET /recipes/create",
 		userContext(
@@ -332,6 +555,30 @@ func main() {
 		userContext(
 			requireAuth(
 				http.HandlerFunc(h.DeleteRecipeHandler))))
+	mux.Handle("POST /recipes/{id}/fork",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.ForkRecipeHandler))))
+	mux.Handle("GET /recipes/{id}/re-extract",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetRecipeReExtractHandler))))
+	mux.Handle("POST /recipes/{id}/re-extract",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostRecipeReExtractHandler))))
+	mux.Handle("GET /recipes/{id}/re-extract/{jobId}",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.GetRecipeReExtractReviewHandler))))
+	mux.Handle("POST /recipes/{id}/re-extract/{jobId}",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostRecipeReExtractApplyHandler))))
+	mux.Handle("POST /recipes/{id}/translate",
+		userContext(
+			requireAuth(
+				http.HandlerFunc(h.PostRecipeTranslateHandler))))
 	mux.Handle("GET /recipes",
 		userContext(
 			http.HandlerFunc(h.ListRecipesHandler)))
@@ -395,6 +642,9 @@ func main() {
 	mux.Handle("POST /api/recipe/upload",
 		requireAPIKey(
 			http.HandlerFunc(h.APICreateRecipeHandler)))
+	mux.Handle("POST /api/extract/text",
+		requireAPIKey(
+			http.HandlerFunc(h.APIExtractTextHandler)))
 	mux.Handle("GET /api/ingredients/search",
 		userContext(
 			requireAuth(
@@ -404,8 +654,81 @@ func main() {
 			requireAuth(
 				http.HandlerFunc(h.APISearchRecipesHandler))))
 
+	mux.Handle("GET /api/v1/recipes",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1ListRecipesHandler)))
+	mux.Handle("POST /api/v1/recipes",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1CreateRecipeHandler)))
+	mux.Handle("GET /api/v1/recipes/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1GetRecipeHandler)))
+	mux.Handle("PUT /api/v1/recipes/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1UpdateRecipeHandler)))
+	mux.Handle("PATCH /api/v1/recipes/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1UpdateRecipeHandler)))
+	mux.Handle("DELETE /api/v1/recipes/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1DeleteRecipeHandler)))
+	mux.Handle("GET /api/v1/tags",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1SearchTagsHandler)))
+	mux.Handle("POST /api/v1/recipes/{id}/tags",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1AddRecipeTagHandler)))
+	mux.Handle("DELETE /api/v1/recipes/{id}/tags/{tagId}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1RemoveRecipeTagHandler)))
+	mux.Handle("GET /api/v1/user-tags",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1ListUserTagsHandler)))
+	mux.Handle("POST /api/v1/recipes/{id}/user-tags",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1AddUserTagHandler)))
+	mux.Handle("DELETE /api/v1/user-tags/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1RemoveUserTagHandler)))
+	mux.Handle("GET /api/v1/recipes/{id}/comments",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1ListCommentsHandler)))
+	mux.Handle("POST /api/v1/recipes/{id}/comments",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1CreateCommentHandler)))
+	mux.Handle("PUT /api/v1/comments/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1UpdateCommentHandler)))
+	mux.Handle("DELETE /api/v1/comments/{id}",
+		requireAPIKey(
+			http.HandlerFunc(h.APIv1DeleteCommentHandler)))
+
 	slog.Info("Ready to serve!")
 
 	handler := otelhttp.NewHandler(middleware.WideEventMiddleware(middleware.Gzip(mux)), "recipe-book")
-	slog.Error("Server failed to start", "error", http.ListenAndServe(addr, handler))
+	server := &http.Server{Addr: addr, Handler: handler}
+
+	// Shut down gracefully on SIGINT/SIGTERM so the deferred cleanup runs,
+	// in particular so the extraction worker can finish or hand back its jobs.
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-signalCtx.Done()
+		slog.Info("Shutting down server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shut down server", "error", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server failed to start", "error", err)
+		return
+	}
+	// ListenAndServe returns as soon as Shutdown starts; wait for in-flight
+	// requests to finish before the deferred cleanup closes what they use.
+	<-shutdownDone
 }
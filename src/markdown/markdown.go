@@ -65,6 +65,16 @@ func processIngredients(source string) string {
 	})
 }
 
+// IngredientReferences returns the names of the ingredients that source
+// references with @ingredient{name|quantity}, in order.
+func IngredientReferences(source string) []string {
+	var names []string
+	for _, match := range ingredientRegex.FindAllStringSubmatch(source, -1) {
+		names = append(names, strings.TrimSpace(match[1]))
+	}
+	return names
+}
+
 var nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
 
 func slugify(s string) string {
@@ -251,3 +251,15 @@ func TestProcessIngredients_HandlesEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestIngredientReferences(t *testing.T) {
+	source := "- @ingredient{flour|250 g}\n- @ingredient{ milk |500 ml}\n\nMix with [[Pancakes]]."
+	got := IngredientReferences(source)
+	want := []string{"flour", "milk"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("IngredientReferences() = %q, want %q", got, want)
+	}
+	if got := IngredientReferences("- 250 g flour"); len(got) != 0 {
+		t.Errorf("IngredientReferences() = %q, want none", got)
+	}
+}
@@ -31,6 +31,8 @@ type Recipe struct {
 	AuthorID       int
 	Image          []byte
 	ParentID       *int
+	Language       string // one of Languages, or "" if unknown
+	TranslationOf  *int
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	Tags           []Tag
@@ -100,6 +102,23 @@ type Tag struct {
 	Name string
 }
 
+// TagSuggestion is a set of tags the LLM suggested for a recipe, waiting for
+// an admin to apply or dismiss it.
+type TagSuggestion struct {
+	ID          int
+	JobID       *int
+	RecipeID    int
+	RecipeTitle string
+	Tags        []string
+	CreatedAt   time.Time
+}
+
+const (
+	TagSuggestionPending   = "pending"
+	TagSuggestionApplied   = "applied"
+	TagSuggestionDismissed = "dismissed"
+)
+
 type UserTag struct {
 	ID       int
 	UserID   int
@@ -152,11 +171,62 @@ type RecipeSearchResult struct {
 	Title string
 }
 
+// RecipeTranslation is a recipe in one language among an original recipe and
+// its translations.
+type RecipeTranslation struct {
+	ID       int
+	Title    string
+	Language string
+	// IsOriginal is set for the recipe the others were translated from.
+	IsOriginal bool
+}
+
+// Language is a language recipes can be written in and translated to.
+type Language struct {
+	Code string
+	Name string
+}
+
+const (
+	LanguageEnglish = "en"
+	LanguageGerman  = "de"
+)
+
+// Languages lists the supported recipe languages.
+var Languages = []Language{
+	{Code: LanguageEnglish, Name: "English"},
+	{Code: LanguageGerman, Name: "German"},
+}
+
+// LanguageName returns the name of the language with code, or "" if it isn't
+// one of Languages.
+func LanguageName(code string) string {
+	for _, language := range Languages {
+		if language.Code == code {
+			return language.Name
+		}
+	}
+	return ""
+}
+
+// IsLanguage reports whether code is one of Languages.
+func IsLanguage(code string) bool {
+	return LanguageName(code) != ""
+}
+
 type UserPreferences struct {
 	UserID   int
 	PageSize int
 	ViewMode string
 	Theme    string
+	// TranslationLanguage is the language extracted recipes in other
+	// languages are translated to, or "" to keep them as they are.
+	TranslationLanguage string
+	// DigestEnabled subscribes the user to the weekly digest email, sent
+	// on DigestDay at DigestHour o'clock server time.
+	DigestEnabled bool
+	DigestDay     time.Weekday
+	DigestHour    int
 }
 
 const (
@@ -175,3 +245,8 @@ const (
 	ThemePizzeriaV10 = "pizzeria-v10"
 	DefaultTheme     = ThemeEditorial
 )
+
+const (
+	DefaultDigestDay  = time.Sunday
+	DefaultDigestHour = 8
+)
@@ -0,0 +1,91 @@
+// Package notifications records events in the users' notification center and
+// emails them to users who asked for it.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+const (
+	TypeExtractionCompleted  = "extraction_completed"
+	TypeExtractionFailed     = "extraction_failed"
+	TypeRegistrationApproved = "registration_approved"
+	TypeRecipeComment        = "recipe_comment"
+)
+
+// EventType is a kind of notification users can choose to also get emails
+// for.
+type EventType struct {
+	Type           string
+	Label          string
+	EmailByDefault bool
+}
+
+// EventTypes lists the configurable notification types in the order they are
+// shown in the settings. The defaults keep the emails that were sent before
+// the notification center existed.
+var EventTypes = []EventType{
+	{Type: TypeExtractionCompleted, Label: "A recipe extraction finished", EmailByDefault: true},
+	{Type: TypeExtractionFailed, Label: "A recipe extraction failed", EmailByDefault: true},
+	{Type: TypeRecipeComment, Label: "Someone commented on one of my recipes", EmailByDefault: false},
+	{Type: TypeRegistrationApproved, Label: "My registration was approved", EmailByDefault: true},
+}
+
+// EmailByDefault reports whether users get emails for the notification type
+// unless they turned them off.
+func EmailByDefault(notificationType string) bool {
+	for _, eventType := range EventTypes {
+		if eventType.Type == notificationType {
+			return eventType.EmailByDefault
+		}
+	}
+	return false
+}
+
+// EmailFunc sends the email version of a notification.
+type EmailFunc func(ctx context.Context, mc mail.MailClient) error
+
+type Notifier struct {
+	store      store.NotificationStore
+	mailClient mail.MailClient
+}
+
+func NewNotifier(notificationStore store.NotificationStore, mailClient mail.MailClient) *Notifier {
+	return &Notifier{store: notificationStore, mailClient: mailClient}
+}
+
+// Notify adds the notification to the user's notification center and, if the
+// user wants emails for its type, calls email. A failure of one doesn't keep
+// the other from happening; both errors are returned.
+func (n *Notifier) Notify(ctx context.Context, notification store.Notification, email EmailFunc) error {
+	var errs []error
+	if _, err := n.store.Create(ctx, notification); err != nil {
+		errs = append(errs, err)
+	}
+
+	if email != nil && n.WantsEmail(ctx, notification.UserID, notification.Type) {
+		if err := email(ctx, n.mailClient); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send notification email: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WantsEmail reports whether the user gets emails for the notification type,
+// falling back to the type's default if the preference can't be read.
+func (n *Notifier) WantsEmail(ctx context.Context, userID int, notificationType string) bool {
+	preferences, err := n.store.GetEmailPreferences(ctx, userID)
+	if err != nil {
+		return EmailByDefault(notificationType)
+	}
+	if email, ok := preferences[notificationType]; ok {
+		return email
+	}
+	return EmailByDefault(notificationType)
+}
@@ -0,0 +1,79 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/mail"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/store/mocks"
+)
+
+func TestNotifier_Notify_EmailsAccordingToPreferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		typ         string
+		preferences map[string]bool
+		wantEmail   bool
+	}{
+		{"default on", TypeExtractionCompleted, nil, true},
+		{"default off", TypeRecipeComment, nil, false},
+		{"turned off", TypeExtractionCompleted, map[string]bool{TypeExtractionCompleted: false}, false},
+		{"turned on", TypeRecipeComment, map[string]bool{TypeRecipeComment: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []store.Notification
+			notificationStore := &mocks.MockNotificationStore{
+				CreateFunc: func(_ context.Context, n store.Notification) (int, error) {
+					created = append(created, n)
+					return 1, nil
+				},
+				GetEmailPreferencesFunc: func(_ context.Context, _ int) (map[string]bool, error) {
+					return tt.preferences, nil
+				},
+			}
+			notifier := NewNotifier(notificationStore, nil)
+
+			emailed := false
+			err := notifier.Notify(context.Background(), store.Notification{UserID: 1, Type: tt.typ, Title: "Hello"}, func(context.Context, mail.MailClient) error {
+				emailed = true
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(created) != 1 {
+				t.Errorf("expected the notification to be stored, got %d", len(created))
+			}
+			if emailed != tt.wantEmail {
+				t.Errorf("emailed = %v, want %v", emailed, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestNotifier_Notify_EmailsEvenIfStoringFails(t *testing.T) {
+	notificationStore := &mocks.MockNotificationStore{
+		CreateFunc: func(context.Context, store.Notification) (int, error) {
+			return 0, errors.New("database unavailable")
+		},
+	}
+	notifier := NewNotifier(notificationStore, nil)
+
+	emailed := false
+	err := notifier.Notify(context.Background(), store.Notification{UserID: 1, Type: TypeExtractionFailed}, func(context.Context, mail.MailClient) error {
+		emailed = true
+		return nil
+	})
+
+	if err == nil {
+		t.Error("expected the store error to be returned")
+	}
+	if !emailed {
+		t.Error("expected the email to be sent anyway")
+	}
+}
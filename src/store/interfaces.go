@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/mr-flannery/go-recipe-book/src/models"
@@ -10,6 +11,8 @@ import (
 type RecipeStore interface {
 	Save(ctx context.Context, recipe models.Recipe) (int, error)
 	GetByID(ctx context.Context, id string) (models.Recipe, error)
+	// GetByIDs returns the recipes that exist, by ID, without their images.
+	GetByIDs(ctx context.Context, ids []int) (map[int]models.Recipe, error)
 	Update(ctx context.Context, recipe models.Recipe) error
 	Delete(ctx context.Context, id string) error
 	GetAll(ctx context.Context) ([]models.Recipe, error)
@@ -17,16 +20,32 @@ type RecipeStore interface {
 	CountFiltered(ctx context.Context, params models.FilterParams) (int, error)
 	GetRandomID(ctx context.Context) (int, error)
 	SearchByTitle(ctx context.Context, query string, limit int) ([]models.RecipeSearchResult, error)
+	GetTranslations(ctx context.Context, originalID int) ([]models.RecipeTranslation, error)
+	// GetIDs returns the IDs of all recipes, or of those without tags.
+	GetIDs(ctx context.Context, untaggedOnly bool) ([]int, error)
 }
 
 type TagStore interface {
 	GetOrCreate(ctx context.Context, name string) (models.Tag, error)
+	// Search returns up to 20 tags containing query, the shortest first, so
+	// the tag itself and its plural come before longer tags containing it.
 	Search(ctx context.Context, query string) ([]models.Tag, error)
 	GetByRecipeID(ctx context.Context, recipeID int) ([]models.Tag, error)
 	GetForRecipes(ctx context.Context, recipeIDs []int) (map[int][]models.Tag, error)
 	AddToRecipe(ctx context.Context, recipeID, tagID int) error
 	RemoveFromRecipe(ctx context.Context, recipeID, tagID int) error
 	SetRecipeTags(ctx context.Context, recipeID int, tagNames []string) error
+	// GetMostUsed returns up to limit tags, the ones on most recipes first.
+	// Tags no recipe uses yet come last.
+	GetMostUsed(ctx context.Context, limit int) ([]models.Tag, error)
+	// SaveSuggestion stores suggested tags for a recipe, replacing a
+	// suggestion for it that wasn't reviewed yet.
+	SaveSuggestion(ctx context.Context, jobID, recipeID int, tags []string) error
+	// GetPendingSuggestions returns the suggestions that weren't reviewed
+	// yet, oldest first.
+	GetPendingSuggestions(ctx context.Context) ([]models.TagSuggestion, error)
+	// ResolveSuggestions marks pending suggestions as applied or dismissed.
+	ResolveSuggestions(ctx context.Context, ids []int, status string) error
 }
 
 type UserTagStore interface {
@@ -106,12 +125,15 @@ type AuthStore interface {
 	GetActiveSessionCount(ctx context.Context, userID int) (int, error)
 	ExtendSession(ctx context.Context, sessionID string) error
 
-	CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string) error
+	// CreateRegistrationRequest, ApproveRegistration and
+	// CreatePasswordResetToken add notification, if not nil, to the email
+	// outbox in the same transaction as the change it announces.
+	CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string, notification *OutgoingEmail) error
 	GetPendingRegistrations(ctx context.Context) ([]RegistrationRequest, error)
 	GetAllRegistrations(ctx context.Context) ([]RegistrationRequest, error)
 	GetAllRegistrationsPaginated(ctx context.Context, limit, offset int) ([]RegistrationRequest, error)
 	CountAllRegistrations(ctx context.Context) (int, error)
-	ApproveRegistration(ctx context.Context, requestID, adminID int) error
+	ApproveRegistration(ctx context.Context, requestID, adminID int, notification *OutgoingEmail) error
 	RejectRegistration(ctx context.Context, requestID, adminID int) error
 
 	CreateUser(ctx context.Context, username, email, passwordHash string, isAdmin bool) error
@@ -120,7 +142,7 @@ type AuthStore interface {
 	GetAllUsers(ctx context.Context) ([]AuthUser, error)
 	DeleteUser(ctx context.Context, userID int) error
 
-	CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *OutgoingEmail) error
 	GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
 	MarkPasswordResetTokenUsed(ctx context.Context, tokenHash string) error
 	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
@@ -133,6 +155,8 @@ type UserPreferencesStore interface {
 	SetPageSize(ctx context.Context, userID, pageSize int) error
 	SetViewMode(ctx context.Context, userID int, viewMode string) error
 	SetTheme(ctx context.Context, userID int, theme string) error
+	SetTranslationLanguage(ctx context.Context, userID int, language string) error
+	SetDigest(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error
 }
 
 type PasswordResetToken struct {
@@ -166,7 +190,7 @@ type ExtractionJob struct {
 	Username     string
 	JobType      string
 	InputURL     *string
-	InputData    []byte
+	InputCount   int
 	Status       string
 	ErrorMessage *string
 	LLMInput     *string
@@ -174,10 +198,131 @@ type ExtractionJob struct {
 	RecipeID     *int
 	RecipeTitle  *string
 	AttemptCount int
+	Usage        ExtractionUsage
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	CompletedAt  *time.Time
 	RetryAfter   *time.Time
+
+	// TargetRecipeID is set for jobs that re-extract an existing recipe.
+	// Instead of creating a recipe, they store the result in ProposedRecipe
+	// for the author to review.
+	TargetRecipeID *int
+	Model          *string
+	ProposedRecipe []byte
+
+	// PromptVersion is the prompt version the job was extracted with. Jobs
+	// answered from the cache get the prompt version and model of the
+	// extraction that filled it.
+	PromptVersion *string
+
+	// ExtractedRecipe is the recipe as it was extracted, before the user
+	// edited it. Comparing the two shows what the extraction got wrong.
+	ExtractedRecipe []byte
+
+	// TargetLanguage is set for translation jobs, which translate the recipe
+	// TargetRecipeID into this language and save the result as a new recipe.
+	TargetLanguage *string
+
+	// BypassCache is set for links the user chose to extract again although
+	// they were extracted before. The job neither uses nor replaces the
+	// cached result.
+	BypassCache bool
+}
+
+// ReapedJob is a processing job whose worker stopped sending heartbeats. It
+// was either put back into the queue or, after too many attempts, failed.
+type ReapedJob struct {
+	ID           int
+	UserID       int
+	JobType      string
+	WorkerID     *string
+	Status       string
+	ErrorMessage *string
+}
+
+// ExtractionJobEvent announces that a job was created or changed status.
+// Events are published by the database on every status change, whichever
+// process made it.
+type ExtractionJobEvent struct {
+	JobID  int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Status string `json:"status"`
+}
+
+var (
+	// ErrJobNotClaimed is returned by Heartbeat when the job is no longer
+	// processing on the given worker, e.g. because it was reaped.
+	ErrJobNotClaimed = errors.New("job is not claimed by this worker")
+	// ErrJobCancelled is returned by Heartbeat when the user cancelled the job.
+	ErrJobCancelled = errors.New("job was cancelled")
+)
+
+// ExtractionUsage is the LLM token usage and cost reported by OpenRouter,
+// summed over all attempts of a job (or all jobs of a user).
+type ExtractionUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	AudioTokens      int
+	CostUSD          float64
+}
+
+// ExtractionQuota limits how many jobs a user may submit per calendar month
+// and how much they may cost. A nil limit means unlimited.
+type ExtractionQuota struct {
+	MonthlyJobLimit     *int
+	MonthlyCostLimitUSD *float64
+}
+
+type UserExtractionUsage struct {
+	UserID   int
+	Username string
+	JobCount int
+	Usage    ExtractionUsage
+	Quota    ExtractionQuota
+}
+
+// ExtractionJobInput is one uploaded file belonging to an extraction job,
+// e.g. a single page of a multi-image job or the PDF of a pdf job.
+type ExtractionJobInput struct {
+	Position    int
+	ContentType string
+	Data        []byte
+}
+
+// ExtractionBatch groups the jobs created from one uploaded URL list or
+// bookmarks export. The counts are aggregated from the batch's jobs.
+type ExtractionBatch struct {
+	ID           int
+	UserID       int
+	Name         string
+	SkippedCount int
+	CreatedAt    time.Time
+	Total        int
+	Pending      int
+	Processing   int
+	Completed    int
+	Failed       int
+	Cancelled    int
+}
+
+// DonePercent is the share of the batch's jobs that finished, whether they
+// completed, failed or were cancelled.
+func (b ExtractionBatch) DonePercent() int {
+	if b.Total == 0 {
+		return 0
+	}
+	return (b.Completed + b.Failed + b.Cancelled) * 100 / b.Total
+}
+
+// ExtractionBatchJob is one job to create as part of a batch. Jobs are not
+// claimed before NotBefore, which is used to spread requests to the same
+// domain over time.
+type ExtractionBatchJob struct {
+	JobType   string
+	InputURL  string
+	Priority  int
+	NotBefore time.Time
 }
 
 type ExtractionFeedback struct {
@@ -192,25 +337,297 @@ type ExtractionFeedback struct {
 }
 
 type ExtractionJobStore interface {
-	Create(ctx context.Context, userID int, jobType string, inputURL *string, inputData []byte) (int, error)
+	Create(ctx context.Context, userID int, jobType string, inputURL *string, inputs []ExtractionJobInput) (int, error)
+	// CreateForced queues a job for inputURL that bypasses the extraction
+	// cache.
+	CreateForced(ctx context.Context, userID int, jobType, inputURL string) (int, error)
+	CreateReExtraction(ctx context.Context, userID, recipeID int, jobType, inputURL string, model *string) (int, error)
+	CreateTranslation(ctx context.Context, userID, recipeID int, language string) (int, error)
+	// CreateTagging queues a tagging job per recipe, which suggests tags for
+	// an admin to review.
+	CreateTagging(ctx context.Context, userID int, recipeIDs []int, priority int) error
 	GetByID(ctx context.Context, id int) (*ExtractionJob, error)
+	GetInputs(ctx context.Context, jobID int) ([]ExtractionJobInput, error)
 	GetByUserID(ctx context.Context, userID int, limit, offset int) ([]ExtractionJob, error)
 	CountByUserID(ctx context.Context, userID int) (int, error)
+	// GetSubmittedURLs returns the input URLs of the user's jobs that are
+	// still queued or running, or that produced a recipe the user still has.
+	GetSubmittedURLs(ctx context.Context, userID int) ([]string, error)
+	// GetScheduledJobs returns the pending URL jobs of all users that may not
+	// start before after, with the latest NotBefore per URL, so new batches can
+	// be spaced after them.
+	GetScheduledJobs(ctx context.Context, after time.Time) ([]ExtractionBatchJob, error)
 	GetAll(ctx context.Context, limit, offset int) ([]ExtractionJob, error)
 	CountAll(ctx context.Context) (int, error)
-	ClaimPendingJob(ctx context.Context) (*ExtractionJob, error)
+	ClaimPendingJob(ctx context.Context, workerID string, maxPerUser int) (*ExtractionJob, error)
+	Heartbeat(ctx context.Context, id int, workerID string) error
+	ReleaseJob(ctx context.Context, id int, workerID string) error
+	Cancel(ctx context.Context, id int) (bool, error)
+	ReapStaleJobs(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]ReapedJob, error)
 	UpdateStatus(ctx context.Context, id int, status string, errorMessage *string) error
 	UpdateLLMData(ctx context.Context, id int, llmInput, llmOutput string) error
 	SetRecipeID(ctx context.Context, id int, recipeID int) error
-	MarkCompleted(ctx context.Context, id int) error
+	SetProposedRecipe(ctx context.Context, id int, proposal []byte) error
+	// MarkProposalApplied records that changes from the job's proposed recipe
+	// were applied to its target recipe.
+	MarkProposalApplied(ctx context.Context, id int) error
+	SetVariant(ctx context.Context, id int, promptVersion, model string) error
+	SetExtractedRecipe(ctx context.Context, id int, recipe []byte) error
+	// GetWithExtractedRecipe returns the newest jobs whose recipe still
+	// exists and has no applied re-extraction proposal, so their recipe only
+	// differs from the extracted one by the author's edits.
+	GetWithExtractedRecipe(ctx context.Context, limit int) ([]ExtractionJob, error)
+	// MarkCompleted adds notification, if not nil, to the email outbox in the
+	// same transaction, unless the job is no longer processing.
+	MarkCompleted(ctx context.Context, id int, notification *OutgoingEmail) error
 	IncrementAttemptCount(ctx context.Context, id int) error
 	ResetForRetry(ctx context.Context, id int) error
 	ScheduleRetry(ctx context.Context, id int, retryAfter time.Time) error
+	AddUsage(ctx context.Context, id int, usage ExtractionUsage) error
+	GetUsageSince(ctx context.Context, userID int, since time.Time) (*UserExtractionUsage, error)
+	// GetAllUsageSince returns the usage of every user, including users
+	// without jobs so that they can be given a quota up front.
+	GetAllUsageSince(ctx context.Context, since time.Time) ([]UserExtractionUsage, error)
+	SetQuota(ctx context.Context, userID int, quota ExtractionQuota) error
+	CreateBatch(ctx context.Context, userID int, name string, skippedCount int, jobs []ExtractionBatchJob) (int, error)
+	GetBatch(ctx context.Context, id int) (*ExtractionBatch, error)
+	GetBatchesByUserID(ctx context.Context, userID int, limit int) ([]ExtractionBatch, error)
+	GetBatchJobs(ctx context.Context, batchID int) ([]ExtractionJob, error)
+}
+
+// ExtractionCacheEntry is a previous extraction result, keyed by normalised
+// URL or by a hash of the uploaded files. RecipeID is the recipe that was
+// created from it, if it still exists. PromptVersion and Model are what the
+// result was extracted with; they are empty for older entries.
+type ExtractionCacheEntry struct {
+	Key           string
+	Result        []byte
+	RecipeID      *int
+	PromptVersion string
+	Model         string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+type ExtractionCacheStore interface {
+	Get(ctx context.Context, key string) (*ExtractionCacheEntry, error)
+	Put(ctx context.Context, key string, result []byte, recipeID int, promptVersion, model string, ttl time.Duration) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// ExtractionFeedbackSummary aggregates the feedback on jobs extracted with
+// one prompt version and model. Both are empty for jobs from before they
+// were recorded.
+type ExtractionFeedbackSummary struct {
+	PromptVersion string
+	Model         string
+	Count         int
+	AverageRating float64
+	// TypeCounts counts the feedback by feedback type.
+	TypeCounts map[string]int
 }
 
 type ExtractionFeedbackStore interface {
 	Create(ctx context.Context, jobID, userID int, rating int, feedbackType string, comment *string) error
 	GetByJobID(ctx context.Context, jobID int) (*ExtractionFeedback, error)
+	// GetByJobIDs returns the feedback on the jobs that have any, by job ID.
+	GetByJobIDs(ctx context.Context, jobIDs []int) (map[int]*ExtractionFeedback, error)
 	GetAll(ctx context.Context, limit, offset int) ([]ExtractionFeedback, error)
 	CountAll(ctx context.Context) (int, error)
+	SummarizeByVariant(ctx context.Context) ([]ExtractionFeedbackSummary, error)
+}
+
+// Notification is an entry in a user's notification center. Link points to
+// the page the notification is about.
+type Notification struct {
+	ID        int
+	UserID    int
+	Type      string
+	Title     string
+	Body      string
+	Link      string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+type NotificationStore interface {
+	Create(ctx context.Context, notification Notification) (int, error)
+	GetByUserID(ctx context.Context, userID int, limit, offset int) ([]Notification, error)
+	CountByUserID(ctx context.Context, userID int) (int, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	// MarkRead marks the user's notification as read and returns it, or nil
+	// if the user has no such notification.
+	MarkRead(ctx context.Context, userID, id int) (*Notification, error)
+	MarkAllRead(ctx context.Context, userID int) error
+	// GetEmailPreferences returns the notification types the user explicitly
+	// turned email on or off for.
+	GetEmailPreferences(ctx context.Context, userID int) (map[string]bool, error)
+	SetEmailPreference(ctx context.Context, userID int, notificationType string, email bool) error
+}
+
+// Job is a background job in the generic job queue. Payload is the job's
+// JSON-encoded input, decoded by the handler registered for its Type.
+type Job struct {
+	ID           int
+	Type         string
+	Payload      []byte
+	Status       string
+	AttemptCount int
+	LastError    *string
+	RetryAfter   *time.Time
+	WorkerID     *string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// JobCount is the number of jobs of one type in one status.
+type JobCount struct {
+	Type   string
+	Status string
+	Count  int
+}
+
+// JobStore is the generic job queue. Jobs are claimed by type, so a process
+// only picks up the jobs it has handlers for.
+type JobStore interface {
+	// Enqueue adds a job that runs once runAfter has passed, or right away
+	// if runAfter is zero.
+	Enqueue(ctx context.Context, jobType string, payload []byte, runAfter time.Time) (int, error)
+	GetByID(ctx context.Context, id int) (*Job, error)
+	// List returns the most recently updated jobs, only those in status
+	// unless it is empty.
+	List(ctx context.Context, status string, limit, offset int) ([]Job, error)
+	Count(ctx context.Context, status string) (int, error)
+	CountByTypeAndStatus(ctx context.Context) ([]JobCount, error)
+	// Claim marks the next due job of one of jobTypes as processing on
+	// workerID and counts the attempt. It returns nil if there is none.
+	Claim(ctx context.Context, workerID string, jobTypes []string) (*Job, error)
+	Heartbeat(ctx context.Context, id int, workerID string) error
+	Complete(ctx context.Context, id int) error
+	// Retry puts a processing job back into the queue to run again once
+	// retryAfter has passed.
+	Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error
+	Fail(ctx context.Context, id int, lastError string) error
+	Release(ctx context.Context, id int, workerID string) error
+	ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]Job, error)
+	// RetryNow queues a failed or waiting job to run right away with a
+	// fresh set of attempts and reports whether it did.
+	RetryNow(ctx context.Context, id int) (bool, error)
+	DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EmailJobType is the job type that delivers an email from the outbox.
+const EmailJobType = "email"
+
+// OutgoingEmail is an email to be added to the outbox. Body is the plain
+// text part; HTMLBody is the optional HTML alternative. Sensitive emails
+// carry a secret, such as a password reset link: the outbox keeps their
+// body only until they are delivered or given up on, and never resends them.
+type OutgoingEmail struct {
+	RecipientEmail string
+	RecipientName  string
+	Subject        string
+	Body           string
+	HTMLBody       string
+	Sensitive      bool
+}
+
+// OutboxEmail is an email in the outbox. Status is pending until it was
+// delivered (sent) or ran out of attempts (failed).
+type OutboxEmail struct {
+	ID             int
+	RecipientEmail string
+	RecipientName  string
+	Subject        string
+	Body           string
+	HTMLBody       string
+	Sensitive      bool
+	Status         string
+	AttemptCount   int
+	LastError      *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	SentAt         *time.Time
+}
+
+// EmailOutboxStore holds outgoing emails until they are delivered. Every
+// email added to it gets an EmailJobType job that delivers it.
+type EmailOutboxStore interface {
+	Add(ctx context.Context, email OutgoingEmail) (int, error)
+	GetByID(ctx context.Context, id int) (*OutboxEmail, error)
+	// List returns the most recent emails, only those in status unless it
+	// is empty.
+	List(ctx context.Context, status string, limit, offset int) ([]OutboxEmail, error)
+	Count(ctx context.Context, status string) (int, error)
+	MarkSent(ctx context.Context, id int) error
+	// RecordFailedAttempt counts a delivery attempt that failed with
+	// lastError; MarkFailed gives up on the email.
+	RecordFailedAttempt(ctx context.Context, id int, lastError string) error
+	MarkFailed(ctx context.Context, id int) error
+	// Resend queues the email for delivery again and reports whether it
+	// did. Pending emails already have a delivery job and sensitive emails
+	// are never resent.
+	Resend(ctx context.Context, id int) (bool, error)
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DigestSubscriber is a user who gets the weekly digest, with what is needed
+// to schedule and address it.
+type DigestSubscriber struct {
+	UserID   int
+	Username string
+	Email    string
+	Day      time.Weekday
+	Hour     int
+	Theme    string
+	Language string
+}
+
+// DigestRecipe is a recipe listed in a digest. CommentCount counts the
+// comments of the digest's week.
+type DigestRecipe struct {
+	ID           int
+	Title        string
+	AuthorName   string
+	CommentCount int
+}
+
+// DigestComment is a comment on one of the digest recipient's recipes.
+type DigestComment struct {
+	RecipeID      int
+	RecipeTitle   string
+	CommenterName string
+	Content       string
+	CreatedAt     time.Time
+}
+
+// DigestContent is what happened in a digest's week.
+type DigestContent struct {
+	// NewRecipes were added by other users.
+	NewRecipes []DigestRecipe
+	// PopularRecipes got the most comments.
+	PopularRecipes []DigestRecipe
+	// ExtractionsCompleted and ExtractionsFailed count the recipient's
+	// extraction jobs that finished.
+	ExtractionsCompleted int
+	ExtractionsFailed    int
+	Comments             []DigestComment
+}
+
+// Empty reports whether nothing happened that is worth an email.
+func (c *DigestContent) Empty() bool {
+	return len(c.NewRecipes) == 0 && len(c.PopularRecipes) == 0 && len(c.Comments) == 0 &&
+		c.ExtractionsCompleted == 0 && c.ExtractionsFailed == 0
+}
+
+type DigestStore interface {
+	// ListUnsent returns the subscribers whose digest for the week starting
+	// on week hasn't been handled yet.
+	ListUnsent(ctx context.Context, week time.Time) ([]DigestSubscriber, error)
+	GetContent(ctx context.Context, userID int, since, until time.Time) (*DigestContent, error)
+	// Record marks the user's digest for week as handled and queues email
+	// with it, unless it is nil. It reports false without queueing anything
+	// if the week was handled before.
+	Record(ctx context.Context, userID int, week time.Time, email *OutgoingEmail) (bool, error)
 }
@@ -20,18 +20,18 @@ type MockAuthStore struct {
 	DeleteUserSessionsFunc               func(ctx context.Context, userID int) error
 	GetActiveSessionCountFunc            func(ctx context.Context, userID int) (int, error)
 	ExtendSessionFunc                    func(ctx context.Context, sessionID string) error
-	CreateRegistrationRequestFunc        func(ctx context.Context, username, email, passwordHash string) error
+	CreateRegistrationRequestFunc        func(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error
 	GetPendingRegistrationsFunc          func(ctx context.Context) ([]store.RegistrationRequest, error)
 	GetAllRegistrationsFunc              func(ctx context.Context) ([]store.RegistrationRequest, error)
 	GetAllRegistrationsPaginatedFunc     func(ctx context.Context, limit, offset int) ([]store.RegistrationRequest, error)
 	CountAllRegistrationsFunc            func(ctx context.Context) (int, error)
-	ApproveRegistrationFunc              func(ctx context.Context, requestID, adminID int) error
+	ApproveRegistrationFunc              func(ctx context.Context, requestID, adminID int, notification *store.OutgoingEmail) error
 	RejectRegistrationFunc               func(ctx context.Context, requestID, adminID int) error
 	CreateUserFunc                       func(ctx context.Context, username, email, passwordHash string, isAdmin bool) error
 	UserExistsFunc                       func(ctx context.Context, username string) (bool, error)
 	GetAllUsersFunc                      func(ctx context.Context) ([]store.AuthUser, error)
 	DeleteUserFunc                       func(ctx context.Context, userID int) error
-	CreatePasswordResetTokenFunc         func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	CreatePasswordResetTokenFunc         func(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error
 	GetPasswordResetTokenFunc            func(ctx context.Context, tokenHash string) (*store.PasswordResetToken, error)
 	MarkPasswordResetTokenUsedFunc       func(ctx context.Context, tokenHash string) error
 	DeleteExpiredPasswordResetTokensFunc func(ctx context.Context) (int64, error)
@@ -123,9 +123,9 @@ func (m *MockAuthStore) ExtendSession(ctx context.Context, sessionID string) err
 	return nil
 }
 
-func (m *MockAuthStore) CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string) error {
+func (m *MockAuthStore) CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
 	if m.CreateRegistrationRequestFunc != nil {
-		return m.CreateRegistrationRequestFunc(ctx, username, email, passwordHash)
+		return m.CreateRegistrationRequestFunc(ctx, username, email, passwordHash, notification)
 	}
 	return nil
 }
@@ -158,9 +158,9 @@ func (m *MockAuthStore) CountAllRegistrations(ctx context.Context) (int, error)
 	return 0, nil
 }
 
-func (m *MockAuthStore) ApproveRegistration(ctx context.Context, requestID, adminID int) error {
+func (m *MockAuthStore) ApproveRegistration(ctx context.Context, requestID, adminID int, notification *store.OutgoingEmail) error {
 	if m.ApproveRegistrationFunc != nil {
-		return m.ApproveRegistrationFunc(ctx, requestID, adminID)
+		return m.ApproveRegistrationFunc(ctx, requestID, adminID, notification)
 	}
 	return nil
 }
@@ -200,9 +200,9 @@ func (m *MockAuthStore) DeleteUser(ctx context.Context, userID int) error {
 	return nil
 }
 
-func (m *MockAuthStore) CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+func (m *MockAuthStore) CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
 	if m.CreatePasswordResetTokenFunc != nil {
-		return m.CreatePasswordResetTokenFunc(ctx, userID, tokenHash, expiresAt)
+		return m.CreatePasswordResetTokenFunc(ctx, userID, tokenHash, expiresAt, notification)
 	}
 	return nil
 }
@@ -2,21 +2,25 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/mr-flannery/go-recipe-book/src/models"
 	"github.com/mr-flannery/go-recipe-book/src/store"
 )
 
 type MockRecipeStore struct {
-	SaveFunc          func(ctx context.Context, recipe models.Recipe) (int, error)
-	GetByIDFunc       func(ctx context.Context, id string) (models.Recipe, error)
-	UpdateFunc        func(ctx context.Context, recipe models.Recipe) error
-	DeleteFunc        func(ctx context.Context, id string) error
-	GetAllFunc        func(ctx context.Context) ([]models.Recipe, error)
-	GetFilteredFunc   func(ctx context.Context, params models.FilterParams) ([]models.Recipe, error)
-	CountFilteredFunc func(ctx context.Context, params models.FilterParams) (int, error)
-	GetRandomIDFunc   func(ctx context.Context) (int, error)
-	SearchByTitleFunc func(ctx context.Context, query string, limit int) ([]models.RecipeSearchResult, error)
+	SaveFunc            func(ctx context.Context, recipe models.Recipe) (int, error)
+	GetByIDFunc         func(ctx context.Context, id string) (models.Recipe, error)
+	GetByIDsFunc        func(ctx context.Context, ids []int) (map[int]models.Recipe, error)
+	UpdateFunc          func(ctx context.Context, recipe models.Recipe) error
+	DeleteFunc          func(ctx context.Context, id string) error
+	GetAllFunc          func(ctx context.Context) ([]models.Recipe, error)
+	GetFilteredFunc     func(ctx context.Context, params models.FilterParams) ([]models.Recipe, error)
+	CountFilteredFunc   func(ctx context.Context, params models.FilterParams) (int, error)
+	GetRandomIDFunc     func(ctx context.Context) (int, error)
+	SearchByTitleFunc   func(ctx context.Context, query string, limit int) ([]models.RecipeSearchResult, error)
+	GetTranslationsFunc func(ctx context.Context, originalID int) ([]models.RecipeTranslation, error)
+	GetIDsFunc          func(ctx context.Context, untaggedOnly bool) ([]int, error)
 }
 
 func (m *MockRecipeStore) Save(ctx context.Context, recipe models.Recipe) (int, error) {
@@ -33,6 +37,13 @@ func (m *MockRecipeStore) GetByID(ctx context.Context, id string) (models.Recipe
 	return models.Recipe{}, nil
 }
 
+func (m *MockRecipeStore) GetByIDs(ctx context.Context, ids []int) (map[int]models.Recipe, error) {
+	if m.GetByIDsFunc != nil {
+		return m.GetByIDsFunc(ctx, ids)
+	}
+	return map[int]models.Recipe{}, nil
+}
+
 func (m *MockRecipeStore) Update(ctx context.Context, recipe models.Recipe) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, recipe)
@@ -82,14 +93,32 @@ func (m *MockRecipeStore) SearchByTitle(ctx context.Context, query string, limit
 	return nil, nil
 }
 
+func (m *MockRecipeStore) GetTranslations(ctx context.Context, originalID int) ([]models.RecipeTranslation, error) {
+	if m.GetTranslationsFunc != nil {
+		return m.GetTranslationsFunc(ctx, originalID)
+	}
+	return nil, nil
+}
+
+func (m *MockRecipeStore) GetIDs(ctx context.Context, untaggedOnly bool) ([]int, error) {
+	if m.GetIDsFunc != nil {
+		return m.GetIDsFunc(ctx, untaggedOnly)
+	}
+	return nil, nil
+}
+
 type MockTagStore struct {
-	GetOrCreateFunc      func(ctx context.Context, name string) (models.Tag, error)
-	SearchFunc           func(ctx context.Context, query string) ([]models.Tag, error)
-	GetByRecipeIDFunc    func(ctx context.Context, recipeID int) ([]models.Tag, error)
-	GetForRecipesFunc    func(ctx context.Context, recipeIDs []int) (map[int][]models.Tag, error)
-	AddToRecipeFunc      func(ctx context.Context, recipeID, tagID int) error
-	RemoveFromRecipeFunc func(ctx context.Context, recipeID, tagID int) error
-	SetRecipeTagsFunc    func(ctx context.Context, recipeID int, tagNames []string) error
+	GetOrCreateFunc           func(ctx context.Context, name string) (models.Tag, error)
+	SearchFunc                func(ctx context.Context, query string) ([]models.Tag, error)
+	GetByRecipeIDFunc         func(ctx context.Context, recipeID int) ([]models.Tag, error)
+	GetForRecipesFunc         func(ctx context.Context, recipeIDs []int) (map[int][]models.Tag, error)
+	AddToRecipeFunc           func(ctx context.Context, recipeID, tagID int) error
+	RemoveFromRecipeFunc      func(ctx context.Context, recipeID, tagID int) error
+	SetRecipeTagsFunc         func(ctx context.Context, recipeID int, tagNames []string) error
+	GetMostUsedFunc           func(ctx context.Context, limit int) ([]models.Tag, error)
+	SaveSuggestionFunc        func(ctx context.Context, jobID, recipeID int, tags []string) error
+	GetPendingSuggestionsFunc func(ctx context.Context) ([]models.TagSuggestion, error)
+	ResolveSuggestionsFunc    func(ctx context.Context, ids []int, status string) error
 }
 
 func (m *MockTagStore) GetOrCreate(ctx context.Context, name string) (models.Tag, error) {
@@ -141,6 +170,34 @@ func (m *MockTagStore) SetRecipeTags(ctx context.Context, recipeID int, tagNames
 	return nil
 }
 
+func (m *MockTagStore) GetMostUsed(ctx context.Context, limit int) ([]models.Tag, error) {
+	if m.GetMostUsedFunc != nil {
+		return m.GetMostUsedFunc(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockTagStore) SaveSuggestion(ctx context.Context, jobID, recipeID int, tags []string) error {
+	if m.SaveSuggestionFunc != nil {
+		return m.SaveSuggestionFunc(ctx, jobID, recipeID, tags)
+	}
+	return nil
+}
+
+func (m *MockTagStore) GetPendingSuggestions(ctx context.Context) ([]models.TagSuggestion, error) {
+	if m.GetPendingSuggestionsFunc != nil {
+		return m.GetPendingSuggestionsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockTagStore) ResolveSuggestions(ctx context.Context, ids []int, status string) error {
+	if m.ResolveSuggestionsFunc != nil {
+		return m.ResolveSuggestionsFunc(ctx, ids, status)
+	}
+	return nil
+}
+
 type MockUserTagStore struct {
 	GetOrCreateFunc   func(ctx context.Context, userID, recipeID int, name string) (models.UserTag, error)
 	SearchFunc        func(ctx context.Context, userID int, query string) ([]string, error)
@@ -282,10 +339,12 @@ func (m *MockIngredientStore) GetOrCreate(ctx context.Context, name string) (int
 }
 
 type MockUserPreferencesStore struct {
-	GetFunc         func(ctx context.Context, userID int) (*models.UserPreferences, error)
-	SetPageSizeFunc func(ctx context.Context, userID, pageSize int) error
-	SetViewModeFunc func(ctx context.Context, userID int, viewMode string) error
-	SetThemeFunc    func(ctx context.Context, userID int, theme string) error
+	GetFunc                    func(ctx context.Context, userID int) (*models.UserPreferences, error)
+	SetPageSizeFunc            func(ctx context.Context, userID, pageSize int) error
+	SetViewModeFunc            func(ctx context.Context, userID int, viewMode string) error
+	SetThemeFunc               func(ctx context.Context, userID int, theme string) error
+	SetTranslationLanguageFunc func(ctx context.Context, userID int, language string) error
+	SetDigestFunc              func(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error
 }
 
 func (m *MockUserPreferencesStore) Get(ctx context.Context, userID int) (*models.UserPreferences, error) {
@@ -316,6 +375,20 @@ func (m *MockUserPreferencesStore) SetTheme(ctx context.Context, userID int, the
 	return nil
 }
 
+func (m *MockUserPreferencesStore) SetTranslationLanguage(ctx context.Context, userID int, language string) error {
+	if m.SetTranslationLanguageFunc != nil {
+		return m.SetTranslationLanguageFunc(ctx, userID, language)
+	}
+	return nil
+}
+
+func (m *MockUserPreferencesStore) SetDigest(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error {
+	if m.SetDigestFunc != nil {
+		return m.SetDigestFunc(ctx, userID, enabled, day, hour)
+	}
+	return nil
+}
+
 type MockAPIKeyStore struct {
 	CreateFunc         func(ctx context.Context, userID int, name string, keyHash string, keyPrefix string, encryptedKey string) (int, error)
 	GetByKeyHashFunc   func(ctx context.Context, keyHash string) (*store.APIKey, error)
@@ -358,3 +431,287 @@ func (m *MockAPIKeyStore) UpdateLastUsed(ctx context.Context, keyID int) error {
 	}
 	return nil
 }
+
+type MockNotificationStore struct {
+	CreateFunc              func(ctx context.Context, notification store.Notification) (int, error)
+	GetByUserIDFunc         func(ctx context.Context, userID int, limit, offset int) ([]store.Notification, error)
+	CountByUserIDFunc       func(ctx context.Context, userID int) (int, error)
+	CountUnreadFunc         func(ctx context.Context, userID int) (int, error)
+	MarkReadFunc            func(ctx context.Context, userID, id int) (*store.Notification, error)
+	MarkAllReadFunc         func(ctx context.Context, userID int) error
+	GetEmailPreferencesFunc func(ctx context.Context, userID int) (map[string]bool, error)
+	SetEmailPreferenceFunc  func(ctx context.Context, userID int, notificationType string, email bool) error
+}
+
+func (m *MockNotificationStore) Create(ctx context.Context, notification store.Notification) (int, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, notification)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationStore) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]store.Notification, error) {
+	if m.GetByUserIDFunc != nil {
+		return m.GetByUserIDFunc(ctx, userID, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockNotificationStore) CountByUserID(ctx context.Context, userID int) (int, error) {
+	if m.CountByUserIDFunc != nil {
+		return m.CountByUserIDFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationStore) CountUnread(ctx context.Context, userID int) (int, error) {
+	if m.CountUnreadFunc != nil {
+		return m.CountUnreadFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationStore) MarkRead(ctx context.Context, userID, id int) (*store.Notification, error) {
+	if m.MarkReadFunc != nil {
+		return m.MarkReadFunc(ctx, userID, id)
+	}
+	return nil, nil
+}
+
+func (m *MockNotificationStore) MarkAllRead(ctx context.Context, userID int) error {
+	if m.MarkAllReadFunc != nil {
+		return m.MarkAllReadFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MockNotificationStore) GetEmailPreferences(ctx context.Context, userID int) (map[string]bool, error) {
+	if m.GetEmailPreferencesFunc != nil {
+		return m.GetEmailPreferencesFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockNotificationStore) SetEmailPreference(ctx context.Context, userID int, notificationType string, email bool) error {
+	if m.SetEmailPreferenceFunc != nil {
+		return m.SetEmailPreferenceFunc(ctx, userID, notificationType, email)
+	}
+	return nil
+}
+
+type MockJobStore struct {
+	EnqueueFunc               func(ctx context.Context, jobType string, payload []byte, runAfter time.Time) (int, error)
+	GetByIDFunc               func(ctx context.Context, id int) (*store.Job, error)
+	ListFunc                  func(ctx context.Context, status string, limit, offset int) ([]store.Job, error)
+	CountFunc                 func(ctx context.Context, status string) (int, error)
+	CountByTypeAndStatusFunc  func(ctx context.Context) ([]store.JobCount, error)
+	ClaimFunc                 func(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error)
+	HeartbeatFunc             func(ctx context.Context, id int, workerID string) error
+	CompleteFunc              func(ctx context.Context, id int) error
+	RetryFunc                 func(ctx context.Context, id int, retryAfter time.Time, lastError string) error
+	FailFunc                  func(ctx context.Context, id int, lastError string) error
+	ReleaseFunc               func(ctx context.Context, id int, workerID string) error
+	ReapStaleFunc             func(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error)
+	RetryNowFunc              func(ctx context.Context, id int) (bool, error)
+	DeleteCompletedBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *MockJobStore) Enqueue(ctx context.Context, jobType string, payload []byte, runAfter time.Time) (int, error) {
+	if m.EnqueueFunc != nil {
+		return m.EnqueueFunc(ctx, jobType, payload, runAfter)
+	}
+	return 0, nil
+}
+
+func (m *MockJobStore) GetByID(ctx context.Context, id int) (*store.Job, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockJobStore) List(ctx context.Context, status string, limit, offset int) ([]store.Job, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockJobStore) Count(ctx context.Context, status string) (int, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, status)
+	}
+	return 0, nil
+}
+
+func (m *MockJobStore) CountByTypeAndStatus(ctx context.Context) ([]store.JobCount, error) {
+	if m.CountByTypeAndStatusFunc != nil {
+		return m.CountByTypeAndStatusFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockJobStore) Claim(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error) {
+	if m.ClaimFunc != nil {
+		return m.ClaimFunc(ctx, workerID, jobTypes)
+	}
+	return nil, nil
+}
+
+func (m *MockJobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	if m.HeartbeatFunc != nil {
+		return m.HeartbeatFunc(ctx, id, workerID)
+	}
+	return nil
+}
+
+func (m *MockJobStore) Complete(ctx context.Context, id int) error {
+	if m.CompleteFunc != nil {
+		return m.CompleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockJobStore) Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error {
+	if m.RetryFunc != nil {
+		return m.RetryFunc(ctx, id, retryAfter, lastError)
+	}
+	return nil
+}
+
+func (m *MockJobStore) Fail(ctx context.Context, id int, lastError string) error {
+	if m.FailFunc != nil {
+		return m.FailFunc(ctx, id, lastError)
+	}
+	return nil
+}
+
+func (m *MockJobStore) Release(ctx context.Context, id int, workerID string) error {
+	if m.ReleaseFunc != nil {
+		return m.ReleaseFunc(ctx, id, workerID)
+	}
+	return nil
+}
+
+func (m *MockJobStore) ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error) {
+	if m.ReapStaleFunc != nil {
+		return m.ReapStaleFunc(ctx, staleBefore, maxAttempts)
+	}
+	return nil, nil
+}
+
+func (m *MockJobStore) RetryNow(ctx context.Context, id int) (bool, error) {
+	if m.RetryNowFunc != nil {
+		return m.RetryNowFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockJobStore) DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteCompletedBeforeFunc != nil {
+		return m.DeleteCompletedBeforeFunc(ctx, before)
+	}
+	return 0, nil
+}
+
+type MockEmailOutboxStore struct {
+	AddFunc                 func(ctx context.Context, email store.OutgoingEmail) (int, error)
+	GetByIDFunc             func(ctx context.Context, id int) (*store.OutboxEmail, error)
+	ListFunc                func(ctx context.Context, status string, limit, offset int) ([]store.OutboxEmail, error)
+	CountFunc               func(ctx context.Context, status string) (int, error)
+	MarkSentFunc            func(ctx context.Context, id int) error
+	RecordFailedAttemptFunc func(ctx context.Context, id int, lastError string) error
+	MarkFailedFunc          func(ctx context.Context, id int) error
+	ResendFunc              func(ctx context.Context, id int) (bool, error)
+	DeleteSentBeforeFunc    func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *MockEmailOutboxStore) Add(ctx context.Context, email store.OutgoingEmail) (int, error) {
+	if m.AddFunc != nil {
+		return m.AddFunc(ctx, email)
+	}
+	return 0, nil
+}
+
+func (m *MockEmailOutboxStore) GetByID(ctx context.Context, id int) (*store.OutboxEmail, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockEmailOutboxStore) List(ctx context.Context, status string, limit, offset int) ([]store.OutboxEmail, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, status, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockEmailOutboxStore) Count(ctx context.Context, status string) (int, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, status)
+	}
+	return 0, nil
+}
+
+func (m *MockEmailOutboxStore) MarkSent(ctx context.Context, id int) error {
+	if m.MarkSentFunc != nil {
+		return m.MarkSentFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockEmailOutboxStore) RecordFailedAttempt(ctx context.Context, id int, lastError string) error {
+	if m.RecordFailedAttemptFunc != nil {
+		return m.RecordFailedAttemptFunc(ctx, id, lastError)
+	}
+	return nil
+}
+
+func (m *MockEmailOutboxStore) MarkFailed(ctx context.Context, id int) error {
+	if m.MarkFailedFunc != nil {
+		return m.MarkFailedFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockEmailOutboxStore) Resend(ctx context.Context, id int) (bool, error) {
+	if m.ResendFunc != nil {
+		return m.ResendFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockEmailOutboxStore) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteSentBeforeFunc != nil {
+		return m.DeleteSentBeforeFunc(ctx, before)
+	}
+	return 0, nil
+}
+
+type MockDigestStore struct {
+	ListUnsentFunc func(ctx context.Context, week time.Time) ([]store.DigestSubscriber, error)
+	GetContentFunc func(ctx context.Context, userID int, since, until time.Time) (*store.DigestContent, error)
+	RecordFunc     func(ctx context.Context, userID int, week time.Time, email *store.OutgoingEmail) (bool, error)
+}
+
+func (m *MockDigestStore) ListUnsent(ctx context.Context, week time.Time) ([]store.DigestSubscriber, error) {
+	if m.ListUnsentFunc != nil {
+		return m.ListUnsentFunc(ctx, week)
+	}
+	return nil, nil
+}
+
+func (m *MockDigestStore) GetContent(ctx context.Context, userID int, since, until time.Time) (*store.DigestContent, error) {
+	if m.GetContentFunc != nil {
+		return m.GetContentFunc(ctx, userID, since, until)
+	}
+	return nil, nil
+}
+
+func (m *MockDigestStore) Record(ctx context.Context, userID int, week time.Time, email *store.OutgoingEmail) (bool, error) {
+	if m.RecordFunc != nil {
+		return m.RecordFunc(ctx, userID, week, email)
+	}
+	return false, nil
+}
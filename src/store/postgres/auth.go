@@ -205,7 +205,7 @@ func (s *AuthStore) ExtendSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
-func (s *AuthStore) CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string) error {
+func (s *AuthStore) CreateRegistrationRequest(ctx context.Context, username, email, passwordHash string, notification *store.OutgoingEmail) error {
 	var count int
 	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE username = $1 OR email = $2", username, email).Scan(&count)
 	if err != nil {
@@ -223,15 +223,31 @@ func (s *AuthStore) CreateRegistrationRequest(ctx context.Context, username, ema
 		return fmt.Errorf("registration request already pending for this username or email")
 	}
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO registration_requests (username, email, password_hash, status)
 		VALUES ($1, $2, $3, 'pending')`
 
-	_, err = s.db.ExecContext(ctx, query, username, email, passwordHash)
+	_, err = tx.ExecContext(ctx, query, username, email, passwordHash)
 	if err != nil {
 		return fmt.Errorf("failed to create registration request: %w", err)
 	}
 
+	if notification != nil {
+		if _, err := queueEmail(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -322,7 +338,7 @@ func (s *AuthStore) CountAllRegistrations(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (s *AuthStore) ApproveRegistration(ctx context.Context, requestID, adminID int) error {
+func (s *AuthStore) ApproveRegistration(ctx context.Context, requestID, adminID int, notification *store.OutgoingEmail) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
@@ -362,6 +378,12 @@ func (s *AuthStore) ApproveRegistration(ctx context.Context, requestID, adminID
 		return fmt.Errorf("failed to update registration request: %w", err)
 	}
 
+	if notification != nil {
+		if _, err := queueEmail(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -454,16 +476,32 @@ func (s *AuthStore) DeleteUser(ctx context.Context, userID int) error {
 	return nil
 }
 
-func (s *AuthStore) CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+func (s *AuthStore) CreatePasswordResetToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, notification *store.OutgoingEmail) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
 		VALUES ($1, $2, $3)`
 
-	_, err := s.db.ExecContext(ctx, query, userID, tokenHash, expiresAt)
+	_, err = tx.ExecContext(ctx, query, userID, tokenHash, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to create password reset token: %w", err)
 	}
 
+	if notification != nil {
+		if _, err := queueEmail(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
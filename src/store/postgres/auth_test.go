@@ -257,7 +257,7 @@ func TestAuthStore_CreateRegistrationRequest_CreatesRequest(t *testing.T) {
 
 	authStore := NewAuthStore(testDB.DB)
 
-	err := authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword")
+	err := authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword", nil)
 	if err != nil {
 		t.Fatalf("failed to create registration request: %v", err)
 	}
@@ -275,7 +275,7 @@ func TestAuthStore_CreateRegistrationRequest_RejectsExistingUser(t *testing.T) {
 	testDB.SeedUser(t, "existinguser", "existing@example.com", "hashedpassword", false)
 	authStore := NewAuthStore(testDB.DB)
 
-	err := authStore.CreateRegistrationRequest(context.Background(), "existinguser", "new@example.com", "hashedpassword")
+	err := authStore.CreateRegistrationRequest(context.Background(), "existinguser", "new@example.com", "hashedpassword", nil)
 	if err == nil {
 		t.Error("expected error for duplicate username")
 	}
@@ -287,12 +287,12 @@ func TestAuthStore_CreateRegistrationRequest_RejectsDuplicatePendingRequest(t *t
 
 	authStore := NewAuthStore(testDB.DB)
 
-	err := authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword")
+	err := authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword", nil)
 	if err != nil {
 		t.Fatalf("failed to create first registration request: %v", err)
 	}
 
-	err = authStore.CreateRegistrationRequest(context.Background(), "newuser", "different@example.com", "hashedpassword")
+	err = authStore.CreateRegistrationRequest(context.Background(), "newuser", "different@example.com", "hashedpassword", nil)
 	if err == nil {
 		t.Error("expected error for duplicate pending request")
 	}
@@ -305,11 +305,11 @@ func TestAuthStore_ApproveRegistration_CreatesUserAndUpdatesRequest(t *testing.T
 	adminID := testDB.SeedUser(t, "admin", "admin@example.com", "hashedpassword", true)
 	authStore := NewAuthStore(testDB.DB)
 
-	authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword")
+	authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword", nil)
 	requests, _ := authStore.GetPendingRegistrations(context.Background())
 	requestID := requests[0].ID
 
-	err := authStore.ApproveRegistration(context.Background(), requestID, adminID)
+	err := authStore.ApproveRegistration(context.Background(), requestID, adminID, nil)
 	if err != nil {
 		t.Fatalf("failed to approve registration: %v", err)
 	}
@@ -332,7 +332,7 @@ func TestAuthStore_RejectRegistration_UpdatesRequestStatus(t *testing.T) {
 	adminID := testDB.SeedUser(t, "admin", "admin@example.com", "hashedpassword", true)
 	authStore := NewAuthStore(testDB.DB)
 
-	authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword")
+	authStore.CreateRegistrationRequest(context.Background(), "newuser", "new@example.com", "hashedpassword", nil)
 	requests, _ := authStore.GetPendingRegistrations(context.Background())
 	requestID := requests[0].ID
 
@@ -474,7 +474,7 @@ func TestAuthStore_CreatePasswordResetToken_CreatesToken(t *testing.T) {
 	authStore := NewAuthStore(testDB.DB)
 
 	expiresAt := time.Now().Add(24 * time.Hour)
-	err := authStore.CreatePasswordResetToken(context.Background(), userID, "hashed-token-123", expiresAt)
+	err := authStore.CreatePasswordResetToken(context.Background(), userID, "hashed-token-123", expiresAt, nil)
 	if err != nil {
 		t.Fatalf("failed to create password reset token: %v", err)
 	}
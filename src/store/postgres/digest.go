@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// digestListLimit caps the recipe lists of a digest; it lists twice as many
+// comments.
+const digestListLimit = 5
+
+type DigestStore struct {
+	db *sql.DB
+}
+
+func NewDigestStore(db *sql.DB) *DigestStore {
+	return &DigestStore{db: db}
+}
+
+func (s *DigestStore) ListUnsent(ctx context.Context, week time.Time) ([]store.DigestSubscriber, error) {
+	query := `
+		SELECT u.id, u.username, u.email, p.digest_day, p.digest_hour,
+			COALESCE(p.theme, ''), COALESCE(p.translation_language, '')
+		FROM user_preferences p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.digest_enabled
+		AND NOT EXISTS (SELECT 1 FROM digest_sends d WHERE d.user_id = p.user_id AND d.week = $1)
+		ORDER BY u.id`
+
+	rows, err := s.db.QueryContext(ctx, query, week.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	var subscribers []store.DigestSubscriber
+	for rows.Next() {
+		var sub store.DigestSubscriber
+		if err := rows.Scan(&sub.UserID, &sub.Username, &sub.Email, &sub.Day, &sub.Hour, &sub.Theme, &sub.Language); err != nil {
+			return nil, fmt.Errorf("failed to scan digest subscriber: %w", err)
+		}
+		subscribers = append(subscribers, sub)
+	}
+	return subscribers, rows.Err()
+}
+
+func (s *DigestStore) GetContent(ctx context.Context, userID int, since, until time.Time) (*store.DigestContent, error) {
+	var content store.DigestContent
+	var err error
+
+	content.NewRecipes, err = s.queryRecipes(ctx, `
+		SELECT r.id, r.title, COALESCE(u.username, ''), 0
+		FROM recipes r
+		LEFT JOIN users u ON u.id = r.author_id
+		WHERE r.created_at >= $1 AND r.created_at < $2
+		AND r.author_id IS DISTINCT FROM $3
+		AND r.translation_of IS NULL
+		ORDER BY r.created_at DESC
+		LIMIT $4`, since, until, userID, digestListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get new recipes: %w", err)
+	}
+
+	content.PopularRecipes, err = s.queryRecipes(ctx, `
+		SELECT r.id, r.title, COALESCE(u.username, ''), COUNT(c.id)
+		FROM comments c
+		JOIN recipes r ON r.id = c.recipe_id
+		LEFT JOIN users u ON u.id = r.author_id
+		WHERE c.created_at >= $1 AND c.created_at < $2
+		GROUP BY r.id, r.title, u.username
+		ORDER BY COUNT(c.id) DESC, MAX(c.created_at) DESC
+		LIMIT $3`, since, until, digestListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular recipes: %w", err)
+	}
+
+	// Failed jobs have no completed_at. They aren't changed after failing,
+	// so their updated_at is when they failed.
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'completed' AND completed_at >= $2 AND completed_at < $3),
+		       COUNT(*) FILTER (WHERE status = 'failed' AND updated_at >= $2 AND updated_at < $3)
+		FROM extraction_jobs
+		WHERE user_id = $1 AND updated_at >= $2`,
+		userID, since, until,
+	).Scan(&content.ExtractionsCompleted, &content.ExtractionsFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction stats: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.title, COALESCE(u.username, ''), c.content_md, c.created_at
+		FROM comments c
+		JOIN recipes r ON r.id = c.recipe_id
+		LEFT JOIN users u ON u.id = c.author_id
+		WHERE r.author_id = $1 AND c.author_id IS DISTINCT FROM $1
+		AND c.created_at >= $2 AND c.created_at < $3
+		ORDER BY c.created_at DESC
+		LIMIT $4`, userID, since, until, digestListLimit*2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var comment store.DigestComment
+		if err := rows.Scan(&comment.RecipeID, &comment.RecipeTitle, &comment.CommenterName, &comment.Content, &comment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		content.Comments = append(content.Comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	return &content, nil
+}
+
+func (s *DigestStore) queryRecipes(ctx context.Context, query string, args ...any) ([]store.DigestRecipe, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []store.DigestRecipe
+	for rows.Next() {
+		var recipe store.DigestRecipe
+		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.AuthorName, &recipe.CommentCount); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, rows.Err()
+}
+
+// Record claims the week with an insert that does nothing if the row exists,
+// so concurrent or repeated runs queue at most one email per user and week.
+func (s *DigestStore) Record(ctx context.Context, userID int, week time.Time, email *store.OutgoingEmail) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO digest_sends (user_id, week) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, week.Format(time.DateOnly),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record digest: %w", err)
+	}
+	if claimed, _ := result.RowsAffected(); claimed == 0 {
+		return false, nil
+	}
+
+	if email != nil {
+		emailID, err := queueEmail(ctx, tx, *email)
+		if err != nil {
+			return false, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE digest_sends SET email_id = $3 WHERE user_id = $1 AND week = $2`, userID, week.Format(time.DateOnly), emailID)
+		if err != nil {
+			return false, fmt.Errorf("failed to record digest email: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func TestDigestStore_RecordsEachWeekOnce(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	otherID := testDB.SeedUser(t, "other", "other@example.com", "hashedpassword", false)
+	prefsStore := NewUserPreferencesStore(testDB.DB)
+	digestStore := NewDigestStore(testDB.DB)
+	ctx := context.Background()
+
+	if err := prefsStore.SetDigest(ctx, userID, true, time.Friday, 18); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if err := prefsStore.SetDigest(ctx, otherID, false, time.Monday, 8); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	week := time.Date(2026, 5, 11, 0, 0, 0, 0, time.Local)
+	subscribers, err := digestStore.ListUnsent(ctx, week)
+	if err != nil {
+		t.Fatalf("failed to list subscribers: %v", err)
+	}
+	if len(subscribers) != 1 || subscribers[0].UserID != userID || subscribers[0].Day != time.Friday || subscribers[0].Hour != 18 {
+		t.Fatalf("expected only the subscribed user, got %+v", subscribers)
+	}
+
+	email := &store.OutgoingEmail{RecipientEmail: "test@example.com", Subject: "Digest", Body: "Hello"}
+	if claimed, err := digestStore.Record(ctx, userID, week, email); err != nil || !claimed {
+		t.Fatalf("expected the first send to be recorded, got %v, %v", claimed, err)
+	}
+	if claimed, err := digestStore.Record(ctx, userID, week, email); err != nil || claimed {
+		t.Fatalf("expected the second send to be skipped, got %v, %v", claimed, err)
+	}
+
+	var emails int
+	if err := testDB.DB.QueryRow(`SELECT COUNT(*) FROM email_outbox`).Scan(&emails); err != nil {
+		t.Fatalf("failed to count emails: %v", err)
+	}
+	if emails != 1 {
+		t.Errorf("expected one queued email, got %d", emails)
+	}
+
+	if subscribers, _ := digestStore.ListUnsent(ctx, week); len(subscribers) != 0 {
+		t.Errorf("expected no unsent digests this week, got %+v", subscribers)
+	}
+	if subscribers, _ := digestStore.ListUnsent(ctx, week.AddDate(0, 0, 7)); len(subscribers) != 1 {
+		t.Errorf("expected the digest to be due again next week, got %+v", subscribers)
+	}
+}
+
+func TestDigestStore_GetContent(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	otherID := testDB.SeedUser(t, "other", "other@example.com", "hashedpassword", false)
+	ownRecipe := testDB.SeedRecipe(t, "Own recipe", "- flour", "Bake", userID)
+	otherRecipe := testDB.SeedRecipe(t, "Other recipe", "- flour", "Bake", otherID)
+	testDB.SeedComment(t, ownRecipe, otherID, "Lovely")
+	testDB.SeedComment(t, ownRecipe, userID, "Thanks")
+	testDB.SeedComment(t, otherRecipe, userID, "Nice")
+
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+	completedJob, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if err := jobStore.MarkCompleted(ctx, completedJob, nil); err != nil {
+		t.Fatalf("failed to complete job: %v", err)
+	}
+	errorMessage := "LLM extraction failed"
+	for i := 0; i < 2; i++ {
+		failedJob, err := jobStore.Create(ctx, userID, "text", nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		if err := jobStore.UpdateStatus(ctx, failedJob, "failed", &errorMessage); err != nil {
+			t.Fatalf("failed to fail job: %v", err)
+		}
+	}
+
+	now := time.Now()
+	content, err := NewDigestStore(testDB.DB).GetContent(ctx, userID, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+
+	if len(content.NewRecipes) != 1 || content.NewRecipes[0].ID != otherRecipe {
+		t.Errorf("expected only the other user's recipe to be new, got %+v", content.NewRecipes)
+	}
+	if len(content.PopularRecipes) != 2 || content.PopularRecipes[0].ID != ownRecipe || content.PopularRecipes[0].CommentCount != 2 {
+		t.Errorf("expected the own recipe to be the most discussed, got %+v", content.PopularRecipes)
+	}
+	if len(content.Comments) != 1 || content.Comments[0].CommenterName != "other" || content.Comments[0].Content != "Lovely" {
+		t.Errorf("expected only the other user's comment, got %+v", content.Comments)
+	}
+	if content.ExtractionsCompleted != 1 || content.ExtractionsFailed != 2 {
+		t.Errorf("expected 1 completed and 2 failed extractions, got %d and %d", content.ExtractionsCompleted, content.ExtractionsFailed)
+	}
+	if content.Empty() {
+		t.Error("expected content not to be empty")
+	}
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type EmailOutboxStore struct {
+	db *sql.DB
+}
+
+func NewEmailOutboxStore(db *sql.DB) *EmailOutboxStore {
+	return &EmailOutboxStore{db: db}
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx, so emails can be
+// queued inside the transaction of the change they announce.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// queueEmail adds the email to the outbox together with the job that
+// delivers it.
+func queueEmail(ctx context.Context, q rowQuerier, email store.OutgoingEmail) (int, error) {
+	query := `
+		WITH email AS (
+			INSERT INTO email_outbox (recipient_email, recipient_name, subject, body, html_body, sensitive)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		), job AS (
+			INSERT INTO jobs (job_type, payload)
+			SELECT $7, jsonb_build_object('email_id', id) FROM email
+		)
+		SELECT id FROM email`
+
+	var id int
+	err := q.QueryRowContext(ctx, query, email.RecipientEmail, email.RecipientName, email.Subject, email.Body, email.HTMLBody, email.Sensitive, store.EmailJobType).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue email: %w", err)
+	}
+	return id, nil
+}
+
+// blankSensitiveBody drops the body of a sensitive email once it is no
+// longer needed for delivery.
+const blankSensitiveBody = `body = CASE WHEN sensitive THEN '' ELSE body END, html_body = CASE WHEN sensitive THEN '' ELSE html_body END`
+
+const outboxColumns = `id, recipient_email, recipient_name, subject, body, html_body, sensitive, status, attempt_count, last_error, created_at, updated_at, sent_at`
+
+func scanOutboxEmail(row rowScanner) (*store.OutboxEmail, error) {
+	var email store.OutboxEmail
+	err := row.Scan(
+		&email.ID, &email.RecipientEmail, &email.RecipientName, &email.Subject, &email.Body, &email.HTMLBody, &email.Sensitive, &email.Status,
+		&email.AttemptCount, &email.LastError, &email.CreatedAt, &email.UpdatedAt, &email.SentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+func (s *EmailOutboxStore) Add(ctx context.Context, email store.OutgoingEmail) (int, error) {
+	return queueEmail(ctx, s.db, email)
+}
+
+func (s *EmailOutboxStore) GetByID(ctx context.Context, id int) (*store.OutboxEmail, error) {
+	email, err := scanOutboxEmail(s.db.QueryRowContext(ctx, `SELECT `+outboxColumns+` FROM email_outbox WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get email: %w", err)
+	}
+	return email, nil
+}
+
+func (s *EmailOutboxStore) List(ctx context.Context, status string, limit, offset int) ([]store.OutboxEmail, error) {
+	query := `
+		SELECT ` + outboxColumns + ` FROM email_outbox
+		WHERE $1::text = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := s.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []store.OutboxEmail
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan email: %w", err)
+		}
+		emails = append(emails, *email)
+	}
+	return emails, rows.Err()
+}
+
+func (s *EmailOutboxStore) Count(ctx context.Context, status string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM email_outbox WHERE $1::text = '' OR status = $1`
+	if err := s.db.QueryRowContext(ctx, query, status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count emails: %w", err)
+	}
+	return count, nil
+}
+
+func (s *EmailOutboxStore) MarkSent(ctx context.Context, id int) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', attempt_count = attempt_count + 1, last_error = NULL, sent_at = NOW(), updated_at = NOW(),
+			` + blankSensitiveBody + `
+		WHERE id = $1`
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark email as sent: %w", err)
+	}
+	return nil
+}
+
+func (s *EmailOutboxStore) RecordFailedAttempt(ctx context.Context, id int, lastError string) error {
+	query := `
+		UPDATE email_outbox
+		SET attempt_count = attempt_count + 1, last_error = $2, updated_at = NOW()
+		WHERE id = $1`
+	if _, err := s.db.ExecContext(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to record failed email attempt: %w", err)
+	}
+	return nil
+}
+
+func (s *EmailOutboxStore) MarkFailed(ctx context.Context, id int) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'failed', updated_at = NOW(), ` + blankSensitiveBody + `
+		WHERE id = $1 AND status = 'pending'`
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark email as failed: %w", err)
+	}
+	return nil
+}
+
+func (s *EmailOutboxStore) Resend(ctx context.Context, id int) (bool, error) {
+	query := `
+		WITH email AS (
+			UPDATE email_outbox
+			SET status = 'pending', attempt_count = 0, last_error = NULL, updated_at = NOW()
+			WHERE id = $1 AND status <> 'pending' AND NOT sensitive
+			RETURNING id
+		), job AS (
+			INSERT INTO jobs (job_type, payload)
+			SELECT $2, jsonb_build_object('email_id', id) FROM email
+		)
+		SELECT COUNT(*) FROM email`
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, id, store.EmailJobType).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to resend email: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *EmailOutboxStore) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM email_outbox WHERE status = 'sent' AND sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent emails: %w", err)
+	}
+	return result.RowsAffected()
+}
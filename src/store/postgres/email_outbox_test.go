@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func claimEmailJob(t *testing.T, jobStore *JobStore) int {
+	t.Helper()
+	job, err := jobStore.Claim(context.Background(), "worker-a", []string{store.EmailJobType})
+	if err != nil {
+		t.Fatalf("failed to claim email job: %v", err)
+	}
+	if job == nil {
+		t.Fatal("expected an email job to be queued")
+	}
+	var payload struct {
+		EmailID int `json:"email_id"`
+	}
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("failed to decode email job: %v", err)
+	}
+	return payload.EmailID
+}
+
+func TestEmailOutboxStore_Add_QueuesDeliveryJob(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	outbox := NewEmailOutboxStore(testDB.DB)
+	ctx := context.Background()
+
+	id, err := outbox.Add(ctx, store.OutgoingEmail{RecipientEmail: "user@example.com", RecipientName: "user", Subject: "Hi", Body: "Hello", HTMLBody: "<p>Hello</p>"})
+	if err != nil {
+		t.Fatalf("failed to add email: %v", err)
+	}
+
+	if emailID := claimEmailJob(t, NewJobStore(testDB.DB)); emailID != id {
+		t.Errorf("expected the job to deliver email %d, got %d", id, emailID)
+	}
+
+	email, err := outbox.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get email: %v", err)
+	}
+	if email.Status != "pending" || email.Subject != "Hi" || email.HTMLBody != "<p>Hello</p>" {
+		t.Errorf("unexpected email %+v", email)
+	}
+}
+
+func TestEmailOutboxStore_FailAndResend(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	outbox := NewEmailOutboxStore(testDB.DB)
+	jobStore := NewJobStore(testDB.DB)
+	ctx := context.Background()
+
+	id, err := outbox.Add(ctx, store.OutgoingEmail{RecipientEmail: "user@example.com", Subject: "Hi", Body: "Hello"})
+	if err != nil {
+		t.Fatalf("failed to add email: %v", err)
+	}
+	claimEmailJob(t, jobStore)
+
+	if err := outbox.RecordFailedAttempt(ctx, id, "provider down"); err != nil {
+		t.Fatalf("failed to record attempt: %v", err)
+	}
+	if err := outbox.MarkFailed(ctx, id); err != nil {
+		t.Fatalf("failed to mark email as failed: %v", err)
+	}
+
+	failed, err := outbox.List(ctx, "failed", 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list emails: %v", err)
+	}
+	if len(failed) != 1 || failed[0].AttemptCount != 1 || failed[0].LastError == nil || *failed[0].LastError != "provider down" {
+		t.Fatalf("unexpected failed emails %+v", failed)
+	}
+
+	resent, err := outbox.Resend(ctx, id)
+	if err != nil || !resent {
+		t.Fatalf("expected the email to be resent, got %v (%v)", resent, err)
+	}
+	if emailID := claimEmailJob(t, jobStore); emailID != id {
+		t.Errorf("expected a new job for email %d, got %d", id, emailID)
+	}
+
+	if err := outbox.MarkSent(ctx, id); err != nil {
+		t.Fatalf("failed to mark email as sent: %v", err)
+	}
+	deleted, err := outbox.DeleteSentBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil || deleted != 1 {
+		t.Errorf("expected the sent email to be deleted, got %d (%v)", deleted, err)
+	}
+}
+
+func TestEmailOutboxStore_Resend_SkipsPendingEmails(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	outbox := NewEmailOutboxStore(testDB.DB)
+	ctx := context.Background()
+
+	id, err := outbox.Add(ctx, store.OutgoingEmail{RecipientEmail: "user@example.com", Subject: "Hi", Body: "Hello"})
+	if err != nil {
+		t.Fatalf("failed to add email: %v", err)
+	}
+
+	resent, err := outbox.Resend(ctx, id)
+	if err != nil || resent {
+		t.Fatalf("expected the pending email not to be resent, got %v (%v)", resent, err)
+	}
+
+	var jobCount int
+	if err := testDB.DB.QueryRow(`SELECT COUNT(*) FROM jobs WHERE job_type = $1`, store.EmailJobType).Scan(&jobCount); err != nil {
+		t.Fatalf("failed to count jobs: %v", err)
+	}
+	if jobCount != 1 {
+		t.Errorf("expected only the original delivery job, got %d jobs", jobCount)
+	}
+}
+
+func TestEmailOutboxStore_SensitiveEmailsAreBlankedAndNotResent(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	outbox := NewEmailOutboxStore(testDB.DB)
+	ctx := context.Background()
+
+	for _, finish := range []func(id int) error{
+		func(id int) error { return outbox.MarkSent(ctx, id) },
+		func(id int) error { return outbox.MarkFailed(ctx, id) },
+	} {
+		id, err := outbox.Add(ctx, store.OutgoingEmail{RecipientEmail: "user@example.com", Subject: "Reset", Body: "https://example.com/reset?token=secret", HTMLBody: "<a>secret</a>", Sensitive: true})
+		if err != nil {
+			t.Fatalf("failed to add email: %v", err)
+		}
+
+		pending, err := outbox.GetByID(ctx, id)
+		if err != nil || !pending.Sensitive || pending.Body == "" {
+			t.Fatalf("expected the pending email to keep its body for delivery, got %+v (%v)", pending, err)
+		}
+
+		if err := finish(id); err != nil {
+			t.Fatalf("failed to finish email: %v", err)
+		}
+		email, err := outbox.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get email: %v", err)
+		}
+		if email.Body != "" || email.HTMLBody != "" {
+			t.Errorf("expected the %s email's body to be blanked, got %+v", email.Status, email)
+		}
+
+		if resent, err := outbox.Resend(ctx, id); err != nil || resent {
+			t.Errorf("expected the %s email not to be resent, got %v (%v)", email.Status, resent, err)
+		}
+	}
+}
+
+func TestAuthStore_CreatePasswordResetToken_QueuesEmail(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	authStore := NewAuthStore(testDB.DB)
+	ctx := context.Background()
+
+	email := store.OutgoingEmail{RecipientEmail: "test@example.com", RecipientName: "testuser", Subject: "Reset", Body: "Link"}
+	if err := authStore.CreatePasswordResetToken(ctx, userID, "hashed-token-123", time.Now().Add(time.Hour), &email); err != nil {
+		t.Fatalf("failed to create password reset token: %v", err)
+	}
+
+	emails, err := NewEmailOutboxStore(testDB.DB).List(ctx, "pending", 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list emails: %v", err)
+	}
+	if len(emails) != 1 || emails[0].RecipientEmail != "test@example.com" {
+		t.Errorf("expected the reset email in the outbox, got %+v", emails)
+	}
+}
+
+func TestExtractionJobStore_MarkCompleted_QueuesEmailOnlyForProcessingJobs(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	processingID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 10); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	cancelledID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if cancelled, err := jobStore.Cancel(ctx, cancelledID); err != nil || !cancelled {
+		t.Fatalf("expected job to be cancelled, got %v (%v)", cancelled, err)
+	}
+
+	for _, jobID := range []int{processingID, cancelledID} {
+		email := store.OutgoingEmail{RecipientEmail: "test@example.com", Subject: "Recipe extracted", Body: "Done"}
+		if err := jobStore.MarkCompleted(ctx, jobID, &email); err != nil {
+			t.Fatalf("failed to mark job completed: %v", err)
+		}
+	}
+
+	emails, err := NewEmailOutboxStore(testDB.DB).List(ctx, "pending", 10, 0)
+	if err != nil {
+		t.Fatalf("failed to list emails: %v", err)
+	}
+	if len(emails) != 1 {
+		t.Errorf("expected only the completed job's email in the outbox, got %+v", emails)
+	}
+}
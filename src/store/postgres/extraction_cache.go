@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type ExtractionCacheStore struct {
+	db *sql.DB
+}
+
+func NewExtractionCacheStore(db *sql.DB) *ExtractionCacheStore {
+	return &ExtractionCacheStore{db: db}
+}
+
+// Get returns the cache entry for key, or nil if there is none or it has
+// expired.
+func (s *ExtractionCacheStore) Get(ctx context.Context, key string) (*store.ExtractionCacheEntry, error) {
+	query := `
+		SELECT cache_key, result, recipe_id, prompt_version, model, created_at, expires_at
+		FROM extraction_cache
+		WHERE cache_key = $1 AND expires_at > NOW()`
+
+	var entry store.ExtractionCacheEntry
+	err := s.db.QueryRowContext(ctx, query, key).Scan(
+		&entry.Key, &entry.Result, &entry.RecipeID, &entry.PromptVersion, &entry.Model, &entry.CreatedAt, &entry.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get extraction cache entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (s *ExtractionCacheStore) Put(ctx context.Context, key string, result []byte, recipeID int, promptVersion, model string, ttl time.Duration) error {
+	query := `
+		INSERT INTO extraction_cache (cache_key, result, recipe_id, prompt_version, model, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (cache_key) DO UPDATE SET
+			result = EXCLUDED.result,
+			recipe_id = EXCLUDED.recipe_id,
+			prompt_version = EXCLUDED.prompt_version,
+			model = EXCLUDED.model,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at`
+
+	_, err := s.db.ExecContext(ctx, query, key, result, recipeID, promptVersion, model, time.Now().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("failed to store extraction cache entry: %w", err)
+	}
+	return nil
+}
+
+func (s *ExtractionCacheStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM extraction_cache WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired extraction cache entries: %w", err)
+	}
+	return result.RowsAffected()
+}
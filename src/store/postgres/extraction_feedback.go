@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/mr-flannery/go-recipe-book/src/store"
 )
@@ -55,6 +56,44 @@ func (s *ExtractionFeedbackStore) GetByJobID(ctx context.Context, jobID int) (*s
 	return &feedback, nil
 }
 
+func (s *ExtractionFeedbackStore) GetByJobIDs(ctx context.Context, jobIDs []int) (map[int]*store.ExtractionFeedback, error) {
+	result := make(map[int]*store.ExtractionFeedback)
+	if len(jobIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(jobIDs))
+	args := make([]interface{}, len(jobIDs))
+	for i, id := range jobIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+		SELECT ef.id, ef.job_id, ef.user_id, u.username, ef.rating, ef.feedback_type, ef.comment, ef.created_at
+		FROM extraction_feedback ef
+		JOIN users u ON ef.user_id = u.id
+		WHERE ef.job_id IN (%s)`, strings.Join(placeholders, ","))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction feedback: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feedback store.ExtractionFeedback
+		if err := rows.Scan(
+			&feedback.ID, &feedback.JobID, &feedback.UserID, &feedback.Username,
+			&feedback.Rating, &feedback.FeedbackType, &feedback.Comment, &feedback.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction feedback: %w", err)
+		}
+		result[feedback.JobID] = &feedback
+	}
+	return result, rows.Err()
+}
+
 func (s *ExtractionFeedbackStore) GetAll(ctx context.Context, limit, offset int) ([]store.ExtractionFeedback, error) {
 	query := `
 		SELECT ef.id, ef.job_id, ef.user_id, u.username, ef.rating, ef.feedback_type, ef.comment, ef.created_at
@@ -94,3 +133,52 @@ func (s *ExtractionFeedbackStore) CountAll(ctx context.Context) (int, error) {
 	}
 	return count, nil
 }
+
+// SummarizeByVariant aggregates the feedback by the prompt version and model
+// of the rated jobs, newest prompt version first.
+func (s *ExtractionFeedbackStore) SummarizeByVariant(ctx context.Context) ([]store.ExtractionFeedbackSummary, error) {
+	query := `
+		SELECT COALESCE(ej.prompt_version, ''), COALESCE(ej.model, ''), ef.feedback_type, COUNT(*), SUM(ef.rating)
+		FROM extraction_feedback ef
+		JOIN extraction_jobs ej ON ef.job_id = ej.id
+		GROUP BY 1, 2, 3
+		ORDER BY 1 DESC, 2`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize extraction feedback: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []store.ExtractionFeedbackSummary
+	var ratingSums []int
+	for rows.Next() {
+		var promptVersion, model, feedbackType string
+		var count, ratingSum int
+		if err := rows.Scan(&promptVersion, &model, &feedbackType, &count, &ratingSum); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction feedback summary: %w", err)
+		}
+
+		last := len(summaries) - 1
+		if last < 0 || summaries[last].PromptVersion != promptVersion || summaries[last].Model != model {
+			summaries = append(summaries, store.ExtractionFeedbackSummary{
+				PromptVersion: promptVersion,
+				Model:         model,
+				TypeCounts:    make(map[string]int),
+			})
+			ratingSums = append(ratingSums, 0)
+			last++
+		}
+		summaries[last].Count += count
+		summaries[last].TypeCounts[feedbackType] = count
+		ratingSums[last] += ratingSum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to summarize extraction feedback: %w", err)
+	}
+
+	for i := range summaries {
+		summaries[i].AverageRating = float64(ratingSums[i]) / float64(summaries[i].Count)
+	}
+	return summaries, nil
+}
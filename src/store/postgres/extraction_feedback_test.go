@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func TestExtractionFeedbackStore_SummarizeByVariant(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	otherUserID := testDB.SeedUser(t, "otheruser", "other@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	feedbackStore := NewExtractionFeedbackStore(testDB.DB)
+	ctx := context.Background()
+
+	rate := func(promptVersion string, ratings map[int]string) {
+		t.Helper()
+		jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		if promptVersion != "" {
+			if err := jobStore.SetVariant(ctx, jobID, promptVersion, "google/gemini-2.5-flash-lite"); err != nil {
+				t.Fatalf("failed to set variant: %v", err)
+			}
+		}
+		raters := []int{userID, otherUserID}
+		i := 0
+		for rating, feedbackType := range ratings {
+			if err := feedbackStore.Create(ctx, jobID, raters[i], rating, feedbackType, nil); err != nil {
+				t.Fatalf("failed to create feedback: %v", err)
+			}
+			i++
+		}
+	}
+	rate("v1", map[int]string{2: "inaccurate", 4: "good"})
+	rate("v1", map[int]string{3: "missing_info"})
+	rate("v2", map[int]string{5: "good"})
+	rate("", map[int]string{1: "other"})
+
+	summaries, err := feedbackStore.SummarizeByVariant(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(summaries) != 3 {
+		t.Fatalf("got %d summaries, want 3: %+v", len(summaries), summaries)
+	}
+	if s := summaries[0]; s.PromptVersion != "v2" || s.Count != 1 || s.AverageRating != 5 {
+		t.Errorf("first summary = %+v, want v2 with one rating of 5", s)
+	}
+	v1 := summaries[1]
+	if v1.PromptVersion != "v1" || v1.Model != "google/gemini-2.5-flash-lite" || v1.Count != 3 || v1.AverageRating != 3 {
+		t.Errorf("second summary = %+v, want v1 with three ratings averaging 3", v1)
+	}
+	if v1.TypeCounts["good"] != 1 || v1.TypeCounts["inaccurate"] != 1 || v1.TypeCounts["missing_info"] != 1 {
+		t.Errorf("v1 type counts = %v", v1.TypeCounts)
+	}
+	if s := summaries[2]; s.PromptVersion != "" || s.Model != "" || s.Count != 1 {
+		t.Errorf("last summary = %+v, want the job without a recorded variant", s)
+	}
+}
@@ -17,27 +17,120 @@ func NewExtractionJobStore(db *sql.DB) *ExtractionJobStore {
 	return &ExtractionJobStore{db: db}
 }
 
-func (s *ExtractionJobStore) Create(ctx context.Context, userID int, jobType string, inputURL *string, inputData []byte) (int, error) {
+func (s *ExtractionJobStore) Create(ctx context.Context, userID int, jobType string, inputURL *string, inputs []store.ExtractionJobInput) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO extraction_jobs (user_id, job_type, input_url, input_data)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO extraction_jobs (user_id, job_type, input_url)
+		VALUES ($1, $2, $3)
 		RETURNING id`
 
 	var id int
-	err := s.db.QueryRowContext(ctx, query, userID, jobType, inputURL, inputData).Scan(&id)
+	err = tx.QueryRowContext(ctx, query, userID, jobType, inputURL).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create extraction job: %w", err)
 	}
 
+	for i, input := range inputs {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO extraction_job_inputs (job_id, position, content_type, data) VALUES ($1, $2, $3, $4)`,
+			id, i, input.ContentType, input.Data)
+		if err != nil {
+			return 0, fmt.Errorf("failed to store extraction job input: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *ExtractionJobStore) CreateForced(ctx context.Context, userID int, jobType, inputURL string) (int, error) {
+	query := `
+		INSERT INTO extraction_jobs (user_id, job_type, input_url, bypass_cache)
+		VALUES ($1, $2, $3, TRUE)
+		RETURNING id`
+
+	var id int
+	err := s.db.QueryRowContext(ctx, query, userID, jobType, inputURL).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create extraction job: %w", err)
+	}
+	return id, nil
+}
+
+// CreateReExtraction queues a job that extracts inputURL again for the
+// existing recipe recipeID. A nil model uses the default model.
+func (s *ExtractionJobStore) CreateReExtraction(ctx context.Context, userID, recipeID int, jobType, inputURL string, model *string) (int, error) {
+	query := `
+		INSERT INTO extraction_jobs (user_id, job_type, input_url, target_recipe_id, model)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	var id int
+	err := s.db.QueryRowContext(ctx, query, userID, jobType, inputURL, recipeID, model).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create re-extraction job: %w", err)
+	}
+	return id, nil
+}
+
+// CreateTranslation queues a job that translates the recipe recipeID into
+// language.
+func (s *ExtractionJobStore) CreateTranslation(ctx context.Context, userID, recipeID int, language string) (int, error) {
+	query := `
+		INSERT INTO extraction_jobs (user_id, job_type, target_recipe_id, target_language)
+		VALUES ($1, 'translation', $2, $3)
+		RETURNING id`
+
+	var id int
+	err := s.db.QueryRowContext(ctx, query, userID, recipeID, language).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create translation job: %w", err)
+	}
 	return id, nil
 }
 
+// CreateTagging queues a tagging job for each of recipeIDs.
+func (s *ExtractionJobStore) CreateTagging(ctx context.Context, userID int, recipeIDs []int, priority int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, recipeID := range recipeIDs {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO extraction_jobs (user_id, job_type, target_recipe_id, priority) VALUES ($1, 'tagging', $2, $3)`,
+			userID, recipeID, priority)
+		if err != nil {
+			return fmt.Errorf("failed to create tagging job: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 func (s *ExtractionJobStore) GetByID(ctx context.Context, id int) (*store.ExtractionJob, error) {
 	query := `
 		SELECT 
-			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url, ej.input_data,
+			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url,
+			(SELECT COUNT(*) FROM extraction_job_inputs eji WHERE eji.job_id = ej.id),
 			ej.status, ej.error_message, ej.llm_input, ej.llm_output,
-			ej.recipe_id, r.title, ej.attempt_count, ej.created_at, ej.updated_at, ej.completed_at
+			ej.recipe_id, r.title, ej.attempt_count,
+			ej.prompt_tokens, ej.completion_tokens, ej.audio_tokens, ej.cost_usd,
+			ej.created_at, ej.updated_at, ej.completed_at,
+			ej.target_recipe_id, ej.model, ej.proposed_recipe, ej.prompt_version,
+			ej.extracted_recipe, ej.target_language
 		FROM extraction_jobs ej
 		JOIN users u ON ej.user_id = u.id
 		LEFT JOIN recipes r ON ej.recipe_id = r.id
@@ -45,9 +138,13 @@ func (s *ExtractionJobStore) GetByID(ctx context.Context, id int) (*store.Extrac
 
 	var job store.ExtractionJob
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&job.ID, &job.UserID, &job.Username, &job.JobType, &job.InputURL, &job.InputData,
+		&job.ID, &job.UserID, &job.Username, &job.JobType, &job.InputURL, &job.InputCount,
 		&job.Status, &job.ErrorMessage, &job.LLMInput, &job.LLMOutput,
-		&job.RecipeID, &job.RecipeTitle, &job.AttemptCount, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+		&job.RecipeID, &job.RecipeTitle, &job.AttemptCount,
+		&job.Usage.PromptTokens, &job.Usage.CompletionTokens, &job.Usage.AudioTokens, &job.Usage.CostUSD,
+		&job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+		&job.TargetRecipeID, &job.Model, &job.ProposedRecipe, &job.PromptVersion,
+		&job.ExtractedRecipe, &job.TargetLanguage,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -59,12 +156,40 @@ func (s *ExtractionJobStore) GetByID(ctx context.Context, id int) (*store.Extrac
 	return &job, nil
 }
 
+func (s *ExtractionJobStore) GetInputs(ctx context.Context, jobID int) ([]store.ExtractionJobInput, error) {
+	query := `
+		SELECT position, content_type, data
+		FROM extraction_job_inputs
+		WHERE job_id = $1
+		ORDER BY position ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extraction job inputs: %w", err)
+	}
+	defer rows.Close()
+
+	var inputs []store.ExtractionJobInput
+	for rows.Next() {
+		var input store.ExtractionJobInput
+		if err := rows.Scan(&input.Position, &input.ContentType, &input.Data); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction job input: %w", err)
+		}
+		inputs = append(inputs, input)
+	}
+
+	return inputs, rows.Err()
+}
+
 func (s *ExtractionJobStore) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]store.ExtractionJob, error) {
 	query := `
 		SELECT 
-			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url, NULL,
+			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url,
+			(SELECT COUNT(*) FROM extraction_job_inputs eji WHERE eji.job_id = ej.id),
 			ej.status, ej.error_message, NULL, NULL,
-			ej.recipe_id, r.title, ej.attempt_count, ej.created_at, ej.updated_at, ej.completed_at
+			ej.recipe_id, r.title, ej.attempt_count,
+			ej.prompt_tokens, ej.completion_tokens, ej.audio_tokens, ej.cost_usd,
+			ej.created_at, ej.updated_at, ej.completed_at
 		FROM extraction_jobs ej
 		JOIN users u ON ej.user_id = u.id
 		LEFT JOIN recipes r ON ej.recipe_id = r.id
@@ -91,12 +216,63 @@ func (s *ExtractionJobStore) CountByUserID(ctx context.Context, userID int) (int
 	return count, nil
 }
 
+func (s *ExtractionJobStore) GetSubmittedURLs(ctx context.Context, userID int) ([]string, error) {
+	query := `
+		SELECT DISTINCT input_url
+		FROM extraction_jobs
+		WHERE user_id = $1 AND input_url IS NOT NULL
+		  AND (status IN ('pending', 'processing') OR (status = 'completed' AND recipe_id IS NOT NULL))`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submitted URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var inputURL string
+		if err := rows.Scan(&inputURL); err != nil {
+			return nil, fmt.Errorf("failed to scan submitted URL: %w", err)
+		}
+		urls = append(urls, inputURL)
+	}
+	return urls, rows.Err()
+}
+
+func (s *ExtractionJobStore) GetScheduledJobs(ctx context.Context, after time.Time) ([]store.ExtractionBatchJob, error) {
+	query := `
+		SELECT job_type, input_url, MAX(retry_after)
+		FROM extraction_jobs
+		WHERE status = 'pending' AND input_url IS NOT NULL AND retry_after > $1
+		GROUP BY job_type, input_url`
+
+	rows, err := s.db.QueryContext(ctx, query, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []store.ExtractionBatchJob
+	for rows.Next() {
+		var job store.ExtractionBatchJob
+		if err := rows.Scan(&job.JobType, &job.InputURL, &job.NotBefore); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
 func (s *ExtractionJobStore) GetAll(ctx context.Context, limit, offset int) ([]store.ExtractionJob, error) {
 	query := `
 		SELECT 
-			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url, NULL,
+			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url,
+			(SELECT COUNT(*) FROM extraction_job_inputs eji WHERE eji.job_id = ej.id),
 			ej.status, ej.error_message, NULL, NULL,
-			ej.recipe_id, r.title, ej.attempt_count, ej.created_at, ej.updated_at, ej.completed_at
+			ej.recipe_id, r.title, ej.attempt_count,
+			ej.prompt_tokens, ej.completion_tokens, ej.audio_tokens, ej.cost_usd,
+			ej.created_at, ej.updated_at, ej.completed_at
 		FROM extraction_jobs ej
 		JOIN users u ON ej.user_id = u.id
 		LEFT JOIN recipes r ON ej.recipe_id = r.id
@@ -122,26 +298,37 @@ func (s *ExtractionJobStore) CountAll(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (s *ExtractionJobStore) ClaimPendingJob(ctx context.Context) (*store.ExtractionJob, error) {
+// ClaimPendingJob claims the next job for workerID. Higher priorities go
+// first; within a priority, users take turns, starting with the user whose
+// last job was claimed the longest time ago. Users that already have
+// maxPerUser jobs processing are skipped. The cap is best effort: two workers
+// claiming at the same moment may both pick the same user.
+func (s *ExtractionJobStore) ClaimPendingJob(ctx context.Context, workerID string, maxPerUser int) (*store.ExtractionJob, error) {
 	query := `
 		UPDATE extraction_jobs
-		SET status = 'processing', updated_at = NOW()
+		SET status = 'processing', worker_id = $1, heartbeat_at = NOW(), claimed_at = NOW(), updated_at = NOW()
 		WHERE id = (
-			SELECT id FROM extraction_jobs
-			WHERE status = 'pending'
-			  AND (retry_after IS NULL OR retry_after <= NOW())
-			ORDER BY created_at ASC
+			SELECT ej.id FROM extraction_jobs ej
+			WHERE ej.status = 'pending'
+			  AND (ej.retry_after IS NULL OR ej.retry_after <= NOW())
+			  AND (SELECT COUNT(*) FROM extraction_jobs p
+			       WHERE p.user_id = ej.user_id AND p.status = 'processing') < $2
+			ORDER BY ej.priority DESC,
+			         (SELECT MAX(l.claimed_at) FROM extraction_jobs l WHERE l.user_id = ej.user_id) ASC NULLS FIRST,
+			         ej.created_at ASC
 			LIMIT 1
-			FOR UPDATE SKIP LOCKED
+			FOR UPDATE OF ej SKIP LOCKED
 		)
-		RETURNING id, user_id, job_type, input_url, input_data, status, error_message,
-		          llm_input, llm_output, recipe_id, attempt_count, created_at, updated_at, completed_at, retry_after`
+		RETURNING id, user_id, job_type, input_url, status, error_message,
+		          llm_input, llm_output, recipe_id, attempt_count, created_at, updated_at, completed_at, retry_after,
+		          target_recipe_id, model, target_language, bypass_cache`
 
 	var job store.ExtractionJob
-	err := s.db.QueryRowContext(ctx, query).Scan(
-		&job.ID, &job.UserID, &job.JobType, &job.InputURL, &job.InputData,
+	err := s.db.QueryRowContext(ctx, query, workerID, maxPerUser).Scan(
+		&job.ID, &job.UserID, &job.JobType, &job.InputURL,
 		&job.Status, &job.ErrorMessage, &job.LLMInput, &job.LLMOutput,
 		&job.RecipeID, &job.AttemptCount, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt, &job.RetryAfter,
+		&job.TargetRecipeID, &job.Model, &job.TargetLanguage, &job.BypassCache,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -153,8 +340,107 @@ func (s *ExtractionJobStore) ClaimPendingJob(ctx context.Context) (*store.Extrac
 	return &job, nil
 }
 
+// Heartbeat marks a claimed job as still being worked on. It also bumps
+// updated_at so that long-running jobs don't look stuck to users.
+func (s *ExtractionJobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	query := `
+		UPDATE extraction_jobs
+		SET heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`
+	result, err := s.db.ExecContext(ctx, query, id, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+	if rows == 0 {
+		var status string
+		err := s.db.QueryRowContext(ctx, `SELECT status FROM extraction_jobs WHERE id = $1`, id).Scan(&status)
+		if err == nil && status == "cancelled" {
+			return store.ErrJobCancelled
+		}
+		return store.ErrJobNotClaimed
+	}
+	return nil
+}
+
+// ReleaseJob hands a claimed job back to the queue without counting the
+// interrupted attempt, e.g. when the worker shuts down.
+func (s *ExtractionJobStore) ReleaseJob(ctx context.Context, id int, workerID string) error {
+	query := `
+		UPDATE extraction_jobs
+		SET status = 'pending', worker_id = NULL, heartbeat_at = NULL,
+		    attempt_count = GREATEST(attempt_count - 1, 0), updated_at = NOW()
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`
+	_, err := s.db.ExecContext(ctx, query, id, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
+	return nil
+}
+
+// Cancel stops a pending or processing job and reports whether it did. A
+// worker processing the job notices on its next heartbeat.
+func (s *ExtractionJobStore) Cancel(ctx context.Context, id int) (bool, error) {
+	query := `
+		UPDATE extraction_jobs
+		SET status = 'cancelled', heartbeat_at = NULL, retry_after = NULL, updated_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'processing')`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ReapStaleJobs requeues processing jobs whose last heartbeat is older than
+// staleBefore. Jobs that already had maxAttempts attempts are failed instead,
+// so a job that keeps crashing its worker doesn't loop forever.
+func (s *ExtractionJobStore) ReapStaleJobs(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.ReapedJob, error) {
+	query := `
+		WITH stale AS (
+			SELECT id, worker_id FROM extraction_jobs
+			WHERE status = 'processing'
+			  AND COALESCE(heartbeat_at, updated_at) < $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE extraction_jobs ej
+		SET status = CASE WHEN ej.attempt_count >= $2 THEN 'failed' ELSE 'pending' END,
+		    error_message = CASE WHEN ej.attempt_count >= $2
+		        THEN 'The job was interrupted too many times' ELSE NULL END,
+		    worker_id = NULL,
+		    heartbeat_at = NULL,
+		    updated_at = NOW()
+		FROM stale
+		WHERE ej.id = stale.id
+		RETURNING ej.id, ej.user_id, ej.job_type, stale.worker_id, ej.status, ej.error_message`
+
+	rows, err := s.db.QueryContext(ctx, query, staleBefore, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reap stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var reaped []store.ReapedJob
+	for rows.Next() {
+		var job store.ReapedJob
+		if err := rows.Scan(&job.ID, &job.UserID, &job.JobType, &job.WorkerID, &job.Status, &job.ErrorMessage); err != nil {
+			return nil, fmt.Errorf("failed to scan reaped job: %w", err)
+		}
+		reaped = append(reaped, job)
+	}
+
+	return reaped, rows.Err()
+}
+
 func (s *ExtractionJobStore) UpdateStatus(ctx context.Context, id int, status string, errorMessage *string) error {
-	query := `UPDATE extraction_jobs SET status = $2, error_message = $3, updated_at = NOW() WHERE id = $1`
+	query := `UPDATE extraction_jobs SET status = $2, error_message = $3, updated_at = NOW() WHERE id = $1 AND status <> 'cancelled'`
 	_, err := s.db.ExecContext(ctx, query, id, status, errorMessage)
 	if err != nil {
 		return fmt.Errorf("failed to update job status: %w", err)
@@ -180,12 +466,116 @@ func (s *ExtractionJobStore) SetRecipeID(ctx context.Context, id int, recipeID i
 	return nil
 }
 
-func (s *ExtractionJobStore) MarkCompleted(ctx context.Context, id int) error {
-	query := `UPDATE extraction_jobs SET status = 'completed', completed_at = NOW(), updated_at = NOW() WHERE id = $1`
+func (s *ExtractionJobStore) SetProposedRecipe(ctx context.Context, id int, proposal []byte) error {
+	query := `UPDATE extraction_jobs SET proposed_recipe = $2, updated_at = NOW() WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, proposal)
+	if err != nil {
+		return fmt.Errorf("failed to set proposed recipe: %w", err)
+	}
+	return nil
+}
+
+func (s *ExtractionJobStore) MarkProposalApplied(ctx context.Context, id int) error {
+	query := `UPDATE extraction_jobs SET proposal_applied_at = NOW(), updated_at = NOW() WHERE id = $1`
 	_, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark proposal applied: %w", err)
+	}
+	return nil
+}
+
+// SetVariant records the prompt version and model a job is extracted with.
+func (s *ExtractionJobStore) SetVariant(ctx context.Context, id int, promptVersion, model string) error {
+	query := `UPDATE extraction_jobs SET prompt_version = $2, model = $3, updated_at = NOW() WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, promptVersion, model)
+	if err != nil {
+		return fmt.Errorf("failed to set job prompt version and model: %w", err)
+	}
+	return nil
+}
+
+// SetExtractedRecipe stores the recipe a job extracted, as JSON.
+func (s *ExtractionJobStore) SetExtractedRecipe(ctx context.Context, id int, recipe []byte) error {
+	query := `UPDATE extraction_jobs SET extracted_recipe = $2, updated_at = NOW() WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, recipe)
+	if err != nil {
+		return fmt.Errorf("failed to set extracted recipe: %w", err)
+	}
+	return nil
+}
+
+// GetWithExtractedRecipe returns the newest jobs that created a recipe which
+// still exists, along with the LLM input and the recipe as it was extracted.
+func (s *ExtractionJobStore) GetWithExtractedRecipe(ctx context.Context, limit int) ([]store.ExtractionJob, error) {
+	query := `
+		SELECT
+			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url,
+			(SELECT COUNT(*) FROM extraction_job_inputs eji WHERE eji.job_id = ej.id),
+			ej.status, ej.llm_input, ej.recipe_id, r.title,
+			ej.created_at, ej.completed_at,
+			ej.model, ej.prompt_version, ej.extracted_recipe
+		FROM extraction_jobs ej
+		JOIN users u ON ej.user_id = u.id
+		JOIN recipes r ON ej.recipe_id = r.id
+		WHERE ej.extracted_recipe IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM extraction_jobs applied
+			WHERE applied.target_recipe_id = ej.recipe_id AND applied.proposal_applied_at IS NOT NULL
+		  )
+		ORDER BY ej.created_at DESC
+		LIMIT $1`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extracted recipes: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []store.ExtractionJob
+	for rows.Next() {
+		var job store.ExtractionJob
+		err := rows.Scan(
+			&job.ID, &job.UserID, &job.Username, &job.JobType, &job.InputURL, &job.InputCount,
+			&job.Status, &job.LLMInput, &job.RecipeID, &job.RecipeTitle,
+			&job.CreatedAt, &job.CompletedAt,
+			&job.Model, &job.PromptVersion, &job.ExtractedRecipe,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan extracted recipe: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (s *ExtractionJobStore) MarkCompleted(ctx context.Context, id int, notification *store.OutgoingEmail) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE extraction_jobs SET status = 'completed', completed_at = NOW(), updated_at = NOW() WHERE id = $1 AND status = 'processing'`
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark job completed: %w", err)
+	}
+	completed, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to mark job completed: %w", err)
 	}
+
+	if notification != nil && completed > 0 {
+		if _, err := queueEmail(ctx, tx, *notification); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -199,7 +589,7 @@ func (s *ExtractionJobStore) IncrementAttemptCount(ctx context.Context, id int)
 }
 
 func (s *ExtractionJobStore) ResetForRetry(ctx context.Context, id int) error {
-	query := `UPDATE extraction_jobs SET status = 'pending', error_message = NULL, retry_after = NULL, updated_at = NOW() WHERE id = $1`
+	query := `UPDATE extraction_jobs SET status = 'pending', error_message = NULL, retry_after = NULL, updated_at = NOW() WHERE id = $1 AND status IN ('failed', 'processing')`
 	_, err := s.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to reset job for retry: %w", err)
@@ -208,7 +598,7 @@ func (s *ExtractionJobStore) ResetForRetry(ctx context.Context, id int) error {
 }
 
 func (s *ExtractionJobStore) ScheduleRetry(ctx context.Context, id int, retryAfter time.Time) error {
-	query := `UPDATE extraction_jobs SET status = 'pending', error_message = NULL, retry_after = $2, updated_at = NOW() WHERE id = $1`
+	query := `UPDATE extraction_jobs SET status = 'pending', error_message = NULL, retry_after = $2, updated_at = NOW() WHERE id = $1 AND status = 'processing'`
 	_, err := s.db.ExecContext(ctx, query, id, retryAfter)
 	if err != nil {
 		return fmt.Errorf("failed to schedule job retry: %w", err)
@@ -216,14 +606,257 @@ func (s *ExtractionJobStore) ScheduleRetry(ctx context.Context, id int, retryAft
 	return nil
 }
 
+func (s *ExtractionJobStore) AddUsage(ctx context.Context, id int, usage store.ExtractionUsage) error {
+	query := `
+		UPDATE extraction_jobs
+		SET prompt_tokens = prompt_tokens + $2,
+		    completion_tokens = completion_tokens + $3,
+		    audio_tokens = audio_tokens + $4,
+		    cost_usd = cost_usd + $5,
+		    updated_at = NOW()
+		WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, usage.PromptTokens, usage.CompletionTokens, usage.AudioTokens, usage.CostUSD)
+	if err != nil {
+		return fmt.Errorf("failed to add job usage: %w", err)
+	}
+	return nil
+}
+
+func (s *ExtractionJobStore) GetUsageSince(ctx context.Context, userID int, since time.Time) (*store.UserExtractionUsage, error) {
+	query := `
+		SELECT
+			u.id, u.username,
+			COUNT(ej.id),
+			COALESCE(SUM(ej.prompt_tokens), 0), COALESCE(SUM(ej.completion_tokens), 0),
+			COALESCE(SUM(ej.audio_tokens), 0), COALESCE(SUM(ej.cost_usd), 0),
+			q.monthly_job_limit, q.monthly_cost_limit_usd
+		FROM users u
+		LEFT JOIN extraction_jobs ej ON ej.user_id = u.id AND ej.created_at >= $2
+		LEFT JOIN extraction_quotas q ON q.user_id = u.id
+		WHERE u.id = $1
+		GROUP BY u.id, u.username, q.monthly_job_limit, q.monthly_cost_limit_usd`
+
+	usage, err := scanUserUsage(s.db.QueryRowContext(ctx, query, userID, since))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get extraction usage: %w", err)
+	}
+	return usage, nil
+}
+
+// GetAllUsageSince returns usage for every user who either submitted a job
+// since the given time or has a quota configured, most expensive first.
+func (s *ExtractionJobStore) GetAllUsageSince(ctx context.Context, since time.Time) ([]store.UserExtractionUsage, error) {
+	query := `
+		SELECT
+			u.id, u.username,
+			COUNT(ej.id),
+			COALESCE(SUM(ej.prompt_tokens), 0), COALESCE(SUM(ej.completion_tokens), 0),
+			COALESCE(SUM(ej.audio_tokens), 0), COALESCE(SUM(ej.cost_usd), 0),
+			q.monthly_job_limit, q.monthly_cost_limit_usd
+		FROM users u
+		LEFT JOIN extraction_jobs ej ON ej.user_id = u.id AND ej.created_at >= $1
+		LEFT JOIN extraction_quotas q ON q.user_id = u.id
+		GROUP BY u.id, u.username, q.monthly_job_limit, q.monthly_cost_limit_usd
+		ORDER BY COALESCE(SUM(ej.cost_usd), 0) DESC, u.username ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extraction usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []store.UserExtractionUsage
+	for rows.Next() {
+		usage, err := scanUserUsage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan extraction usage: %w", err)
+		}
+		usages = append(usages, *usage)
+	}
+	return usages, rows.Err()
+}
+
+func (s *ExtractionJobStore) SetQuota(ctx context.Context, userID int, quota store.ExtractionQuota) error {
+	if quota.MonthlyJobLimit == nil && quota.MonthlyCostLimitUSD == nil {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM extraction_quotas WHERE user_id = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove extraction quota: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		INSERT INTO extraction_quotas (user_id, monthly_job_limit, monthly_cost_limit_usd)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET monthly_job_limit = EXCLUDED.monthly_job_limit,
+		    monthly_cost_limit_usd = EXCLUDED.monthly_cost_limit_usd,
+		    updated_at = NOW()`
+	_, err := s.db.ExecContext(ctx, query, userID, quota.MonthlyJobLimit, quota.MonthlyCostLimitUSD)
+	if err != nil {
+		return fmt.Errorf("failed to set extraction quota: %w", err)
+	}
+	return nil
+}
+
+func (s *ExtractionJobStore) CreateBatch(ctx context.Context, userID int, name string, skippedCount int, jobs []store.ExtractionBatchJob) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var batchID int
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO extraction_batches (user_id, name, skipped_count) VALUES ($1, $2, $3) RETURNING id`,
+		userID, name, skippedCount).Scan(&batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create extraction batch: %w", err)
+	}
+
+	for _, job := range jobs {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO extraction_jobs (user_id, job_type, input_url, batch_id, priority, retry_after) VALUES ($1, $2, $3, $4, $5, $6)`,
+			userID, job.JobType, job.InputURL, batchID, job.Priority, job.NotBefore)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create batch extraction job: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return batchID, nil
+}
+
+const batchSelect = `
+	SELECT
+		b.id, b.user_id, b.name, b.skipped_count, b.created_at,
+		COUNT(ej.id),
+		COUNT(ej.id) FILTER (WHERE ej.status = 'pending'),
+		COUNT(ej.id) FILTER (WHERE ej.status = 'processing'),
+		COUNT(ej.id) FILTER (WHERE ej.status = 'completed'),
+		COUNT(ej.id) FILTER (WHERE ej.status = 'failed'),
+		COUNT(ej.id) FILTER (WHERE ej.status = 'cancelled')
+	FROM extraction_batches b
+	LEFT JOIN extraction_jobs ej ON ej.batch_id = b.id`
+
+func (s *ExtractionJobStore) GetBatch(ctx context.Context, id int) (*store.ExtractionBatch, error) {
+	query := batchSelect + `
+		WHERE b.id = $1
+		GROUP BY b.id`
+
+	batch, err := scanBatch(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get extraction batch: %w", err)
+	}
+	return batch, nil
+}
+
+func (s *ExtractionJobStore) GetBatchesByUserID(ctx context.Context, userID int, limit int) ([]store.ExtractionBatch, error) {
+	query := batchSelect + `
+		WHERE b.user_id = $1
+		GROUP BY b.id
+		ORDER BY b.created_at DESC
+		LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extraction batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []store.ExtractionBatch
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan extraction batch: %w", err)
+		}
+		batches = append(batches, *batch)
+	}
+	return batches, rows.Err()
+}
+
+func (s *ExtractionJobStore) GetBatchJobs(ctx context.Context, batchID int) ([]store.ExtractionJob, error) {
+	query := `
+		SELECT 
+			ej.id, ej.user_id, u.username, ej.job_type, ej.input_url,
+			(SELECT COUNT(*) FROM extraction_job_inputs eji WHERE eji.job_id = ej.id),
+			ej.status, ej.error_message, NULL, NULL,
+			ej.recipe_id, r.title, ej.attempt_count,
+			ej.prompt_tokens, ej.completion_tokens, ej.audio_tokens, ej.cost_usd,
+			ej.created_at, ej.updated_at, ej.completed_at
+		FROM extraction_jobs ej
+		JOIN users u ON ej.user_id = u.id
+		LEFT JOIN recipes r ON ej.recipe_id = r.id
+		WHERE ej.batch_id = $1
+		ORDER BY ej.id ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch extraction jobs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanJobs(rows)
+}
+
+func scanBatch(row rowScanner) (*store.ExtractionBatch, error) {
+	var batch store.ExtractionBatch
+	err := row.Scan(
+		&batch.ID, &batch.UserID, &batch.Name, &batch.SkippedCount, &batch.CreatedAt,
+		&batch.Total, &batch.Pending, &batch.Processing, &batch.Completed, &batch.Failed, &batch.Cancelled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUserUsage(row rowScanner) (*store.UserExtractionUsage, error) {
+	var usage store.UserExtractionUsage
+	var jobLimit sql.NullInt64
+	var costLimit sql.NullFloat64
+	err := row.Scan(
+		&usage.UserID, &usage.Username, &usage.JobCount,
+		&usage.Usage.PromptTokens, &usage.Usage.CompletionTokens, &usage.Usage.AudioTokens, &usage.Usage.CostUSD,
+		&jobLimit, &costLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobLimit.Valid {
+		limit := int(jobLimit.Int64)
+		usage.Quota.MonthlyJobLimit = &limit
+	}
+	if costLimit.Valid {
+		usage.Quota.MonthlyCostLimitUSD = &costLimit.Float64
+	}
+	return &usage, nil
+}
+
 func scanJobs(rows *sql.Rows) ([]store.ExtractionJob, error) {
 	var jobs []store.ExtractionJob
 	for rows.Next() {
 		var job store.ExtractionJob
 		err := rows.Scan(
-			&job.ID, &job.UserID, &job.Username, &job.JobType, &job.InputURL, &job.InputData,
+			&job.ID, &job.UserID, &job.Username, &job.JobType, &job.InputURL, &job.InputCount,
 			&job.Status, &job.ErrorMessage, &job.LLMInput, &job.LLMOutput,
-			&job.RecipeID, &job.RecipeTitle, &job.AttemptCount, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+			&job.RecipeID, &job.RecipeTitle, &job.AttemptCount,
+			&job.Usage.PromptTokens, &job.Usage.CompletionTokens, &job.Usage.AudioTokens, &job.Usage.CostUSD,
+			&job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan extraction job: %w", err)
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+// ExtractionJobEventsChannel is the channel the extraction_jobs triggers
+// notify on when a job is created or changes status.
+const ExtractionJobEventsChannel = "extraction_job_events"
+
+// listenerPingInterval is how often an idle listener connection is checked,
+// so a connection that silently died is noticed and re-established.
+const listenerPingInterval = 90 * time.Second
+
+// ListenExtractionJobEvents passes every extraction job event to publish until
+// ctx is cancelled. It holds its own connection outside the pool and
+// reconnects when it is lost; events sent while disconnected are missed.
+func ListenExtractionJobEvents(ctx context.Context, connectionString string, publish func(store.ExtractionJobEvent)) error {
+	return listenExtractionJobEvents(ctx, connectionString, publish, nil)
+}
+
+// listenExtractionJobEvents is ListenExtractionJobEvents, calling ready, if
+// not nil, once changes are being listened for.
+func listenExtractionJobEvents(ctx context.Context, connectionString string, publish func(store.ExtractionJobEvent), ready func()) error {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			slog.Warn("Lost connection for extraction job events", "error", err)
+		case pq.ListenerEventReconnected:
+			slog.Info("Reconnected for extraction job events")
+		case pq.ListenerEventConnectionAttemptFailed:
+			slog.Warn("Failed to connect for extraction job events", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(ExtractionJobEventsChannel); err != nil {
+		return fmt.Errorf("failed to listen for extraction job events: %w", err)
+	}
+	if ready != nil {
+		ready()
+	}
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case notification := <-listener.Notify:
+			// A nil notification signals a reconnect.
+			if notification == nil {
+				continue
+			}
+			var event store.ExtractionJobEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				slog.Warn("Ignoring malformed extraction job event", "payload", notification.Extra, "error", err)
+				continue
+			}
+			publish(event)
+
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
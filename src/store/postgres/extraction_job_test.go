@@ -0,0 +1,490 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func TestExtractionJobStore_Heartbeat_FailsForJobClaimedByOtherWorker(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 10); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+
+	if err := jobStore.Heartbeat(ctx, jobID, "worker-a"); err != nil {
+		t.Errorf("expected heartbeat of the claiming worker to succeed, got %v", err)
+	}
+	if err := jobStore.Heartbeat(ctx, jobID, "worker-b"); !errors.Is(err, store.ErrJobNotClaimed) {
+		t.Errorf("expected ErrJobNotClaimed for other worker, got %v", err)
+	}
+}
+
+func TestExtractionJobStore_ReapStaleJobs_RequeuesOrFailsStaleJobs(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	var jobIDs []int
+	for i := 0; i < 3; i++ {
+		jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 10); err != nil {
+			t.Fatalf("failed to claim job: %v", err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	staleID, exhaustedID, aliveID := jobIDs[0], jobIDs[1], jobIDs[2]
+
+	_, err := testDB.DB.Exec(`UPDATE extraction_jobs SET heartbeat_at = NOW() - INTERVAL '10 minutes', attempt_count = 1 WHERE id = $1`, staleID)
+	if err != nil {
+		t.Fatalf("failed to age job: %v", err)
+	}
+	_, err = testDB.DB.Exec(`UPDATE extraction_jobs SET heartbeat_at = NOW() - INTERVAL '10 minutes', attempt_count = 3 WHERE id = $1`, exhaustedID)
+	if err != nil {
+		t.Fatalf("failed to age job: %v", err)
+	}
+
+	reaped, err := jobStore.ReapStaleJobs(ctx, time.Now().Add(-3*time.Minute), 3)
+	if err != nil {
+		t.Fatalf("failed to reap stale jobs: %v", err)
+	}
+	if len(reaped) != 2 {
+		t.Fatalf("expected 2 reaped jobs, got %d", len(reaped))
+	}
+
+	wantStatus := map[int]string{staleID: "pending", exhaustedID: "failed", aliveID: "processing"}
+	for id, want := range wantStatus {
+		job, err := jobStore.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		if job.Status != want {
+			t.Errorf("job %d status = %q, want %q", id, job.Status, want)
+		}
+	}
+}
+
+func TestExtractionJobStore_ReleaseJob_DoesNotCountInterruptedAttempt(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 10); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if err := jobStore.IncrementAttemptCount(ctx, jobID); err != nil {
+		t.Fatalf("failed to increment attempt count: %v", err)
+	}
+
+	if err := jobStore.ReleaseJob(ctx, jobID, "worker-a"); err != nil {
+		t.Fatalf("failed to release job: %v", err)
+	}
+
+	job, err := jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if job.Status != "pending" || job.AttemptCount != 0 {
+		t.Errorf("got status %q with %d attempts, want pending with 0 attempts", job.Status, job.AttemptCount)
+	}
+}
+
+func TestExtractionJobStore_ClaimPendingJob_TakesTurnsBetweenUsers(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	busyUser := testDB.SeedUser(t, "busy", "busy@example.com", "hashedpassword", false)
+	otherUser := testDB.SeedUser(t, "other", "other@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := jobStore.Create(ctx, busyUser, "text", nil, nil); err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+	}
+	otherJobID, err := jobStore.Create(ctx, otherUser, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	first, err := jobStore.ClaimPendingJob(ctx, "worker-a", 1)
+	if err != nil || first == nil {
+		t.Fatalf("failed to claim first job: %v", err)
+	}
+	if first.UserID != busyUser {
+		t.Fatalf("expected the oldest job to be claimed first, got user %d", first.UserID)
+	}
+
+	second, err := jobStore.ClaimPendingJob(ctx, "worker-a", 1)
+	if err != nil || second == nil {
+		t.Fatalf("failed to claim second job: %v", err)
+	}
+	if second.ID != otherJobID {
+		t.Errorf("expected the other user's job next, got job %d of user %d", second.ID, second.UserID)
+	}
+
+	third, err := jobStore.ClaimPendingJob(ctx, "worker-a", 1)
+	if err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if third != nil {
+		t.Errorf("expected no job while both users are at their concurrency cap, got job %d", third.ID)
+	}
+}
+
+func TestExtractionJobStore_Cancel_MakesHeartbeatReportCancellation(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 1); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+
+	cancelled, err := jobStore.Cancel(ctx, jobID)
+	if err != nil || !cancelled {
+		t.Fatalf("expected job to be cancelled, got %v, %v", cancelled, err)
+	}
+	if err := jobStore.Heartbeat(ctx, jobID, "worker-a"); !errors.Is(err, store.ErrJobCancelled) {
+		t.Errorf("expected ErrJobCancelled, got %v", err)
+	}
+	if err := jobStore.MarkCompleted(ctx, jobID, nil); err != nil {
+		t.Fatalf("failed to mark job completed: %v", err)
+	}
+
+	job, _ := jobStore.GetByID(ctx, jobID)
+	if job.Status != "cancelled" {
+		t.Errorf("status = %q, want cancelled to stick", job.Status)
+	}
+
+	if cancelled, _ := jobStore.Cancel(ctx, jobID); cancelled {
+		t.Error("expected a cancelled job not to be cancelled again")
+	}
+}
+
+func TestExtractionJobStore_CreateReExtraction_KeepsTargetAndProposal(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	recipeID := testDB.SeedRecipe(t, "Pancakes", "- flour", "Mix.", userID)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	model := "google/gemini-2.5-flash"
+	jobID, err := jobStore.CreateReExtraction(ctx, userID, recipeID, "website", "https://example.com/pancakes", &model)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	claimed, err := jobStore.ClaimPendingJob(ctx, "worker-a", 10)
+	if err != nil || claimed == nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if claimed.TargetRecipeID == nil || *claimed.TargetRecipeID != recipeID {
+		t.Errorf("claimed job target = %v, want %d", claimed.TargetRecipeID, recipeID)
+	}
+	if claimed.Model == nil || *claimed.Model != model {
+		t.Errorf("claimed job model = %v, want %q", claimed.Model, model)
+	}
+
+	if err := jobStore.SetProposedRecipe(ctx, jobID, []byte(`{"title": "Fluffy Pancakes"}`)); err != nil {
+		t.Fatalf("failed to set proposed recipe: %v", err)
+	}
+
+	job, err := jobStore.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if !strings.Contains(string(job.ProposedRecipe), "Fluffy Pancakes") {
+		t.Errorf("proposed recipe = %s, want the stored proposal", job.ProposedRecipe)
+	}
+	if job.RecipeID != nil {
+		t.Errorf("expected re-extraction not to link a new recipe, got %d", *job.RecipeID)
+	}
+}
+
+func TestExtractionJobStore_GetWithExtractedRecipe_ReturnsJobsWithExistingRecipes(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	keptID := testDB.SeedRecipe(t, "Pancakes", "- flour", "Mix.", userID)
+	deletedID := testDB.SeedRecipe(t, "Waffles", "- flour", "Mix.", userID)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	snapshot := []byte(`{"title": "Pancakes"}`)
+	var jobIDs []int
+	for _, recipeID := range []int{keptID, deletedID, 0} {
+		url := "https://example.com/recipe"
+		jobID, err := jobStore.Create(ctx, userID, "website", &url, nil)
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		if recipeID != 0 {
+			if err := jobStore.SetRecipeID(ctx, jobID, recipeID); err != nil {
+				t.Fatalf("failed to set recipe ID: %v", err)
+			}
+			if err := jobStore.SetExtractedRecipe(ctx, jobID, snapshot); err != nil {
+				t.Fatalf("failed to set extracted recipe: %v", err)
+			}
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	if _, err := testDB.DB.ExecContext(ctx, `DELETE FROM recipes WHERE id = $1`, deletedID); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+
+	jobs, err := jobStore.GetWithExtractedRecipe(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to get jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != jobIDs[0] {
+		t.Fatalf("got %d jobs, want only job %d whose recipe still exists", len(jobs), jobIDs[0])
+	}
+	if !strings.Contains(string(jobs[0].ExtractedRecipe), "Pancakes") || jobs[0].RecipeTitle == nil || *jobs[0].RecipeTitle != "Pancakes" {
+		t.Errorf("job = %+v, want the snapshot and recipe title", jobs[0])
+	}
+
+	job, err := jobStore.GetByID(ctx, jobIDs[0])
+	if err != nil || job == nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if string(job.ExtractedRecipe) == "" {
+		t.Error("expected GetByID to return the extracted recipe")
+	}
+}
+
+func TestExtractionJobStore_GetWithExtractedRecipe_SkipsRecipesWithAppliedProposals(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	recipeID := testDB.SeedRecipe(t, "Pancakes", "- flour", "Mix.", userID)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	url := "https://example.com/pancakes"
+	jobID, err := jobStore.Create(ctx, userID, "website", &url, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if err := jobStore.SetRecipeID(ctx, jobID, recipeID); err != nil {
+		t.Fatalf("failed to set recipe ID: %v", err)
+	}
+	if err := jobStore.SetExtractedRecipe(ctx, jobID, []byte(`{"title": "Pancakes"}`)); err != nil {
+		t.Fatalf("failed to set extracted recipe: %v", err)
+	}
+	reExtractID, err := jobStore.CreateReExtraction(ctx, userID, recipeID, "website", url, nil)
+	if err != nil {
+		t.Fatalf("failed to create re-extraction: %v", err)
+	}
+
+	jobs, err := jobStore.GetWithExtractedRecipe(ctx, 10)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("got %d jobs (%v) before applying the proposal, want 1", len(jobs), err)
+	}
+
+	if err := jobStore.MarkProposalApplied(ctx, reExtractID); err != nil {
+		t.Fatalf("failed to mark proposal as applied: %v", err)
+	}
+	jobs, err = jobStore.GetWithExtractedRecipe(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to get jobs: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("got %d jobs, want none for a recipe changed by an applied proposal", len(jobs))
+	}
+}
+
+func TestExtractionJobStore_GetSubmittedURLs_ReturnsOnlyTheUsersOpenAndExtractedLinks(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	otherID := testDB.SeedUser(t, "otheruser", "other@example.com", "hashedpassword", false)
+	keptID := testDB.SeedRecipe(t, "Pancakes", "- flour", "Mix.", userID)
+	deletedID := testDB.SeedRecipe(t, "Waffles", "- flour", "Mix.", userID)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	create := func(userID int, url, status string, recipeID int) {
+		t.Helper()
+		jobID, err := jobStore.Create(ctx, userID, "website", &url, nil)
+		if err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+		if recipeID != 0 {
+			if err := jobStore.SetRecipeID(ctx, jobID, recipeID); err != nil {
+				t.Fatalf("failed to set recipe ID: %v", err)
+			}
+		}
+		if err := jobStore.UpdateStatus(ctx, jobID, status, nil); err != nil {
+			t.Fatalf("failed to update status: %v", err)
+		}
+	}
+	create(userID, "https://example.com/pending", "pending", 0)
+	create(userID, "https://example.com/kept", "completed", keptID)
+	create(userID, "https://example.com/deleted", "completed", deletedID)
+	create(userID, "https://example.com/failed", "failed", 0)
+	create(otherID, "https://example.com/other", "pending", 0)
+	if _, err := testDB.DB.ExecContext(ctx, `DELETE FROM recipes WHERE id = $1`, deletedID); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+
+	urls, err := jobStore.GetSubmittedURLs(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to get submitted URLs: %v", err)
+	}
+	slices.Sort(urls)
+	if want := []string{"https://example.com/kept", "https://example.com/pending"}; !slices.Equal(urls, want) {
+		t.Errorf("urls = %v, want %v", urls, want)
+	}
+}
+
+func TestExtractionJobStore_GetScheduledJobs_ReturnsLatestSlotPerURLOfAllUsers(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	aliceID := testDB.SeedUser(t, "alice", "alice@example.com", "hashedpassword", false)
+	bobID := testDB.SeedUser(t, "bob", "bob@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	now := time.Now()
+	url := "https://example.com/recipes/pancakes"
+	if _, err := jobStore.CreateBatch(ctx, aliceID, "alice.txt", 0, []store.ExtractionBatchJob{
+		{JobType: "website", InputURL: url, NotBefore: now.Add(time.Minute)},
+		{JobType: "website", InputURL: "https://example.com/recipes/started", NotBefore: now.Add(-time.Minute)},
+	}); err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	if _, err := jobStore.CreateBatch(ctx, bobID, "bob.txt", 0, []store.ExtractionBatchJob{
+		{JobType: "website", InputURL: url, NotBefore: now.Add(time.Hour)},
+	}); err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+
+	jobs, err := jobStore.GetScheduledJobs(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to get scheduled jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].InputURL != url || jobs[0].JobType != "website" {
+		t.Fatalf("jobs = %+v, want only %s", jobs, url)
+	}
+	if want := now.Add(time.Hour); jobs[0].NotBefore.Sub(want).Abs() > time.Second {
+		t.Errorf("not before = %v, want the latest slot %v", jobs[0].NotBefore, want)
+	}
+}
+
+func TestListenExtractionJobEvents_ReceivesStatusChanges(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan store.ExtractionJobEvent, 10)
+	ready := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- listenExtractionJobEvents(ctx, testDB.ConnectionString(), func(event store.ExtractionJobEvent) {
+			events <- event
+		}, func() { close(ready) })
+	}()
+	select {
+	case <-ready:
+	case err := <-errs:
+		t.Fatalf("listener stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the listener")
+	}
+
+	jobID, err := jobStore.Create(ctx, userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if _, err := jobStore.ClaimPendingJob(ctx, "worker-a", 1); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if err := jobStore.Heartbeat(ctx, jobID, "worker-a"); err != nil {
+		t.Fatalf("failed to send heartbeat: %v", err)
+	}
+	if err := jobStore.MarkCompleted(ctx, jobID, nil); err != nil {
+		t.Fatalf("failed to mark job completed: %v", err)
+	}
+
+	var statuses []string
+	for len(statuses) < 3 {
+		select {
+		case event := <-events:
+			if event.JobID != jobID || event.UserID != userID {
+				t.Fatalf("unexpected event: %+v", event)
+			}
+			statuses = append(statuses, event.Status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", statuses)
+		}
+	}
+	if want := []string{"pending", "processing", "completed"}; strings.Join(statuses, ",") != strings.Join(want, ",") {
+		t.Errorf("statuses = %v, want %v (heartbeats must not notify)", statuses, want)
+	}
+}
+
+func TestExtractionJobStore_GetAllUsageSince_ListsUsersWithoutJobs(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	activeID := testDB.SeedUser(t, "active", "active@example.com", "hashedpassword", false)
+	newID := testDB.SeedUser(t, "newcomer", "newcomer@example.com", "hashedpassword", false)
+	jobStore := NewExtractionJobStore(testDB.DB)
+	ctx := context.Background()
+
+	if _, err := jobStore.Create(ctx, activeID, "text", nil, nil); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	usages, err := jobStore.GetAllUsageSince(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+
+	jobCounts := map[int]int{}
+	for _, usage := range usages {
+		jobCounts[usage.UserID] = usage.JobCount
+	}
+	if count, ok := jobCounts[activeID]; !ok || count != 1 {
+		t.Errorf("expected one job for the active user, got %v", jobCounts)
+	}
+	if count, ok := jobCounts[newID]; !ok || count != 0 {
+		t.Errorf("expected the user without jobs to be listed, got %v", jobCounts)
+	}
+}
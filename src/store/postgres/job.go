@@ -0,0 +1,280 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type JobStore struct {
+	db *sql.DB
+}
+
+func NewJobStore(db *sql.DB) *JobStore {
+	return &JobStore{db: db}
+}
+
+const jobColumns = `id, job_type, payload, status, attempt_count, last_error, retry_after, worker_id, created_at, updated_at, completed_at`
+
+func scanJob(row interface{ Scan(...any) error }) (*store.Job, error) {
+	var job store.Job
+	err := row.Scan(
+		&job.ID, &job.Type, &job.Payload, &job.Status, &job.AttemptCount, &job.LastError,
+		&job.RetryAfter, &job.WorkerID, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *JobStore) queryJobs(ctx context.Context, query string, args ...any) ([]store.Job, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []store.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *JobStore) Enqueue(ctx context.Context, jobType string, payload []byte, runAfter time.Time) (int, error) {
+	var retryAfter *time.Time
+	if !runAfter.IsZero() {
+		retryAfter = &runAfter
+	}
+
+	var id int
+	query := `INSERT INTO jobs (job_type, payload, retry_after) VALUES ($1, $2, $3) RETURNING id`
+	if err := s.db.QueryRowContext(ctx, query, jobType, payload, retryAfter).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return id, nil
+}
+
+func (s *JobStore) GetByID(ctx context.Context, id int) (*store.Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *JobStore) List(ctx context.Context, status string, limit, offset int) ([]store.Job, error) {
+	query := `
+		SELECT ` + jobColumns + ` FROM jobs
+		WHERE $1::text = '' OR status = $1
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+	jobs, err := s.queryJobs(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *JobStore) Count(ctx context.Context, status string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM jobs WHERE $1::text = '' OR status = $1`
+	if err := s.db.QueryRowContext(ctx, query, status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return count, nil
+}
+
+func (s *JobStore) CountByTypeAndStatus(ctx context.Context) ([]store.JobCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT job_type, status, COUNT(*)
+		FROM jobs
+		GROUP BY job_type, status
+		ORDER BY job_type, status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []store.JobCount
+	for rows.Next() {
+		var count store.JobCount
+		if err := rows.Scan(&count.Type, &count.Status, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// Claim takes the oldest due job of the given types. The attempt is counted
+// right away, so a job that crashes its worker still uses up an attempt.
+func (s *JobStore) Claim(ctx context.Context, workerID string, jobTypes []string) (*store.Job, error) {
+	if len(jobTypes) == 0 {
+		return nil, nil
+	}
+
+	args := []any{workerID}
+	placeholders := make([]string, len(jobTypes))
+	for i, jobType := range jobTypes {
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args = append(args, jobType)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE jobs
+		SET status = 'processing', worker_id = $1, heartbeat_at = NOW(),
+		    attempt_count = attempt_count + 1, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'pending'
+			  AND job_type IN (%s)
+			  AND (retry_after IS NULL OR retry_after <= NOW())
+			ORDER BY COALESCE(retry_after, created_at), id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns, strings.Join(placeholders, ", "))
+
+	job, err := scanJob(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *JobStore) Heartbeat(ctx context.Context, id int, workerID string) error {
+	query := `
+		UPDATE jobs
+		SET heartbeat_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`
+	result, err := s.db.ExecContext(ctx, query, id, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+	if rows == 0 {
+		return store.ErrJobNotClaimed
+	}
+	return nil
+}
+
+func (s *JobStore) Complete(ctx context.Context, id int) error {
+	query := `
+		UPDATE jobs
+		SET status = 'completed', last_error = NULL, worker_id = NULL, heartbeat_at = NULL,
+		    completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'`
+	if _, err := s.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+func (s *JobStore) Retry(ctx context.Context, id int, retryAfter time.Time, lastError string) error {
+	query := `
+		UPDATE jobs
+		SET status = 'pending', last_error = $2, retry_after = $3, worker_id = NULL, heartbeat_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'`
+	if _, err := s.db.ExecContext(ctx, query, id, lastError, retryAfter); err != nil {
+		return fmt.Errorf("failed to schedule job retry: %w", err)
+	}
+	return nil
+}
+
+func (s *JobStore) Fail(ctx context.Context, id int, lastError string) error {
+	query := `
+		UPDATE jobs
+		SET status = 'failed', last_error = $2, worker_id = NULL, heartbeat_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'`
+	if _, err := s.db.ExecContext(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to fail job: %w", err)
+	}
+	return nil
+}
+
+// Release hands a claimed job back to the queue without counting the
+// interrupted attempt, e.g. when the worker shuts down.
+func (s *JobStore) Release(ctx context.Context, id int, workerID string) error {
+	query := `
+		UPDATE jobs
+		SET status = 'pending', worker_id = NULL, heartbeat_at = NULL,
+		    attempt_count = GREATEST(attempt_count - 1, 0), updated_at = NOW()
+		WHERE id = $1 AND worker_id = $2 AND status = 'processing'`
+	if _, err := s.db.ExecContext(ctx, query, id, workerID); err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
+	return nil
+}
+
+// ReapStale requeues processing jobs whose last heartbeat is older than
+// staleBefore, and fails those that already had maxAttempts attempts. The
+// returned jobs carry the worker they were taken from.
+func (s *JobStore) ReapStale(ctx context.Context, staleBefore time.Time, maxAttempts int) ([]store.Job, error) {
+	query := `
+		WITH stale AS (
+			SELECT id, worker_id FROM jobs
+			WHERE status = 'processing'
+			  AND COALESCE(heartbeat_at, updated_at) < $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE jobs j
+		SET status = CASE WHEN j.attempt_count >= $2 THEN 'failed' ELSE 'pending' END,
+		    last_error = CASE WHEN j.attempt_count >= $2
+		        THEN 'The job was interrupted too many times' ELSE j.last_error END,
+		    worker_id = NULL,
+		    heartbeat_at = NULL,
+		    updated_at = NOW()
+		FROM stale
+		WHERE j.id = stale.id
+		RETURNING j.id, j.job_type, j.payload, j.status, j.attempt_count, j.last_error,
+		          j.retry_after, stale.worker_id, j.created_at, j.updated_at, j.completed_at`
+	jobs, err := s.queryJobs(ctx, query, staleBefore, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reap stale jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (s *JobStore) RetryNow(ctx context.Context, id int) (bool, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'pending', attempt_count = 0, retry_after = NULL, updated_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'failed')`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry job: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retry job: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (s *JobStore) DeleteCompletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE status = 'completed' AND completed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete completed jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
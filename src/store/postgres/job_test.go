@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func TestJobStore_Claim_TakesDueJobsOfTheGivenTypes(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	store := NewJobStore(testDB.DB)
+	ctx := context.Background()
+
+	if _, err := store.Enqueue(ctx, "other", []byte(`{}`), time.Time{}); err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+	if _, err := store.Enqueue(ctx, "mail", []byte(`{"to":"later"}`), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+	dueID, err := store.Enqueue(ctx, "mail", []byte(`{"to":"now"}`), time.Time{})
+	if err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+
+	job, err := store.Claim(ctx, "worker-a", []string{"mail"})
+	if err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if job == nil || job.ID != dueID {
+		t.Fatalf("expected to claim job %d, got %+v", dueID, job)
+	}
+	if job.Status != "processing" || job.AttemptCount != 1 {
+		t.Errorf("expected a processing job on its first attempt, got %s on attempt %d", job.Status, job.AttemptCount)
+	}
+
+	job, err = store.Claim(ctx, "worker-a", []string{"mail"})
+	if err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if job != nil {
+		t.Errorf("expected no due mail job left, got %+v", job)
+	}
+}
+
+func TestJobStore_RetryAndRetryNow(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	store := NewJobStore(testDB.DB)
+	ctx := context.Background()
+
+	id, err := store.Enqueue(ctx, "mail", []byte(`{}`), time.Time{})
+	if err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+	if _, err := store.Claim(ctx, "worker-a", []string{"mail"}); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+	if err := store.Retry(ctx, id, time.Now().Add(time.Hour), "provider down"); err != nil {
+		t.Fatalf("failed to retry job: %v", err)
+	}
+
+	job, err := store.Claim(ctx, "worker-a", []string{"mail"})
+	if err != nil || job != nil {
+		t.Fatalf("expected the retried job not to be due yet, got %+v (%v)", job, err)
+	}
+	waiting, err := store.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if waiting.Status != "pending" || waiting.LastError == nil || *waiting.LastError != "provider down" || waiting.RetryAfter == nil {
+		t.Errorf("unexpected waiting job %+v", waiting)
+	}
+
+	retried, err := store.RetryNow(ctx, id)
+	if err != nil || !retried {
+		t.Fatalf("expected RetryNow to queue the job, got %v (%v)", retried, err)
+	}
+	job, err = store.Claim(ctx, "worker-a", []string{"mail"})
+	if err != nil || job == nil {
+		t.Fatalf("expected to claim the job again, got %+v (%v)", job, err)
+	}
+	if job.AttemptCount != 1 {
+		t.Errorf("expected a fresh set of attempts, got attempt %d", job.AttemptCount)
+	}
+}
+
+func TestJobStore_ReapStale_FailsJobsOutOfAttempts(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+	store := NewJobStore(testDB.DB)
+	ctx := context.Background()
+
+	id, err := store.Enqueue(ctx, "mail", []byte(`{}`), time.Time{})
+	if err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+	if _, err := store.Claim(ctx, "worker-a", []string{"mail"}); err != nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+
+	reaped, err := store.ReapStale(ctx, time.Now().Add(time.Minute), 1)
+	if err != nil {
+		t.Fatalf("failed to reap stale jobs: %v", err)
+	}
+	if len(reaped) != 1 || reaped[0].ID != id || reaped[0].Status != "failed" {
+		t.Fatalf("expected job %d to be failed, got %+v", id, reaped)
+	}
+	if reaped[0].WorkerID == nil || *reaped[0].WorkerID != "worker-a" {
+		t.Errorf("expected the reaped job to name its previous worker, got %v", reaped[0].WorkerID)
+	}
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+)
+
+type NotificationStore struct {
+	db *sql.DB
+}
+
+func NewNotificationStore(db *sql.DB) *NotificationStore {
+	return &NotificationStore{db: db}
+}
+
+func (s *NotificationStore) Create(ctx context.Context, notification store.Notification) (int, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, link)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	var id int
+	err := s.db.QueryRowContext(ctx, query,
+		notification.UserID, notification.Type, notification.Title, notification.Body, notification.Link,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *NotificationStore) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]store.Notification, error) {
+	query := `
+		SELECT id, user_id, type, title, body, link, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []store.Notification
+	for rows.Next() {
+		var n store.Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.Link, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (s *NotificationStore) CountByUserID(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (s *NotificationStore) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (s *NotificationStore) MarkRead(ctx context.Context, userID, id int) (*store.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, type, title, body, link, read_at, created_at`
+
+	var n store.Notification
+	err := s.db.QueryRowContext(ctx, query, id, userID).Scan(
+		&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.Link, &n.ReadAt, &n.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	return &n, nil
+}
+
+func (s *NotificationStore) MarkAllRead(ctx context.Context, userID int) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+func (s *NotificationStore) GetEmailPreferences(ctx context.Context, userID int) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT type, email FROM notification_preferences WHERE user_id = $1`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	preferences := make(map[string]bool)
+	for rows.Next() {
+		var notificationType string
+		var email bool
+		if err := rows.Scan(&notificationType, &email); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences[notificationType] = email
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+func (s *NotificationStore) SetEmailPreference(ctx context.Context, userID int, notificationType string, email bool) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, type, email)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, type) DO UPDATE SET email = EXCLUDED.email`
+
+	_, err := s.db.ExecContext(ctx, query, userID, notificationType, email)
+	if err != nil {
+		return fmt.Errorf("failed to set notification preference: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/store"
+	"github.com/mr-flannery/go-recipe-book/src/testutil"
+)
+
+func TestNotificationStore_MarkRead_OnlyMarksOwnNotifications(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	otherID := testDB.SeedUser(t, "other", "other@example.com", "hashedpassword", false)
+	notificationStore := NewNotificationStore(testDB.DB)
+	ctx := context.Background()
+
+	var ids []int
+	for _, title := range []string{"First", "Second"} {
+		id, err := notificationStore.Create(ctx, store.Notification{UserID: userID, Type: "recipe_comment", Title: title, Link: "/recipes/1"})
+		if err != nil {
+			t.Fatalf("failed to create notification: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if n, err := notificationStore.MarkRead(ctx, otherID, ids[0]); err != nil || n != nil {
+		t.Fatalf("expected other users not to mark the notification, got %+v, %v", n, err)
+	}
+	n, err := notificationStore.MarkRead(ctx, userID, ids[0])
+	if err != nil || n == nil || n.ReadAt == nil {
+		t.Fatalf("expected notification to be marked read, got %+v, %v", n, err)
+	}
+
+	unread, err := notificationStore.CountUnread(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to count unread notifications: %v", err)
+	}
+	if unread != 1 {
+		t.Errorf("unread = %d, want 1", unread)
+	}
+
+	if err := notificationStore.MarkAllRead(ctx, userID); err != nil {
+		t.Fatalf("failed to mark all read: %v", err)
+	}
+	if unread, _ := notificationStore.CountUnread(ctx, userID); unread != 0 {
+		t.Errorf("unread after marking all read = %d, want 0", unread)
+	}
+}
+
+func TestNotificationStore_SetEmailPreference_OverwritesPreviousChoice(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	notificationStore := NewNotificationStore(testDB.DB)
+	ctx := context.Background()
+
+	if err := notificationStore.SetEmailPreference(ctx, userID, "recipe_comment", true); err != nil {
+		t.Fatalf("failed to set preference: %v", err)
+	}
+	if err := notificationStore.SetEmailPreference(ctx, userID, "recipe_comment", false); err != nil {
+		t.Fatalf("failed to set preference: %v", err)
+	}
+
+	preferences, err := notificationStore.GetEmailPreferences(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to get preferences: %v", err)
+	}
+	if email, ok := preferences["recipe_comment"]; !ok || email {
+		t.Errorf("preferences = %v, want recipe_comment turned off", preferences)
+	}
+	if len(preferences) != 1 {
+		t.Errorf("expected only the explicit choice to be stored, got %v", preferences)
+	}
+}
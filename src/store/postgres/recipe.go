@@ -19,11 +19,11 @@ func NewRecipeStore(db *sql.DB) *RecipeStore {
 }
 
 func (s *RecipeStore) Save(ctx context.Context, recipe models.Recipe) (int, error) {
-	query := `INSERT INTO recipes (title, description, ingredients_md, instructions_md, prep_time, cook_time, calories, source, author_id, image, parent_id, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`
+	query := `INSERT INTO recipes (title, description, ingredients_md, instructions_md, prep_time, cook_time, calories, source, author_id, image, parent_id, language, translation_of, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $15) RETURNING id`
 
 	var id int
-	err := s.db.QueryRowContext(ctx, query, recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD, recipe.PrepTime, recipe.CookTime, recipe.Calories, recipe.Source, recipe.AuthorID, recipe.Image, recipe.ParentID, time.Now(), time.Now()).Scan(&id)
+	err := s.db.QueryRowContext(ctx, query, recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD, recipe.PrepTime, recipe.CookTime, recipe.Calories, recipe.Source, recipe.AuthorID, recipe.Image, recipe.ParentID, recipe.Language, recipe.TranslationOf, time.Now(), time.Now()).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -35,8 +35,8 @@ func (s *RecipeStore) GetByID(ctx context.Context, id string) (models.Recipe, er
 	var recipe models.Recipe
 
 	err := s.db.
-		QueryRowContext(ctx, "SELECT id, title, COALESCE(description, ''), ingredients_md, instructions_md, prep_time, cook_time, calories, COALESCE(source, ''), author_id, image, parent_id, created_at, updated_at FROM recipes WHERE id = $1", id).
-		Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.CreatedAt, &recipe.UpdatedAt)
+		QueryRowContext(ctx, "SELECT id, title, COALESCE(description, ''), ingredients_md, instructions_md, prep_time, cook_time, calories, COALESCE(source, ''), author_id, image, parent_id, COALESCE(language, ''), translation_of, created_at, updated_at FROM recipes WHERE id = $1", id).
+		Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.Language, &recipe.TranslationOf, &recipe.CreatedAt, &recipe.UpdatedAt)
 
 	if err != nil {
 		return models.Recipe{}, err
@@ -45,9 +45,39 @@ func (s *RecipeStore) GetByID(ctx context.Context, id string) (models.Recipe, er
 	return recipe, nil
 }
 
+func (s *RecipeStore) GetByIDs(ctx context.Context, ids []int) (map[int]models.Recipe, error) {
+	result := make(map[int]models.Recipe)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := fmt.Sprintf("SELECT id, title, COALESCE(description, ''), ingredients_md, instructions_md, prep_time, cook_time, calories, COALESCE(source, ''), author_id, parent_id, COALESCE(language, ''), translation_of, created_at, updated_at FROM recipes WHERE id IN (%s)", strings.Join(placeholders, ","))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recipes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipe models.Recipe
+		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.ParentID, &recipe.Language, &recipe.TranslationOf, &recipe.CreatedAt, &recipe.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recipe: %w", err)
+		}
+		result[recipe.ID] = recipe
+	}
+	return result, rows.Err()
+}
+
 func (s *RecipeStore) Update(ctx context.Context, recipe models.Recipe) error {
-	_, err := s.db.ExecContext(ctx, "UPDATE recipes SET title = $1, description = $2, ingredients_md = $3, instructions_md = $4, prep_time = $5, cook_time = $6, calories = $7, source = $8, image = $9, updated_at = $10 WHERE id = $11",
-		recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD, recipe.PrepTime, recipe.CookTime, recipe.Calories, recipe.Source, recipe.Image, time.Now(), recipe.ID)
+	_, err := s.db.ExecContext(ctx, "UPDATE recipes SET title = $1, description = $2, ingredients_md = $3, instructions_md = $4, prep_time = $5, cook_time = $6, calories = $7, source = $8, image = $9, language = NULLIF($10, ''), updated_at = $11 WHERE id = $12",
+		recipe.Title, recipe.Description, recipe.IngredientsMD, recipe.InstructionsMD, recipe.PrepTime, recipe.CookTime, recipe.Calories, recipe.Source, recipe.Image, recipe.Language, time.Now(), recipe.ID)
 
 	return err
 }
@@ -58,7 +88,7 @@ func (s *RecipeStore) Delete(ctx context.Context, id string) error {
 }
 
 func (s *RecipeStore) GetAll(ctx context.Context) ([]models.Recipe, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT id, title, COALESCE(description, ''), ingredients_md, instructions_md, prep_time, cook_time, calories, COALESCE(source, ''), author_id, image, parent_id, created_at, updated_at FROM recipes")
+	rows, err := s.db.QueryContext(ctx, "SELECT id, title, COALESCE(description, ''), ingredients_md, instructions_md, prep_time, cook_time, calories, COALESCE(source, ''), author_id, image, parent_id, COALESCE(language, ''), translation_of, created_at, updated_at FROM recipes")
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch recipes: %v", err)
@@ -68,7 +98,7 @@ func (s *RecipeStore) GetAll(ctx context.Context) ([]models.Recipe, error) {
 	var recipes []models.Recipe
 	for rows.Next() {
 		var recipe models.Recipe
-		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.CreatedAt, &recipe.UpdatedAt); err != nil {
+		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.Language, &recipe.TranslationOf, &recipe.CreatedAt, &recipe.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan recipe: %v", err)
 		}
 		recipes = append(recipes, recipe)
@@ -82,7 +112,7 @@ func (s *RecipeStore) GetAll(ctx context.Context) ([]models.Recipe, error) {
 }
 
 func (s *RecipeStore) GetFiltered(ctx context.Context, params models.FilterParams) ([]models.Recipe, error) {
-	query := "SELECT DISTINCT r.id, r.title, COALESCE(r.description, ''), r.ingredients_md, r.instructions_md, r.prep_time, r.cook_time, r.calories, COALESCE(r.source, ''), r.author_id, r.image, r.parent_id, r.created_at, r.updated_at FROM recipes r"
+	query := "SELECT DISTINCT r.id, r.title, COALESCE(r.description, ''), r.ingredients_md, r.instructions_md, r.prep_time, r.cook_time, r.calories, COALESCE(r.source, ''), r.author_id, r.image, r.parent_id, COALESCE(r.language, ''), r.translation_of, r.created_at, r.updated_at FROM recipes r"
 	args := []interface{}{}
 	argIndex := 1
 
@@ -214,7 +244,7 @@ func (s *RecipeStore) GetFiltered(ctx context.Context, params models.FilterParam
 	var recipes []models.Recipe
 	for rows.Next() {
 		var recipe models.Recipe
-		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.CreatedAt, &recipe.UpdatedAt); err != nil {
+		if err := rows.Scan(&recipe.ID, &recipe.Title, &recipe.Description, &recipe.IngredientsMD, &recipe.InstructionsMD, &recipe.PrepTime, &recipe.CookTime, &recipe.Calories, &recipe.Source, &recipe.AuthorID, &recipe.Image, &recipe.ParentID, &recipe.Language, &recipe.TranslationOf, &recipe.CreatedAt, &recipe.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan recipe: %v", err)
 		}
 		recipes = append(recipes, recipe)
@@ -356,6 +386,29 @@ func (s *RecipeStore) GetRandomID(ctx context.Context) (int, error) {
 	return id, nil
 }
 
+func (s *RecipeStore) GetIDs(ctx context.Context, untaggedOnly bool) ([]int, error) {
+	query := "SELECT id FROM recipes ORDER BY id"
+	if untaggedOnly {
+		query = "SELECT id FROM recipes r WHERE NOT EXISTS (SELECT 1 FROM recipe_tags rt WHERE rt.recipe_id = r.id) ORDER BY id"
+	}
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recipe IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan recipe ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 func (s *RecipeStore) SearchByTitle(ctx context.Context, query string, limit int) ([]models.RecipeSearchResult, error) {
 	searchPattern := "%" + strings.ToLower(query) + "%"
 	rows, err := s.db.QueryContext(ctx,
@@ -378,3 +431,29 @@ func (s *RecipeStore) SearchByTitle(ctx context.Context, query string, limit int
 
 	return results, rows.Err()
 }
+
+// GetTranslations returns the recipe originalID and the recipes translated
+// from it, the original first.
+func (s *RecipeStore) GetTranslations(ctx context.Context, originalID int) ([]models.RecipeTranslation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, title, COALESCE(language, ''), id = $1 FROM recipes
+		WHERE id = $1 OR translation_of = $1
+		ORDER BY id = $1 DESC, language, id`,
+		originalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+	defer rows.Close()
+
+	var translations []models.RecipeTranslation
+	for rows.Next() {
+		var t models.RecipeTranslation
+		if err := rows.Scan(&t.ID, &t.Title, &t.Language, &t.IsOriginal); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		translations = append(translations, t)
+	}
+
+	return translations, rows.Err()
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -42,7 +43,7 @@ func (s *TagStore) GetOrCreate(ctx context.Context, name string) (models.Tag, er
 
 func (s *TagStore) Search(ctx context.Context, query string) ([]models.Tag, error) {
 	searchPattern := "%" + strings.ToLower(strings.TrimSpace(query)) + "%"
-	rows, err := s.db.QueryContext(ctx, "SELECT id, name FROM tags WHERE name LIKE $1 ORDER BY name LIMIT 20", searchPattern)
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name FROM tags WHERE name LIKE $1 ORDER BY length(name), name LIMIT 20", searchPattern)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search tags: %v", err)
 	}
@@ -177,3 +178,97 @@ func (s *TagStore) SetRecipeTags(ctx context.Context, recipeID int, tagNames []s
 
 	return tx.Commit()
 }
+
+func (s *TagStore) GetMostUsed(ctx context.Context, limit int) ([]models.Tag, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.name
+		FROM tags t
+		LEFT JOIN recipe_tags rt ON t.id = rt.tag_id
+		GROUP BY t.id, t.name
+		ORDER BY COUNT(rt.tag_id) DESC, t.name
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most used tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (s *TagStore) SaveSuggestion(ctx context.Context, jobID, recipeID int, tags []string) error {
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode suggested tags: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM tag_suggestions WHERE recipe_id = $1 AND status = $2", recipeID, models.TagSuggestionPending)
+	if err != nil {
+		return fmt.Errorf("failed to replace tag suggestion: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO tag_suggestions (job_id, recipe_id, tags) VALUES ($1, $2, $3)", jobID, recipeID, tagsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save tag suggestion: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *TagStore) GetPendingSuggestions(ctx context.Context) ([]models.TagSuggestion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ts.id, ts.job_id, ts.recipe_id, r.title, ts.tags, ts.created_at
+		FROM tag_suggestions ts
+		INNER JOIN recipes r ON ts.recipe_id = r.id
+		WHERE ts.status = $1
+		ORDER BY ts.created_at, ts.id`, models.TagSuggestionPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []models.TagSuggestion
+	for rows.Next() {
+		var suggestion models.TagSuggestion
+		var tagsJSON []byte
+		if err := rows.Scan(&suggestion.ID, &suggestion.JobID, &suggestion.RecipeID, &suggestion.RecipeTitle, &tagsJSON, &suggestion.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tag suggestion: %w", err)
+		}
+		if err := json.Unmarshal(tagsJSON, &suggestion.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode suggested tags: %w", err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+func (s *TagStore) ResolveSuggestions(ctx context.Context, ids []int, status string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := []any{status, models.TagSuggestionPending}
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+3)
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf("UPDATE tag_suggestions SET status = $1, reviewed_at = NOW() WHERE status = $2 AND id IN (%s)", strings.Join(placeholders, ", "))
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to resolve tag suggestions: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/mr-flannery/go-recipe-book/src/testutil"
@@ -90,6 +91,29 @@ func TestTagStore_Search_FindsMatchingTags(t *testing.T) {
 	}
 }
 
+func TestTagStore_Search_ReturnsShortestMatchesFirst(t *testing.T) {
+
+	testDB := testutil.GetTestDatabase(t)
+
+	testDB.SeedTag(t, "chocolate-cookies")
+	testDB.SeedTag(t, "cookies")
+	testDB.SeedTag(t, "cookie")
+	store := NewTagStore(testDB.DB)
+
+	tags, err := store.Search(context.Background(), "cookie")
+	if err != nil {
+		t.Fatalf("failed to search tags: %v", err)
+	}
+
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if want := []string{"cookie", "cookies", "chocolate-cookies"}; !slices.Equal(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
 func TestTagStore_Search_ReturnsEmptyForNoMatches(t *testing.T) {
 
 	testDB := testutil.GetTestDatabase(t)
@@ -278,3 +302,91 @@ func TestTagStore_SetRecipeTags_SkipsEmptyTagNames(t *testing.T) {
 		t.Errorf("expected 1 tag (empty ones skipped), got %d", len(tags))
 	}
 }
+
+func TestTagStore_SaveSuggestion_ReplacesPendingSuggestion(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	recipeID := testDB.SeedRecipe(t, "Test Recipe", "- flour", "Mix it", userID)
+	jobID, err := NewExtractionJobStore(testDB.DB).Create(context.Background(), userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	store := NewTagStore(testDB.DB)
+	ctx := context.Background()
+
+	if err := store.SaveSuggestion(ctx, jobID, recipeID, []string{"cake"}); err != nil {
+		t.Fatalf("failed to save suggestion: %v", err)
+	}
+	if err := store.SaveSuggestion(ctx, jobID, recipeID, []string{"dessert", "baking"}); err != nil {
+		t.Fatalf("failed to save suggestion: %v", err)
+	}
+
+	suggestions, err := store.GetPendingSuggestions(ctx)
+	if err != nil {
+		t.Fatalf("failed to get suggestions: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 pending suggestion, got %d", len(suggestions))
+	}
+	if got := suggestions[0]; got.RecipeTitle != "Test Recipe" || len(got.Tags) != 2 || got.Tags[0] != "dessert" {
+		t.Errorf("unexpected suggestion %+v", got)
+	}
+}
+
+func TestTagStore_ResolveSuggestions_RemovesThemFromPending(t *testing.T) {
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	recipeID1 := testDB.SeedRecipe(t, "First Recipe", "- flour", "Mix it", userID)
+	recipeID2 := testDB.SeedRecipe(t, "Second Recipe", "- flour", "Mix it", userID)
+	jobID, err := NewExtractionJobStore(testDB.DB).Create(context.Background(), userID, "text", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	store := NewTagStore(testDB.DB)
+	ctx := context.Background()
+
+	for _, recipeID := range []int{recipeID1, recipeID2} {
+		if err := store.SaveSuggestion(ctx, jobID, recipeID, []string{"soup"}); err != nil {
+			t.Fatalf("failed to save suggestion: %v", err)
+		}
+	}
+	suggestions, err := store.GetPendingSuggestions(ctx)
+	if err != nil || len(suggestions) != 2 {
+		t.Fatalf("expected 2 pending suggestions, got %d (%v)", len(suggestions), err)
+	}
+
+	if err := store.ResolveSuggestions(ctx, []int{suggestions[0].ID}, "applied"); err != nil {
+		t.Fatalf("failed to resolve suggestions: %v", err)
+	}
+
+	suggestions, err = store.GetPendingSuggestions(ctx)
+	if err != nil {
+		t.Fatalf("failed to get suggestions: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].RecipeID != recipeID2 {
+		t.Errorf("expected only the second recipe's suggestion to be pending, got %+v", suggestions)
+	}
+}
+
+func TestTagStore_GetMostUsed_IncludesUnusedTags(t *testing.T) {
+
+	testDB := testutil.GetTestDatabase(t)
+
+	userID := testDB.SeedUser(t, "testuser", "test@example.com", "hashedpassword", false)
+	recipeID := testDB.SeedRecipe(t, "Recipe 1", "- flour", "Mix it", userID)
+	usedID := testDB.SeedTag(t, "soup")
+	testDB.SeedTag(t, "brunch")
+	testDB.SeedRecipeTag(t, recipeID, usedID)
+	store := NewTagStore(testDB.DB)
+
+	tags, err := store.GetMostUsed(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("failed to get most used tags: %v", err)
+	}
+
+	if len(tags) != 2 || tags[0].Name != "soup" || tags[1].Name != "brunch" {
+		t.Errorf("expected the used tag first and the unused one after it, got %+v", tags)
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/mr-flannery/go-recipe-book/src/models"
 )
@@ -18,16 +19,21 @@ func NewUserPreferencesStore(db *sql.DB) *UserPreferencesStore {
 func (s *UserPreferencesStore) Get(ctx context.Context, userID int) (*models.UserPreferences, error) {
 	var prefs models.UserPreferences
 	err := s.db.QueryRowContext(ctx,
-		"SELECT user_id, page_size, COALESCE(view_mode, $2), COALESCE(theme, $3) FROM user_preferences WHERE user_id = $1",
+		`SELECT user_id, page_size, COALESCE(view_mode, $2), COALESCE(theme, $3), COALESCE(translation_language, ''),
+			digest_enabled, digest_day, digest_hour
+		FROM user_preferences WHERE user_id = $1`,
 		userID, models.DefaultViewMode, models.DefaultTheme,
-	).Scan(&prefs.UserID, &prefs.PageSize, &prefs.ViewMode, &prefs.Theme)
+	).Scan(&prefs.UserID, &prefs.PageSize, &prefs.ViewMode, &prefs.Theme, &prefs.TranslationLanguage,
+		&prefs.DigestEnabled, &prefs.DigestDay, &prefs.DigestHour)
 
 	if err == sql.ErrNoRows {
 		return &models.UserPreferences{
-			UserID:   userID,
-			PageSize: models.DefaultPageSize,
-			ViewMode: models.DefaultViewMode,
-			Theme:    models.DefaultTheme,
+			UserID:     userID,
+			PageSize:   models.DefaultPageSize,
+			ViewMode:   models.DefaultViewMode,
+			Theme:      models.DefaultTheme,
+			DigestDay:  models.DefaultDigestDay,
+			DigestHour: models.DefaultDigestHour,
 		}, nil
 	}
 	if err != nil {
@@ -65,3 +71,27 @@ func (s *UserPreferencesStore) SetTheme(ctx context.Context, userID int, theme s
 	)
 	return err
 }
+
+// SetTranslationLanguage sets the language extracted recipes are translated
+// to; "" turns the translation off.
+func (s *UserPreferencesStore) SetTranslationLanguage(ctx context.Context, userID int, language string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO user_preferences (user_id, page_size, translation_language, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET translation_language = NULLIF($3, ''), updated_at = NOW()`,
+		userID, models.DefaultPageSize, language,
+	)
+	return err
+}
+
+// SetDigest subscribes the user to the weekly digest on day at hour, or
+// unsubscribes them while keeping the schedule.
+func (s *UserPreferencesStore) SetDigest(ctx context.Context, userID int, enabled bool, day time.Weekday, hour int) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO user_preferences (user_id, page_size, digest_enabled, digest_day, digest_hour, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET digest_enabled = $3, digest_day = $4, digest_hour = $5, updated_at = NOW()`,
+		userID, models.DefaultPageSize, enabled, int(day), hour,
+	)
+	return err
+}
@@ -76,7 +76,8 @@ var funcMap = template.FuncMap{
 	"slice": func(items ...any) []any {
 		return items
 	},
-	"hasPrefix": strings.HasPrefix,
+	"hasPrefix":    strings.HasPrefix,
+	"languageName": models.LanguageName,
 	"renderSource": func(source string) template.HTML {
 		if source == "" {
 			return ""
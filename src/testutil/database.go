@@ -63,8 +63,7 @@ func SetupSharedTestDatabase() *TestDatabase {
 			log.Fatalf("failed to get mapped port: %v", err)
 		}
 
-		connStr := fmt.Sprintf("host=%s port=%s user=testuser password=testpass dbname=testdb sslmode=disable",
-			host, mappedPort.Port())
+		connStr := connectionString(host, mappedPort.Port())
 
 		db, err := sql.Open("postgres", connStr)
 		if err != nil {
@@ -92,6 +91,16 @@ func SetupSharedTestDatabase() *TestDatabase {
 	return sharedDB
 }
 
+func connectionString(host, port string) string {
+	return fmt.Sprintf("host=%s port=%s user=testuser password=testpass dbname=testdb sslmode=disable", host, port)
+}
+
+// ConnectionString returns a connection string for opening further
+// connections to the test database, e.g. for LISTEN.
+func (td *TestDatabase) ConnectionString() string {
+	return connectionString(td.Host, td.Port)
+}
+
 func TeardownSharedTestDatabase() {
 	sharedMu.Lock()
 	defer sharedMu.Unlock()
@@ -167,6 +176,9 @@ func (td *TestDatabase) ResetAllTables(t *testing.T) {
 		"recipes",
 		"tags",
 		"users",
+		"email_outbox",
+		"jobs",
+		"digest_sends",
 	}
 
 	for _, table := range tables {
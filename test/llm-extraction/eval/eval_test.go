@@ -0,0 +1,306 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+)
+
+var (
+	live          = flag.Bool("live", false, "call OpenRouter instead of replaying the recordings, and record the responses and scores (needs OPENROUTER_API_KEY)")
+	update        = flag.Bool("update", false, "write this run's scores to the baseline, e.g. after accepting a regression or changing the scoring")
+	model         = flag.String("model", extraction.Models[0].ID, "OpenRouter model to evaluate")
+	promptVersion = flag.String("prompt", extraction.DefaultPromptVersion, "prompt version to evaluate")
+	allowMissing  = flag.Bool("allow-missing", false, "skip samples without a recording or request hash instead of failing, e.g. while adding samples")
+)
+
+// dataDir holds the samples, the expected recipes, the recordings and the
+// score baseline.
+const dataDir = ".."
+
+// baselineTolerance absorbs rounding in the stored scores.
+const baselineTolerance = 0.0001
+
+// evalCase is a sample with a ground truth recipe in expected/.
+type evalCase struct {
+	ID       string
+	Sample   string
+	Expected *extraction.ExtractedRecipe
+}
+
+// TestExtraction extracts every sample that has an expected recipe and fails
+// if a score dropped below the baseline. By default it replays the recorded
+// LLM responses, so it runs offline; -live records new ones.
+func TestExtraction(t *testing.T) {
+	apiKey := os.Getenv("OPENROUTER_API_KEY")
+	if *live && apiKey == "" {
+		t.Fatal("-live needs OPENROUTER_API_KEY")
+	}
+
+	cases, err := loadCases()
+	if err != nil {
+		t.Fatalf("failed to load cases: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no samples with expected recipes found")
+	}
+
+	baseline, err := loadBaseline()
+	if err != nil {
+		t.Fatalf("failed to load baseline: %v", err)
+	}
+
+	scores := make(map[string]Score)
+	for _, c := range cases {
+		key := *promptVersion + "/" + *model + "/" + c.ID
+		t.Run(c.ID, func(t *testing.T) {
+			recording := filepath.Join(dataDir, "recordings", *promptVersion, *model, c.ID+".json")
+			if !*live {
+				if _, err := os.Stat(recording); os.IsNotExist(err) {
+					missingRecording(t, "no recording for %s, run with -live to record one", key)
+				}
+			}
+
+			client := extraction.NewLLMClientWithHTTPClient(apiKey, &http.Client{
+				Timeout:   120 * time.Second,
+				Transport: &recordingTransport{path: recording, live: *live},
+			})
+			ctx := extraction.WithPromptVersion(extraction.WithModel(context.Background(), *model), *promptVersion)
+			ctx, usage := extraction.WithUsage(ctx)
+
+			recipe, err := extract(ctx, client, c.Sample)
+			if errors.Is(err, errUnhashedRecording) {
+				missingRecording(t, "recording for %s has no request hash, run with -live to re-record it", key)
+			}
+			if err != nil {
+				t.Fatalf("extraction failed: %v", err)
+			}
+
+			score := ScoreRecipe(c.Expected, recipe)
+			scores[key] = score
+			t.Logf("title %.0f%%, ingredients %.0f%%, instructions %.0f%%, metadata %.0f%%: overall %.1f%%",
+				score.Title*100, score.Ingredients*100, score.Instructions*100, score.Metadata*100, score.Overall*100)
+			if *live {
+				t.Logf("cost $%.4f", usage.CostUSD)
+			}
+
+			if previous, ok := baseline[key]; ok && score.Overall < previous-baselineTolerance {
+				t.Errorf("overall score %.1f%% is below the baseline of %.1f%%", score.Overall*100, previous*100)
+			} else if !ok && !*live && !*update {
+				t.Logf("no baseline for %s, run with -update to add one", key)
+			}
+		})
+	}
+
+	if *live || *update {
+		for key, score := range scores {
+			baseline[key] = score.Overall
+		}
+		if err := saveBaseline(baseline); err != nil {
+			t.Fatalf("failed to save baseline: %v", err)
+		}
+	}
+}
+
+// missingRecording fails the sample, or skips it with -allow-missing, so the
+// eval can't pass without comparing anything to the baseline.
+func missingRecording(t *testing.T, format string, args ...any) {
+	t.Helper()
+	if *allowMissing {
+		t.Skipf(format, args...)
+	}
+	t.Fatalf(format, args...)
+}
+
+// extract runs a sample through the LLM client the way the worker handles
+// the matching job type.
+func extract(ctx context.Context, client *extraction.LLMClient, sample string) (*extraction.ExtractedRecipe, error) {
+	data, err := os.ReadFile(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(sample)
+	var recipe *extraction.ExtractedRecipe
+	switch ext := filepath.Ext(name); {
+	case ext == ".html":
+		_, recipe, err = client.ExtractRecipeFromText(ctx, "website", extraction.ExtractTextContent(string(data)))
+	case ext == ".jpg" || ext == ".jpeg" || ext == ".png":
+		_, recipe, err = client.ExtractRecipeFromImage(ctx, data, "")
+	case ext == ".mp3":
+		_, recipe, err = client.ExtractRecipeFromAudio(ctx, data, "")
+	case ext == ".txt" && strings.HasPrefix(name, "video-"):
+		_, recipe, err = client.ExtractRecipeFromText(ctx, "transcript", string(data))
+	case ext == ".txt":
+		_, recipe, err = client.ExtractRecipeFromText(ctx, "text", string(data))
+	default:
+		return nil, fmt.Errorf("don't know how to extract %s", name)
+	}
+	return recipe, err
+}
+
+// loadCases pairs every expected/<id>.json with the sample samples/<id>.*.
+// Files starting with an underscore are templates.
+func loadCases() ([]evalCase, error) {
+	expectedFiles, err := filepath.Glob(filepath.Join(dataDir, "expected", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []evalCase
+	for _, expectedFile := range expectedFiles {
+		id := strings.TrimSuffix(filepath.Base(expectedFile), ".json")
+		if strings.HasPrefix(id, "_") {
+			continue
+		}
+
+		samples, err := filepath.Glob(filepath.Join(dataDir, "samples", id+".*"))
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) != 1 {
+			return nil, fmt.Errorf("expected one sample for %s, found %d", id, len(samples))
+		}
+
+		data, err := os.ReadFile(expectedFile)
+		if err != nil {
+			return nil, err
+		}
+		var expected extraction.ExtractedRecipe
+		if err := json.Unmarshal(data, &expected); err != nil {
+			return nil, fmt.Errorf("%s: %w", expectedFile, err)
+		}
+
+		cases = append(cases, evalCase{ID: id, Sample: samples[0], Expected: &expected})
+	}
+	return cases, nil
+}
+
+// The baseline maps "<prompt version>/<model>/<sample>" to the last accepted
+// overall score.
+func baselinePath() string { return filepath.Join(dataDir, "baseline.json") }
+
+func loadBaseline() (map[string]float64, error) {
+	baseline := make(map[string]float64)
+	data, err := os.ReadFile(baselinePath())
+	if os.IsNotExist(err) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", baselinePath(), err)
+	}
+	return baseline, nil
+}
+
+func saveBaseline(baseline map[string]float64) error {
+	for key, score := range baseline {
+		baseline[key] = math.Round(score*10000) / 10000
+	}
+
+	// json.Marshal sorts map keys, which keeps diffs of the file small.
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(baselinePath(), append(data, '\n'), 0o644)
+}
+
+// errUnhashedRecording marks recordings made before their request was
+// hashed, which can't be checked against the current request.
+var errUnhashedRecording = errors.New("recording has no request hash")
+
+// recordingTransport answers OpenRouter requests with the recorded response
+// at path. In live mode it sends them and records the response instead.
+//
+// Next to each response it stores a hash of the request body, so a recording
+// is only replayed for the request it was made for. A changed prompt, sample
+// or request option needs a new recording.
+type recordingTransport struct {
+	path string
+	live bool
+}
+
+func (rt *recordingTransport) hashPath() string {
+	return strings.TrimSuffix(rt.path, ".json") + ".request.sha256"
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	sum := sha256.Sum256(requestBody)
+	requestHash := hex.EncodeToString(sum[:])
+
+	if !rt.live {
+		recordedHash, err := os.ReadFile(rt.hashPath())
+		if os.IsNotExist(err) {
+			return nil, errUnhashedRecording
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request hash: %w", err)
+		}
+		if strings.TrimSpace(string(recordedHash)) != requestHash {
+			return nil, fmt.Errorf("recording %s was made for a different request, run with -live to re-record it", rt.path)
+		}
+
+		data, err := os.ReadFile(rt.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(data)),
+			Request:    req,
+		}, nil
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var formatted bytes.Buffer
+	if err := json.Indent(&formatted, body, "", "  "); err != nil {
+		formatted.Reset()
+		formatted.Write(body)
+	}
+	if err := os.MkdirAll(filepath.Dir(rt.path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(rt.path, formatted.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(rt.hashPath(), []byte(requestHash+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,169 @@
+// Package eval scores recipe extraction against hand-written ground truth.
+// Its tests run the extraction package's LLM client on the samples in
+// test/llm-extraction; see the README there.
+package eval
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+)
+
+// Score rates how close an extracted recipe is to the expected one. All
+// values are between 0 and 1.
+type Score struct {
+	Title        float64 `json:"title"`
+	Ingredients  float64 `json:"ingredients"`
+	Instructions float64 `json:"instructions"`
+	Metadata     float64 `json:"metadata"`
+	Overall      float64 `json:"overall"`
+}
+
+// Weights of the parts of a score in Score.Overall.
+const (
+	titleWeight        = 0.10
+	ingredientsWeight  = 0.35
+	instructionsWeight = 0.35
+	metadataWeight     = 0.20
+)
+
+// ScoreRecipe compares an extracted recipe with the expected one.
+func ScoreRecipe(expected, actual *extraction.ExtractedRecipe) Score {
+	score := Score{
+		Title:        scoreTitle(expected.Title, actual.Title),
+		Ingredients:  scoreListLength(expected.IngredientsMD, actual.IngredientsMD),
+		Instructions: scoreListLength(expected.InstructionsMD, actual.InstructionsMD),
+		Metadata:     scoreMetadata(expected, actual),
+	}
+	score.Overall = score.Title*titleWeight +
+		score.Ingredients*ingredientsWeight +
+		score.Instructions*instructionsWeight +
+		score.Metadata*metadataWeight
+	return score
+}
+
+func normalizeText(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// scoreTitle gives full marks for the same title, 0.8 if one title contains
+// the other and otherwise up to 0.7 for the share of expected words found.
+func scoreTitle(expected, actual string) float64 {
+	e, a := normalizeText(expected), normalizeText(actual)
+	if e == a {
+		return 1.0
+	}
+	if strings.Contains(e, a) || strings.Contains(a, e) {
+		return 0.8
+	}
+
+	expectedWords := strings.Fields(e)
+	if len(expectedWords) == 0 {
+		return 0.0
+	}
+	actualWords := make(map[string]bool)
+	for _, word := range strings.Fields(a) {
+		actualWords[word] = true
+	}
+	matches := 0
+	for _, word := range expectedWords {
+		if actualWords[word] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(expectedWords)) * 0.7
+}
+
+// countListItems counts the bullet and numbered list items of a markdown
+// list.
+func countListItems(md string) int {
+	count := 0
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			count++
+		case len(line) > 0 && line[0] >= '1' && line[0] <= '9' && strings.Contains(line, "."):
+			count++
+		}
+	}
+	return count
+}
+
+// scoreListLength compares the number of list items, as a rough measure of
+// whether all ingredients or steps were found. Extracting too many items is
+// penalized like extracting too few.
+func scoreListLength(expected, actual string) float64 {
+	expectedCount := countListItems(expected)
+	actualCount := countListItems(actual)
+
+	if expectedCount == 0 {
+		if actualCount == 0 {
+			return 1.0
+		}
+		return 0.5
+	}
+
+	ratio := float64(actualCount) / float64(expectedCount)
+	if ratio > 1.0 {
+		ratio = 1.0 / ratio
+	}
+	return ratio
+}
+
+// scoreMetadata averages the scores of the times and calories the expected
+// recipe has.
+func scoreMetadata(expected, actual *extraction.ExtractedRecipe) float64 {
+	var scores []float64
+	if expected.PrepTimeMinutes != nil {
+		scores = append(scores, scoreMinutes(*expected.PrepTimeMinutes, actual.PrepTimeMinutes))
+	}
+	if expected.CookTimeMinutes != nil {
+		scores = append(scores, scoreMinutes(*expected.CookTimeMinutes, actual.CookTimeMinutes))
+	}
+	if expected.CaloriesPerServing != nil {
+		scores = append(scores, scoreCalories(*expected.CaloriesPerServing, actual.CaloriesPerServing))
+	}
+
+	if len(scores) == 0 {
+		return 1.0
+	}
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+	}
+	return sum / float64(len(scores))
+}
+
+func scoreMinutes(expected int, actual *int) float64 {
+	if actual == nil {
+		return 0.0
+	}
+	switch diff := math.Abs(float64(expected - *actual)); {
+	case diff == 0:
+		return 1.0
+	case diff <= 5:
+		return 0.8
+	case diff <= 15:
+		return 0.5
+	default:
+		return 0.0
+	}
+}
+
+func scoreCalories(expected int, actual *int) float64 {
+	if actual == nil || expected == 0 {
+		return 0.0
+	}
+	switch ratio := float64(*actual) / float64(expected); {
+	case ratio >= 0.9 && ratio <= 1.1:
+		return 1.0
+	case ratio >= 0.7 && ratio <= 1.3:
+		return 0.7
+	case ratio >= 0.5 && ratio <= 1.5:
+		return 0.4
+	default:
+		return 0.0
+	}
+}
@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/mr-flannery/go-recipe-book/src/extraction"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestScoreTitle(t *testing.T) {
+	tests := []struct {
+		expected, actual string
+		want             float64
+	}{
+		{"Banh Mi", "banh mi ", 1.0},
+		{"Banh Mi", "Banh Mi (Vietnamese sandwich)", 0.8},
+		{"Carrot Pecan Cake", "Pecan Carrot Cake", 0.7},
+		{"Carrot Cake", "Chocolate Cake", 0.35},
+	}
+
+	for _, tt := range tests {
+		if got := scoreTitle(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("scoreTitle(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestScoreListLength(t *testing.T) {
+	expected := "- flour\n- sugar\n- eggs\n- butter"
+
+	if got := scoreListLength(expected, "- flour\n* sugar\n- eggs\n- butter"); got != 1.0 {
+		t.Errorf("same length: got %v, want 1", got)
+	}
+	if got := scoreListLength(expected, "- flour\n- sugar"); got != 0.5 {
+		t.Errorf("half the items: got %v, want 0.5", got)
+	}
+	if got := scoreListLength(expected, "1. a\n2. b\n3. c\n4. d\n5. e\n6. f\n7. g\n8. h"); got != 0.5 {
+		t.Errorf("twice the items: got %v, want 0.5", got)
+	}
+}
+
+func TestScoreRecipe(t *testing.T) {
+	expected := &extraction.ExtractedRecipe{
+		Title:           "Fanta-Muffins",
+		IngredientsMD:   "- 240 g Mehl\n- 1 Ei",
+		InstructionsMD:  "1. Mischen\n2. Backen",
+		CookTimeMinutes: intPtr(25),
+	}
+
+	if got := ScoreRecipe(expected, expected).Overall; got < 0.9999 {
+		t.Errorf("identical recipes: overall = %v, want 1", got)
+	}
+
+	actual := *expected
+	actual.CookTimeMinutes = intPtr(20)
+	actual.CaloriesPerServing = intPtr(300)
+	score := ScoreRecipe(expected, &actual)
+	if score.Metadata != 0.8 {
+		t.Errorf("metadata = %v, want 0.8 for a cook time 5 minutes off", score.Metadata)
+	}
+	if want := 1 - 0.2*metadataWeight; score.Overall < want-1e-9 || score.Overall > want+1e-9 {
+		t.Errorf("overall = %v, want %v", score.Overall, want)
+	}
+}